@@ -42,6 +42,7 @@ webhooks:
     - kongconsumers
     - kongplugins
     - kongclusterplugins
+    - kongingresses
   - apiGroups:
     - ''
     apiVersions:
@@ -60,6 +61,15 @@ webhooks:
     resources:
     - gateways
     - httproutes
+  - apiGroups:
+    - networking.k8s.io
+    apiVersions:
+    - 'v1'
+    operations:
+    - CREATE
+    - UPDATE
+    resources:
+    - ingresses
   clientConfig:
     service:
       namespace: kong
@@ -16,14 +16,16 @@ import (
 const (
 	outputFile = "../../internal/controllers/configuration/zz_generated_controllers.go"
 
-	corev1     = "k8s.io/api/core/v1"
-	netv1      = "k8s.io/api/networking/v1"
-	netv1beta1 = "k8s.io/api/networking/v1beta1"
-	extv1beta1 = "k8s.io/api/extensions/v1beta1"
+	corev1      = "k8s.io/api/core/v1"
+	discoveryv1 = "k8s.io/api/discovery/v1"
+	netv1       = "k8s.io/api/networking/v1"
+	netv1beta1  = "k8s.io/api/networking/v1beta1"
+	extv1beta1  = "k8s.io/api/extensions/v1beta1"
 
 	kongv1          = "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1"
 	kongv1beta1     = "github.com/kong/kubernetes-ingress-controller/v2/api/configuration/v1beta1"
 	knativev1alpha1 = "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	gatewayv1alpha2 = "sigs.k8s.io/gateway-api/apis/v1alpha2"
 )
 
 // inputControllersNeeded is a list of the supported Types for the
@@ -59,6 +61,20 @@ var inputControllersNeeded = &typesNeeded{
 		AcceptsIngressClassNameSpec:       false,
 		RBACVerbs:                         []string{"list", "watch"},
 	},
+	typeNeeded{
+		Group:                             "discovery.k8s.io",
+		Version:                           "v1",
+		Kind:                              "EndpointSlice",
+		PackageImportAlias:                "discoveryv1",
+		PackageAlias:                      "DiscoveryV1",
+		Package:                           discoveryv1,
+		Plural:                            "endpointslices",
+		CacheType:                         "EndpointSlice",
+		NeedsStatusPermissions:            false,
+		AcceptsIngressClassNameAnnotation: false,
+		AcceptsIngressClassNameSpec:       false,
+		RBACVerbs:                         []string{"list", "watch"},
+	},
 	typeNeeded{
 		Group:                             "\"\"",
 		Version:                           "v1",
@@ -86,6 +102,7 @@ var inputControllersNeeded = &typesNeeded{
 		CapableOfStatusUpdates:            true,
 		AcceptsIngressClassNameAnnotation: true,
 		AcceptsIngressClassNameSpec:       true,
+		WatchesBackendServices:            true,
 		RBACVerbs:                         []string{"get", "list", "watch"},
 	},
 	typeNeeded{
@@ -100,6 +117,7 @@ var inputControllersNeeded = &typesNeeded{
 		NeedsStatusPermissions:            false,
 		AcceptsIngressClassNameAnnotation: false,
 		AcceptsIngressClassNameSpec:       false,
+		FiltersByIngressClassController:   true,
 		RBACVerbs:                         []string{"get", "list", "watch"},
 	},
 	typeNeeded{
@@ -186,6 +204,7 @@ var inputControllersNeeded = &typesNeeded{
 		NeedsStatusPermissions:            true,
 		AcceptsIngressClassNameAnnotation: true,
 		AcceptsIngressClassNameSpec:       false,
+		WatchesCredentialSecrets:          true,
 		RBACVerbs:                         []string{"get", "list", "watch"},
 	},
 	typeNeeded{
@@ -233,6 +252,20 @@ var inputControllersNeeded = &typesNeeded{
 		AcceptsIngressClassNameSpec:       false,
 		RBACVerbs:                         []string{"get", "list", "watch"},
 	},
+	typeNeeded{
+		Group:                             "gateway.networking.k8s.io",
+		Version:                           "v1alpha2",
+		Kind:                              "ReferencePolicy",
+		PackageImportAlias:                "gatewayv1alpha2",
+		PackageAlias:                      "GatewayV1Alpha2",
+		Package:                           gatewayv1alpha2,
+		Plural:                            "referencepolicies",
+		CacheType:                         "ReferencePolicy",
+		NeedsStatusPermissions:            false,
+		AcceptsIngressClassNameAnnotation: false,
+		AcceptsIngressClassNameSpec:       false,
+		RBACVerbs:                         []string{"list", "watch"},
+	},
 }
 
 var inputRBACPermissionsNeeded = &rbacsNeeded{
@@ -348,6 +381,18 @@ type typeNeeded struct {
 	// an attribute in its specification named .IngressClassName
 	AcceptsIngressClassNameSpec bool
 
+	// FiltersByIngressClassController indicates that the object is an IngressClass and that the controller
+	// should only listen to IngressClasses handled by Kong (or to changes of the default class).
+	FiltersByIngressClassController bool
+
+	// WatchesCredentialSecrets indicates that the object references credential Secrets by name and that the
+	// controller should re-enqueue the object when one of those Secrets changes.
+	WatchesCredentialSecrets bool
+
+	// WatchesBackendServices indicates that the object references backend Services by name and that the
+	// controller should re-enqueue the object when one of those Services is created.
+	WatchesBackendServices bool
+
 	// NeedsStatusPermissions indicates whether permissions for the object should also include permissions to update
 	// its status
 	NeedsStatusPermissions bool
@@ -397,6 +442,7 @@ import (
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	extv1beta1 "k8s.io/api/extensions/v1beta1"
 	netv1 "k8s.io/api/networking/v1"
 	netv1beta1 "k8s.io/api/networking/v1beta1"
@@ -410,11 +456,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	ctrlutils "github.com/kong/kubernetes-ingress-controller/v2/internal/controllers/utils"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/kubernetes/object/status"
-	"github.com/kong/kubernetes-ingress-controller/v2/internal/util"
 	kongv1 "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1"
 	kongv1beta1 "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1beta1"
 )
@@ -444,6 +490,7 @@ type {{.PackageAlias}}{{.Kind}}Reconciler struct {
 
 	DataplaneAddressFinder *dataplane.AddressFinder
 	StatusQueue            *status.Queue
+	StatusUpdateRetries    int
 {{- end}}
 {{- if or .AcceptsIngressClassNameSpec .AcceptsIngressClassNameAnnotation}}
 
@@ -476,13 +523,55 @@ func (r *{{.PackageAlias}}{{.Kind}}Reconciler) SetupWithManager(mgr ctrl.Manager
 		}
 	}
 {{- end}}
+{{- if .WatchesCredentialSecrets}}
+	// re-enqueue objects when the credential Secrets they reference change
+	if err := mgr.GetFieldIndexer().IndexField(
+		context.Background(),
+		&{{.PackageImportAlias}}.{{.Kind}}{},
+		CredentialSecretsIndexKey,
+		index{{.Kind}}CredentialSecrets,
+	); err != nil {
+		return err
+	}
+	if err := c.Watch(
+		&source.Kind{Type: &corev1.Secret{}},
+		handler.EnqueueRequestsFromMapFunc(r.list{{.Kind}}sForCredentialSecret),
+	); err != nil {
+		return err
+	}
+{{- end}}
+{{- if .WatchesBackendServices}}
+	// re-enqueue objects when the backend Services they reference are created
+	if err := mgr.GetFieldIndexer().IndexField(
+		context.Background(),
+		&{{.PackageImportAlias}}.{{.Kind}}{},
+		BackendServicesIndexKey,
+		index{{.PackageAlias}}{{.Kind}}BackendServices,
+	); err != nil {
+		return err
+	}
+	if err := c.Watch(
+		&source.Kind{Type: &corev1.Service{}},
+		handler.EnqueueRequestsFromMapFunc(r.listObjectsForBackendService),
+		backendServiceCreatedPredicate,
+	); err != nil {
+		return err
+	}
+{{- end}}
 {{- if .AcceptsIngressClassNameAnnotation}}
-	preds := ctrlutils.GeneratePredicateFuncsForIngressClassFilter(r.IngressClassName, {{.AcceptsIngressClassNameSpec}}, true)
+	preds := ctrlutils.NewPredicateRegistry(r.IngressClassName).For(schema.GroupVersionKind{
+		Group:   "{{.Group}}",
+		Version: "{{.Version}}",
+		Kind:    "{{.Kind}}",
+	})
+{{- end}}
+{{- if .FiltersByIngressClassController}}
+	preds := ctrlutils.GeneratePredicateFuncsForIngressClass(ctrlutils.IngressClassKongController)
 {{- end}}
 	return c.Watch(
 		&source.Kind{Type: &{{.PackageImportAlias}}.{{.Kind}}{}},
 		&handler.EnqueueRequestForObject{},
-{{- if .AcceptsIngressClassNameAnnotation}}
+{{- if or .AcceptsIngressClassNameAnnotation .FiltersByIngressClassController}}
 		preds,
 {{- end}}
 	)
@@ -507,11 +596,12 @@ func (r *{{.PackageAlias}}{{.Kind}}Reconciler) Reconcile(ctx context.Context, re
 		}
 		return ctrl.Result{}, err
 	}
-	log.V(util.DebugLevel).Info("reconciling resource", "namespace", req.Namespace, "name", req.Name)
+	debugLog := ctrlutils.DebugLogger(log, obj)
+	debugLog.Info("reconciling resource", "namespace", req.Namespace, "name", req.Name)
 
 	// clean the object up if it's being deleted
 	if !obj.DeletionTimestamp.IsZero() && time.Now().After(obj.DeletionTimestamp.Time) {
-		log.V(util.DebugLevel).Info("resource is being deleted, its configuration will be removed", "type", "{{.Kind}}", "namespace", req.Namespace, "name", req.Name)
+		debugLog.Info("resource is being deleted, its configuration will be removed", "type", "{{.Kind}}", "namespace", req.Namespace, "name", req.Name)
 		objectExistsInCache, err := r.DataplaneClient.ObjectExists(obj)
 		if err != nil {
 			return ctrl.Result{}, err
@@ -527,7 +617,16 @@ func (r *{{.PackageAlias}}{{.Kind}}Reconciler) Reconcile(ctx context.Context, re
 {{if .AcceptsIngressClassNameAnnotation}}
 	// if the object is not configured with our ingress.class, then we need to ensure it's removed from the cache
 	if !ctrlutils.MatchesIngressClassName(obj, r.IngressClassName) {
-		log.V(util.DebugLevel).Info("object missing ingress class, ensuring it's removed from configuration", "namespace", req.Namespace, "name", req.Name)
+		objectExistsInCache, err := r.DataplaneClient.ObjectExists(obj)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if objectExistsInCache {
+			// the object was previously configured with our ingress class, but its class was changed
+			log.Info("object is no longer configured with our ingress class, removing its configuration", "namespace", req.Namespace, "name", req.Name)
+		} else {
+			debugLog.Info("object missing ingress class, ensuring it's removed from configuration", "namespace", req.Namespace, "name", req.Name)
+		}
 		return ctrl.Result{}, r.DataplaneClient.DeleteObject(obj)
 	}
 {{end}}
@@ -539,19 +638,19 @@ func (r *{{.PackageAlias}}{{.Kind}}Reconciler) Reconcile(ctx context.Context, re
 {{- if .CapableOfStatusUpdates}}
 	// if status updates are enabled report the status for the object
 	if r.DataplaneClient.AreKubernetesObjectReportsEnabled() {
-		log.V(util.DebugLevel).Info("determining whether data-plane configuration has succeeded", "namespace", req.Namespace, "name", req.Name)
+		debugLog.Info("determining whether data-plane configuration has succeeded", "namespace", req.Namespace, "name", req.Name)
 		if !r.DataplaneClient.KubernetesObjectIsConfigured(obj) {
-			log.V(util.DebugLevel).Error(fmt.Errorf("resource not yet configured in the data-plane"), "namespace", req.Namespace, "name", req.Name)
+			debugLog.Error(fmt.Errorf("resource not yet configured in the data-plane"), "namespace", req.Namespace, "name", req.Name)
 			return ctrl.Result{Requeue: true}, nil // requeue until the object has been properly configured
 		}
 
-		log.V(util.DebugLevel).Info("determining gateway addresses for object status updates", "namespace", req.Namespace, "name", req.Name)
-		addrs, err := r.DataplaneAddressFinder.GetLoadBalancerAddresses()
+		debugLog.Info("determining gateway addresses for object status updates", "namespace", req.Namespace, "name", req.Name)
+		addrs, err := r.DataplaneAddressFinder.GetLoadBalancerAddressesForObject(obj)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
 
-		log.V(util.DebugLevel).Info("found addresses for data-plane updating object status", "namespace", req.Namespace, "name", req.Name)
+		debugLog.Info("found addresses for data-plane updating object status", "namespace", req.Namespace, "name", req.Name)
 {{- if eq .Group "networking.internal.knative.dev"}}
 		var knativeLBIngress []knativev1alpha1.LoadBalancerIngressStatus
 		for _, addr := range addrs {
@@ -563,17 +662,20 @@ func (r *{{.PackageAlias}}{{.Kind}}Reconciler) Reconcile(ctx context.Context, re
 		}
 		ingressCondSet := knativeApis.NewLivingConditionSet()
 		if obj.Status.PublicLoadBalancer == nil || len(obj.Status.PublicLoadBalancer.Ingress) != len(addrs) || !reflect.DeepEqual(obj.Status.PublicLoadBalancer.Ingress, knativeLBIngress) {
-			obj.Status.MarkLoadBalancerReady(knativeLBIngress, knativeLBIngress)
-			ingressCondSet.Manage(&obj.Status).MarkTrue(knativev1alpha1.IngressConditionReady)
-			ingressCondSet.Manage(&obj.Status).MarkTrue(knativev1alpha1.IngressConditionNetworkConfigured)
-			obj.Status.ObservedGeneration = obj.Generation
+			return ctrl.Result{}, ctrlutils.UpdateStatusWithRetry(ctx, r.Client, obj, r.StatusUpdateRetries, func() {
+				obj.Status.MarkLoadBalancerReady(knativeLBIngress, knativeLBIngress)
+				ingressCondSet.Manage(&obj.Status).MarkTrue(knativev1alpha1.IngressConditionReady)
+				ingressCondSet.Manage(&obj.Status).MarkTrue(knativev1alpha1.IngressConditionNetworkConfigured)
+				obj.Status.ObservedGeneration = obj.Generation
+			})
 {{- else}}
 		if len(obj.Status.LoadBalancer.Ingress) != len(addrs) || !reflect.DeepEqual(obj.Status.LoadBalancer.Ingress, addrs) {
-			obj.Status.LoadBalancer.Ingress = addrs
+			return ctrl.Result{}, ctrlutils.UpdateStatusWithRetry(ctx, r.Client, obj, r.StatusUpdateRetries, func() {
+				obj.Status.LoadBalancer.Ingress = addrs
+			})
 {{- end}}
-			return ctrl.Result{}, r.Status().Update(ctx, obj)
 		} else {
-			log.V(util.DebugLevel).Info("status update not needed", "namespace", req.Namespace, "name", req.Name)
+			debugLog.Info("status update not needed", "namespace", req.Namespace, "name", req.Name)
 		}
 	}
 {{- end}}
@@ -34,6 +34,8 @@ type HTTPClientOpts struct {
 	TLSClientKeyPath string
 	// mTLS client key for authentication.
 	TLSClientKey string
+	// Policy for retrying Admin API calls while Kong is unavailable.
+	RetryPolicy RetryPolicy
 }
 
 // MakeHTTPClient returns an HTTP client with the specified mTLS/headers configuration.
@@ -128,10 +130,14 @@ func MakeHTTPClient(opts *HTTPClientOpts) (*http.Client, error) {
 
 	transport := http.DefaultTransport.(*http.Transport).Clone()
 	transport.TLSClientConfig = &tlsConfig
+	var rt http.RoundTripper = transport
+	if opts.RetryPolicy.MaxRetries > 0 {
+		rt = NewRetryRoundTripper(opts.RetryPolicy, transport)
+	}
 	return &http.Client{
 		Transport: &HeaderRoundTripper{
 			headers: opts.Headers,
-			rt:      transport,
+			rt:      rt,
 		},
 	}, nil
 }
@@ -0,0 +1,134 @@
+package adminapi
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures how requests to the Kong Admin API are retried when
+// Kong is unavailable. Delays grow exponentially from BaseDelay by Multiplier,
+// are capped at MaxDelay and are then randomized by Jitter so that multiple
+// controller replicas don't retry in lockstep.
+type RetryPolicy struct {
+	// Maximum number of times a request is retried. 0 disables retries.
+	MaxRetries int
+	// Delay before the first retry.
+	BaseDelay time.Duration
+	// Upper bound of the delay between two attempts.
+	MaxDelay time.Duration
+	// Factor by which the delay grows after each retry.
+	Multiplier float64
+	// Fraction (between 0 and 1) of each delay which is randomized. 1 is full
+	// jitter, i.e. delays are picked uniformly between 0 and the capped delay.
+	Jitter float64
+}
+
+// DefaultRetryPolicy returns a RetryPolicy using full jitter which can be used
+// as a starting point for configuring retries.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 5,
+		BaseDelay:  100 * time.Millisecond,
+		MaxDelay:   5 * time.Second,
+		Multiplier: 2,
+		Jitter:     1,
+	}
+}
+
+// Delay returns the delay before the provided retry (starting at 0), using
+// random, which returns values in [0, 1), to apply the jitter.
+func (p RetryPolicy) Delay(retry int, random func() float64) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(p.BaseDelay) * math.Pow(multiplier, float64(retry))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	jitter := math.Min(math.Max(p.Jitter, 0), 1)
+	return time.Duration(delay * (1 - jitter*random()))
+}
+
+// RetryRoundTripper retries requests which failed because the Kong Admin API
+// could not be reached or was temporarily unavailable, according to a
+// RetryPolicy.
+type RetryRoundTripper struct {
+	policy RetryPolicy
+	rt     http.RoundTripper
+
+	random func() float64
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetryRoundTripper wraps the provided RoundTripper to retry requests
+// according to the provided RetryPolicy.
+func NewRetryRoundTripper(policy RetryPolicy, rt http.RoundTripper) *RetryRoundTripper {
+	return &RetryRoundTripper{
+		policy: policy,
+		rt:     rt,
+		random: rand.Float64, //nolint:gosec
+		sleep:  sleepContext,
+	}
+}
+
+// RoundTrip satisfies the RoundTripper interface.
+func (t *RetryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	for retry := 0; ; retry++ {
+		resp, err := t.rt.RoundTrip(req)
+		if retry >= t.policy.MaxRetries || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		// the body of a response which is going to be retried is not handed to
+		// the caller, so it must be closed here.
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if err := t.sleep(req.Context(), t.policy.Delay(retry, t.random)); err != nil {
+			return nil, err
+		}
+		if req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// shouldRetry indicates whether a request which resulted in the provided
+// response or error should be retried. Requests are only retried if they can
+// be sent again (i.e. their body can be re-read) and either failed to reach
+// Kong or were answered with a status indicating that Kong is unavailable.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,173 @@
+package adminapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingRoundTripper fails the first failures requests, either with an error
+// or with the configured status, and then responds with 200 OK. It records the
+// bodies of the requests it receives.
+type failingRoundTripper struct {
+	failures int
+	status   int
+	attempts int
+	bodies   []string
+}
+
+func (f *failingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.attempts++
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		f.bodies = append(f.bodies, string(body))
+	}
+	if f.attempts <= f.failures {
+		if f.status != 0 {
+			return &http.Response{StatusCode: f.status, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+		return nil, errors.New("connection refused")
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+}
+
+func newTestRetryRoundTripper(policy RetryPolicy, rt http.RoundTripper, random float64) (*RetryRoundTripper, *[]time.Duration) {
+	delays := []time.Duration{}
+	t := NewRetryRoundTripper(policy, rt)
+	t.random = func() float64 { return random }
+	t.sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	return t, &delays
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{
+		BaseDelay:  100 * time.Millisecond,
+		MaxDelay:   time.Second,
+		Multiplier: 2,
+	}
+
+	t.Log("verifying that delays grow exponentially up to the maximum delay without jitter")
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	for retry, want := range expected {
+		assert.Equal(t, want, policy.Delay(retry, func() float64 { return 0.5 }))
+	}
+
+	t.Log("verifying that jittered delays stay between the unjittered delay reduced by the jitter fraction and the unjittered delay")
+	policy.Jitter = 0.5
+	for retry, want := range expected {
+		for _, random := range []float64{0, 0.25, 0.5, 0.999} {
+			delay := policy.Delay(retry, func() float64 { return random })
+			assert.GreaterOrEqual(t, delay, want/2)
+			assert.LessOrEqual(t, delay, want)
+		}
+	}
+
+	t.Log("verifying that full jitter spreads delays between 0 and the unjittered delay")
+	policy.Jitter = 1
+	assert.Equal(t, time.Second, policy.Delay(10, func() float64 { return 0 }))
+	assert.Equal(t, 250*time.Millisecond, policy.Delay(10, func() float64 { return 0.75 }))
+	assert.Equal(t, time.Duration(0), policy.Delay(0, func() float64 { return 1 }))
+}
+
+func TestRetryRoundTripper(t *testing.T) {
+	policy := RetryPolicy{
+		MaxRetries: 5,
+		BaseDelay:  100 * time.Millisecond,
+		MaxDelay:   time.Second,
+		Multiplier: 2,
+		Jitter:     1,
+	}
+
+	t.Run("retries until the request succeeds", func(t *testing.T) {
+		fake := &failingRoundTripper{failures: 4}
+		rt, delays := newTestRetryRoundTripper(policy, fake, 0.5)
+		req, err := http.NewRequest(http.MethodPost, "http://kong:8001/config", strings.NewReader("config"))
+		require.NoError(t, err)
+
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"config", "config", "config", "config", "config"}, fake.bodies,
+			"the request body should be replayed on every attempt")
+
+		bounds := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond}
+		require.Len(t, *delays, len(bounds))
+		for i, delay := range *delays {
+			assert.GreaterOrEqual(t, delay, time.Duration(0))
+			assert.LessOrEqual(t, delay, bounds[i])
+		}
+	})
+
+	t.Run("retries responses indicating that kong is unavailable", func(t *testing.T) {
+		fake := &failingRoundTripper{failures: 2, status: http.StatusServiceUnavailable}
+		rt, delays := newTestRetryRoundTripper(policy, fake, 0)
+		req, err := http.NewRequest(http.MethodGet, "http://kong:8001/", nil)
+		require.NoError(t, err)
+
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, *delays)
+	})
+
+	t.Run("gives up after the maximum number of retries", func(t *testing.T) {
+		fake := &failingRoundTripper{failures: 10}
+		rt, delays := newTestRetryRoundTripper(policy, fake, 0)
+		req, err := http.NewRequest(http.MethodGet, "http://kong:8001/", nil)
+		require.NoError(t, err)
+
+		_, err = rt.RoundTrip(req)
+		require.Error(t, err)
+		assert.Equal(t, []time.Duration{
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+			800 * time.Millisecond,
+			time.Second,
+		}, *delays)
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		fake := &failingRoundTripper{failures: 1, status: http.StatusBadRequest}
+		rt, delays := newTestRetryRoundTripper(policy, fake, 0)
+		req, err := http.NewRequest(http.MethodGet, "http://kong:8001/", nil)
+		require.NoError(t, err)
+
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Empty(t, *delays)
+	})
+
+	t.Run("stops retrying when the request context is done", func(t *testing.T) {
+		fake := &failingRoundTripper{failures: 10}
+		rt := NewRetryRoundTripper(policy, fake)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://kong:8001/", nil)
+		require.NoError(t, err)
+
+		_, err = rt.RoundTrip(req)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,58 @@
+package adminapi
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// WorkspaceResolver maps Kubernetes namespaces to the Kong Enterprise workspaces
+// the configuration generated from their objects is synced to.
+type WorkspaceResolver struct {
+	// Reader is used to read the workspace annotation of namespaces. It may be
+	// nil if namespaces are only mapped statically.
+	Reader client.Reader
+	// Annotation is the namespace annotation holding the name of the workspace
+	// of the namespace. Namespace annotations are ignored if it's empty.
+	Annotation string
+	// Static maps namespace names to workspace names. Static mappings take
+	// precedence over namespace annotations.
+	Static map[string]string
+	// Default is the workspace of namespaces without a workspace. An empty
+	// Default is the workspace of the Admin API client, as when workspaces are
+	// not resolved per namespace.
+	Default string
+}
+
+// Resolve returns the workspace of the provided namespace: its static mapping
+// if there is one, otherwise the value of its workspace annotation if set,
+// otherwise the default workspace. Cluster-scoped objects (with an empty
+// namespace) and namespaces which don't exist belong to the default workspace.
+func (r *WorkspaceResolver) Resolve(ctx context.Context, namespace string) (string, error) {
+	if namespace == "" {
+		return r.Default, nil
+	}
+	if workspace, ok := r.Static[namespace]; ok && workspace != "" {
+		return workspace, nil
+	}
+	if r.Reader == nil || r.Annotation == "" {
+		return r.Default, nil
+	}
+
+	ns := &corev1.Namespace{}
+	if err := r.Reader.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.Default, nil
+		}
+		return "", fmt.Errorf("failed to get namespace %s to resolve its workspace: %w", namespace, err)
+	}
+	if workspace := ns.Annotations[r.Annotation]; workspace != "" {
+		return workspace, nil
+	}
+	return r.Default, nil
+}
@@ -0,0 +1,63 @@
+package adminapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWorkspaceResolver(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	namespace := func(name string, anns map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: anns}}
+	}
+	const annotation = "konghq.com/workspace"
+
+	resolver := &WorkspaceResolver{
+		Reader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			namespace("team-a", map[string]string{annotation: "workspace-a"}),
+			namespace("team-b", map[string]string{annotation: "workspace-b"}),
+			namespace("plain", nil),
+		).Build(),
+		Annotation: annotation,
+		Static:     map[string]string{"team-b": "static-b", "team-c": "static-c"},
+	}
+
+	for _, tt := range []struct {
+		namespace string
+		expected  string
+	}{
+		{namespace: "team-a", expected: "workspace-a"},
+		{namespace: "team-b", expected: "static-b"},
+		{namespace: "team-c", expected: "static-c"},
+		{namespace: "plain", expected: ""},
+		{namespace: "missing", expected: ""},
+		{namespace: "", expected: ""},
+	} {
+		workspace, err := resolver.Resolve(ctx, tt.namespace)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, workspace, "namespace %q", tt.namespace)
+	}
+
+	t.Log("verifying that namespaces without a workspace fall back to the configured default")
+	resolver.Default = "default-ws"
+	for _, ns := range []string{"plain", "missing", ""} {
+		workspace, err := resolver.Resolve(ctx, ns)
+		require.NoError(t, err)
+		assert.Equal(t, "default-ws", workspace)
+	}
+
+	t.Log("verifying that namespace annotations are ignored without an annotation key")
+	resolver.Annotation = ""
+	workspace, err := resolver.Resolve(ctx, "team-a")
+	require.NoError(t, err)
+	assert.Equal(t, "default-ws", workspace)
+}
@@ -19,3 +19,20 @@ const (
 	ErrTextCantRetrieveGatewayClass    = "gatewayclass for this gateway could not be retrieved"
 	ErrTextInvalidGatewayConfiguration = "gateway metadata and/or spec are invalid"
 )
+
+const (
+	ErrTextIngressHostInvalid      = "ingress host %q is not a valid DNS name: %s"
+	ErrTextIngressHostPathConflict = "ingress %s/%s declares host and path pairs already declared by other ingresses: %s"
+	ErrTextIngressesUnretrievable  = "could not retrieve ingresses from the kubernetes API"
+)
+
+const (
+	ErrTextKongIngressHashOnRequired             = "upstream algorithm consistent-hashing requires hash_on to be set"
+	ErrTextKongIngressHashOnHeaderRequired       = "upstream hash_on header requires hash_on_header to be set"
+	ErrTextKongIngressHashOnCookieRequired       = "upstream hash_on cookie requires hash_on_cookie to be set"
+	ErrTextKongIngressHashFallbackHeaderRequired = "upstream hash_fallback header requires hash_fallback_header to be set"
+	ErrTextKongIngressHashFallbackWithCookie     = "upstream hash_fallback must be none when hash_on is cookie"
+	ErrTextKongIngressHashFallbackSameAsHashOn   = "upstream hash_fallback cannot be the same as hash_on"
+	ErrTextKongIngressProxyPathWithGRPC          = "proxy path cannot be set when the proxy protocol is %s"
+	ErrTextKongIngressRouteStripPathWithGRPC     = "route strip_path cannot be enabled with the %s protocol"
+)
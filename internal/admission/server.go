@@ -11,6 +11,7 @@ import (
 	"github.com/sirupsen/logrus"
 	admission "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
@@ -170,6 +171,11 @@ var (
 		Version:  configuration.SchemeGroupVersion.Version,
 		Resource: "kongclusterplugins",
 	}
+	kongIngressGVResource = meta.GroupVersionResource{
+		Group:    configuration.SchemeGroupVersion.Group,
+		Version:  configuration.SchemeGroupVersion.Version,
+		Resource: "kongingresses",
+	}
 	secretGVResource = meta.GroupVersionResource{
 		Group:    corev1.SchemeGroupVersion.Group,
 		Version:  corev1.SchemeGroupVersion.Version,
@@ -185,6 +191,11 @@ var (
 		Version:  gatewayv1alpha2.SchemeGroupVersion.Version,
 		Resource: "httproutes",
 	}
+	ingressGVResource = meta.GroupVersionResource{
+		Group:    netv1.SchemeGroupVersion.Group,
+		Version:  netv1.SchemeGroupVersion.Version,
+		Resource: "ingresses",
+	}
 )
 
 func (a RequestHandler) handleValidation(ctx context.Context, request admission.AdmissionRequest) (
@@ -257,6 +268,17 @@ func (a RequestHandler) handleValidation(ctx context.Context, request admission.
 		if err != nil {
 			return nil, err
 		}
+	case kongIngressGVResource:
+		kongIngress := configuration.KongIngress{}
+		deserializer := codecs.UniversalDeserializer()
+		_, _, err = deserializer.Decode(request.Object.Raw, nil, &kongIngress)
+		if err != nil {
+			return nil, err
+		}
+		ok, message, err = a.Validator.ValidateKongIngress(ctx, kongIngress)
+		if err != nil {
+			return nil, err
+		}
 	case secretGVResource:
 		secret := corev1.Secret{}
 		deserializer := codecs.UniversalDeserializer()
@@ -306,6 +328,17 @@ func (a RequestHandler) handleValidation(ctx context.Context, request admission.
 		if err != nil {
 			return nil, err
 		}
+	case ingressGVResource:
+		ingress := netv1.Ingress{}
+		deserializer := codecs.UniversalDeserializer()
+		_, _, err = deserializer.Decode(request.Object.Raw, nil, &ingress)
+		if err != nil {
+			return nil, err
+		}
+		ok, message, err = a.Validator.ValidateIngress(ctx, ingress)
+		if err != nil {
+			return nil, err
+		}
 	default:
 		return nil, fmt.Errorf("unknown resource type to validate: %s/%s %s",
 			request.Resource.Group, request.Resource.Version,
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/lithammer/dedent"
@@ -14,9 +15,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	admission "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
 	configuration "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1"
 )
 
@@ -55,6 +59,14 @@ func (v KongFakeValidator) ValidateHTTPRoute(ctx context.Context, gateway gatewa
 	return v.Result, v.Message, v.Error
 }
 
+func (v KongFakeValidator) ValidateIngress(ctx context.Context, ingress netv1.Ingress) (bool, string, error) {
+	return v.Result, v.Message, v.Error
+}
+
+func (v KongFakeValidator) ValidateKongIngress(ctx context.Context, kongIngress configuration.KongIngress) (bool, string, error) {
+	return v.Result, v.Message, v.Error
+}
+
 func TestServeHTTPBasic(t *testing.T) {
 	assert := assert.New(t)
 	res := httptest.NewRecorder()
@@ -324,6 +336,260 @@ func TestValidationWebhook(t *testing.T) {
 					Result:  &metav1.Status{},
 				},
 			},
+			{
+				name: "validate ingress with a valid host",
+				reqBody: dedent.Dedent(`
+					{
+						"kind": "AdmissionReview",
+						"apiVersion": "` + apiVersion + `",
+						"request": {
+							"uid": "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+							"resource": {
+								"group": "networking.k8s.io",
+								"version": "v1",
+								"resource": "ingresses"
+							},
+							"object": {
+								"apiVersion": "networking.k8s.io/v1",
+								"kind": "Ingress",
+								"spec": {
+									"rules": [{"host": "foo.example.com"}]
+								}
+							},
+						"operation": "CREATE"
+						}
+					}`),
+				validator:    NewKongHTTPValidator(nil, nil, logrus.New(), nil, annotations.DefaultIngressClass),
+				wantRespCode: http.StatusOK,
+				wantSuccessResponse: admission.AdmissionResponse{
+					UID:     "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+					Allowed: true,
+					Result:  &metav1.Status{},
+				},
+			},
+			{
+				name: "validate ingress with a wildcard host",
+				reqBody: dedent.Dedent(`
+					{
+						"kind": "AdmissionReview",
+						"apiVersion": "` + apiVersion + `",
+						"request": {
+							"uid": "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+							"resource": {
+								"group": "networking.k8s.io",
+								"version": "v1",
+								"resource": "ingresses"
+							},
+							"object": {
+								"apiVersion": "networking.k8s.io/v1",
+								"kind": "Ingress",
+								"spec": {
+									"rules": [{"host": "*.example.com"}]
+								}
+							},
+						"operation": "CREATE"
+						}
+					}`),
+				validator:    NewKongHTTPValidator(nil, nil, logrus.New(), nil, annotations.DefaultIngressClass),
+				wantRespCode: http.StatusOK,
+				wantSuccessResponse: admission.AdmissionResponse{
+					UID:     "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+					Allowed: true,
+					Result:  &metav1.Status{},
+				},
+			},
+			{
+				name: "validate ingress with an invalid host",
+				reqBody: dedent.Dedent(`
+					{
+						"kind": "AdmissionReview",
+						"apiVersion": "` + apiVersion + `",
+						"request": {
+							"uid": "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+							"resource": {
+								"group": "networking.k8s.io",
+								"version": "v1",
+								"resource": "ingresses"
+							},
+							"object": {
+								"apiVersion": "networking.k8s.io/v1",
+								"kind": "Ingress",
+								"spec": {
+									"rules": [{"host": "foo bar.example.com"}]
+								}
+							},
+						"operation": "CREATE"
+						}
+					}`),
+				validator:    NewKongHTTPValidator(nil, nil, logrus.New(), nil, annotations.DefaultIngressClass),
+				wantRespCode: http.StatusOK,
+				wantSuccessResponse: admission.AdmissionResponse{
+					UID:     "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+					Allowed: false,
+					Result: &metav1.Status{
+						Code: http.StatusBadRequest,
+						Message: fmt.Sprintf(ErrTextIngressHostInvalid, "foo bar.example.com",
+							strings.Join(utilvalidation.IsDNS1123Subdomain("foo bar.example.com"), ", ")),
+					},
+				},
+			},
+			{
+				name: "validate coherent kong ingress",
+				reqBody: dedent.Dedent(`
+					{
+						"kind": "AdmissionReview",
+						"apiVersion": "` + apiVersion + `",
+						"request": {
+							"uid": "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+							"resource": {
+								"group": "configuration.konghq.com",
+								"version": "v1",
+								"resource": "kongingresses"
+							},
+							"object": {
+								"apiVersion": "configuration.konghq.com/v1",
+								"kind": "KongIngress",
+								"upstream": {"algorithm": "consistent-hashing", "hash_on": "header", "hash_on_header": "x-user"}
+							},
+						"operation": "CREATE"
+						}
+					}`),
+				validator:    NewKongHTTPValidator(nil, nil, logrus.New(), nil, annotations.DefaultIngressClass),
+				wantRespCode: http.StatusOK,
+				wantSuccessResponse: admission.AdmissionResponse{
+					UID:     "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+					Allowed: true,
+					Result:  &metav1.Status{},
+				},
+			},
+			{
+				name: "validate kong ingress with consistent-hashing and no hash_on",
+				reqBody: dedent.Dedent(`
+					{
+						"kind": "AdmissionReview",
+						"apiVersion": "` + apiVersion + `",
+						"request": {
+							"uid": "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+							"resource": {
+								"group": "configuration.konghq.com",
+								"version": "v1",
+								"resource": "kongingresses"
+							},
+							"object": {
+								"apiVersion": "configuration.konghq.com/v1",
+								"kind": "KongIngress",
+								"upstream": {"algorithm": "consistent-hashing"}
+							},
+						"operation": "CREATE"
+						}
+					}`),
+				validator:    NewKongHTTPValidator(nil, nil, logrus.New(), nil, annotations.DefaultIngressClass),
+				wantRespCode: http.StatusOK,
+				wantSuccessResponse: admission.AdmissionResponse{
+					UID:     "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+					Allowed: false,
+					Result: &metav1.Status{
+						Code:    http.StatusBadRequest,
+						Message: ErrTextKongIngressHashOnRequired,
+					},
+				},
+			},
+			{
+				name: "validate kong ingress with hash_on header and no hash_on_header",
+				reqBody: dedent.Dedent(`
+					{
+						"kind": "AdmissionReview",
+						"apiVersion": "` + apiVersion + `",
+						"request": {
+							"uid": "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+							"resource": {
+								"group": "configuration.konghq.com",
+								"version": "v1",
+								"resource": "kongingresses"
+							},
+							"object": {
+								"apiVersion": "configuration.konghq.com/v1",
+								"kind": "KongIngress",
+								"upstream": {"hash_on": "header"}
+							},
+						"operation": "CREATE"
+						}
+					}`),
+				validator:    NewKongHTTPValidator(nil, nil, logrus.New(), nil, annotations.DefaultIngressClass),
+				wantRespCode: http.StatusOK,
+				wantSuccessResponse: admission.AdmissionResponse{
+					UID:     "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+					Allowed: false,
+					Result: &metav1.Status{
+						Code:    http.StatusBadRequest,
+						Message: ErrTextKongIngressHashOnHeaderRequired,
+					},
+				},
+			},
+			{
+				name: "validate kong ingress with a grpc proxy and a path",
+				reqBody: dedent.Dedent(`
+					{
+						"kind": "AdmissionReview",
+						"apiVersion": "` + apiVersion + `",
+						"request": {
+							"uid": "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+							"resource": {
+								"group": "configuration.konghq.com",
+								"version": "v1",
+								"resource": "kongingresses"
+							},
+							"object": {
+								"apiVersion": "configuration.konghq.com/v1",
+								"kind": "KongIngress",
+								"proxy": {"protocol": "grpc", "path": "/foo"}
+							},
+						"operation": "CREATE"
+						}
+					}`),
+				validator:    NewKongHTTPValidator(nil, nil, logrus.New(), nil, annotations.DefaultIngressClass),
+				wantRespCode: http.StatusOK,
+				wantSuccessResponse: admission.AdmissionResponse{
+					UID:     "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+					Allowed: false,
+					Result: &metav1.Status{
+						Code:    http.StatusBadRequest,
+						Message: fmt.Sprintf(ErrTextKongIngressProxyPathWithGRPC, "grpc"),
+					},
+				},
+			},
+			{
+				name: "validate kong ingress with a grpcs route stripping the path",
+				reqBody: dedent.Dedent(`
+					{
+						"kind": "AdmissionReview",
+						"apiVersion": "` + apiVersion + `",
+						"request": {
+							"uid": "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+							"resource": {
+								"group": "configuration.konghq.com",
+								"version": "v1",
+								"resource": "kongingresses"
+							},
+							"object": {
+								"apiVersion": "configuration.konghq.com/v1",
+								"kind": "KongIngress",
+								"route": {"protocols": ["grpcs"], "strip_path": true}
+							},
+						"operation": "CREATE"
+						}
+					}`),
+				validator:    NewKongHTTPValidator(nil, nil, logrus.New(), nil, annotations.DefaultIngressClass),
+				wantRespCode: http.StatusOK,
+				wantSuccessResponse: admission.AdmissionResponse{
+					UID:     "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+					Allowed: false,
+					Result: &metav1.Status{
+						Code:    http.StatusBadRequest,
+						Message: fmt.Sprintf(ErrTextKongIngressRouteStripPathWithGRPC, "grpcs"),
+					},
+				},
+			},
 		} {
 			t.Run(fmt.Sprintf("%s/%s", apiVersion, tt.name), func(t *testing.T) {
 				// arrange
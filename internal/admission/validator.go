@@ -8,8 +8,10 @@ import (
 	"github.com/kong/go-kong/kong"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
@@ -18,6 +20,7 @@ import (
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/kongstate"
 	credsvalidation "github.com/kong/kubernetes-ingress-controller/v2/internal/validation/consumers/credentials"
 	gatewayvalidators "github.com/kong/kubernetes-ingress-controller/v2/internal/validation/gateway"
+	ingressvalidation "github.com/kong/kubernetes-ingress-controller/v2/internal/validation/ingress"
 	kongv1 "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1"
 )
 
@@ -29,6 +32,8 @@ type KongValidator interface {
 	ValidateCredential(ctx context.Context, secret corev1.Secret) (bool, string, error)
 	ValidateGateway(ctx context.Context, gateway gatewayv1alpha2.Gateway) (bool, string, error)
 	ValidateHTTPRoute(ctx context.Context, httproute gatewayv1alpha2.HTTPRoute) (bool, string, error)
+	ValidateIngress(ctx context.Context, ingress netv1.Ingress) (bool, string, error)
+	ValidateKongIngress(ctx context.Context, kongIngress kongv1.KongIngress) (bool, string, error)
 }
 
 // KongHTTPValidator implements KongValidator interface to validate Kong
@@ -40,7 +45,8 @@ type KongHTTPValidator struct {
 	SecretGetter  kongstate.SecretGetter
 	ManagerClient client.Client
 
-	ingressClassMatcher func(*metav1.ObjectMeta, annotations.ClassMatching) bool
+	ingressClassMatcher   func(*metav1.ObjectMeta, annotations.ClassMatching) bool
+	ingressV1ClassMatcher func(*netv1.Ingress, annotations.ClassMatching) bool
 }
 
 // NewKongHTTPValidator provides a new KongHTTPValidator object provided a
@@ -62,7 +68,8 @@ func NewKongHTTPValidator(
 		SecretGetter:  &managerClientSecretGetter{managerClient: managerClient},
 		ManagerClient: managerClient,
 
-		ingressClassMatcher: matcher,
+		ingressClassMatcher:   matcher,
+		ingressV1ClassMatcher: annotations.IngressClassValidatorFuncFromV1Ingress(ingressClass),
 	}
 }
 
@@ -365,6 +372,104 @@ func (validator KongHTTPValidator) ValidateHTTPRoute(
 	return gatewayvalidators.ValidateHTTPRoute(&httproute, managedGateways...)
 }
 
+// ValidateIngress checks that the hosts of the rules of an Ingress are valid
+// DNS names (optionally prefixed with a wildcard label) and that no host and
+// path pair is declared more than once, neither by the Ingress itself nor by
+// another Ingress of the same ingress class. Ingresses which explicitly belong
+// to another ingress class are not validated. Other Ingresses are only
+// checked if the validator has a ManagerClient.
+func (validator KongHTTPValidator) ValidateIngress(
+	ctx context.Context, ingress netv1.Ingress,
+) (bool, string, error) {
+	if !validator.isManagedIngress(&ingress) {
+		return true, "", nil
+	}
+
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host == "" {
+			continue
+		}
+		var errs []string
+		if strings.HasPrefix(rule.Host, "*.") {
+			errs = utilvalidation.IsWildcardDNS1123Subdomain(rule.Host)
+		} else {
+			errs = utilvalidation.IsDNS1123Subdomain(rule.Host)
+		}
+		if len(errs) > 0 {
+			return false, fmt.Sprintf(ErrTextIngressHostInvalid, rule.Host, strings.Join(errs, ", ")), nil
+		}
+	}
+
+	if _, _, err := annotations.ExtractHealthcheckActiveConcurrency(ingress.Namespace, ingress.Name, ingress.Annotations); err != nil {
+		return false, err.Error(), nil
+	}
+
+	if err := ingressvalidation.ValidateIngressHostUniqueness(&ingress); err != nil {
+		return false, err.Error(), nil
+	}
+
+	if validator.ManagerClient != nil {
+		conflicts, err := ingressvalidation.FindIngressHostPathConflicts(ctx, validator.ManagerClient, &ingress, validator.isManagedIngress)
+		if err != nil {
+			return false, ErrTextIngressesUnretrievable, err
+		}
+		if len(conflicts) > 0 {
+			return false, fmt.Sprintf(ErrTextIngressHostPathConflict, ingress.Namespace, ingress.Name, strings.Join(conflicts, ", ")), nil
+		}
+	}
+
+	return true, "", nil
+}
+
+// ValidateKongIngress checks that the upstream, proxy and route overrides of a
+// KongIngress are coherent with each other, rejecting combinations which Kong
+// would otherwise only reject when the configuration is synced.
+func (validator KongHTTPValidator) ValidateKongIngress(
+	_ context.Context, kongIngress kongv1.KongIngress,
+) (bool, string, error) {
+	if upstream := kongIngress.Upstream; upstream != nil {
+		hashOn := stringValue(upstream.HashOn)
+		hashFallback := stringValue(upstream.HashFallback)
+
+		if stringValue(upstream.Algorithm) == "consistent-hashing" && (hashOn == "" || hashOn == "none") {
+			return false, ErrTextKongIngressHashOnRequired, nil
+		}
+		if hashOn == "header" && stringValue(upstream.HashOnHeader) == "" {
+			return false, ErrTextKongIngressHashOnHeaderRequired, nil
+		}
+		if hashOn == "cookie" && stringValue(upstream.HashOnCookie) == "" {
+			return false, ErrTextKongIngressHashOnCookieRequired, nil
+		}
+		if hashFallback == "header" && stringValue(upstream.HashFallbackHeader) == "" {
+			return false, ErrTextKongIngressHashFallbackHeaderRequired, nil
+		}
+		if hashOn == "cookie" && hashFallback != "" && hashFallback != "none" {
+			return false, ErrTextKongIngressHashFallbackWithCookie, nil
+		}
+		// hashing on the same header twice is only coherent when the headers differ
+		if hashFallback != "" && hashFallback != "none" && hashFallback == hashOn &&
+			(hashOn != "header" || stringValue(upstream.HashOnHeader) == stringValue(upstream.HashFallbackHeader)) {
+			return false, ErrTextKongIngressHashFallbackSameAsHashOn, nil
+		}
+	}
+
+	if proxy := kongIngress.Proxy; proxy != nil {
+		if protocol := stringValue(proxy.Protocol); isGRPCProtocol(protocol) && stringValue(proxy.Path) != "" {
+			return false, fmt.Sprintf(ErrTextKongIngressProxyPathWithGRPC, protocol), nil
+		}
+	}
+
+	if route := kongIngress.Route; route != nil && route.StripPath != nil && *route.StripPath {
+		for _, protocol := range route.Protocols {
+			if protocol != nil && isGRPCProtocol(string(*protocol)) {
+				return false, fmt.Sprintf(ErrTextKongIngressRouteStripPathWithGRPC, *protocol), nil
+			}
+		}
+	}
+
+	return true, "", nil
+}
+
 // -----------------------------------------------------------------------------
 // KongHTTPValidator - Private Methods
 // -----------------------------------------------------------------------------
@@ -392,6 +497,13 @@ func (validator KongHTTPValidator) listManagedConsumers(ctx context.Context) ([]
 	return managedConsumers, nil
 }
 
+// isManagedIngress indicates whether an Ingress belongs to the ingress class
+// of the validator, as with the Ingresses validated by ValidateIngress.
+func (validator KongHTTPValidator) isManagedIngress(ingress *netv1.Ingress) bool {
+	return validator.ingressClassMatcher(&ingress.ObjectMeta, annotations.ExactOrEmptyClassMatch) &&
+		validator.ingressV1ClassMatcher(ingress, annotations.ExactOrEmptyClassMatch)
+}
+
 // -----------------------------------------------------------------------------
 // Private - Manager Client Secret Getter
 // -----------------------------------------------------------------------------
@@ -407,3 +519,19 @@ func (m *managerClientSecretGetter) GetSecret(namespace, name string) (*corev1.S
 		Name:      name,
 	}, secret)
 }
+
+// -----------------------------------------------------------------------------
+// Private - Helper Functions
+// -----------------------------------------------------------------------------
+
+func isGRPCProtocol(protocol string) bool {
+	return protocol == "grpc" || protocol == "grpcs"
+}
+
+// stringValue dereferences the provided string, providing "" for nil.
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -6,8 +6,14 @@ import (
 	"testing"
 
 	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	netv1 "k8s.io/api/networking/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/store"
@@ -288,4 +294,314 @@ func TestKongHTTPValidator_ValidateClusterPlugin(t *testing.T) {
 	}
 }
 
+func TestKongHTTPValidator_ValidateIngress(t *testing.T) {
+	otherClass := "nginx"
+	validator := NewKongHTTPValidator(nil, nil, logrus.New(), nil, annotations.DefaultIngressClass)
+
+	tests := []struct {
+		name      string
+		hosts     []string
+		className *string
+		wantOK    bool
+	}{
+		{
+			name:   "valid hosts",
+			hosts:  []string{"example.com", "foo.example.com"},
+			wantOK: true,
+		},
+		{
+			name:   "wildcard host",
+			hosts:  []string{"*.example.com"},
+			wantOK: true,
+		},
+		{
+			name:   "rule without a host",
+			hosts:  []string{""},
+			wantOK: true,
+		},
+		{
+			name:   "host with uppercase characters",
+			hosts:  []string{"Foo.example.com"},
+			wantOK: false,
+		},
+		{
+			name:   "host with a space",
+			hosts:  []string{"example.com", "foo bar.example.com"},
+			wantOK: false,
+		},
+		{
+			name:   "wildcard in the middle of the host",
+			hosts:  []string{"foo.*.example.com"},
+			wantOK: false,
+		},
+		{
+			name:      "invalid host of an ingress belonging to another class",
+			hosts:     []string{"foo bar.example.com"},
+			className: &otherClass,
+			wantOK:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ingress := netv1.Ingress{Spec: netv1.IngressSpec{IngressClassName: tt.className}}
+			for _, host := range tt.hosts {
+				ingress.Spec.Rules = append(ingress.Spec.Rules, netv1.IngressRule{Host: host})
+			}
+			ok, msg, err := validator.ValidateIngress(context.Background(), ingress)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Empty(t, msg)
+			} else {
+				assert.Contains(t, msg, "is not a valid DNS name")
+			}
+		})
+	}
+}
+
+func TestKongHTTPValidator_ValidateIngressHealthcheckActiveConcurrency(t *testing.T) {
+	validator := NewKongHTTPValidator(nil, nil, logrus.New(), nil, annotations.DefaultIngressClass)
+	key := annotations.AnnotationPrefix + annotations.HealthcheckActiveConcurrencyKey
+
+	for _, tt := range []struct {
+		name   string
+		value  string
+		wantOK bool
+	}{
+		{name: "positive", value: "32", wantOK: true},
+		{name: "zero", value: "0"},
+		{name: "negative", value: "-1"},
+		{name: "not a number", value: "many"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ingress := netv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        "foo",
+				Annotations: map[string]string{key: tt.value},
+			}}
+			ok, msg, err := validator.ValidateIngress(context.Background(), ingress)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Empty(t, msg)
+			} else {
+				assert.Contains(t, msg, key)
+			}
+		})
+	}
+}
+
+func TestKongHTTPValidator_ValidateIngressHostUniqueness(t *testing.T) {
+	validator := NewKongHTTPValidator(nil, nil, logrus.New(), nil, annotations.DefaultIngressClass)
+	rule := func(host, path string) netv1.IngressRule {
+		return netv1.IngressRule{
+			Host: host,
+			IngressRuleValue: netv1.IngressRuleValue{
+				HTTP: &netv1.HTTPIngressRuleValue{Paths: []netv1.HTTPIngressPath{{Path: path}}},
+			},
+		}
+	}
+
+	ingress := netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec: netv1.IngressSpec{Rules: []netv1.IngressRule{
+			rule("foo.example.com", "/foo"),
+			rule("foo.example.com", "/bar"),
+		}},
+	}
+	ok, msg, err := validator.ValidateIngress(context.Background(), ingress)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Empty(t, msg)
+
+	ingress.Spec.Rules = append(ingress.Spec.Rules, rule("foo.example.com", "/foo"))
+	ok, msg, err = validator.ValidateIngress(context.Background(), ingress)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "ingress default/test declares the following host and path pairs more than once: foo.example.com/foo", msg)
+}
+
+func TestKongHTTPValidator_ValidateIngressHostPathConflicts(t *testing.T) {
+	otherClass := "nginx"
+	ingress := func(name string, className *string, host, path string) *netv1.Ingress {
+		return &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: netv1.IngressSpec{
+				IngressClassName: className,
+				Rules: []netv1.IngressRule{{
+					Host: host,
+					IngressRuleValue: netv1.IngressRuleValue{
+						HTTP: &netv1.HTTPIngressRuleValue{Paths: []netv1.HTTPIngressPath{{Path: path}}},
+					},
+				}},
+			},
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, netv1.AddToScheme(scheme))
+	managerClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		ingress("existing", nil, "foo.example.com", "/foo"),
+		ingress("unrelated", nil, "bar.example.com", "/foo"),
+		ingress("other-class", &otherClass, "baz.example.com", "/foo"),
+	).Build()
+	validator := NewKongHTTPValidator(nil, nil, logrus.New(), managerClient, annotations.DefaultIngressClass)
+
+	t.Log("verifying that a host and path pair declared by another ingress is rejected")
+	ok, msg, err := validator.ValidateIngress(context.Background(), *ingress("new", nil, "foo.example.com", "/foo"))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "ingress default/new declares host and path pairs already declared by other ingresses: foo.example.com/foo (default/existing)", msg)
+
+	t.Log("verifying that host and path pairs which aren't declared by other ingresses are accepted")
+	ok, msg, err = validator.ValidateIngress(context.Background(), *ingress("new", nil, "foo.example.com", "/bar"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Empty(t, msg)
+
+	t.Log("verifying that an ingress doesn't conflict with its current version on updates")
+	ok, _, err = validator.ValidateIngress(context.Background(), *ingress("existing", nil, "foo.example.com", "/foo"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	t.Log("verifying that ingresses of another class are ignored")
+	ok, _, err = validator.ValidateIngress(context.Background(), *ingress("new", nil, "baz.example.com", "/foo"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestKongHTTPValidator_ValidateKongIngress(t *testing.T) {
+	validator := NewKongHTTPValidator(nil, nil, logrus.New(), nil, annotations.DefaultIngressClass)
+	grpcs := configurationv1.KongProtocol("grpcs")
+	https := configurationv1.KongProtocol("https")
+
+	tests := []struct {
+		name        string
+		kongIngress configurationv1.KongIngress
+		wantOK      bool
+		wantMessage string
+	}{
+		{
+			name:   "empty kong ingress",
+			wantOK: true,
+		},
+		{
+			name: "consistent-hashing on a cookie",
+			kongIngress: configurationv1.KongIngress{Upstream: &configurationv1.KongIngressUpstream{
+				Algorithm:    kong.String("consistent-hashing"),
+				HashOn:       kong.String("cookie"),
+				HashOnCookie: kong.String("session"),
+			}},
+			wantOK: true,
+		},
+		{
+			name: "consistent-hashing without hash_on",
+			kongIngress: configurationv1.KongIngress{Upstream: &configurationv1.KongIngressUpstream{
+				Algorithm: kong.String("consistent-hashing"),
+			}},
+			wantMessage: ErrTextKongIngressHashOnRequired,
+		},
+		{
+			name: "consistent-hashing with hash_on none",
+			kongIngress: configurationv1.KongIngress{Upstream: &configurationv1.KongIngressUpstream{
+				Algorithm: kong.String("consistent-hashing"),
+				HashOn:    kong.String("none"),
+			}},
+			wantMessage: ErrTextKongIngressHashOnRequired,
+		},
+		{
+			name: "hash_on cookie without hash_on_cookie",
+			kongIngress: configurationv1.KongIngress{Upstream: &configurationv1.KongIngressUpstream{
+				HashOn: kong.String("cookie"),
+			}},
+			wantMessage: ErrTextKongIngressHashOnCookieRequired,
+		},
+		{
+			name: "hash_fallback header without hash_fallback_header",
+			kongIngress: configurationv1.KongIngress{Upstream: &configurationv1.KongIngressUpstream{
+				HashOn:       kong.String("ip"),
+				HashFallback: kong.String("header"),
+			}},
+			wantMessage: ErrTextKongIngressHashFallbackHeaderRequired,
+		},
+		{
+			name: "hash_fallback with hash_on cookie",
+			kongIngress: configurationv1.KongIngress{Upstream: &configurationv1.KongIngressUpstream{
+				HashOn:       kong.String("cookie"),
+				HashOnCookie: kong.String("session"),
+				HashFallback: kong.String("ip"),
+			}},
+			wantMessage: ErrTextKongIngressHashFallbackWithCookie,
+		},
+		{
+			name: "hash_fallback same as hash_on",
+			kongIngress: configurationv1.KongIngress{Upstream: &configurationv1.KongIngressUpstream{
+				HashOn:       kong.String("ip"),
+				HashFallback: kong.String("ip"),
+			}},
+			wantMessage: ErrTextKongIngressHashFallbackSameAsHashOn,
+		},
+		{
+			name: "hash_fallback on a different header than hash_on",
+			kongIngress: configurationv1.KongIngress{Upstream: &configurationv1.KongIngressUpstream{
+				HashOn:             kong.String("header"),
+				HashOnHeader:       kong.String("x-user"),
+				HashFallback:       kong.String("header"),
+				HashFallbackHeader: kong.String("x-tenant"),
+			}},
+			wantOK: true,
+		},
+		{
+			name: "hash_fallback on the same header as hash_on",
+			kongIngress: configurationv1.KongIngress{Upstream: &configurationv1.KongIngressUpstream{
+				HashOn:             kong.String("header"),
+				HashOnHeader:       kong.String("x-user"),
+				HashFallback:       kong.String("header"),
+				HashFallbackHeader: kong.String("x-user"),
+			}},
+			wantMessage: ErrTextKongIngressHashFallbackSameAsHashOn,
+		},
+		{
+			name: "grpc proxy with a path",
+			kongIngress: configurationv1.KongIngress{Proxy: &configurationv1.KongIngressService{
+				Protocol: kong.String("grpc"),
+				Path:     kong.String("/foo"),
+			}},
+			wantMessage: fmt.Sprintf(ErrTextKongIngressProxyPathWithGRPC, "grpc"),
+		},
+		{
+			name: "https proxy with a path",
+			kongIngress: configurationv1.KongIngress{Proxy: &configurationv1.KongIngressService{
+				Protocol: kong.String("https"),
+				Path:     kong.String("/foo"),
+			}},
+			wantOK: true,
+		},
+		{
+			name: "grpcs route stripping the path",
+			kongIngress: configurationv1.KongIngress{Route: &configurationv1.KongIngressRoute{
+				Protocols: []*configurationv1.KongProtocol{&https, &grpcs},
+				StripPath: kong.Bool(true),
+			}},
+			wantMessage: fmt.Sprintf(ErrTextKongIngressRouteStripPathWithGRPC, "grpcs"),
+		},
+		{
+			name: "grpcs route preserving the path",
+			kongIngress: configurationv1.KongIngress{Route: &configurationv1.KongIngressRoute{
+				Protocols: []*configurationv1.KongProtocol{&grpcs},
+				StripPath: kong.Bool(false),
+			}},
+			wantOK: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, msg, err := validator.ValidateKongIngress(context.Background(), tt.kongIngress)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantMessage, msg)
+		})
+	}
+}
+
 func fakeClassMatcher(*metav1.ObjectMeta, annotations.ClassMatching) bool { return true }
@@ -17,6 +17,8 @@ limitations under the License.
 package annotations
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 
 	networkingv1 "k8s.io/api/networking/v1"
@@ -31,10 +33,41 @@ const (
 	ExactClassMatch        ClassMatching = iota
 )
 
+// PathHandling is the way in which the paths of an Ingress are matched by the
+// routes translated from it, as configured by the konghq.com/path-handling
+// annotation. It is unrelated to the path_handling field of Kong routes.
+type PathHandling string
+
+const (
+	// PathHandlingExact matches the path exactly.
+	PathHandlingExact PathHandling = "exact"
+	// PathHandlingPrefix matches the path as a prefix of the request path.
+	PathHandlingPrefix PathHandling = "prefix"
+	// PathHandlingRegex matches the path as a regular expression.
+	PathHandlingRegex PathHandling = "regex"
+)
+
+// UpstreamTarget is the way in which the targets of the upstream of a Service
+// are resolved, as configured by the konghq.com/upstream-target annotation.
+type UpstreamTarget string
+
+const (
+	// UpstreamTargetEndpoints targets the endpoints (pod IPs) of the Service.
+	UpstreamTargetEndpoints UpstreamTarget = "endpoints"
+	// UpstreamTargetClusterIP targets the cluster IP of the Service.
+	UpstreamTargetClusterIP UpstreamTarget = "cluster-ip"
+	// UpstreamTargetServiceDNS targets the cluster DNS name of the Service.
+	UpstreamTargetServiceDNS UpstreamTarget = "service-dns"
+)
+
 const (
 	IngressClassKey        = "kubernetes.io/ingress.class"
 	KnativeIngressClassKey = "networking.knative.dev/ingress.class"
 
+	// KnativeIngressClassAnnotationKey is the ingress class annotation used by
+	// newer Knative releases, which supersedes the legacy KnativeIngressClassKey.
+	KnativeIngressClassAnnotationKey = "networking.knative.dev/ingress-class"
+
 	AnnotationPrefix = "konghq.com"
 
 	ConfigurationKey     = "/override"
@@ -53,6 +86,60 @@ const (
 	RequestBuffering     = "/request-buffering"
 	ResponseBuffering    = "/response-buffering"
 	HostAliasesKey       = "/host-aliases"
+	StatusAddressKey     = "/status-address"
+	PathHandlingKey      = "/path-handling"
+
+	// KongIngressClassKey is the konghq.com ingress class annotation, which
+	// predates the .spec.ingressClassName of Ingresses like IngressClassKey.
+	KongIngressClassKey = "/ingress-class"
+
+	UpstreamFallbackServiceKey = "/upstream-fallback-service"
+	FallbackServiceKey         = "/fallback-service"
+	UpstreamWeightKey          = "/upstream-weight"
+	UpstreamTargetKey          = "/upstream-target"
+
+	ConnectTimeoutKey = "/connect-timeout"
+	ReadTimeoutKey    = "/read-timeout"
+	WriteTimeoutKey   = "/write-timeout"
+
+	// HealthcheckActiveConcurrencyKey is an annotation of Ingresses which sets
+	// the number of targets checked concurrently by the active health checks
+	// of the upstreams of their Kong services.
+	HealthcheckActiveConcurrencyKey = "/healthcheck-active-concurrency"
+
+	// TLSPassthroughKey is an annotation of TCPIngresses which makes the rules
+	// with a host route TLS connections by their SNI without terminating TLS.
+	TLSPassthroughKey = "/tls-passthrough"
+
+	// IgnoreKey is an annotation of Ingresses which, set to "true", excludes
+	// them from the controller regardless of their ingress class, e.g. so
+	// that classless Ingresses can be left to another controller which also
+	// handles the default IngressClass.
+	IgnoreKey = "/ignore"
+
+	// TLSMinVersionKey is an annotation of Ingresses which requires the TLS
+	// connections of their HTTPS routes to use at least the provided TLS
+	// version, e.g. "TLSv1.2".
+	TLSMinVersionKey = "/tls-min-version"
+
+	// CACertificatesKey is an annotation of Services which lists the
+	// comma-separated names of Secrets in the Service's namespace holding the
+	// PEM-encoded CA certificates used to verify the TLS certificates of the
+	// upstream.
+	CACertificatesKey = "/ca-certificates"
+
+	// DefaultNamespacesKey is an annotation of IngressClasses which lists the
+	// comma-separated namespaces within which the IngressClass is treated as
+	// the default IngressClass.
+	DefaultNamespacesKey = "/default-namespaces"
+
+	// TagsKey is an annotation of KongConsumers which adds comma-separated
+	// tags to the Kong consumer.
+	TagsKey = "/tags"
+
+	// DebugKey is an annotation which raises the log verbosity of the reconciliation
+	// and translation of a single object to debug level when set to "true".
+	DebugKey = "/debug"
 
 	// GatewayUnmanagedAnnotation is an annotation used on a Gateway resource to
 	// indicate that the Gateway should be reconciled according to unmanaged
@@ -141,6 +228,23 @@ func ExtractKongPluginsFromAnnotations(anns map[string]string) []string {
 	return kongPluginCRs
 }
 
+// ExtractKongPluginsDedup extracts the KongPlugin resource names configured
+// using the konghq.com/plugins annotation like ExtractKongPluginsFromAnnotations
+// does, but removes duplicate names while preserving the order in which the
+// names were first seen.
+func ExtractKongPluginsDedup(anns map[string]string) []string {
+	var kongPluginCRs []string
+	seen := make(map[string]struct{})
+	for _, kongPlugin := range ExtractKongPluginsFromAnnotations(anns) {
+		if _, ok := seen[kongPlugin]; ok {
+			continue
+		}
+		seen[kongPlugin] = struct{}{}
+		kongPluginCRs = append(kongPluginCRs, kongPlugin)
+	}
+	return kongPluginCRs
+}
+
 // ExtractConfigurationName extracts the name of the KongIngress object that holds
 // information about the configuration to use in Routes, Services and Upstreams
 func ExtractConfigurationName(anns map[string]string) string {
@@ -158,6 +262,24 @@ func ExtractProtocolNames(anns map[string]string) []string {
 	return strings.Split(val, ",")
 }
 
+// ExtractProtocols extracts the comma-separated protocols of the
+// konghq.com/protocols annotation of a Service. The annotation shares its key
+// with the one setting the protocols of routes, and overlaps with the
+// konghq.com/protocol annotation of Services, which takes precedence. ok is
+// false if the annotation is absent or empty. The protocols aren't validated,
+// which is up to the caller.
+func ExtractProtocols(anns map[string]string) ([]string, bool) {
+	val, exists := anns[AnnotationPrefix+ProtocolsKey]
+	if !exists || strings.TrimSpace(val) == "" {
+		return nil, false
+	}
+	var protocols []string
+	for _, protocol := range strings.Split(val, ",") {
+		protocols = append(protocols, strings.TrimSpace(protocol))
+	}
+	return protocols, true
+}
+
 // ExtractClientCertificate extracts the secret name containing the
 // client-certificate to use.
 func ExtractClientCertificate(anns map[string]string) string {
@@ -188,6 +310,12 @@ func HasForceSSLRedirectAnnotation(anns map[string]string) bool {
 	return anns["ingress.kubernetes.io/force-ssl-redirect"] == "true"
 }
 
+// IsIgnored returns true if the annotation konghq.com/ignore is set to "true"
+// in anns.
+func IsIgnored(anns map[string]string) bool {
+	return anns[AnnotationPrefix+IgnoreKey] == "true"
+}
+
 // ExtractPreserveHost extracts the preserve-host annotation value.
 func ExtractPreserveHost(anns map[string]string) string {
 	return anns[AnnotationPrefix+PreserveHostKey]
@@ -253,6 +381,244 @@ func ExtractHostAliases(anns map[string]string) ([]string, bool) {
 	return strings.Split(val, ","), true
 }
 
+// ExtractStatusAddresses extracts the addresses which should be reported in the status
+// of an object in lieu of the addresses of the data-plane.
+func ExtractStatusAddresses(anns map[string]string) ([]string, bool) {
+	val, exists := anns[AnnotationPrefix+StatusAddressKey]
+	if !exists || strings.TrimSpace(val) == "" {
+		return nil, false
+	}
+	var addrs []string
+	for _, addr := range strings.Split(val, ",") {
+		addrs = append(addrs, strings.TrimSpace(addr))
+	}
+	return addrs, true
+}
+
+// ExtractUpstreamFallbackService extracts the name and (optional) port of the
+// Service whose endpoints are added to an upstream as low-weight fallback targets.
+// The annotation value is in "name" or "name:port" format.
+func ExtractUpstreamFallbackService(anns map[string]string) (name string, port string, ok bool) {
+	return splitServiceReference(anns[AnnotationPrefix+UpstreamFallbackServiceKey])
+}
+
+// ExtractFallbackService extracts the name and (optional) port of the Service
+// to route to when the backend Service is missing or has no endpoints. It is
+// unused when the konghq.com/upstream-fallback-service annotation of the
+// backend Service already provides the upstream with fallback targets.
+// The annotation value is in "name" or "name:port" format.
+func ExtractFallbackService(anns map[string]string) (name string, port string, ok bool) {
+	return splitServiceReference(anns[AnnotationPrefix+FallbackServiceKey])
+}
+
+// ExtractUpstreamWeights extracts the weights given to the targets of the Services
+// an upstream balances between, keyed by Service name. The annotation value is a
+// comma-separated list of "name=weight" pairs. Malformed pairs and pairs with a
+// weight which is not a positive integer are ignored, so that the targets of such
+// Services keep Kong's default weight.
+func ExtractUpstreamWeights(anns map[string]string) map[string]int {
+	val := strings.TrimSpace(anns[AnnotationPrefix+UpstreamWeightKey])
+	if val == "" {
+		return nil
+	}
+	weights := make(map[string]int)
+	for _, pair := range strings.Split(val, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		name := strings.TrimSpace(parts[0])
+		weight, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if name == "" || err != nil || weight <= 0 {
+			continue
+		}
+		weights[name] = weight
+	}
+	if len(weights) == 0 {
+		return nil
+	}
+	return weights
+}
+
+// ExtractConnectTimeout extracts the connect timeout, in milliseconds, of the
+// Kong service generated for the object with the provided namespace, name and
+// annotations. ok is false if the annotation is not set, and an
+// *AnnotationError is returned if it is not a positive integer.
+func ExtractConnectTimeout(namespace, name string, anns map[string]string) (timeout int, ok bool, err error) {
+	return extractTimeout(namespace, name, anns, ConnectTimeoutKey)
+}
+
+// ExtractReadTimeout extracts the read timeout, in milliseconds, of the Kong
+// service generated for the object with the provided namespace, name and
+// annotations. ok is false if the annotation is not set, and an
+// *AnnotationError is returned if it is not a positive integer.
+func ExtractReadTimeout(namespace, name string, anns map[string]string) (timeout int, ok bool, err error) {
+	return extractTimeout(namespace, name, anns, ReadTimeoutKey)
+}
+
+// ExtractWriteTimeout extracts the write timeout, in milliseconds, of the Kong
+// service generated for the object with the provided namespace, name and
+// annotations. ok is false if the annotation is not set, and an
+// *AnnotationError is returned if it is not a positive integer.
+func ExtractWriteTimeout(namespace, name string, anns map[string]string) (timeout int, ok bool, err error) {
+	return extractTimeout(namespace, name, anns, WriteTimeoutKey)
+}
+
+func extractTimeout(namespace, name string, anns map[string]string, key string) (int, bool, error) {
+	val, exists := anns[AnnotationPrefix+key]
+	if !exists {
+		return 0, false, nil
+	}
+	timeout, err := strconv.Atoi(strings.TrimSpace(val))
+	if err == nil && timeout <= 0 {
+		err = fmt.Errorf("timeout must be positive")
+	}
+	if err != nil {
+		return 0, false, &AnnotationError{
+			Namespace: namespace,
+			Name:      name,
+			Key:       AnnotationPrefix + key,
+			Value:     val,
+			Err:       err,
+		}
+	}
+	return timeout, true, nil
+}
+
+// ExtractHealthcheckActiveConcurrency extracts the number of targets checked
+// concurrently by the active health checks of the upstreams generated for the
+// object with the provided namespace, name and annotations. ok is false if the
+// annotation is not set, and an *AnnotationError is returned if it is not a
+// positive integer.
+func ExtractHealthcheckActiveConcurrency(namespace, name string, anns map[string]string) (concurrency int, ok bool, err error) {
+	val, exists := anns[AnnotationPrefix+HealthcheckActiveConcurrencyKey]
+	if !exists {
+		return 0, false, nil
+	}
+	concurrency, err = strconv.Atoi(strings.TrimSpace(val))
+	if err == nil && concurrency <= 0 {
+		err = fmt.Errorf("concurrency must be positive")
+	}
+	if err != nil {
+		return 0, false, &AnnotationError{
+			Namespace: namespace,
+			Name:      name,
+			Key:       AnnotationPrefix + HealthcheckActiveConcurrencyKey,
+			Value:     val,
+			Err:       err,
+		}
+	}
+	return concurrency, true, nil
+}
+
+// TLSVersions are the TLS versions accepted by the konghq.com/tls-min-version
+// annotation, in ascending order and named as in Kong's ssl_protocols.
+var TLSVersions = []string{"TLSv1", "TLSv1.1", "TLSv1.2", "TLSv1.3"}
+
+// ExtractTLSMinVersion extracts the minimum TLS version of the routes
+// generated for the object with the provided namespace, name and annotations.
+// Versions are accepted as named by Kong (e.g. "TLSv1.2") or by their number
+// (e.g. "1.2") and are returned as named by Kong. ok is false if the
+// annotation is not set, and an *AnnotationError is returned if the version
+// is unknown.
+func ExtractTLSMinVersion(namespace, name string, anns map[string]string) (version string, ok bool, err error) {
+	val, exists := anns[AnnotationPrefix+TLSMinVersionKey]
+	if !exists {
+		return "", false, nil
+	}
+	version = strings.TrimSpace(val)
+	if !strings.HasPrefix(version, "TLSv") {
+		version = "TLSv" + version
+	}
+	if version == "TLSv1.0" {
+		version = "TLSv1"
+	}
+	for _, known := range TLSVersions {
+		if version == known {
+			return version, true, nil
+		}
+	}
+	return "", false, &AnnotationError{
+		Namespace: namespace,
+		Name:      name,
+		Key:       AnnotationPrefix + TLSMinVersionKey,
+		Value:     val,
+		Err:       fmt.Errorf("unknown TLS version, expected one of %s", strings.Join(TLSVersions, ", ")),
+	}
+}
+
+// ExtractCACertificates extracts the names of the Secrets holding the CA
+// certificates which verify the TLS certificates of the upstream of a Service.
+func ExtractCACertificates(anns map[string]string) []string {
+	var names []string
+	for _, name := range strings.Split(anns[AnnotationPrefix+CACertificatesKey], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func splitServiceReference(val string) (name string, port string, ok bool) {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return "", "", false
+	}
+	parts := strings.SplitN(val, ":", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1], true
+	}
+	return parts[0], "", true
+}
+
+// ExtractDebug extracts whether or not the reconciliation and translation of an
+// object should be logged at debug level regardless of the configured log level.
+func ExtractDebug(anns map[string]string) bool {
+	return anns[AnnotationPrefix+DebugKey] == "true"
+}
+
+// ExtractTLSPassthrough extracts whether or not the TLS connections routed by the
+// rules of a TCPIngress are passed through to the backend without being terminated.
+func ExtractTLSPassthrough(anns map[string]string) bool {
+	return anns[AnnotationPrefix+TLSPassthroughKey] == "true"
+}
+
+// ExtractTags extracts the comma-separated tags of the konghq.com/tags
+// annotation, skipping empty tags.
+func ExtractTags(anns map[string]string) []string {
+	var tags []string
+	for _, tag := range strings.Split(anns[AnnotationPrefix+TagsKey], ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// ExtractPathHandling extracts the way in which the paths of an Ingress are
+// matched from the konghq.com/path-handling annotation. ok is false if the
+// annotation is not set or is not one of the supported values.
+func ExtractPathHandling(anns map[string]string) (PathHandling, bool) {
+	switch handling := PathHandling(anns[AnnotationPrefix+PathHandlingKey]); handling {
+	case PathHandlingExact, PathHandlingPrefix, PathHandlingRegex:
+		return handling, true
+	default:
+		return "", false
+	}
+}
+
+// ExtractUpstreamTarget extracts the way in which the targets of the upstream
+// of a Service are resolved from the konghq.com/upstream-target annotation. ok
+// is false if the annotation is not set or is not one of the supported values.
+func ExtractUpstreamTarget(anns map[string]string) (UpstreamTarget, bool) {
+	switch target := UpstreamTarget(anns[AnnotationPrefix+UpstreamTargetKey]); target {
+	case UpstreamTargetEndpoints, UpstreamTargetClusterIP, UpstreamTargetServiceDNS:
+		return target, true
+	default:
+		return "", false
+	}
+}
+
 // ExtractUnmanagedGatewayMode extracts the value of the unmanaged gateway
 // mode annotation.
 func ExtractUnmanagedGatewayMode(anns map[string]string) (string, bool) {
@@ -17,10 +17,13 @@ limitations under the License.
 package annotations
 
 import (
+	"errors"
 	"reflect"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 	extensions "k8s.io/api/extensions/v1beta1"
 	networkingv1 "k8s.io/api/networking/v1"
@@ -161,6 +164,59 @@ func TestExtractKongPluginsFromAnnotations(t *testing.T) {
 	}
 }
 
+func TestExtractKongPluginsDedup(t *testing.T) {
+	type args struct {
+		anns map[string]string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "empty",
+			args: args{
+				anns: map[string]string{},
+			},
+			want: nil,
+		},
+		{
+			name: "duplicates",
+			args: args{
+				anns: map[string]string{
+					"konghq.com/plugins": "kp-rl,kp-cors,kp-rl,kp-auth,kp-cors",
+				},
+			},
+			want: []string{"kp-rl", "kp-cors", "kp-auth"},
+		},
+		{
+			name: "duplicates with surrounding whitespace",
+			args: args{
+				anns: map[string]string{
+					"konghq.com/plugins": " kp-rl ,kp-cors,  kp-rl",
+				},
+			},
+			want: []string{"kp-rl", "kp-cors"},
+		},
+		{
+			name: "empty entries",
+			args: args{
+				anns: map[string]string{
+					"konghq.com/plugins": ",kp-rl,, ,kp-cors,",
+				},
+			},
+			want: []string{"kp-rl", "kp-cors"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractKongPluginsDedup(tt.args.anns); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractKongPluginsDedup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestExtractConfigurationName(t *testing.T) {
 	type args struct {
 		anns map[string]string
@@ -245,6 +301,43 @@ func TestExtractProtocolNames(t *testing.T) {
 	}
 }
 
+func TestExtractProtocols(t *testing.T) {
+	tests := []struct {
+		name   string
+		anns   map[string]string
+		want   []string
+		wantOK bool
+	}{
+		{
+			name:   "valid",
+			anns:   map[string]string{"konghq.com/protocols": "https, grpcs,wss"},
+			want:   []string{"https", "grpcs", "wss"},
+			wantOK: true,
+		},
+		{
+			name:   "unvalidated",
+			anns:   map[string]string{"konghq.com/protocols": "https,udp"},
+			want:   []string{"https", "udp"},
+			wantOK: true,
+		},
+		{
+			name: "empty",
+			anns: map[string]string{"konghq.com/protocols": " "},
+		},
+		{
+			name: "absent",
+			anns: map[string]string{"konghq.com/protocol": "https"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ExtractProtocols(tt.anns)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestExtractClientCertificate(t *testing.T) {
 	type args struct {
 		anns map[string]string
@@ -689,3 +782,296 @@ func TestExtractHostAliases(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractStatusAddresses(t *testing.T) {
+	type args struct {
+		anns map[string]string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "empty",
+			want: nil,
+		},
+		{
+			name: "non-empty",
+			args: args{
+				anns: map[string]string{
+					"konghq.com/status-address": "10.0.0.1, lb.example.com",
+				},
+			},
+			want: []string{"10.0.0.1", "lb.example.com"},
+		},
+		{
+			name: "misconfigured",
+			args: args{
+				anns: map[string]string{
+					"konghq.com/status-address": " ",
+				},
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			if got, _ = ExtractStatusAddresses(tt.args.anns); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractStatusAddresses() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractFallbackService(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		anns     map[string]string
+		wantName string
+		wantPort string
+		wantOK   bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:     "name only",
+			anns:     map[string]string{"konghq.com/fallback-service": "maintenance"},
+			wantName: "maintenance",
+			wantOK:   true,
+		},
+		{
+			name:     "name and port",
+			anns:     map[string]string{"konghq.com/fallback-service": "maintenance:http"},
+			wantName: "maintenance",
+			wantPort: "http",
+			wantOK:   true,
+		},
+		{
+			name:     "upstream fallback service is a distinct annotation",
+			anns:     map[string]string{"konghq.com/upstream-fallback-service": "maintenance:8080"},
+			wantName: "",
+			wantOK:   false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			name, port, ok := ExtractFallbackService(tt.anns)
+			assert.Equal(t, tt.wantName, name)
+			assert.Equal(t, tt.wantPort, port)
+			assert.Equal(t, tt.wantOK, ok)
+
+			name, port, ok = ExtractUpstreamFallbackService(map[string]string{
+				"konghq.com/upstream-fallback-service": tt.anns["konghq.com/fallback-service"],
+			})
+			assert.Equal(t, tt.wantName, name)
+			assert.Equal(t, tt.wantPort, port)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func TestExtractUpstreamWeights(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		anns map[string]string
+		want map[string]int
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "weights",
+			anns: map[string]string{"konghq.com/upstream-weight": "app=90, app-canary=10"},
+			want: map[string]int{"app": 90, "app-canary": 10},
+		},
+		{
+			name: "zero, negative and malformed weights are ignored",
+			anns: map[string]string{"konghq.com/upstream-weight": "app=90,app-canary=0,other=-1,bad=x,noweight,=5"},
+			want: map[string]int{"app": 90},
+		},
+		{
+			name: "no valid weights",
+			anns: map[string]string{"konghq.com/upstream-weight": "app=0"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExtractUpstreamWeights(tt.anns))
+		})
+	}
+}
+
+func TestExtractTimeouts(t *testing.T) {
+	anns := map[string]string{
+		"konghq.com/connect-timeout": "1000",
+		"konghq.com/read-timeout":    "0",
+		"konghq.com/write-timeout":   "fast",
+	}
+	timeout, ok, err := ExtractConnectTimeout("default", "foo", anns)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 1000, timeout)
+	_, ok, err = ExtractReadTimeout("default", "foo", anns)
+	assert.False(t, ok)
+	assert.Error(t, err)
+	_, ok, err = ExtractWriteTimeout("default", "foo", anns)
+	assert.False(t, ok)
+	assert.Error(t, err)
+	_, ok, err = ExtractConnectTimeout("default", "foo", nil)
+	assert.False(t, ok)
+	assert.NoError(t, err)
+}
+
+func TestExtractHealthcheckActiveConcurrency(t *testing.T) {
+	concurrency, ok, err := ExtractHealthcheckActiveConcurrency("default", "foo",
+		map[string]string{"konghq.com/healthcheck-active-concurrency": " 16 "})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 16, concurrency)
+	for _, val := range []string{"0", "-2", "lots"} {
+		_, ok, err = ExtractHealthcheckActiveConcurrency("default", "foo",
+			map[string]string{"konghq.com/healthcheck-active-concurrency": val})
+		assert.False(t, ok, val)
+		var annErr *AnnotationError
+		assert.True(t, errors.As(err, &annErr), val)
+	}
+	_, ok, err = ExtractHealthcheckActiveConcurrency("default", "foo", nil)
+	assert.False(t, ok)
+	assert.NoError(t, err)
+}
+
+func TestAnnotationError(t *testing.T) {
+	_, _, err := ExtractConnectTimeout("default", "foo", map[string]string{"konghq.com/connect-timeout": "soon"})
+	var annErr *AnnotationError
+	require.True(t, errors.As(err, &annErr))
+	assert.Equal(t, "default", annErr.Namespace)
+	assert.Equal(t, "foo", annErr.Name)
+	assert.Equal(t, "konghq.com/connect-timeout", annErr.Key)
+	assert.Equal(t, "soon", annErr.Value)
+	var numErr *strconv.NumError
+	assert.True(t, errors.As(err, &numErr), "the underlying error should be unwrapped")
+	assert.Equal(t, `invalid value "soon" of annotation konghq.com/connect-timeout of default/foo: `+numErr.Error(), err.Error())
+
+	_, _, err = ExtractWriteTimeout("default", "bar", map[string]string{"konghq.com/write-timeout": "-5"})
+	require.True(t, errors.As(err, &annErr))
+	assert.Equal(t, "bar", annErr.Name)
+	assert.Equal(t, "konghq.com/write-timeout", annErr.Key)
+	assert.Equal(t, "-5", annErr.Value)
+	assert.Error(t, annErr.Err)
+}
+
+func TestExtractTLSMinVersion(t *testing.T) {
+	for _, tt := range []struct {
+		val  string
+		want string
+	}{
+		{val: "TLSv1.2", want: "TLSv1.2"},
+		{val: " 1.3 ", want: "TLSv1.3"},
+		{val: "1.0", want: "TLSv1"},
+		{val: "TLSv1", want: "TLSv1"},
+	} {
+		version, ok, err := ExtractTLSMinVersion("default", "foo", map[string]string{"konghq.com/tls-min-version": tt.val})
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, tt.want, version)
+	}
+
+	_, ok, err := ExtractTLSMinVersion("default", "foo", nil)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = ExtractTLSMinVersion("default", "foo", map[string]string{"konghq.com/tls-min-version": "SSLv3"})
+	assert.False(t, ok)
+	var annErr *AnnotationError
+	require.True(t, errors.As(err, &annErr))
+	assert.Equal(t, "konghq.com/tls-min-version", annErr.Key)
+	assert.Equal(t, "SSLv3", annErr.Value)
+}
+
+func TestExtractCACertificates(t *testing.T) {
+	assert.Empty(t, ExtractCACertificates(nil))
+	assert.Empty(t, ExtractCACertificates(map[string]string{"konghq.com/ca-certificates": " "}))
+	assert.Equal(t, []string{"root-ca", "intermediate-ca"},
+		ExtractCACertificates(map[string]string{"konghq.com/ca-certificates": "root-ca, intermediate-ca,"}))
+}
+
+func TestExtractDebug(t *testing.T) {
+	assert.False(t, ExtractDebug(nil))
+	assert.False(t, ExtractDebug(map[string]string{"konghq.com/debug": "false"}))
+	assert.False(t, ExtractDebug(map[string]string{"konghq.com/debug": ""}))
+	assert.True(t, ExtractDebug(map[string]string{"konghq.com/debug": "true"}))
+}
+
+func TestExtractTLSPassthrough(t *testing.T) {
+	assert.False(t, ExtractTLSPassthrough(nil))
+	assert.False(t, ExtractTLSPassthrough(map[string]string{"konghq.com/tls-passthrough": "false"}))
+	assert.False(t, ExtractTLSPassthrough(map[string]string{"konghq.com/tls-passthrough": ""}))
+	assert.True(t, ExtractTLSPassthrough(map[string]string{"konghq.com/tls-passthrough": "true"}))
+}
+
+func TestExtractTags(t *testing.T) {
+	assert.Nil(t, ExtractTags(nil))
+	assert.Nil(t, ExtractTags(map[string]string{"konghq.com/tags": " , "}))
+	assert.Equal(t, []string{"team:payments", "tier:gold"}, ExtractTags(map[string]string{"konghq.com/tags": "team:payments, tier:gold,"}))
+}
+
+func TestExtractPathHandling(t *testing.T) {
+	tests := []struct {
+		name   string
+		anns   map[string]string
+		want   PathHandling
+		wantOK bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:   "exact",
+			anns:   map[string]string{"konghq.com/path-handling": "exact"},
+			want:   PathHandlingExact,
+			wantOK: true,
+		},
+		{
+			name:   "prefix",
+			anns:   map[string]string{"konghq.com/path-handling": "prefix"},
+			want:   PathHandlingPrefix,
+			wantOK: true,
+		},
+		{
+			name:   "regex",
+			anns:   map[string]string{"konghq.com/path-handling": "regex"},
+			want:   PathHandlingRegex,
+			wantOK: true,
+		},
+		{
+			name: "invalid",
+			anns: map[string]string{"konghq.com/path-handling": "v1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ExtractPathHandling(tt.anns)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func TestExtractUpstreamTarget(t *testing.T) {
+	for _, tt := range []struct {
+		val    string
+		want   UpstreamTarget
+		wantOK bool
+	}{
+		{val: ""},
+		{val: "endpoints", want: UpstreamTargetEndpoints, wantOK: true},
+		{val: "cluster-ip", want: UpstreamTargetClusterIP, wantOK: true},
+		{val: "service-dns", want: UpstreamTargetServiceDNS, wantOK: true},
+		{val: "pod-ip"},
+	} {
+		got, ok := ExtractUpstreamTarget(map[string]string{"konghq.com/upstream-target": tt.val})
+		assert.Equal(t, tt.want, got, tt.val)
+		assert.Equal(t, tt.wantOK, ok, tt.val)
+	}
+}
@@ -0,0 +1,28 @@
+package annotations
+
+import "fmt"
+
+// AnnotationError is the error returned when the value of an annotation of an
+// object can't be parsed.
+type AnnotationError struct {
+	// Namespace is the namespace of the object with the annotation.
+	Namespace string
+	// Name is the name of the object with the annotation.
+	Name string
+	// Key is the full key of the annotation, e.g. "konghq.com/connect-timeout".
+	Key string
+	// Value is the malformed value of the annotation.
+	Value string
+	// Err is the reason why the value can't be parsed.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *AnnotationError) Error() string {
+	return fmt.Sprintf("invalid value %q of annotation %s of %s/%s: %v", e.Value, e.Key, e.Namespace, e.Name, e.Err)
+}
+
+// Unwrap returns the reason why the value can't be parsed.
+func (e *AnnotationError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,99 @@
+package configuration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/sendconfig"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/util"
+)
+
+func TestNetV1IngressReconcilerIngressClassChange(t *testing.T) {
+	// a DB-less Kong Admin API which only serves its root configuration
+	adminAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"version":"2.7.0","configuration":{"database":"off"}}`))
+	}))
+	defer adminAPI.Close()
+	kongClient, err := kong.NewClient(kong.String(adminAPI.URL), adminAPI.Client())
+	require.NoError(t, err)
+	dataplaneClient, err := dataplane.NewKongClient(logrus.New(), time.Second, annotations.DefaultIngressClass, false,
+		util.ConfigDumpDiagnostic{}, sendconfig.Kong{Client: kongClient})
+	require.NoError(t, err)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, netv1.AddToScheme(scheme))
+	kongClass, nginxClass := annotations.DefaultIngressClass, "nginx"
+	ingress := &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   corev1.NamespaceDefault,
+			Name:        "ingress",
+			Annotations: map[string]string{annotations.IngressClassKey: kongClass},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ingress).Build()
+	r := &NetV1IngressReconciler{
+		Client:           c,
+		Log:              logr.Discard(),
+		Scheme:           scheme,
+		DataplaneClient:  dataplaneClient,
+		IngressClassName: kongClass,
+	}
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}}
+
+	t.Log("verifying that an ingress with our class is added to the configuration")
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	exists, err := dataplaneClient.ObjectExists(ingress)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	t.Log("verifying that the configuration is removed when the ingress is moved to another class in its spec")
+	require.NoError(t, c.Get(ctx, req.NamespacedName, ingress))
+	ingress.Spec.IngressClassName = &nginxClass
+	require.NoError(t, c.Update(ctx, ingress))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	exists, err = dataplaneClient.ObjectExists(ingress)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	t.Log("verifying that the ingress is added back when it's moved back to our class")
+	require.NoError(t, c.Get(ctx, req.NamespacedName, ingress))
+	ingress.Spec.IngressClassName = &kongClass
+	require.NoError(t, c.Update(ctx, ingress))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	exists, err = dataplaneClient.ObjectExists(ingress)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	t.Log("verifying that the configuration is removed when the ingress class annotation is changed")
+	require.NoError(t, c.Get(ctx, req.NamespacedName, ingress))
+	ingress.Spec.IngressClassName = nil
+	ingress.Annotations[annotations.IngressClassKey] = nginxClass
+	require.NoError(t, c.Update(ctx, ingress))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	exists, err = dataplaneClient.ObjectExists(ingress)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
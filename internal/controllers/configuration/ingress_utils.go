@@ -0,0 +1,84 @@
+package configuration
+
+import (
+	"context"
+
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// -----------------------------------------------------------------------------
+// Ingress Utilities
+// -----------------------------------------------------------------------------
+
+// BackendServicesIndexKey is the name of the cache index of objects by the names of the backend Services
+// they reference.
+const BackendServicesIndexKey = "backendServices"
+
+// backendServiceCreatedPredicate only lets Service creation events through: updates to existing Services are
+// already handled by the Service reconciler, but objects which referenced a Service before it existed need to be
+// reconciled again once it's created.
+var backendServiceCreatedPredicate = predicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return true },
+	UpdateFunc:  func(event.UpdateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// indexNetV1IngressBackendServices indexes Ingresses by the names of the Services of their default backend and rules.
+func indexNetV1IngressBackendServices(obj client.Object) []string {
+	ingress, ok := obj.(*netv1.Ingress)
+	if !ok {
+		return nil
+	}
+
+	var services []string
+	seen := make(map[string]struct{})
+	addBackend := func(backend *netv1.IngressBackend) {
+		if backend == nil || backend.Service == nil {
+			return
+		}
+		if _, ok := seen[backend.Service.Name]; ok {
+			return
+		}
+		seen[backend.Service.Name] = struct{}{}
+		services = append(services, backend.Service.Name)
+	}
+
+	addBackend(ingress.Spec.DefaultBackend)
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for i := range rule.HTTP.Paths {
+			addBackend(&rule.HTTP.Paths[i].Backend)
+		}
+	}
+	return services
+}
+
+// listObjectsForBackendService is a watch mapping function which enqueues the Ingresses which reference the
+// provided Service as a backend, so that Ingresses created before their backends are promptly synced to Kong.
+func (r *NetV1IngressReconciler) listObjectsForBackendService(service client.Object) (recs []reconcile.Request) {
+	ingresses := &netv1.IngressList{}
+	if err := r.Client.List(context.Background(), ingresses,
+		client.InNamespace(service.GetNamespace()),
+		client.MatchingFields{BackendServicesIndexKey: service.GetName()},
+	); err != nil {
+		r.Log.Error(err, "failed to list ingresses for backend service", "namespace", service.GetNamespace(), "name", service.GetName())
+		return
+	}
+	for _, ingress := range ingresses.Items {
+		recs = append(recs, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: ingress.Namespace,
+				Name:      ingress.Name,
+			},
+		})
+	}
+	return
+}
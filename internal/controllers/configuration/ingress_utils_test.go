@@ -0,0 +1,68 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func backendIngress(namespace, name string, services ...string) *netv1.Ingress {
+	ingress := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	for _, service := range services {
+		ingress.Spec.Rules = append(ingress.Spec.Rules, netv1.IngressRule{
+			IngressRuleValue: netv1.IngressRuleValue{HTTP: &netv1.HTTPIngressRuleValue{
+				Paths: []netv1.HTTPIngressPath{{
+					Path: "/" + service,
+					Backend: netv1.IngressBackend{
+						Service: &netv1.IngressServiceBackend{Name: service},
+					},
+				}},
+			}},
+		})
+	}
+	return ingress
+}
+
+func TestIndexNetV1IngressBackendServices(t *testing.T) {
+	ingress := backendIngress(corev1.NamespaceDefault, "ingress", "foo", "bar", "foo")
+	ingress.Spec.DefaultBackend = &netv1.IngressBackend{Service: &netv1.IngressServiceBackend{Name: "default"}}
+	ingress.Spec.Rules = append(ingress.Spec.Rules, netv1.IngressRule{Host: "example.com"})
+	assert.Equal(t, []string{"default", "foo", "bar"}, indexNetV1IngressBackendServices(ingress))
+	assert.Empty(t, indexNetV1IngressBackendServices(&netv1.Ingress{}))
+	assert.Nil(t, indexNetV1IngressBackendServices(&corev1.Service{}))
+}
+
+func TestListObjectsForBackendService(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, netv1.AddToScheme(scheme))
+
+	r := &NetV1IngressReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			backendIngress(corev1.NamespaceDefault, "ingress", "foo"),
+			backendIngress("other", "ingress", "foo"),
+		).Build(),
+		Log: logr.Discard(),
+	}
+
+	t.Log("verifying that the creation of a backend service re-enqueues the ingress referencing it")
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "foo"}}
+	assert.True(t, backendServiceCreatedPredicate.Create(event.CreateEvent{Object: service}))
+	assert.Equal(t, []reconcile.Request{{
+		NamespacedName: types.NamespacedName{Namespace: corev1.NamespaceDefault, Name: "ingress"},
+	}}, r.listObjectsForBackendService(service))
+
+	t.Log("verifying that other service events are left to the service reconciler")
+	assert.False(t, backendServiceCreatedPredicate.Update(event.UpdateEvent{ObjectOld: service, ObjectNew: service}))
+	assert.False(t, backendServiceCreatedPredicate.Delete(event.DeleteEvent{Object: service}))
+	assert.False(t, backendServiceCreatedPredicate.Generic(event.GenericEvent{Object: service}))
+}
@@ -0,0 +1,50 @@
+package configuration
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kongv1 "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1"
+)
+
+// -----------------------------------------------------------------------------
+// KongConsumer Utilities
+// -----------------------------------------------------------------------------
+
+// CredentialSecretsIndexKey is the name of the cache index of objects by the names of the credential Secrets
+// they reference.
+const CredentialSecretsIndexKey = "credentialSecrets"
+
+// indexKongConsumerCredentialSecrets indexes KongConsumers by the names of their credential Secrets.
+func indexKongConsumerCredentialSecrets(obj client.Object) []string {
+	consumer, ok := obj.(*kongv1.KongConsumer)
+	if !ok {
+		return nil
+	}
+	return consumer.Credentials
+}
+
+// listKongConsumersForCredentialSecret is a watch mapping function which enqueues the KongConsumers which
+// reference the provided Secret as a credential, so that credential rotations are promptly synced to Kong.
+func (r *KongV1KongConsumerReconciler) listKongConsumersForCredentialSecret(secret client.Object) (recs []reconcile.Request) {
+	consumers := &kongv1.KongConsumerList{}
+	if err := r.Client.List(context.Background(), consumers,
+		client.InNamespace(secret.GetNamespace()),
+		client.MatchingFields{CredentialSecretsIndexKey: secret.GetName()},
+	); err != nil {
+		r.Log.Error(err, "failed to list kongconsumers for credential secret", "namespace", secret.GetNamespace(), "name", secret.GetName())
+		return
+	}
+	for _, consumer := range consumers.Items {
+		recs = append(recs, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: consumer.Namespace,
+				Name:      consumer.Name,
+			},
+		})
+	}
+	return
+}
@@ -0,0 +1,54 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kongv1 "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1"
+)
+
+func TestIndexKongConsumerCredentialSecrets(t *testing.T) {
+	consumer := &kongv1.KongConsumer{
+		ObjectMeta:  metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "consumer"},
+		Credentials: []string{"key-auth", "basic-auth"},
+	}
+	assert.Equal(t, []string{"key-auth", "basic-auth"}, indexKongConsumerCredentialSecrets(consumer))
+	assert.Empty(t, indexKongConsumerCredentialSecrets(&kongv1.KongConsumer{}))
+	assert.Nil(t, indexKongConsumerCredentialSecrets(&corev1.Secret{}))
+}
+
+func TestListKongConsumersForCredentialSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, kongv1.AddToScheme(scheme))
+
+	consumer := &kongv1.KongConsumer{
+		ObjectMeta:  metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "consumer"},
+		Credentials: []string{"key-auth"},
+	}
+	otherNamespaceConsumer := &kongv1.KongConsumer{
+		ObjectMeta:  metav1.ObjectMeta{Namespace: "other", Name: "consumer"},
+		Credentials: []string{"key-auth"},
+	}
+	r := &KongV1KongConsumerReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(consumer, otherNamespaceConsumer).Build(),
+		Log:    logr.Discard(),
+	}
+
+	t.Log("verifying that an update to a credential secret re-enqueues the consumer referencing it")
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "key-auth"},
+		StringData: map[string]string{"kongCredType": "key-auth", "key": "rotated"},
+	}
+	assert.Equal(t, []reconcile.Request{{
+		NamespacedName: types.NamespacedName{Namespace: corev1.NamespaceDefault, Name: "consumer"},
+	}}, r.listKongConsumersForCredentialSecret(secret))
+}
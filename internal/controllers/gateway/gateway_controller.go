@@ -239,7 +239,9 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	if err := r.Get(ctx, req.NamespacedName, gateway); err != nil {
 		if errors.IsNotFound(err) {
 			debug(log, gateway, "reconciliation triggered but gateway does not exist, ignoring")
-			return ctrl.Result{Requeue: false}, nil
+			gateway.Namespace = req.Namespace
+			gateway.Name = req.Name
+			return ctrl.Result{Requeue: false}, r.DataplaneClient.DeleteObject(gateway)
 		}
 		return ctrl.Result{Requeue: true}, err
 	}
@@ -257,7 +259,7 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 	if gwc.Spec.ControllerName != ControllerName {
 		debug(log, gateway, "unsupported gatewayclass controllername, ignoring", "gatewayclass", gwc.Name, "controllername", gwc.Spec.ControllerName)
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, r.DataplaneClient.DeleteObject(gateway)
 	}
 
 	// if there's any deletion timestamp on the object, we can simply ignore it. At this point
@@ -266,7 +268,14 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	debug(log, gateway, "checking deletion timestamp")
 	if gateway.DeletionTimestamp != nil {
 		debug(log, gateway, "gateway is being deleted, ignoring")
-		return ctrl.Result{Requeue: false}, nil
+		return ctrl.Result{Requeue: false}, r.DataplaneClient.DeleteObject(gateway)
+	}
+
+	// the certificates referenced by the TLS configuration of the gateway's listeners
+	// are configured in the data-plane, so the gateway is cached for the parser.
+	debug(log, gateway, "updating the gateway in the data-plane cache")
+	if err := r.DataplaneClient.UpdateObject(gateway); err != nil {
+		return ctrl.Result{}, err
 	}
 
 	// reconciliation assumes unmanaged mode, in the future we may have a slot here for
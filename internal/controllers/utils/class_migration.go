@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"encoding/json"
+
+	extv1beta1 "k8s.io/api/extensions/v1beta1"
+	netv1 "k8s.io/api/networking/v1"
+	netv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
+)
+
+// ----------------------------------------------------------------------------
+// Class Migration - Public Functions
+// ----------------------------------------------------------------------------
+
+// BuildClassMigrationPatch builds a JSON merge patch which sets the .spec.ingressClassName of an object
+// to the value of its deprecated ingress class annotation. A patch is only built for objects which
+// support a class in their .spec, have the annotation set and have no class set in their .spec yet,
+// otherwise (nil, false) is returned. The annotation itself is left untouched by the patch.
+func BuildClassMigrationPatch(obj client.Object) (client.Patch, bool) {
+	switch obj.(type) {
+	case *netv1.Ingress, *netv1beta1.Ingress, *extv1beta1.Ingress:
+	default:
+		return nil, false
+	}
+
+	class := obj.GetAnnotations()[annotations.IngressClassKey]
+	if class == "" || specIngressClassOf(obj) != "" {
+		return nil, false
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"ingressClassName": class,
+		},
+	})
+	if err != nil {
+		return nil, false
+	}
+	return client.RawPatch(types.MergePatchType, data), true
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	netv1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
+)
+
+func TestBuildClassMigrationPatch(t *testing.T) {
+	kong := annotations.DefaultIngressClass
+	other := "other"
+	classAnnotation := map[string]string{annotations.IngressClassKey: kong}
+
+	for _, tt := range []struct {
+		name      string
+		obj       client.Object
+		wantPatch string
+	}{
+		{
+			name: "annotation only",
+			obj: &netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Annotations: classAnnotation},
+			},
+			wantPatch: `{"spec":{"ingressClassName":"kong"}}`,
+		},
+		{
+			name: "annotation only on a networking.k8s.io/v1beta1 ingress",
+			obj: &netv1beta1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Annotations: classAnnotation},
+			},
+			wantPatch: `{"spec":{"ingressClassName":"kong"}}`,
+		},
+		{
+			name: "spec only",
+			obj: &netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+				Spec:       netv1.IngressSpec{IngressClassName: &kong},
+			},
+		},
+		{
+			name: "both annotation and spec",
+			obj: &netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Annotations: classAnnotation},
+				Spec:       netv1.IngressSpec{IngressClassName: &other},
+			},
+		},
+		{
+			name: "neither annotation nor spec",
+			obj: &netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+			},
+		},
+		{
+			name: "object without a spec class",
+			obj: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Annotations: classAnnotation},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, ok := BuildClassMigrationPatch(tt.obj)
+			if tt.wantPatch == "" {
+				assert.False(t, ok)
+				assert.Nil(t, patch)
+				return
+			}
+
+			require.True(t, ok)
+			assert.Equal(t, types.MergePatchType, patch.Type())
+			data, err := patch.Data(tt.obj)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.wantPatch, string(data))
+		})
+	}
+}
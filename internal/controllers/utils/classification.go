@@ -0,0 +1,87 @@
+package utils
+
+import (
+	knative "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
+)
+
+// ----------------------------------------------------------------------------
+// Classification - Vars & Consts
+// ----------------------------------------------------------------------------
+
+const (
+	// ClassificationReasonSpecMatches indicates that the class in the .spec of the object matches.
+	ClassificationReasonSpecMatches = "spec class matches"
+
+	// ClassificationReasonAnnotationMatches indicates that the class annotation of the object matches.
+	ClassificationReasonAnnotationMatches = "annotation matches"
+
+	// ClassificationReasonDefaultClass indicates that the object has no class and the class is the default.
+	ClassificationReasonDefaultClass = "default class, no class set"
+
+	// ClassificationReasonNoClass indicates that the object has no class and the class is not the default.
+	ClassificationReasonNoClass = "no class set, not the default class"
+
+	// ClassificationReasonMismatch indicates that the object has a class which does not match.
+	ClassificationReasonMismatch = "class mismatch"
+)
+
+// ----------------------------------------------------------------------------
+// Classification - Public Types & Functions
+// ----------------------------------------------------------------------------
+
+// Classification describes whether an object would be reconciled for an ingress class, and why.
+type Classification struct {
+	Key     client.ObjectKey
+	Matched bool
+	Reason  string
+}
+
+// ClassifyObjects explains, for each of the provided objects, whether it would be reconciled for the provided
+// ingress class and why. It follows the same rules as MatchesClass, but doesn't record any metrics so it can be
+// used for dry runs.
+func ClassifyObjects(objs []client.Object, class string, isDefault bool) []Classification {
+	classifications := make([]Classification, 0, len(objs))
+	for _, obj := range objs {
+		matched, reason := classifyObject(obj, class, isDefault)
+		classifications = append(classifications, Classification{
+			Key:     client.ObjectKeyFromObject(obj),
+			Matched: matched,
+			Reason:  reason,
+		})
+	}
+	return classifications
+}
+
+// ----------------------------------------------------------------------------
+// Classification - Private Functions
+// ----------------------------------------------------------------------------
+
+func classifyObject(obj client.Object, class string, isDefault bool) (bool, string) {
+	specClass := specIngressClassOf(obj)
+	if specClass != "" {
+		if specClass == class {
+			return true, ClassificationReasonSpecMatches
+		}
+		return false, ClassificationReasonMismatch
+	}
+
+	key := annotations.IngressClassKey
+	if _, ok := obj.(*knative.Ingress); ok {
+		key = annotations.KnativeIngressClassKey
+	}
+	annotationClass := obj.GetAnnotations()[key]
+	if annotationClass != "" && annotationClass == class {
+		return true, ClassificationReasonAnnotationMatches
+	}
+
+	if annotationClass == "" {
+		if isDefault {
+			return true, ClassificationReasonDefaultClass
+		}
+		return false, ClassificationReasonNoClass
+	}
+	return false, ClassificationReasonMismatch
+}
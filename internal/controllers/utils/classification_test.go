@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	knative "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
+)
+
+func TestClassifyObjects(t *testing.T) {
+	kong, nginx := annotations.DefaultIngressClass, "nginx"
+	meta := func(name string, anns map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: corev1.NamespaceDefault, Annotations: anns}
+	}
+	key := func(name string) client.ObjectKey {
+		return client.ObjectKey{Namespace: corev1.NamespaceDefault, Name: name}
+	}
+
+	objs := []client.Object{
+		&netv1.Ingress{ObjectMeta: meta("spec", nil), Spec: netv1.IngressSpec{IngressClassName: &kong}},
+		&netv1.Ingress{ObjectMeta: meta("annotation", map[string]string{annotations.IngressClassKey: kong})},
+		&knative.Ingress{ObjectMeta: meta("knative", map[string]string{annotations.KnativeIngressClassKey: kong})},
+		&netv1.Ingress{ObjectMeta: meta("classless", nil)},
+		&netv1.Ingress{ObjectMeta: meta("spec-mismatch", nil), Spec: netv1.IngressSpec{IngressClassName: &nginx}},
+		&corev1.Service{ObjectMeta: meta("annotation-mismatch", map[string]string{annotations.IngressClassKey: nginx})},
+	}
+
+	t.Log("classifying objects for the default class")
+	assert.Equal(t, []Classification{
+		{Key: key("spec"), Matched: true, Reason: ClassificationReasonSpecMatches},
+		{Key: key("annotation"), Matched: true, Reason: ClassificationReasonAnnotationMatches},
+		{Key: key("knative"), Matched: true, Reason: ClassificationReasonAnnotationMatches},
+		{Key: key("classless"), Matched: true, Reason: ClassificationReasonDefaultClass},
+		{Key: key("spec-mismatch"), Matched: false, Reason: ClassificationReasonMismatch},
+		{Key: key("annotation-mismatch"), Matched: false, Reason: ClassificationReasonMismatch},
+	}, ClassifyObjects(objs, kong, true))
+
+	t.Log("classifying objects for a class which is not the default")
+	classifications := ClassifyObjects(objs, kong, false)
+	assert.Equal(t, Classification{Key: key("classless"), Matched: false, Reason: ClassificationReasonNoClass}, classifications[3])
+
+	t.Log("verifying that classifications agree with MatchesClass")
+	for i, obj := range objs {
+		assert.Equal(t, MatchesClass(obj, kong, false), classifications[i].Matched)
+	}
+}
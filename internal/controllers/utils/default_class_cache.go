@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
+)
+
+// ----------------------------------------------------------------------------
+// DefaultClassCache - Public Functions
+// ----------------------------------------------------------------------------
+
+// IsDefaultIngressClass indicates whether the provided object is an IngressClass annotated as the default
+// IngressClass of the cluster.
+func IsDefaultIngressClass(obj client.Object) bool {
+	if _, ok := obj.(*netv1.IngressClass); !ok {
+		return false
+	}
+	return obj.GetAnnotations()[netv1.AnnotationIsDefaultIngressClass] == "true"
+}
+
+// ParseDefaultClassAnnotation reports the default IngressClass annotation of the provided object. isDefault is only
+// true for the exact value "true", as with IsDefaultIngressClass, present indicates whether the annotation is set at
+// all and raw is its value. This allows callers to warn about values such as "True" or "1" which are set but
+// don't make the class the default.
+func ParseDefaultClassAnnotation(obj client.Object) (isDefault bool, present bool, raw string) {
+	raw, present = obj.GetAnnotations()[netv1.AnnotationIsDefaultIngressClass]
+	return raw == "true", present, raw
+}
+
+// DefaultClassNamespaces parses the konghq.com/default-namespaces annotation of the provided IngressClass, which lists
+// the comma-separated namespaces within which the IngressClass is treated as the default IngressClass, so that
+// classless objects in those namespaces are adopted. Whitespace around the namespaces is ignored and repeated
+// namespaces are only listed once. The returned bool is false if the annotation is absent or malformed, i.e. empty,
+// with an empty entry or with an entry which isn't a valid namespace name.
+func DefaultClassNamespaces(ic *netv1.IngressClass) ([]string, bool) {
+	raw, ok := ic.GetAnnotations()[annotations.AnnotationPrefix+annotations.DefaultNamespacesKey]
+	if !ok {
+		return nil, false
+	}
+	var namespaces []string
+	seen := map[string]struct{}{}
+	for _, namespace := range strings.Split(raw, ",") {
+		namespace = strings.TrimSpace(namespace)
+		if len(validation.IsDNS1123Label(namespace)) > 0 {
+			return nil, false
+		}
+		if _, ok := seen[namespace]; ok {
+			continue
+		}
+		seen[namespace] = struct{}{}
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces, true
+}
+
+// ResolveDefaultClassFromConfigMap reads the name of the default ingress class from the provided key of a ConfigMap,
+// so that it can be changed at runtime without restarting the controller. An error wrapping the API error is returned
+// if the ConfigMap can't be retrieved, and an error is returned if the key is missing or empty.
+func ResolveDefaultClassFromConfigMap(ctx context.Context, c client.Reader, nn types.NamespacedName, key string) (string, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, nn, configMap); err != nil {
+		return "", fmt.Errorf("failed to get default ingress class ConfigMap %s: %w", nn, err)
+	}
+	class, ok := configMap.Data[key]
+	if !ok {
+		return "", fmt.Errorf("default ingress class ConfigMap %s has no %q key", nn, key)
+	}
+	class = strings.TrimSpace(class)
+	if class == "" {
+		return "", fmt.Errorf("default ingress class ConfigMap %s has an empty %q key", nn, key)
+	}
+	return class, nil
+}
+
+// ListKongIngressClasses lists the IngressClasses of the cluster which are served by the controller with the provided
+// name, i.e. whose spec.controller matches controllerName.
+func ListKongIngressClasses(ctx context.Context, c client.Reader, controllerName string) ([]netv1.IngressClass, error) {
+	classes := &netv1.IngressClassList{}
+	if err := c.List(ctx, classes); err != nil {
+		return nil, fmt.Errorf("failed to list IngressClasses: %w", err)
+	}
+	var owned []netv1.IngressClass
+	for _, class := range classes.Items {
+		if class.Spec.Controller == controllerName {
+			owned = append(owned, class)
+		}
+	}
+	return owned, nil
+}
+
+// ----------------------------------------------------------------------------
+// DefaultClassCache - Public Types
+// ----------------------------------------------------------------------------
+
+// DefaultClassCache caches the name of the default IngressClass of the cluster so that callers which need to know
+// it don't have to list all IngressClasses on every reconciliation. The cache is populated by Refresh, which should
+// be called whenever IngressClasses change, and is safe for concurrent use.
+type DefaultClassCache struct {
+	lock  sync.RWMutex
+	name  string
+	found bool
+}
+
+// ----------------------------------------------------------------------------
+// DefaultClassCache - Public Methods
+// ----------------------------------------------------------------------------
+
+// Refresh lists the IngressClasses of the cluster and caches the name of the default IngressClass. If several
+// IngressClasses are annotated as the default, the most recently created one is used, as Kubernetes does. The
+// cached value is left unchanged if the IngressClasses can't be listed.
+func (c *DefaultClassCache) Refresh(ctx context.Context, reader client.Reader) error {
+	classes := &netv1.IngressClassList{}
+	if err := reader.List(ctx, classes); err != nil {
+		return err
+	}
+
+	var defaultClass *netv1.IngressClass
+	for i := range classes.Items {
+		class := &classes.Items[i]
+		if !IsDefaultIngressClass(class) {
+			continue
+		}
+		if defaultClass == nil ||
+			defaultClass.CreationTimestamp.Before(&class.CreationTimestamp) ||
+			(defaultClass.CreationTimestamp.Equal(&class.CreationTimestamp) && class.Name < defaultClass.Name) {
+			defaultClass = class
+		}
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.name, c.found = "", defaultClass != nil
+	if defaultClass != nil {
+		c.name = defaultClass.Name
+	}
+	return nil
+}
+
+// DefaultClassName provides the name of the default IngressClass as of the last Refresh. The returned bool is false
+// if the cluster had no default IngressClass or the cache was never refreshed.
+func (c *DefaultClassCache) DefaultClassName() (string, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.name, c.found
+}
+
+// IsDefault indicates whether the provided ingress class is the default IngressClass as of the last Refresh.
+func (c *DefaultClassCache) IsDefault(class string) bool {
+	name, ok := c.DefaultClassName()
+	return ok && name == class
+}
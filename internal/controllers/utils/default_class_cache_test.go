@@ -0,0 +1,212 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
+)
+
+func TestDefaultClassCache(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, netv1.AddToScheme(scheme))
+	ctx := context.Background()
+	now := time.Now()
+	ingressClass := func(name string, isDefault bool, created time.Time) *netv1.IngressClass {
+		class := &netv1.IngressClass{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+		}}
+		if isDefault {
+			class.Annotations = map[string]string{netv1.AnnotationIsDefaultIngressClass: "true"}
+		}
+		return class
+	}
+
+	t.Log("verifying that the cache is empty before it's refreshed")
+	cache := &DefaultClassCache{}
+	_, ok := cache.DefaultClassName()
+	assert.False(t, ok)
+
+	t.Log("verifying that no default class is found in a cluster without a default IngressClass")
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		ingressClass("kong", false, now),
+		ingressClass("nginx", false, now),
+	).Build()
+	require.NoError(t, cache.Refresh(ctx, c))
+	name, ok := cache.DefaultClassName()
+	assert.False(t, ok)
+	assert.Empty(t, name)
+	assert.False(t, cache.IsDefault("kong"))
+
+	t.Log("verifying that a refresh picks up a new default IngressClass")
+	class := &netv1.IngressClass{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "kong"}, class))
+	class.Annotations = map[string]string{netv1.AnnotationIsDefaultIngressClass: "true"}
+	require.NoError(t, c.Update(ctx, class))
+	require.NoError(t, cache.Refresh(ctx, c))
+	name, ok = cache.DefaultClassName()
+	assert.True(t, ok)
+	assert.Equal(t, "kong", name)
+	assert.True(t, cache.IsDefault("kong"))
+	assert.False(t, cache.IsDefault("nginx"))
+
+	t.Log("verifying that the most recently created default IngressClass wins")
+	c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		ingressClass("kong", true, now.Add(-time.Hour)),
+		ingressClass("nginx", true, now),
+	).Build()
+	require.NoError(t, cache.Refresh(ctx, c))
+	name, ok = cache.DefaultClassName()
+	assert.True(t, ok)
+	assert.Equal(t, "nginx", name)
+
+	t.Log("verifying that the cache can be read concurrently while it's refreshed")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			name, ok := cache.DefaultClassName()
+			assert.True(t, ok)
+			assert.Equal(t, "nginx", name)
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, cache.Refresh(ctx, c))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestIsDefaultIngressClass(t *testing.T) {
+	annotated := metav1.ObjectMeta{Annotations: map[string]string{netv1.AnnotationIsDefaultIngressClass: "true"}}
+	assert.True(t, IsDefaultIngressClass(&netv1.IngressClass{ObjectMeta: annotated}))
+	assert.False(t, IsDefaultIngressClass(&netv1.IngressClass{}))
+	assert.False(t, IsDefaultIngressClass(&netv1.Ingress{ObjectMeta: annotated}))
+}
+
+func TestParseDefaultClassAnnotation(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		annotations map[string]string
+		isDefault   bool
+		present     bool
+		raw         string
+	}{
+		{name: "true", annotations: map[string]string{netv1.AnnotationIsDefaultIngressClass: "true"}, isDefault: true, present: true, raw: "true"},
+		{name: "True", annotations: map[string]string{netv1.AnnotationIsDefaultIngressClass: "True"}, present: true, raw: "True"},
+		{name: "1", annotations: map[string]string{netv1.AnnotationIsDefaultIngressClass: "1"}, present: true, raw: "1"},
+		{name: "false", annotations: map[string]string{netv1.AnnotationIsDefaultIngressClass: "false"}, present: true, raw: "false"},
+		{name: "empty", annotations: map[string]string{netv1.AnnotationIsDefaultIngressClass: ""}, present: true},
+		{name: "absent", annotations: map[string]string{"foo": "bar"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			class := &netv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "kong", Annotations: tt.annotations}}
+			isDefault, present, raw := ParseDefaultClassAnnotation(class)
+			assert.Equal(t, tt.isDefault, isDefault)
+			assert.Equal(t, tt.present, present)
+			assert.Equal(t, tt.raw, raw)
+			assert.Equal(t, IsDefaultIngressClass(class), isDefault)
+		})
+	}
+}
+
+func TestDefaultClassNamespaces(t *testing.T) {
+	key := annotations.AnnotationPrefix + annotations.DefaultNamespacesKey
+	for _, tt := range []struct {
+		name        string
+		annotations map[string]string
+		namespaces  []string
+		ok          bool
+	}{
+		{name: "single namespace", annotations: map[string]string{key: "team-a"}, namespaces: []string{"team-a"}, ok: true},
+		{name: "several namespaces", annotations: map[string]string{key: "team-a, team-b,team-c "}, namespaces: []string{"team-a", "team-b", "team-c"}, ok: true},
+		{name: "repeated namespaces", annotations: map[string]string{key: "team-a,team-b,team-a"}, namespaces: []string{"team-a", "team-b"}, ok: true},
+		{name: "empty", annotations: map[string]string{key: ""}},
+		{name: "blank", annotations: map[string]string{key: "  "}},
+		{name: "empty entry", annotations: map[string]string{key: "team-a,,team-b"}},
+		{name: "trailing comma", annotations: map[string]string{key: "team-a,"}},
+		{name: "invalid namespace name", annotations: map[string]string{key: "team-a,Team_B"}},
+		{name: "absent", annotations: map[string]string{"foo": "bar"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			class := &netv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "kong", Annotations: tt.annotations}}
+			namespaces, ok := DefaultClassNamespaces(class)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.namespaces, namespaces)
+		})
+	}
+}
+
+func TestResolveDefaultClassFromConfigMap(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	ctx := context.Background()
+	nn := types.NamespacedName{Namespace: "kong", Name: "ingress-config"}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: nn.Namespace, Name: nn.Name},
+		Data: map[string]string{
+			"default-ingress-class": " kong\n",
+			"empty":                 "",
+		},
+	}).Build()
+
+	t.Log("verifying that the class is read from the configured key")
+	class, err := ResolveDefaultClassFromConfigMap(ctx, c, nn, "default-ingress-class")
+	require.NoError(t, err)
+	assert.Equal(t, "kong", class)
+
+	t.Log("verifying that a missing or empty key is an error")
+	_, err = ResolveDefaultClassFromConfigMap(ctx, c, nn, "missing")
+	assert.EqualError(t, err, `default ingress class ConfigMap kong/ingress-config has no "missing" key`)
+	_, err = ResolveDefaultClassFromConfigMap(ctx, c, nn, "empty")
+	assert.EqualError(t, err, `default ingress class ConfigMap kong/ingress-config has an empty "empty" key`)
+
+	t.Log("verifying that a missing ConfigMap is an error")
+	_, err = ResolveDefaultClassFromConfigMap(ctx, c, types.NamespacedName{Namespace: "kong", Name: "missing"}, "default-ingress-class")
+	require.Error(t, err)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestListKongIngressClasses(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, netv1.AddToScheme(scheme))
+	ingressClass := func(name, controller string) *netv1.IngressClass {
+		return &netv1.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       netv1.IngressClassSpec{Controller: controller},
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		ingressClass("kong", IngressClassKongController),
+		ingressClass("kong-internal", IngressClassKongController),
+		ingressClass("nginx", "k8s.io/ingress-nginx"),
+		ingressClass("empty", ""),
+	).Build()
+
+	classes, err := ListKongIngressClasses(context.Background(), c, IngressClassKongController)
+	require.NoError(t, err)
+	names := make([]string, 0, len(classes))
+	for _, class := range classes {
+		names = append(names, class.Name)
+	}
+	assert.ElementsMatch(t, []string{"kong", "kong-internal"}, names)
+
+	classes, err = ListKongIngressClasses(context.Background(), c, "example.com/other")
+	require.NoError(t, err)
+	assert.Empty(t, classes)
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// ----------------------------------------------------------------------------
+// PredicateRegistry - Vars
+// ----------------------------------------------------------------------------
+
+// specIngressClassKinds are the kinds which have an ingress class in their .spec.
+var specIngressClassKinds = map[schema.GroupKind]struct{}{
+	{Group: "networking.k8s.io", Kind: "Ingress"}: {},
+	{Group: "extensions", Kind: "Ingress"}:        {},
+}
+
+// ----------------------------------------------------------------------------
+// PredicateRegistry - Public Types
+// ----------------------------------------------------------------------------
+
+// PredicateRegistry builds the ingress class filter predicates of the watched types from a single ingress class
+// filtering policy, so that the parameters of the predicates of the different types can't get out of sync. The spec
+// check of the policy only applies to the types which have an ingress class in their .spec.
+type PredicateRegistry struct {
+	// Name is the ingress class which objects are filtered by.
+	Name string
+	// SpecCheckEnabled enables the check of the ingress class in the .spec of the types which have one.
+	SpecCheckEnabled bool
+	// AnnotationCheckEnabled enables the check of the ingress class annotation.
+	AnnotationCheckEnabled bool
+	// LabelCheckEnabled enables the check of the IngressClassLabel of objects without an ingress class in their .spec
+	// or annotations.
+	LabelCheckEnabled bool
+	// RejectOnMisconfig makes predicates with both checks disabled reject every object.
+	RejectOnMisconfig bool
+	// IsDefault makes predicates accept classless objects, as the ingress class is the default class.
+	IsDefault bool
+	// StrictClass makes predicates reject classless objects even if IsDefault is set.
+	StrictClass bool
+	// RequireBoth makes the predicates of the types which have an ingress class in their .spec only accept objects
+	// with the ingress class in both their .spec and their annotation. Classless objects of any type are rejected.
+	RequireBoth bool
+	// Recorder records events on the objects which are filtered out because of another ingress class. It may be nil.
+	Recorder record.EventRecorder
+}
+
+// NewPredicateRegistry provides a PredicateRegistry for the provided ingress class with both the spec and the
+// annotation checks enabled.
+func NewPredicateRegistry(name string) *PredicateRegistry {
+	return &PredicateRegistry{
+		Name:                   name,
+		SpecCheckEnabled:       true,
+		AnnotationCheckEnabled: true,
+	}
+}
+
+// ----------------------------------------------------------------------------
+// PredicateRegistry - Public Methods
+// ----------------------------------------------------------------------------
+
+// For provides the ingress class filter predicate of the provided type.
+func (r *PredicateRegistry) For(gvk schema.GroupVersionKind) predicate.Funcs {
+	return GeneratePredicateFuncsForClassConfig(ClassConfig{
+		Name:                   r.Name,
+		SpecCheckEnabled:       r.SpecCheckEnabledFor(gvk),
+		AnnotationCheckEnabled: r.AnnotationCheckEnabled,
+		LabelCheckEnabled:      r.LabelCheckEnabled,
+		IsDefault:              r.IsDefault,
+		StrictClass:            r.StrictClass || r.RequireBoth,
+		RequireBoth:            r.RequireBoth && r.hasSpecIngressClass(gvk),
+	}, r.RejectOnMisconfig, r.Recorder)
+}
+
+// SpecCheckEnabledFor indicates whether the predicates of the provided type check the ingress class in its .spec,
+// which is only the case for types which have one.
+func (r *PredicateRegistry) SpecCheckEnabledFor(gvk schema.GroupVersionKind) bool {
+	return r.SpecCheckEnabled && r.hasSpecIngressClass(gvk)
+}
+
+// ----------------------------------------------------------------------------
+// PredicateRegistry - Private Methods
+// ----------------------------------------------------------------------------
+
+func (r *PredicateRegistry) hasSpecIngressClass(gvk schema.GroupVersionKind) bool {
+	_, ok := specIngressClassKinds[gvk.GroupKind()]
+	return ok
+}
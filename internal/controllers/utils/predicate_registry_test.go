@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
+	kongv1beta1 "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1beta1"
+)
+
+func TestPredicateRegistry(t *testing.T) {
+	kong := annotations.DefaultIngressClass
+	ingressGVK := netv1.SchemeGroupVersion.WithKind("Ingress")
+	tcpIngressGVK := kongv1beta1.GroupVersion.WithKind("TCPIngress")
+
+	t.Log("verifying that the spec check is enabled for Ingresses and skipped for TCPIngresses")
+	registry := NewPredicateRegistry(kong)
+	assert.True(t, registry.SpecCheckEnabledFor(ingressGVK))
+	assert.True(t, registry.SpecCheckEnabledFor(schema.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "Ingress"}))
+	assert.False(t, registry.SpecCheckEnabledFor(tcpIngressGVK))
+	assert.False(t, registry.SpecCheckEnabledFor(corev1.SchemeGroupVersion.WithKind("Service")))
+
+	t.Log("verifying that the predicate of Ingresses matches the class in their .spec")
+	ingress := &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "ing", Namespace: corev1.NamespaceDefault},
+		Spec:       netv1.IngressSpec{IngressClassName: &kong},
+	}
+	assert.True(t, registry.For(ingressGVK).Create(event.CreateEvent{Object: ingress}))
+
+	t.Log("verifying that the predicate of TCPIngresses matches the class annotation")
+	tcpIngress := &kongv1beta1.TCPIngress{ObjectMeta: metav1.ObjectMeta{
+		Name:        "tcp",
+		Namespace:   corev1.NamespaceDefault,
+		Annotations: map[string]string{annotations.IngressClassKey: kong},
+	}}
+	assert.True(t, registry.For(tcpIngressGVK).Create(event.CreateEvent{Object: tcpIngress}))
+
+	t.Log("verifying that a policy rejecting on misconfiguration without an annotation check rejects TCPIngresses, which have no spec check")
+	registry = &PredicateRegistry{Name: kong, SpecCheckEnabled: true, RejectOnMisconfig: true}
+	assert.False(t, registry.For(tcpIngressGVK).Create(event.CreateEvent{Object: tcpIngress}))
+	assert.True(t, registry.For(ingressGVK).Create(event.CreateEvent{Object: ingress}))
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"context"
+
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ----------------------------------------------------------------------------
+// Status Updates - Public Vars & Consts
+// ----------------------------------------------------------------------------
+
+// DefaultStatusUpdateRetries is the default number of times a status update which conflicts with a concurrent change
+// of the object is retried.
+const DefaultStatusUpdateRetries = 5
+
+// ----------------------------------------------------------------------------
+// Status Updates - Public Functions
+// ----------------------------------------------------------------------------
+
+// UpdateStatusWithRetry sets the status of an object with applyStatus and writes it with the status subresource. When
+// the write conflicts with a concurrent change of the object, the object is re-fetched, applyStatus re-applies the
+// status fields to the latest version and the write is retried, up to the provided number of retries, so that the
+// concurrent change isn't overwritten. applyStatus must only mutate the status of the object it was provided for.
+// The conflict error of the last attempt is returned once the retries are exhausted.
+func UpdateStatusWithRetry(ctx context.Context, c client.Client, obj client.Object, retries int, applyStatus func()) error {
+	backoff := retry.DefaultRetry
+	backoff.Steps = 1
+	if retries > 0 {
+		backoff.Steps += retries
+	}
+
+	attempt := 0
+	return retry.RetryOnConflict(backoff, func() error {
+		if attempt > 0 {
+			if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+				return err
+			}
+		}
+		attempt++
+		applyStatus()
+		return c.Status().Update(ctx, obj)
+	})
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// conflictingStatusClient is a client whose status writes fail with a conflict for the provided number of attempts
+// before going through.
+type conflictingStatusClient struct {
+	client.Client
+	conflicts int
+	attempts  int
+}
+
+func (c *conflictingStatusClient) Status() client.StatusWriter {
+	return &conflictingStatusWriter{StatusWriter: c.Client.Status(), client: c}
+}
+
+type conflictingStatusWriter struct {
+	client.StatusWriter
+	client *conflictingStatusClient
+}
+
+func (w *conflictingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	w.client.attempts++
+	if w.client.attempts <= w.client.conflicts {
+		return apierrors.NewConflict(netv1.Resource("ingresses"), obj.GetName(), assert.AnError)
+	}
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func TestUpdateStatusWithRetry(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, netv1.AddToScheme(scheme))
+	addrs := []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	newClient := func(conflicts int) (*conflictingStatusClient, *netv1.Ingress) {
+		ingress := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "test"}}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ingress).Build()
+		stale := &netv1.Ingress{}
+		require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(ingress), stale))
+
+		t.Log("changing the object concurrently so that the copy of the controller is stale")
+		current := stale.DeepCopy()
+		current.Labels = map[string]string{"foo": "bar"}
+		require.NoError(t, c.Update(context.Background(), current))
+		return &conflictingStatusClient{Client: c, conflicts: conflicts}, stale
+	}
+
+	t.Log("verifying that a conflicting status update is retried with the latest version of the object")
+	c, ingress := newClient(1)
+	require.NoError(t, UpdateStatusWithRetry(context.Background(), c, ingress, DefaultStatusUpdateRetries, func() {
+		ingress.Status.LoadBalancer.Ingress = addrs
+	}))
+	assert.Equal(t, 2, c.attempts)
+	updated := &netv1.Ingress{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(ingress), updated))
+	assert.Equal(t, addrs, updated.Status.LoadBalancer.Ingress)
+	assert.Equal(t, map[string]string{"foo": "bar"}, updated.Labels, "the concurrent change must not be overwritten")
+
+	t.Log("verifying that the conflict is returned once the retries are exhausted")
+	c, ingress = newClient(3)
+	err := UpdateStatusWithRetry(context.Background(), c, ingress, 2, func() {
+		ingress.Status.LoadBalancer.Ingress = addrs
+	})
+	assert.True(t, apierrors.IsConflict(err))
+	assert.Equal(t, 3, c.attempts)
+}
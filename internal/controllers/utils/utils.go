@@ -1,19 +1,105 @@
 package utils
 
 import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	extv1beta1 "k8s.io/api/extensions/v1beta1"
 	netv1 "k8s.io/api/networking/v1"
 	netv1beta1 "k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	knative "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/metrics"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/util"
+	kongv1beta1 "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1beta1"
 )
 
+// IngressClassKongController is the .spec.controller value of IngressClasses handled by Kong.
+const IngressClassKongController = "ingress-controllers.konghq.com/kong"
+
+// IngressClassLabel is the label which objects can be classed with instead of the ingress class annotation, e.g. when
+// GitOps tooling makes labels easier to manage than annotations. The label is only consulted for objects which have no
+// ingress class in their .spec or annotations.
+const IngressClassLabel = "konghq.com/ingress-class"
+
+// IngressClassMismatchReason is the reason of events recorded on objects skipped because of their ingress class.
+const IngressClassMismatchReason = "IngressClassMismatch"
+
+// classAnnotationKeys are the annotations which can configure the ingress class of an object.
+var classAnnotationKeys = []string{
+	annotations.IngressClassKey,
+	annotations.KnativeIngressClassKey,
+	annotations.KnativeIngressClassAnnotationKey,
+}
+
+// ClassMatchRecorder records the outcome of ingress class filtering for an object kind. Outcomes are one of
+// metrics.ClassOutcomeMatched, metrics.ClassOutcomeMatchedDefault, metrics.ClassOutcomeDroppedMismatch
+// or metrics.ClassOutcomeDroppedEmpty.
+type ClassMatchRecorder interface {
+	RecordClassMatch(kind, outcome string)
+}
+
+// classMatchRecorder holds the recorder used by the class matching helpers and predicates. Predicates are evaluated
+// concurrently by every controller, so the recorder is swapped atomically.
+var classMatchRecorder atomic.Value
+
+// classMatchRecorderHolder wraps recorders so that every value stored in classMatchRecorder has the same type, as
+// required by atomic.Value.
+type classMatchRecorderHolder struct {
+	ClassMatchRecorder
+}
+
+// SetClassMatchRecorder replaces the recorder used by the class matching helpers and predicates and returns the
+// previously configured recorder so that it can be restored. It is safe to call while controllers are running.
+func SetClassMatchRecorder(recorder ClassMatchRecorder) ClassMatchRecorder {
+	return classMatchRecorder.Swap(classMatchRecorderHolder{recorder}).(classMatchRecorderHolder).ClassMatchRecorder
+}
+
+// DebugLogger returns the logger to use for debug logs about the provided object. Debug logs are normally emitted at
+// debug verbosity, but for objects annotated with konghq.com/debug: "true" they are emitted at the verbosity of the
+// provided logger so that a single object can be traced without raising the verbosity of all logs.
+func DebugLogger(log logr.Logger, obj client.Object) logr.Logger {
+	if annotations.ExtractDebug(obj.GetAnnotations()) {
+		return log
+	}
+	return log.V(util.DebugLevel)
+}
+
+// classMatchLogger holds the logger used by the class matching helpers and predicates to log their decisions. Like
+// classMatchRecorder, it is swapped atomically as predicates are evaluated concurrently by every controller.
+var classMatchLogger atomic.Value
+
+func init() {
+	classMatchRecorder.Store(classMatchRecorderHolder{metrics.ClassFilterRecorder{}})
+	classMatchLogger.Store(logr.Discard())
+}
+
+// SetLogger replaces the logger used by the class matching helpers and predicates, which log the class of every
+// object they evaluate and the resulting decision at debug level, and returns the previously configured logger.
+// Logging doesn't affect the decisions of the helpers. By default nothing is logged. It is safe to call while
+// controllers are running.
+func SetLogger(log logr.Logger) logr.Logger {
+	return classMatchLogger.Swap(log).(logr.Logger)
+}
+
 // HasAnnotation is a helper function to determine whether an object has a given annotation, and whether it's
 // to the value provided.
 func HasAnnotation(obj client.Object, key, expectedValue string) bool {
@@ -22,11 +108,16 @@ func HasAnnotation(obj client.Object, key, expectedValue string) bool {
 }
 
 // MatchesIngressClassName indicates whether or not an object indicates that it's supported by the ingress class name provided.
+// The class configured in the .spec of an object takes precedence over its ingress class annotation, so that objects
+// moved to another ingress class through their .spec are no longer supported even if they still have our annotation.
+// Ingresses with the konghq.com/ignore annotation are never supported.
 func MatchesIngressClassName(obj client.Object, ingressClassName string) bool {
-	if ing, ok := obj.(*netv1.Ingress); ok {
-		if ing.Spec.IngressClassName != nil && *ing.Spec.IngressClassName == ingressClassName {
-			return true
-		}
+	if isIgnoredIngress(obj) {
+		return false
+	}
+
+	if class := specIngressClassOf(obj); class != "" {
+		return class == ingressClassName
 	}
 
 	if _, ok := obj.(*knative.Ingress); ok {
@@ -36,43 +127,477 @@ func MatchesIngressClassName(obj client.Object, ingressClassName string) bool {
 	return HasAnnotation(obj, annotations.IngressClassKey, ingressClassName)
 }
 
+// MatchesClass indicates whether or not an object belongs to the provided ingress class: either its .spec or its
+// ingress class annotation are set to the class, or it has no class at all and the class is the default IngressClass.
+// An ingress class annotation set to an empty value is treated as if it were absent, i.e. the object is classless
+// and belongs to the default IngressClass.
+func MatchesClass(obj client.Object, class string, isDefault bool) bool {
+	return matchesClassFunc(obj, isDefault, func(objClass string) bool {
+		return objClass == class
+	})
+}
+
+// MatchesClassLabel indicates whether or not an object is labeled with the provided ingress class using the
+// IngressClassLabel. Unlike MatchesClass, neither the .spec nor the annotations of the object are consulted.
+func MatchesClassLabel(obj client.Object, class string) bool {
+	objClass := obj.GetLabels()[IngressClassLabel]
+	return objClass != "" && objClass == class
+}
+
+// MatchesClassWithAnnotations is the annotation-based logic of MatchesClass for callers which already have the
+// annotations of an object at hand. isKnative indicates whether the annotations belong to a Knative Ingress, which
+// is classed by its own annotation key. Unlike MatchesClass, the outcome is not recorded as there is no object kind.
+func MatchesClassWithAnnotations(anns map[string]string, isKnative bool, class string, isDefault bool) bool {
+	return isClassOutcomeMatched(classAnnotationsOutcome(anns, isKnative, isDefault, func(objClass string) bool {
+		return objClass == class
+	}))
+}
+
+// MatchesClassPattern behaves like MatchesClass, but supports a trailing "*" glob in the pattern so that a single
+// controller can claim a family of classes (e.g. "kong-tenant-*" matches "kong-tenant-a" and "kong-tenant-b").
+// Patterns without a trailing glob behave exactly like MatchesClass.
+func MatchesClassPattern(obj client.Object, pattern string, isDefault bool) bool {
+	prefix := strings.TrimSuffix(pattern, "*")
+	if prefix == pattern {
+		return MatchesClass(obj, pattern, isDefault)
+	}
+	return matchesClassFunc(obj, isDefault, func(objClass string) bool {
+		return strings.HasPrefix(objClass, prefix)
+	})
+}
+
+// IsIngressClassEmpty determines whether an object has no ingress class configured. Depending on the type of the
+// object the class is configured in its .spec, in its annotations, or in either.
+func IsIngressClassEmpty(obj client.Object) bool {
+	anns := obj.GetAnnotations()
+	switch obj := obj.(type) {
+	case *netv1.Ingress:
+		return (obj.Spec.IngressClassName == nil || *obj.Spec.IngressClassName == "") && anns[annotations.IngressClassKey] == ""
+	case *netv1beta1.Ingress:
+		return (obj.Spec.IngressClassName == nil || *obj.Spec.IngressClassName == "") && anns[annotations.IngressClassKey] == ""
+	case *extv1beta1.Ingress:
+		return (obj.Spec.IngressClassName == nil || *obj.Spec.IngressClassName == "") && anns[annotations.IngressClassKey] == ""
+	case *knative.Ingress:
+		return anns[annotations.KnativeIngressClassKey] == ""
+	case *corev1.Service, *kongv1beta1.TCPIngress, *kongv1beta1.UDPIngress:
+		return anns[annotations.IngressClassKey] == ""
+	case *gatewayv1alpha2.Gateway:
+		return obj.Spec.GatewayClassName == ""
+	}
+	return anns[annotations.IngressClassKey] == "" && anns[annotations.KnativeIngressClassKey] == ""
+}
+
+// IsClasslessAdoptedByUs determines whether the controller is responsible for an object because the object has no
+// ingress class configured and the controller's class is currently the default class. Classed objects are never
+// adopted, whether or not their class matches, so that reconcilers can tell objects relying on the default apart and
+// stop reconciling them once the default changes to another class.
+func IsClasslessAdoptedByUs(obj client.Object, currentDefaultIsOurs bool) bool {
+	return currentDefaultIsOurs && IsIngressClassEmpty(obj)
+}
+
+// IsMatchedByDefaultOnly indicates whether an object matches the provided ingress class, as with MatchesClass, only
+// because it has no ingress class configured and the class is the default class, as opposed to objects which opted in
+// to the class explicitly. The class doesn't change the outcome as classless objects match any default class, it's
+// accepted for parity with MatchesClass. Unlike MatchesClass, no outcome is recorded.
+func IsMatchedByDefaultOnly(obj client.Object, class string, isDefault bool) bool {
+	return isDefault && !isIgnoredIngress(obj) && IsIngressClassEmpty(obj)
+}
+
+// MigrateClassAnnotationToSpec moves the ingress class configured in the deprecated ingress class annotation of an
+// Ingress into its .spec.ingressClassName and removes the annotation, mutating the object in place. The
+// kubernetes.io/ingress.class annotation takes precedence over a konghq.com/ingress-class annotation, and both are
+// removed. Ingresses which already have a class in their .spec, and objects other than Ingresses, are left alone.
+// changed indicates whether the object was mutated and thus has to be updated by the caller.
+func MigrateClassAnnotationToSpec(obj client.Object) (changed bool) {
+	specClass := specIngressClassNameOf(obj)
+	if specClass == nil || (*specClass != nil && **specClass != "") {
+		return false
+	}
+
+	anns := obj.GetAnnotations()
+	var class string
+	for _, key := range []string{annotations.IngressClassKey, annotations.AnnotationPrefix + annotations.KongIngressClassKey} {
+		value, ok := anns[key]
+		if !ok {
+			continue
+		}
+		if class == "" {
+			class = strings.TrimSpace(value)
+		}
+		delete(anns, key)
+		changed = true
+	}
+	if !changed {
+		return false
+	}
+
+	obj.SetAnnotations(anns)
+	if class != "" {
+		*specClass = &class
+	}
+	return true
+}
+
+// DetectClassConflict indicates whether an object has both an ingress class configured in its .spec and an ingress
+// class annotation, and the two disagree. The configured classes are returned regardless of whether they conflict.
+func DetectClassConflict(obj client.Object) (conflict bool, specClass, annotationClass string) {
+	specClass = specIngressClassOf(obj)
+	annotationClass = obj.GetAnnotations()[annotations.IngressClassKey]
+	return specClass != "" && annotationClass != "" && specClass != annotationClass, specClass, annotationClass
+}
+
+// ClaimedClasses returns every ingress class an object claims across the class in its .spec, the standard ingress
+// class annotation, the Knative ingress class annotation and the legacy Knative ingress class annotation, in that
+// order and without duplicates. Unlike ingressClassOf, no precedence is applied, which makes it suitable for conflict
+// detection and auditing. nil is returned for classless objects.
+func ClaimedClasses(obj client.Object) []string {
+	anns := obj.GetAnnotations()
+	var classes []string
+	for _, class := range []string{
+		specIngressClassOf(obj),
+		anns[annotations.IngressClassKey],
+		anns[annotations.KnativeIngressClassAnnotationKey],
+		anns[annotations.KnativeIngressClassKey],
+	} {
+		if class == "" {
+			continue
+		}
+		duplicate := false
+		for _, claimed := range classes {
+			if claimed == class {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			classes = append(classes, class)
+		}
+	}
+	return classes
+}
+
+// ClassFieldsEqual indicates whether two versions of an object agree on all the fields which determine their ingress
+// class: the class in their .spec, the ingress class and Knative ingress class annotations and the ingress class
+// label. Changes of any other field are ignored, which allows reconcilers to return early when nothing
+// class-relevant changed. Objects of different types are never equal.
+func ClassFieldsEqual(a, b client.Object) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+	if specIngressClassOf(a) != specIngressClassOf(b) {
+		return false
+	}
+	annsA, annsB := a.GetAnnotations(), b.GetAnnotations()
+	for _, key := range classAnnotationKeys {
+		if annsA[key] != annsB[key] {
+			return false
+		}
+	}
+	return a.GetLabels()[IngressClassLabel] == b.GetLabels()[IngressClassLabel]
+}
+
+// ClassConfigHash provides a deterministic FNV-1a hash, hex-encoded, of the same fields ClassFieldsEqual compares: the
+// type of the object, the class in its .spec, the ingress class and Knative ingress class annotations and the ingress
+// class label. Fields are hashed in a fixed order, so the hash doesn't depend on the iteration order of the annotations
+// and labels, and objects which are ClassFieldsEqual always hash equally. Reconcilers can store the hash in an
+// annotation of the object and skip work when it's unchanged.
+func ClassConfigHash(obj client.Object) string {
+	h := fnv.New64a()
+	writeField := func(value string) {
+		// values are length-prefixed so that no two sequences of values hash the same input
+		fmt.Fprintf(h, "%d:%s", len(value), value)
+	}
+	writeField(reflect.TypeOf(obj).String())
+	writeField(specIngressClassOf(obj))
+	anns := obj.GetAnnotations()
+	for _, key := range classAnnotationKeys {
+		writeField(anns[key])
+	}
+	writeField(obj.GetLabels()[IngressClassLabel])
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// MatchesIngressClassExclude indicates whether or not an object should be supported when all ingress classes except
+// the provided excluded classes are supported. Objects without any ingress class are always supported.
+func MatchesIngressClassExclude(obj client.Object, excludedClasses []string) bool {
+	class := ingressClassOf(obj)
+	if class == "" {
+		return true
+	}
+	for _, excluded := range excludedClasses {
+		if class == excluded {
+			return false
+		}
+	}
+	return true
+}
+
 // GeneratePredicateFuncsForIngressClassFilter builds a controller-runtime reconciliation predicate function which filters out objects
 // which do not have the "kubernetes.io/ingress.class" annotation configured and set to the provided value or in their .spec.
-func GeneratePredicateFuncsForIngressClassFilter(name string, specCheckEnabled, annotationCheckEnabled bool) predicate.Funcs {
+//
+// Disabling both the spec and the annotation check is a misconfiguration which is logged when the predicate is built. When
+// rejectOnMisconfig is true, such a predicate rejects every object so that the misconfiguration is obvious. When
+// strictClass is true, objects without any ingress class (see IsIngressClassEmpty) are filtered out, so that the
+// controller never claims objects which weren't explicitly assigned to it. Use GeneratePredicateFuncsForClassConfig to
+// also match objects by their IngressClassLabel or to accept classless objects for the default class.
+func GeneratePredicateFuncsForIngressClassFilter(name string, specCheckEnabled, annotationCheckEnabled, rejectOnMisconfig, strictClass bool) predicate.Funcs {
+	return GeneratePredicateFuncsForIngressClassFilterWithRecorder(name, specCheckEnabled, annotationCheckEnabled, rejectOnMisconfig, strictClass, nil)
+}
+
+// GeneratePredicateFuncsForIngressClassFilterWithRecorder behaves like GeneratePredicateFuncsForIngressClassFilter, but
+// additionally records an IngressClassMismatchReason event on objects which are filtered out because they are configured
+// with another ingress class. Classless objects are filtered out silently. The recorder may be nil.
+func GeneratePredicateFuncsForIngressClassFilterWithRecorder(
+	name string,
+	specCheckEnabled, annotationCheckEnabled, rejectOnMisconfig, strictClass bool,
+	recorder record.EventRecorder,
+) predicate.Funcs {
+	return GeneratePredicateFuncsForClassConfig(ClassConfig{
+		Name:                   name,
+		SpecCheckEnabled:       specCheckEnabled,
+		AnnotationCheckEnabled: annotationCheckEnabled,
+		StrictClass:            strictClass,
+	}, rejectOnMisconfig, recorder)
+}
+
+// GeneratePredicateFuncsForClassConfig behaves like GeneratePredicateFuncsForIngressClassFilterWithRecorder for the
+// checks enabled in the provided ingress class configuration, which allows the IngressClassLabel to be consulted as a
+// fallback for objects without an ingress class in their .spec or annotations, and classless objects to be accepted
+// when the class is the default class. With StrictClass, classless objects are always filtered out. With RequireBoth,
+// objects are only accepted if both their .spec and their annotation are configured with the ingress class.
+func GeneratePredicateFuncsForClassConfig(cfg ClassConfig, rejectOnMisconfig bool, recorder record.EventRecorder) predicate.Funcs {
+	if !cfg.RequireBoth && !cfg.SpecCheckEnabled && !cfg.AnnotationCheckEnabled && !cfg.LabelCheckEnabled {
+		ctrl.Log.WithName("predicates").Error(
+			fmt.Errorf("the ingress class spec, annotation and label checks are disabled"),
+			"ingress class filter is misconfigured: no object can match ingress class", "class", cfg.Name, "rejectOnMisconfig", rejectOnMisconfig,
+		)
+		if rejectOnMisconfig {
+			return predicate.NewPredicateFuncs(func(client.Object) bool { return false })
+		}
+	}
+
 	preds := predicate.NewPredicateFuncs(func(obj client.Object) bool {
-		if annotationCheckEnabled && IsIngressClassAnnotationConfigured(obj, name) {
-			return true
+		outcome := metrics.ClassOutcomeMatched
+		if !ShouldReconcile(obj, cfg) {
+			outcome = metrics.ClassOutcomeDroppedMismatch
+			if isIgnoredIngress(obj) {
+				outcome = metrics.ClassOutcomeDroppedIgnored
+			} else if IsIngressClassEmpty(obj) && labelClassOf(obj, cfg) == "" {
+				outcome = metrics.ClassOutcomeDroppedEmpty
+			}
 		}
-		if specCheckEnabled && IsIngressClassSpecConfigured(obj, name) {
-			return true
+		recordClassMatch(obj, outcome)
+		if outcome == metrics.ClassOutcomeDroppedMismatch && recorder != nil {
+			class := ingressClassOf(obj)
+			if class == "" {
+				class = labelClassOf(obj, cfg)
+			}
+			if cfg.RequireBoth && class == cfg.Name {
+				recorder.Eventf(obj, corev1.EventTypeNormal, IngressClassMismatchReason,
+					"object skipped: ingress class %q must be configured in both the .spec and the %s annotation",
+					cfg.Name, annotations.IngressClassKey)
+			} else {
+				recorder.Eventf(obj, corev1.EventTypeNormal, IngressClassMismatchReason,
+					"object skipped: expected ingress class %q, found %q", cfg.Name, class)
+			}
 		}
-		return false
+		return outcome == metrics.ClassOutcomeMatched
 	})
 	preds.UpdateFunc = func(e event.UpdateEvent) bool {
-		if annotationCheckEnabled && IsIngressClassAnnotationConfigured(e.ObjectOld, name) || IsIngressClassAnnotationConfigured(e.ObjectNew, name) {
+		return ShouldReconcile(e.ObjectOld, cfg) || ShouldReconcile(e.ObjectNew, cfg)
+	}
+	return preds
+}
+
+// GeneratePredicateFuncsWithNamespaceFilter builds a controller-runtime reconciliation predicate function like
+// GeneratePredicateFuncsForIngressClassFilter which additionally filters out objects whose namespace is not in the
+// provided allow-list before the ingress class checks are applied. An empty allow-list allows all namespaces, and
+// cluster-scoped objects are never filtered out by their namespace.
+func GeneratePredicateFuncsWithNamespaceFilter(
+	name string,
+	specCheckEnabled, annotationCheckEnabled, rejectOnMisconfig, strictClass bool,
+	namespaces []string,
+) predicate.Funcs {
+	classPreds := GeneratePredicateFuncsForIngressClassFilter(name, specCheckEnabled, annotationCheckEnabled, rejectOnMisconfig, strictClass)
+	if len(namespaces) == 0 {
+		return classPreds
+	}
+
+	allowed := make(map[string]struct{}, len(namespaces))
+	for _, namespace := range namespaces {
+		allowed[namespace] = struct{}{}
+	}
+	inAllowedNamespace := func(obj client.Object) bool {
+		if obj.GetNamespace() == "" {
 			return true
 		}
-		if specCheckEnabled && IsIngressClassSpecConfigured(e.ObjectOld, name) || IsIngressClassSpecConfigured(e.ObjectNew, name) {
+		_, ok := allowed[obj.GetNamespace()]
+		return ok
+	}
+
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return inAllowedNamespace(e.Object) && classPreds.Create(e)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return inAllowedNamespace(e.Object) && classPreds.Delete(e)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return inAllowedNamespace(e.ObjectNew) && classPreds.Update(e)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return inAllowedNamespace(e.Object) && classPreds.Generic(e)
+		},
+	}
+}
+
+// GeneratePredicateFuncsForIngressClassFilterWithDenyList builds a controller-runtime reconciliation predicate function
+// like GeneratePredicateFuncsForIngressClassFilter which additionally filters out objects whose ingress class, in their
+// .spec or in their annotations, is one of the provided denied classes.
+//
+// The deny-list only applies to objects which have a class explicitly set: classless objects are still handled by the
+// default class logic (i.e. they are never denied), as they don't belong to any other controller yet.
+func GeneratePredicateFuncsForIngressClassFilterWithDenyList(
+	name string,
+	specCheckEnabled, annotationCheckEnabled, rejectOnMisconfig, strictClass bool,
+	deniedClasses []string,
+) predicate.Funcs {
+	classPreds := GeneratePredicateFuncsForIngressClassFilter(name, specCheckEnabled, annotationCheckEnabled, rejectOnMisconfig, strictClass)
+	if len(deniedClasses) == 0 {
+		return classPreds
+	}
+
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return !isIngressClassDenied(e.Object, deniedClasses) && classPreds.Create(e)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return !isIngressClassDenied(e.Object, deniedClasses) && classPreds.Delete(e)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !isIngressClassDenied(e.ObjectNew, deniedClasses) && classPreds.Update(e)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return !isIngressClassDenied(e.Object, deniedClasses) && classPreds.Generic(e)
+		},
+	}
+}
+
+// GenerateClassTransitionPredicate builds a controller-runtime reconciliation predicate function which filters create,
+// delete and generic events like GeneratePredicateFuncsForIngressClassFilter with both the spec and the annotation
+// checks enabled, but which only lets update events through when the effective ingress class of the object changed,
+// including when an object gained or lost its class. Other updates are filtered out to reduce reconciliation churn.
+func GenerateClassTransitionPredicate(name string) predicate.Funcs {
+	preds := GeneratePredicateFuncsForIngressClassFilter(name, true, true, false, false)
+	preds.UpdateFunc = func(e event.UpdateEvent) bool {
+		return ingressClassOf(e.ObjectOld) != ingressClassOf(e.ObjectNew) ||
+			IsIngressClassEmpty(e.ObjectOld) != IsIngressClassEmpty(e.ObjectNew)
+	}
+	return preds
+}
+
+// IgnoreDeletingPredicate builds a controller-runtime reconciliation predicate function which filters out update events
+// of objects pending deletion, i.e. with a deletion timestamp, which only wait for finalizers other than the provided
+// finalizers of the controller: the objects are about to be removed, and the delete event which follows still passes.
+// Other events always pass, so that the predicate can be composed with the ingress class predicates using
+// predicate.And.
+func IgnoreDeletingPredicate(ownFinalizers ...string) predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !isPendingForeignFinalizers(e.ObjectNew, ownFinalizers)
+		},
+	}
+}
+
+// ClassConfig is the ingress class configuration of a controller.
+type ClassConfig struct {
+	// Name is the name of the ingress class handled by the controller.
+	Name string
+
+	// SpecCheckEnabled indicates whether objects are matched by the ingress class in their .spec.
+	SpecCheckEnabled bool
+
+	// AnnotationCheckEnabled indicates whether objects are matched by their ingress class annotation.
+	AnnotationCheckEnabled bool
+
+	// LabelCheckEnabled indicates whether objects without an ingress class in their .spec or annotations are
+	// matched by their IngressClassLabel.
+	LabelCheckEnabled bool
+
+	// IsDefault indicates whether the ingress class is the default class, in which case classless objects match.
+	IsDefault bool
+
+	// StrictClass indicates whether classless objects are rejected even if the ingress class is the default class,
+	// so that the controller never claims objects which weren't explicitly assigned to it.
+	StrictClass bool
+
+	// RequireBoth indicates whether objects only match when both the ingress class in their .spec and their ingress
+	// class annotation are the ingress class, e.g. to rule out any ambiguity with overlapping controllers. It takes
+	// precedence over the other checks, and classless or labeled objects never match.
+	RequireBoth bool
+}
+
+// ShouldReconcile indicates whether an object would be reconciled by a controller with the provided ingress class
+// configuration. This is the decision logic of the predicates built by GeneratePredicateFuncsForIngressClassFilter,
+// which should be used by any other check so that the two can't drift apart. Ingresses with the konghq.com/ignore
+// annotation are never reconciled. As in MatchesIngressClassName, which the reconcilers check, the class configured
+// in the .spec takes precedence over the class annotations.
+func ShouldReconcile(obj client.Object, cfg ClassConfig) bool {
+	if isIgnoredIngress(obj) {
+		return false
+	}
+	if class := specIngressClassOf(obj); class != "" && class != cfg.Name {
+		return false
+	}
+	if cfg.RequireBoth {
+		return IsIngressClassSpecConfigured(obj, cfg.Name) && IsIngressClassAnnotationConfigured(obj, cfg.Name)
+	}
+	if cfg.AnnotationCheckEnabled && IsIngressClassAnnotationConfigured(obj, cfg.Name) {
+		return true
+	}
+	if cfg.SpecCheckEnabled && IsIngressClassSpecConfigured(obj, cfg.Name) {
+		return true
+	}
+	if !IsIngressClassEmpty(obj) {
+		return false
+	}
+	if labelClass := labelClassOf(obj, cfg); labelClass != "" {
+		return labelClass == cfg.Name
+	}
+	return cfg.IsDefault && !cfg.StrictClass
+}
+
+// GeneratePredicateFuncsForIngressClass builds a controller-runtime reconciliation predicate function for IngressClass
+// objects which filters out IngressClasses that are not handled by the provided controller. On update, events are
+// also passed when the is-default-class annotation changed so that default class toggles are never missed.
+func GeneratePredicateFuncsForIngressClass(controllerName string) predicate.Funcs {
+	preds := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return isIngressClassControlledBy(obj, controllerName)
+	})
+	preds.UpdateFunc = func(e event.UpdateEvent) bool {
+		if isIngressClassControlledBy(e.ObjectOld, controllerName) || isIngressClassControlledBy(e.ObjectNew, controllerName) {
 			return true
 		}
-		return false
+		return e.ObjectOld.GetAnnotations()[netv1.AnnotationIsDefaultIngressClass] !=
+			e.ObjectNew.GetAnnotations()[netv1.AnnotationIsDefaultIngressClass]
 	}
 	return preds
 }
 
 // IsIngressClassAnnotationConfigured determines whether an object has an ingress.class annotation configured that
 // matches the provide IngressClassName (and is therefore an object configured to be reconciled by that class).
+// Annotations set to an empty value are not considered configured, as with MatchesClass.
 //
 // NOTE: keep in mind that the ingress.class annotation is deprecated and will be removed in a future release
 //       of Kubernetes in favor of the .spec based implementation.
 func IsIngressClassAnnotationConfigured(obj client.Object, expectedIngressClassName string) bool {
-	if foundIngressClassName, ok := obj.GetAnnotations()[annotations.IngressClassKey]; ok {
+	if foundIngressClassName, ok := obj.GetAnnotations()[annotations.IngressClassKey]; ok && foundIngressClassName != "" {
 		if foundIngressClassName == expectedIngressClassName {
 			return true
 		}
 	}
 
-	if foundIngressClassName, ok := obj.GetAnnotations()[annotations.KnativeIngressClassKey]; ok {
+	if foundIngressClassName, ok := obj.GetAnnotations()[annotations.KnativeIngressClassKey]; ok && foundIngressClassName != "" {
 		if foundIngressClassName == expectedIngressClassName {
 			return true
 		}
@@ -83,6 +608,7 @@ func IsIngressClassAnnotationConfigured(obj client.Object, expectedIngressClassN
 
 // IsIngressClassAnnotationConfigured determines whether an object has IngressClassName field in its spec and whether the value
 // matches the provide IngressClassName (and is therefore an object configured to be reconciled by that class).
+// Gateways are matched by their .spec.gatewayClassName and GatewayClasses by their own name.
 func IsIngressClassSpecConfigured(obj client.Object, expectedIngressClassName string) bool {
 	switch obj := obj.(type) {
 	case *netv1.Ingress:
@@ -91,12 +617,268 @@ func IsIngressClassSpecConfigured(obj client.Object, expectedIngressClassName st
 		return obj.Spec.IngressClassName != nil && *obj.Spec.IngressClassName == expectedIngressClassName
 	case *extv1beta1.Ingress:
 		return obj.Spec.IngressClassName != nil && *obj.Spec.IngressClassName == expectedIngressClassName
+	case *gatewayv1alpha2.Gateway:
+		return string(obj.Spec.GatewayClassName) == expectedIngressClassName
+	case *gatewayv1alpha2.GatewayClass:
+		return obj.Name == expectedIngressClassName
 	}
 	return false
 }
 
+// matchesClassFunc indicates whether the class configured in either the .spec or the annotations of an object is
+// accepted by the provided function. Classless objects match when isDefault is true. The outcome is recorded with
+// the configured ClassMatchRecorder.
+func matchesClassFunc(obj client.Object, isDefault bool, matches func(string) bool) bool {
+	_, isKnative := obj.(*knative.Ingress)
+	var outcome string
+	if isIgnoredIngress(obj) {
+		outcome = metrics.ClassOutcomeDroppedIgnored
+	} else if class := specIngressClassOf(obj); class != "" {
+		// as in MatchesIngressClassName, the class in the .spec takes precedence over the class annotations
+		outcome = metrics.ClassOutcomeMatched
+		if !matches(class) {
+			outcome = metrics.ClassOutcomeDroppedMismatch
+		}
+	} else {
+		outcome = classAnnotationsOutcome(obj.GetAnnotations(), isKnative, isDefault, matches)
+	}
+	recordClassMatch(obj, outcome)
+	return isClassOutcomeMatched(outcome)
+}
+
+// classAnnotationsOutcome determines the class filtering outcome for the class configured in the provided
+// annotations. Annotations without a class, including a class annotation set to an empty value, match by default
+// when isDefault is true.
+func classAnnotationsOutcome(anns map[string]string, isKnative bool, isDefault bool, matches func(string) bool) string {
+	key := annotations.IngressClassKey
+	if isKnative {
+		key = annotations.KnativeIngressClassKey
+	}
+	class := anns[key]
+	if class == "" {
+		if isDefault {
+			return metrics.ClassOutcomeMatchedDefault
+		}
+		return metrics.ClassOutcomeDroppedEmpty
+	}
+	if matches(class) {
+		return metrics.ClassOutcomeMatched
+	}
+	return metrics.ClassOutcomeDroppedMismatch
+}
+
+// isClassOutcomeMatched indicates whether a class filtering outcome accepts the object.
+func isClassOutcomeMatched(outcome string) bool {
+	return outcome == metrics.ClassOutcomeMatched || outcome == metrics.ClassOutcomeMatchedDefault
+}
+
+// recordClassMatch records a class filtering outcome for the kind of the provided object and logs it at debug level.
+// Objects retrieved from the cache usually have an empty TypeMeta, in which case the name of the Go type is used as
+// the kind.
+func recordClassMatch(obj client.Object, outcome string) {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if kind == "" {
+		kind = reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+	}
+	classMatchRecorder.Load().(classMatchRecorderHolder).RecordClassMatch(kind, outcome)
+	classMatchLogger.Load().(logr.Logger).V(util.DebugLevel).Info("ingress class resolved",
+		"kind", kind, "namespace", obj.GetNamespace(), "name", obj.GetName(),
+		"class", ingressClassOf(obj), "outcome", outcome, "matched", isClassOutcomeMatched(outcome))
+}
+
+// specIngressClassOf returns the ingress class configured in the .spec of an object, if any.
+//
+// NOTE: Knative Ingresses are classed by their annotations only, the knative.dev/networking API this controller
+//       is built against has no class field in the .spec of an Ingress. Once it has, it belongs here so that
+//       it takes precedence over the annotations of Knative Ingresses as it does for other Ingresses.
+func specIngressClassOf(obj client.Object) string {
+	switch obj := obj.(type) {
+	case *netv1.Ingress:
+		if obj.Spec.IngressClassName != nil {
+			return *obj.Spec.IngressClassName
+		}
+	case *netv1beta1.Ingress:
+		if obj.Spec.IngressClassName != nil {
+			return *obj.Spec.IngressClassName
+		}
+	case *extv1beta1.Ingress:
+		if obj.Spec.IngressClassName != nil {
+			return *obj.Spec.IngressClassName
+		}
+	}
+	return ""
+}
+
+// isIgnoredIngress indicates whether an object is an Ingress excluded from the controller by the konghq.com/ignore
+// annotation, regardless of its ingress class.
+func isIgnoredIngress(obj client.Object) bool {
+	switch obj.(type) {
+	case *netv1.Ingress, *netv1beta1.Ingress, *extv1beta1.Ingress, *knative.Ingress:
+		return annotations.IsIgnored(obj.GetAnnotations())
+	}
+	return false
+}
+
+// specIngressClassNameOf returns a pointer to the .spec.ingressClassName field of an Ingress, or nil for objects
+// other than Ingresses.
+func specIngressClassNameOf(obj client.Object) **string {
+	switch obj := obj.(type) {
+	case *netv1.Ingress:
+		return &obj.Spec.IngressClassName
+	case *netv1beta1.Ingress:
+		return &obj.Spec.IngressClassName
+	case *extv1beta1.Ingress:
+		return &obj.Spec.IngressClassName
+	}
+	return nil
+}
+
+// ingressClassOf returns the effective ingress class of an object: the class configured in its .spec takes
+// precedence over the class configured in its annotations. An empty string is returned for classless objects.
+func ingressClassOf(obj client.Object) string {
+	if class := specIngressClassOf(obj); class != "" {
+		return class
+	}
+	if _, ok := obj.(*knative.Ingress); ok {
+		return obj.GetAnnotations()[annotations.KnativeIngressClassKey]
+	}
+	return obj.GetAnnotations()[annotations.IngressClassKey]
+}
+
+// labelClassOf returns the ingress class of an object's IngressClassLabel if the label check of the provided ingress
+// class configuration is enabled, and an empty string otherwise.
+func labelClassOf(obj client.Object, cfg ClassConfig) string {
+	if !cfg.LabelCheckEnabled {
+		return ""
+	}
+	return obj.GetLabels()[IngressClassLabel]
+}
+
+// isPendingForeignFinalizers indicates whether an object is pending deletion and only waits for finalizers which
+// aren't one of the provided finalizers.
+func isPendingForeignFinalizers(obj client.Object, ownFinalizers []string) bool {
+	if obj.GetDeletionTimestamp() == nil || len(obj.GetFinalizers()) == 0 {
+		return false
+	}
+	for _, finalizer := range obj.GetFinalizers() {
+		for _, own := range ownFinalizers {
+			if finalizer == own {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isIngressClassDenied determines whether the ingress class configured in the .spec or in the annotations of an object
+// is one of the provided denied classes. Classless objects are never denied.
+func isIngressClassDenied(obj client.Object, deniedClasses []string) bool {
+	classes := []string{
+		specIngressClassOf(obj),
+		obj.GetAnnotations()[annotations.IngressClassKey],
+		obj.GetAnnotations()[annotations.KnativeIngressClassKey],
+	}
+	for _, class := range classes {
+		if class == "" {
+			continue
+		}
+		for _, denied := range deniedClasses {
+			if class == denied {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isIngressClassControlledBy determines whether an object is an IngressClass handled by the provided controller.
+func isIngressClassControlledBy(obj client.Object, controllerName string) bool {
+	class, ok := obj.(*netv1.IngressClass)
+	return ok && class.Spec.Controller == controllerName
+}
+
+// EnqueueAllClassless lists the objects of each of the provided kinds and returns reconcile requests for all of
+// those which have no ingress class configured, e.g. to re-enqueue them when the default IngressClass changes.
+// Kinds which are not known to the client or not installed in the cluster are skipped.
+func EnqueueAllClassless(ctx context.Context, c client.Client, kinds []schema.GroupVersionKind) ([]reconcile.Request, error) {
+	var recs []reconcile.Request
+	for _, gvk := range kinds {
+		listGVK := gvk.GroupVersion().WithKind(gvk.Kind + "List")
+		obj, err := c.Scheme().New(listGVK)
+		if err != nil {
+			if runtime.IsNotRegisteredError(err) {
+				continue
+			}
+			return nil, err
+		}
+		list, ok := obj.(client.ObjectList)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a list type", listGVK)
+		}
+		if err := c.List(ctx, list); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list %s: %w", gvk.Kind, err)
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			itemObj, ok := item.(client.Object)
+			if !ok || !IsIngressClassEmpty(itemObj) {
+				continue
+			}
+			recs = append(recs, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: itemObj.GetNamespace(),
+					Name:      itemObj.GetName(),
+				},
+			})
+		}
+	}
+	return recs, nil
+}
+
+// ingressGVKs are the kinds of Ingresses which the controller can handle, in order of preference.
+var ingressGVKs = []schema.GroupVersionKind{
+	netv1.SchemeGroupVersion.WithKind("Ingress"),
+	netv1beta1.SchemeGroupVersion.WithKind("Ingress"),
+	extv1beta1.SchemeGroupVersion.WithKind("Ingress"),
+	knative.SchemeGroupVersion.WithKind("Ingress"),
+	kongv1beta1.SchemeGroupVersion.WithKind("TCPIngress"),
+	kongv1beta1.SchemeGroupVersion.WithKind("UDPIngress"),
+}
+
+// SupportedIngressGVKs returns the kinds of Ingresses which the controller can handle that are served by the
+// apiserver, as resolved with the RESTMapper of the client, in order of preference: the networking.k8s.io/v1,
+// networking.k8s.io/v1beta1 and extensions/v1beta1 Ingresses, Knative Ingresses, TCPIngresses and UDPIngresses. As
+// with CRDExists, kinds are only omitted when the RESTMapper doesn't know them.
+func SupportedIngressGVKs(c client.Client) []schema.GroupVersionKind {
+	var supported []schema.GroupVersionKind
+	for _, gvk := range ingressGVKs {
+		if _, err := GVRForKind(c, gvk); meta.IsNoMatchError(err) {
+			continue
+		}
+		supported = append(supported, gvk)
+	}
+	return supported
+}
+
 // CRDExists returns false if CRD does not exist
 func CRDExists(client client.Client, gvr schema.GroupVersionResource) bool {
 	_, err := client.RESTMapper().KindFor(gvr)
 	return !meta.IsNoMatchError(err)
 }
+
+// GVRForKind resolves the resource of the provided kind with the RESTMapper of the client, e.g. "tcpingresses" for
+// the TCPIngress kind, so that callers of CRDExists don't have to pluralize kinds by hand. A no match error (see
+// meta.IsNoMatchError) is returned if the apiserver doesn't serve the kind.
+func GVRForKind(c client.Client, gvk schema.GroupVersionKind) (schema.GroupVersionResource, error) {
+	mapping, err := c.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	return mapping.Resource, nil
+}
@@ -0,0 +1,1679 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	extv1beta1 "k8s.io/api/extensions/v1beta1"
+	netv1 "k8s.io/api/networking/v1"
+	netv1beta1 "k8s.io/api/networking/v1beta1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	knative "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/metrics"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/util"
+	kongv1 "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1"
+	kongv1beta1 "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1beta1"
+)
+
+func TestMatchesIngressClassExclude(t *testing.T) {
+	nginx, kong := "nginx", "kong"
+	excluded := []string{"nginx", "traefik"}
+
+	for _, tt := range []struct {
+		name     string
+		obj      client.Object
+		expected bool
+	}{
+		{
+			name: "ingress with an excluded class in its spec",
+			obj: &netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "ing", Namespace: corev1.NamespaceDefault},
+				Spec:       netv1.IngressSpec{IngressClassName: &nginx},
+			},
+			expected: false,
+		},
+		{
+			name: "ingress with an excluded class in its annotations",
+			obj: &netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "ing",
+					Namespace:   corev1.NamespaceDefault,
+					Annotations: map[string]string{annotations.IngressClassKey: "traefik"},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "ingress with a non-excluded class",
+			obj: &netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "ing",
+					Namespace:   corev1.NamespaceDefault,
+					Annotations: map[string]string{annotations.IngressClassKey: annotations.DefaultIngressClass},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "ingress with a non-excluded class in its spec overriding an excluded annotation",
+			obj: &netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "ing",
+					Namespace:   corev1.NamespaceDefault,
+					Annotations: map[string]string{annotations.IngressClassKey: "nginx"},
+				},
+				Spec: netv1.IngressSpec{IngressClassName: &kong},
+			},
+			expected: true,
+		},
+		{
+			name: "ingress without a class",
+			obj: &netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "ing", Namespace: corev1.NamespaceDefault},
+			},
+			expected: true,
+		},
+		{
+			name: "knative ingress with an excluded class",
+			obj: &knative.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "ing",
+					Namespace:   corev1.NamespaceDefault,
+					Annotations: map[string]string{annotations.KnativeIngressClassKey: "nginx"},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "tcpingress without a class",
+			obj: &kongv1beta1.TCPIngress{
+				ObjectMeta: metav1.ObjectMeta{Name: "tcp", Namespace: corev1.NamespaceDefault},
+			},
+			expected: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MatchesIngressClassExclude(tt.obj, excluded))
+		})
+	}
+}
+
+func TestMatchesIngressClassName(t *testing.T) {
+	nginx, kong := "nginx", "kong"
+	meta := func(anns map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: "ing", Namespace: corev1.NamespaceDefault, Annotations: anns}
+	}
+
+	for _, tt := range []struct {
+		name            string
+		obj             client.Object
+		expected        bool
+		expectedDefault bool
+	}{
+		{
+			name:            "v1 ingress with the class in its spec",
+			obj:             &netv1.Ingress{ObjectMeta: meta(nil), Spec: netv1.IngressSpec{IngressClassName: &kong}},
+			expected:        true,
+			expectedDefault: true,
+		},
+		{
+			name:            "v1beta1 ingress with the class in its spec",
+			obj:             &netv1beta1.Ingress{ObjectMeta: meta(nil), Spec: netv1beta1.IngressSpec{IngressClassName: &kong}},
+			expected:        true,
+			expectedDefault: true,
+		},
+		{
+			name:            "v1beta1 ingress with another class in its spec",
+			obj:             &netv1beta1.Ingress{ObjectMeta: meta(nil), Spec: netv1beta1.IngressSpec{IngressClassName: &nginx}},
+			expected:        false,
+			expectedDefault: false,
+		},
+		{
+			name: "v1 ingress moved to another class in its spec but still annotated with the class",
+			obj: &netv1.Ingress{
+				ObjectMeta: meta(map[string]string{annotations.IngressClassKey: kong}),
+				Spec:       netv1.IngressSpec{IngressClassName: &nginx},
+			},
+			expected:        false,
+			expectedDefault: false,
+		},
+		{
+			name: "v1beta1 ingress moved to another class in its spec but still annotated with the class",
+			obj: &netv1beta1.Ingress{
+				ObjectMeta: meta(map[string]string{annotations.IngressClassKey: kong}),
+				Spec:       netv1beta1.IngressSpec{IngressClassName: &nginx},
+			},
+			expected:        false,
+			expectedDefault: false,
+		},
+		{
+			name:            "v1beta1 ingress with the class in its annotations",
+			obj:             &netv1beta1.Ingress{ObjectMeta: meta(map[string]string{annotations.IngressClassKey: kong})},
+			expected:        true,
+			expectedDefault: true,
+		},
+		{
+			name:            "v1beta1 ingress without a class",
+			obj:             &netv1beta1.Ingress{ObjectMeta: meta(nil)},
+			expected:        false,
+			expectedDefault: true,
+		},
+		{
+			name:            "extensions v1beta1 ingress with the class in its spec",
+			obj:             &extv1beta1.Ingress{ObjectMeta: meta(nil), Spec: extv1beta1.IngressSpec{IngressClassName: &kong}},
+			expected:        true,
+			expectedDefault: true,
+		},
+		{
+			name:            "extensions v1beta1 ingress with another class in its spec",
+			obj:             &extv1beta1.Ingress{ObjectMeta: meta(nil), Spec: extv1beta1.IngressSpec{IngressClassName: &nginx}},
+			expected:        false,
+			expectedDefault: false,
+		},
+		{
+			name:            "extensions v1beta1 ingress without a class",
+			obj:             &extv1beta1.Ingress{ObjectMeta: meta(nil)},
+			expected:        false,
+			expectedDefault: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MatchesIngressClassName(tt.obj, kong))
+			assert.Equal(t, tt.expectedDefault, MatchesClass(tt.obj, kong, true))
+
+			preds := GeneratePredicateFuncsForIngressClassFilter(kong, true, true, false, false)
+			assert.Equal(t, tt.expected, preds.Create(event.CreateEvent{Object: tt.obj}),
+				"the predicate must agree with the reconcilers")
+		})
+	}
+}
+
+func TestMigrateClassAnnotationToSpec(t *testing.T) {
+	kong := "kong"
+	other := "other"
+
+	t.Log("verifying that the ingress class annotation is moved into the .spec")
+	ingress := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		annotations.IngressClassKey: kong,
+		"konghq.com/ingress-class":  other,
+		"foo":                       "bar",
+	}}}
+	assert.True(t, MigrateClassAnnotationToSpec(ingress))
+	require.NotNil(t, ingress.Spec.IngressClassName)
+	assert.Equal(t, kong, *ingress.Spec.IngressClassName)
+	assert.Equal(t, map[string]string{"foo": "bar"}, ingress.Annotations)
+
+	t.Log("verifying that the migration is idempotent")
+	assert.False(t, MigrateClassAnnotationToSpec(ingress))
+	assert.Equal(t, kong, *ingress.Spec.IngressClassName)
+	assert.Equal(t, map[string]string{"foo": "bar"}, ingress.Annotations)
+
+	t.Log("verifying that the konghq.com/ingress-class annotation is migrated too")
+	legacy := &netv1beta1.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"konghq.com/ingress-class": kong}}}
+	assert.True(t, MigrateClassAnnotationToSpec(legacy))
+	require.NotNil(t, legacy.Spec.IngressClassName)
+	assert.Equal(t, kong, *legacy.Spec.IngressClassName)
+	assert.Empty(t, legacy.Annotations)
+
+	t.Log("verifying that ingresses with a class in their .spec are left alone")
+	ingress = &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{annotations.IngressClassKey: kong}},
+		Spec:       netv1.IngressSpec{IngressClassName: &other},
+	}
+	assert.False(t, MigrateClassAnnotationToSpec(ingress))
+	assert.Equal(t, other, *ingress.Spec.IngressClassName)
+	assert.Equal(t, map[string]string{annotations.IngressClassKey: kong}, ingress.Annotations)
+
+	t.Log("verifying that classless ingresses and other objects are left alone")
+	assert.False(t, MigrateClassAnnotationToSpec(&netv1.Ingress{}))
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{annotations.IngressClassKey: kong}}}
+	assert.False(t, MigrateClassAnnotationToSpec(service))
+	assert.Equal(t, map[string]string{annotations.IngressClassKey: kong}, service.Annotations)
+}
+
+func TestDetectClassConflict(t *testing.T) {
+	nginx, kong := "nginx", "kong"
+	meta := func(anns map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: "ing", Namespace: corev1.NamespaceDefault, Annotations: anns}
+	}
+
+	for _, tt := range []struct {
+		name                    string
+		obj                     client.Object
+		expectedConflict        bool
+		expectedSpecClass       string
+		expectedAnnotationClass string
+	}{
+		{
+			name: "spec and annotation agree",
+			obj: &netv1.Ingress{
+				ObjectMeta: meta(map[string]string{annotations.IngressClassKey: kong}),
+				Spec:       netv1.IngressSpec{IngressClassName: &kong},
+			},
+			expectedConflict:        false,
+			expectedSpecClass:       kong,
+			expectedAnnotationClass: kong,
+		},
+		{
+			name: "spec and annotation conflict",
+			obj: &netv1beta1.Ingress{
+				ObjectMeta: meta(map[string]string{annotations.IngressClassKey: nginx}),
+				Spec:       netv1beta1.IngressSpec{IngressClassName: &kong},
+			},
+			expectedConflict:        true,
+			expectedSpecClass:       kong,
+			expectedAnnotationClass: nginx,
+		},
+		{
+			name:              "only spec",
+			obj:               &extv1beta1.Ingress{ObjectMeta: meta(nil), Spec: extv1beta1.IngressSpec{IngressClassName: &kong}},
+			expectedConflict:  false,
+			expectedSpecClass: kong,
+		},
+		{
+			name:                    "only annotation",
+			obj:                     &netv1.Ingress{ObjectMeta: meta(map[string]string{annotations.IngressClassKey: nginx})},
+			expectedConflict:        false,
+			expectedAnnotationClass: nginx,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			conflict, specClass, annotationClass := DetectClassConflict(tt.obj)
+			assert.Equal(t, tt.expectedConflict, conflict)
+			assert.Equal(t, tt.expectedSpecClass, specClass)
+			assert.Equal(t, tt.expectedAnnotationClass, annotationClass)
+		})
+	}
+}
+
+func TestClaimedClasses(t *testing.T) {
+	kong, nginx := annotations.DefaultIngressClass, "nginx"
+	meta := func(anns map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: "test", Namespace: corev1.NamespaceDefault, Annotations: anns}
+	}
+
+	for _, tt := range []struct {
+		name     string
+		obj      client.Object
+		expected []string
+	}{
+		{
+			name: "classless object",
+			obj:  &netv1.Ingress{ObjectMeta: meta(nil)},
+		},
+		{
+			name:     "spec only",
+			obj:      &netv1.Ingress{ObjectMeta: meta(nil), Spec: netv1.IngressSpec{IngressClassName: &kong}},
+			expected: []string{kong},
+		},
+		{
+			name: "overlapping spec and annotation",
+			obj: &netv1.Ingress{
+				ObjectMeta: meta(map[string]string{annotations.IngressClassKey: kong}),
+				Spec:       netv1.IngressSpec{IngressClassName: &kong},
+			},
+			expected: []string{kong},
+		},
+		{
+			name: "distinct spec and annotation",
+			obj: &netv1.Ingress{
+				ObjectMeta: meta(map[string]string{annotations.IngressClassKey: nginx}),
+				Spec:       netv1.IngressSpec{IngressClassName: &kong},
+			},
+			expected: []string{kong, nginx},
+		},
+		{
+			name: "overlapping knative annotations",
+			obj: &knative.Ingress{ObjectMeta: meta(map[string]string{
+				annotations.KnativeIngressClassAnnotationKey: kong,
+				annotations.KnativeIngressClassKey:           kong,
+			})},
+			expected: []string{kong},
+		},
+		{
+			name: "distinct signals across all annotations",
+			obj: &netv1beta1.Ingress{ObjectMeta: meta(map[string]string{
+				annotations.IngressClassKey:                  "a",
+				annotations.KnativeIngressClassAnnotationKey: "b",
+				annotations.KnativeIngressClassKey:           "c",
+			})},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name: "object without a spec class",
+			obj: &kongv1beta1.TCPIngress{ObjectMeta: meta(map[string]string{
+				annotations.IngressClassKey:        nginx,
+				annotations.KnativeIngressClassKey: nginx,
+			})},
+			expected: []string{nginx},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClaimedClasses(tt.obj))
+		})
+	}
+}
+
+func TestGeneratePredicateFuncsForIngressClass(t *testing.T) {
+	preds := GeneratePredicateFuncsForIngressClass(IngressClassKongController)
+
+	kongClass := &netv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "kong"},
+		Spec:       netv1.IngressClassSpec{Controller: IngressClassKongController},
+	}
+	otherClass := &netv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+		Spec:       netv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+	}
+
+	t.Log("verifying that events for an IngressClass handled by the controller pass")
+	assert.True(t, preds.Create(event.CreateEvent{Object: kongClass}))
+	assert.True(t, preds.Delete(event.DeleteEvent{Object: kongClass}))
+	assert.True(t, preds.Update(event.UpdateEvent{ObjectOld: kongClass, ObjectNew: kongClass}))
+
+	t.Log("verifying that events for an unrelated IngressClass are filtered out")
+	assert.False(t, preds.Create(event.CreateEvent{Object: otherClass}))
+	assert.False(t, preds.Delete(event.DeleteEvent{Object: otherClass}))
+	assert.False(t, preds.Generic(event.GenericEvent{Object: otherClass}))
+	assert.False(t, preds.Update(event.UpdateEvent{ObjectOld: otherClass, ObjectNew: otherClass.DeepCopy()}))
+
+	t.Log("verifying that an update toggling the default class passes even for an unrelated IngressClass")
+	defaultClass := otherClass.DeepCopy()
+	defaultClass.Annotations = map[string]string{netv1.AnnotationIsDefaultIngressClass: "true"}
+	assert.True(t, preds.Update(event.UpdateEvent{ObjectOld: otherClass, ObjectNew: defaultClass}))
+	assert.True(t, preds.Update(event.UpdateEvent{ObjectOld: defaultClass, ObjectNew: otherClass}))
+}
+
+func TestMatchesClassPattern(t *testing.T) {
+	tenantA, other := "kong-tenant-a", "nginx"
+	ingressWithSpec := func(class *string) client.Object {
+		return &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "ing", Namespace: corev1.NamespaceDefault},
+			Spec:       netv1.IngressSpec{IngressClassName: class},
+		}
+	}
+	ingressWithAnnotation := func(class string) client.Object {
+		return &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "ing",
+				Namespace:   corev1.NamespaceDefault,
+				Annotations: map[string]string{annotations.IngressClassKey: class},
+			},
+		}
+	}
+
+	for _, tt := range []struct {
+		name      string
+		obj       client.Object
+		pattern   string
+		isDefault bool
+		expected  bool
+	}{
+		{
+			name:     "prefix match in spec",
+			obj:      ingressWithSpec(&tenantA),
+			pattern:  "kong-tenant-*",
+			expected: true,
+		},
+		{
+			name:     "prefix match in annotation",
+			obj:      ingressWithAnnotation("kong-tenant-b"),
+			pattern:  "kong-tenant-*",
+			expected: true,
+		},
+		{
+			name:     "prefix match of the bare prefix",
+			obj:      ingressWithAnnotation("kong-tenant-"),
+			pattern:  "kong-tenant-*",
+			expected: true,
+		},
+		{
+			name:     "prefix non-match",
+			obj:      ingressWithSpec(&other),
+			pattern:  "kong-tenant-*",
+			expected: false,
+		},
+		{
+			name:     "prefix non-match of a shorter class",
+			obj:      ingressWithAnnotation("kong"),
+			pattern:  "kong-tenant-*",
+			expected: false,
+		},
+		{
+			name:     "exact match",
+			obj:      ingressWithSpec(&tenantA),
+			pattern:  "kong-tenant-a",
+			expected: true,
+		},
+		{
+			name:     "exact non-match does not glob",
+			obj:      ingressWithAnnotation("kong-tenant-a"),
+			pattern:  "kong-tenant-",
+			expected: false,
+		},
+		{
+			name:      "classless object matches the default class",
+			obj:       ingressWithSpec(nil),
+			pattern:   "kong-tenant-*",
+			isDefault: true,
+			expected:  true,
+		},
+		{
+			name:     "classless object does not match a non-default class",
+			obj:      ingressWithSpec(nil),
+			pattern:  "kong-tenant-*",
+			expected: false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MatchesClassPattern(tt.obj, tt.pattern, tt.isDefault))
+			if !strings.HasSuffix(tt.pattern, "*") {
+				assert.Equal(t, MatchesClass(tt.obj, tt.pattern, tt.isDefault), MatchesClassPattern(tt.obj, tt.pattern, tt.isDefault))
+			}
+		})
+	}
+}
+
+func TestIsIngressClassEmpty(t *testing.T) {
+	kong := annotations.DefaultIngressClass
+	withClassAnnotation := metav1.ObjectMeta{
+		Name:        "obj",
+		Namespace:   corev1.NamespaceDefault,
+		Annotations: map[string]string{annotations.IngressClassKey: kong},
+	}
+	withKnativeClassAnnotation := metav1.ObjectMeta{
+		Name:        "obj",
+		Namespace:   corev1.NamespaceDefault,
+		Annotations: map[string]string{annotations.KnativeIngressClassKey: kong},
+	}
+	withoutClass := metav1.ObjectMeta{Name: "obj", Namespace: corev1.NamespaceDefault}
+
+	for _, tt := range []struct {
+		name     string
+		obj      client.Object
+		expected bool
+	}{
+		{"netv1 ingress without class", &netv1.Ingress{ObjectMeta: withoutClass}, true},
+		{"netv1 ingress with class annotation", &netv1.Ingress{ObjectMeta: withClassAnnotation}, false},
+		{"netv1 ingress with class spec", &netv1.Ingress{ObjectMeta: withoutClass, Spec: netv1.IngressSpec{IngressClassName: &kong}}, false},
+		{"netv1beta1 ingress without class", &netv1beta1.Ingress{ObjectMeta: withoutClass}, true},
+		{"netv1beta1 ingress with class annotation", &netv1beta1.Ingress{ObjectMeta: withClassAnnotation}, false},
+		{"netv1beta1 ingress with class spec", &netv1beta1.Ingress{ObjectMeta: withoutClass, Spec: netv1beta1.IngressSpec{IngressClassName: &kong}}, false},
+		{"extv1beta1 ingress without class", &extv1beta1.Ingress{ObjectMeta: withoutClass}, true},
+		{"extv1beta1 ingress with class annotation", &extv1beta1.Ingress{ObjectMeta: withClassAnnotation}, false},
+		{"extv1beta1 ingress with class spec", &extv1beta1.Ingress{ObjectMeta: withoutClass, Spec: extv1beta1.IngressSpec{IngressClassName: &kong}}, false},
+		{"knative ingress without class", &knative.Ingress{ObjectMeta: withoutClass}, true},
+		{"knative ingress with class annotation", &knative.Ingress{ObjectMeta: withKnativeClassAnnotation}, false},
+		{"tcpingress without class", &kongv1beta1.TCPIngress{ObjectMeta: withoutClass}, true},
+		{"tcpingress with class annotation", &kongv1beta1.TCPIngress{ObjectMeta: withClassAnnotation}, false},
+		{"udpingress without class", &kongv1beta1.UDPIngress{ObjectMeta: withoutClass}, true},
+		{"udpingress with class annotation", &kongv1beta1.UDPIngress{ObjectMeta: withClassAnnotation}, false},
+		{"service without class", &corev1.Service{ObjectMeta: withoutClass}, true},
+		{"service with class annotation", &corev1.Service{ObjectMeta: withClassAnnotation}, false},
+		{"service with knative class annotation only", &corev1.Service{ObjectMeta: withKnativeClassAnnotation}, true},
+		{"gateway without class", &gatewayv1alpha2.Gateway{ObjectMeta: withoutClass}, true},
+		{"gateway with class", &gatewayv1alpha2.Gateway{ObjectMeta: withoutClass, Spec: gatewayv1alpha2.GatewaySpec{GatewayClassName: "kong"}}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsIngressClassEmpty(tt.obj))
+		})
+	}
+}
+
+func TestClassFieldsEqual(t *testing.T) {
+	kong, other := annotations.DefaultIngressClass, "other"
+	meta := func(anns, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: "ing", Namespace: corev1.NamespaceDefault, Annotations: anns, Labels: labels}
+	}
+	classAnnotation := map[string]string{annotations.IngressClassKey: kong}
+
+	for _, tt := range []struct {
+		name     string
+		a, b     client.Object
+		expected bool
+	}{
+		{
+			name: "netv1 ingress with unrelated annotation and rule changes",
+			a:    &netv1.Ingress{ObjectMeta: meta(classAnnotation, nil), Spec: netv1.IngressSpec{IngressClassName: &kong}},
+			b: &netv1.Ingress{
+				ObjectMeta: meta(map[string]string{annotations.IngressClassKey: kong, "konghq.com/strip-path": "true"}, map[string]string{"app": "foo"}),
+				Spec:       netv1.IngressSpec{IngressClassName: &kong, Rules: []netv1.IngressRule{{Host: "example.com"}}},
+			},
+			expected: true,
+		},
+		{
+			name:     "netv1 ingress with spec class change",
+			a:        &netv1.Ingress{ObjectMeta: meta(nil, nil), Spec: netv1.IngressSpec{IngressClassName: &kong}},
+			b:        &netv1.Ingress{ObjectMeta: meta(nil, nil), Spec: netv1.IngressSpec{IngressClassName: &other}},
+			expected: false,
+		},
+		{
+			name:     "netv1 ingress with spec class removed",
+			a:        &netv1.Ingress{ObjectMeta: meta(nil, nil), Spec: netv1.IngressSpec{IngressClassName: &kong}},
+			b:        &netv1.Ingress{ObjectMeta: meta(nil, nil)},
+			expected: false,
+		},
+		{
+			name:     "netv1beta1 ingress with class annotation change",
+			a:        &netv1beta1.Ingress{ObjectMeta: meta(classAnnotation, nil)},
+			b:        &netv1beta1.Ingress{ObjectMeta: meta(map[string]string{annotations.IngressClassKey: other}, nil)},
+			expected: false,
+		},
+		{
+			name:     "netv1beta1 ingress with unrelated backend change",
+			a:        &netv1beta1.Ingress{ObjectMeta: meta(classAnnotation, nil)},
+			b:        &netv1beta1.Ingress{ObjectMeta: meta(classAnnotation, nil), Spec: netv1beta1.IngressSpec{Backend: &netv1beta1.IngressBackend{ServiceName: "svc"}}},
+			expected: true,
+		},
+		{
+			name:     "extv1beta1 ingress with spec class change",
+			a:        &extv1beta1.Ingress{ObjectMeta: meta(nil, nil), Spec: extv1beta1.IngressSpec{IngressClassName: &kong}},
+			b:        &extv1beta1.Ingress{ObjectMeta: meta(nil, nil), Spec: extv1beta1.IngressSpec{IngressClassName: &other}},
+			expected: false,
+		},
+		{
+			name:     "knative ingress with class annotation change",
+			a:        &knative.Ingress{ObjectMeta: meta(map[string]string{annotations.KnativeIngressClassKey: kong}, nil)},
+			b:        &knative.Ingress{ObjectMeta: meta(map[string]string{annotations.KnativeIngressClassKey: other}, nil)},
+			expected: false,
+		},
+		{
+			name:     "netv1 ingress with class label change",
+			a:        &netv1.Ingress{ObjectMeta: meta(nil, map[string]string{IngressClassLabel: kong})},
+			b:        &netv1.Ingress{ObjectMeta: meta(nil, map[string]string{IngressClassLabel: other})},
+			expected: false,
+		},
+		{
+			name:     "objects of different types",
+			a:        &netv1.Ingress{ObjectMeta: meta(classAnnotation, nil)},
+			b:        &netv1beta1.Ingress{ObjectMeta: meta(classAnnotation, nil)},
+			expected: false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClassFieldsEqual(tt.a, tt.b))
+			assert.Equal(t, tt.expected, ClassFieldsEqual(tt.b, tt.a))
+		})
+	}
+}
+
+func TestClassConfigHash(t *testing.T) {
+	kong, other := annotations.DefaultIngressClass, "other"
+	ingress := func(specClass *string, anns, labels map[string]string) *netv1.Ingress {
+		return &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "ing", Namespace: corev1.NamespaceDefault, Annotations: anns, Labels: labels},
+			Spec:       netv1.IngressSpec{IngressClassName: specClass},
+		}
+	}
+	base := ingress(&kong, map[string]string{annotations.IngressClassKey: kong}, map[string]string{IngressClassLabel: kong})
+	hash := ClassConfigHash(base)
+	assert.Len(t, hash, 16)
+
+	t.Log("verifying that identical objects hash equally")
+	assert.Equal(t, hash, ClassConfigHash(base.DeepCopy()))
+
+	t.Log("verifying that the hash is stable across map iteration order and unrelated fields")
+	anns := map[string]string{}
+	for i := 0; i < 32; i++ {
+		anns[fmt.Sprintf("example.com/annotation-%d", i)] = "value"
+	}
+	anns[annotations.IngressClassKey] = kong
+	unrelated := ingress(&kong, anns, map[string]string{"app": "foo", IngressClassLabel: kong})
+	unrelated.Spec.Rules = []netv1.IngressRule{{Host: "example.com"}}
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, hash, ClassConfigHash(unrelated))
+	}
+
+	t.Log("verifying that differing class values hash differently")
+	for name, obj := range map[string]client.Object{
+		"spec class":         ingress(&other, map[string]string{annotations.IngressClassKey: kong}, map[string]string{IngressClassLabel: kong}),
+		"no spec class":      ingress(nil, map[string]string{annotations.IngressClassKey: kong}, map[string]string{IngressClassLabel: kong}),
+		"class annotation":   ingress(&kong, map[string]string{annotations.IngressClassKey: other}, map[string]string{IngressClassLabel: kong}),
+		"knative annotation": ingress(&kong, map[string]string{annotations.IngressClassKey: kong, annotations.KnativeIngressClassKey: kong}, map[string]string{IngressClassLabel: kong}),
+		"class label":        ingress(&kong, map[string]string{annotations.IngressClassKey: kong}, map[string]string{IngressClassLabel: other}),
+		"different type":     &netv1beta1.Ingress{ObjectMeta: base.ObjectMeta},
+	} {
+		assert.NotEqual(t, hash, ClassConfigHash(obj), name)
+	}
+
+	t.Log("verifying that the hash agrees with ClassFieldsEqual")
+	changed := ingress(&other, nil, nil)
+	assert.Equal(t, ClassFieldsEqual(base, unrelated), ClassConfigHash(base) == ClassConfigHash(unrelated))
+	assert.Equal(t, ClassFieldsEqual(base, changed), ClassConfigHash(base) == ClassConfigHash(changed))
+}
+
+func TestIsClasslessAdoptedByUs(t *testing.T) {
+	kong := annotations.DefaultIngressClass
+	classless := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "ing", Namespace: corev1.NamespaceDefault}}
+	classedSpec := &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "ing", Namespace: corev1.NamespaceDefault},
+		Spec:       netv1.IngressSpec{IngressClassName: &kong},
+	}
+	classedAnnotation := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+		Name:        "ing",
+		Namespace:   corev1.NamespaceDefault,
+		Annotations: map[string]string{annotations.IngressClassKey: "nginx"},
+	}}
+
+	t.Log("verifying that classed objects are never adopted")
+	for _, obj := range []client.Object{classedSpec, classedAnnotation} {
+		assert.False(t, IsClasslessAdoptedByUs(obj, true))
+		assert.False(t, IsClasslessAdoptedByUs(obj, false))
+	}
+
+	t.Log("verifying that classless objects are adopted only while the default class is ours")
+	assert.True(t, IsClasslessAdoptedByUs(classless, true))
+	assert.False(t, IsClasslessAdoptedByUs(classless, false))
+}
+
+func TestIsMatchedByDefaultOnly(t *testing.T) {
+	kong, nginx := annotations.DefaultIngressClass, "nginx"
+	meta := func(anns map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: "ing", Namespace: corev1.NamespaceDefault, Annotations: anns}
+	}
+	for _, tt := range []struct {
+		name      string
+		obj       client.Object
+		isDefault bool
+		expected  bool
+	}{
+		{name: "explicit spec match", obj: &netv1.Ingress{ObjectMeta: meta(nil), Spec: netv1.IngressSpec{IngressClassName: &kong}}, isDefault: true},
+		{name: "explicit annotation match", obj: &netv1.Ingress{ObjectMeta: meta(map[string]string{annotations.IngressClassKey: kong})}, isDefault: true},
+		{name: "explicit knative match", obj: &knative.Ingress{ObjectMeta: meta(map[string]string{annotations.KnativeIngressClassKey: kong})}, isDefault: true},
+		{name: "default-only match", obj: &netv1.Ingress{ObjectMeta: meta(nil)}, isDefault: true, expected: true},
+		{name: "default-only match with empty annotation", obj: &netv1.Ingress{ObjectMeta: meta(map[string]string{annotations.IngressClassKey: ""})}, isDefault: true, expected: true},
+		{name: "default-only tcpingress match", obj: &kongv1beta1.TCPIngress{ObjectMeta: meta(nil)}, isDefault: true, expected: true},
+		{name: "classless without default", obj: &netv1.Ingress{ObjectMeta: meta(nil)}},
+		{name: "mismatch", obj: &netv1.Ingress{ObjectMeta: meta(nil), Spec: netv1.IngressSpec{IngressClassName: &nginx}}, isDefault: true},
+		{name: "ignored", obj: &netv1.Ingress{ObjectMeta: meta(map[string]string{annotations.AnnotationPrefix + annotations.IgnoreKey: "true"})}, isDefault: true},
+	} {
+		assert.Equal(t, tt.expected, IsMatchedByDefaultOnly(tt.obj, kong, tt.isDefault), tt.name)
+		if tt.expected {
+			assert.True(t, MatchesClass(tt.obj, kong, tt.isDefault), tt.name)
+		}
+	}
+}
+
+func TestMatchesClassEmptyAnnotation(t *testing.T) {
+	kong := annotations.DefaultIngressClass
+	empty := map[string]string{annotations.IngressClassKey: ""}
+	for _, obj := range []client.Object{
+		&netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "ing", Namespace: corev1.NamespaceDefault, Annotations: empty}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: corev1.NamespaceDefault, Annotations: empty}},
+		&knative.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "kn", Namespace: corev1.NamespaceDefault, Annotations: map[string]string{
+			annotations.KnativeIngressClassKey: "",
+		}}},
+	} {
+		t.Logf("verifying that a %T with an empty class annotation is treated as classless", obj)
+		assert.True(t, IsIngressClassEmpty(obj))
+		assert.True(t, MatchesClass(obj, kong, true))
+		assert.False(t, MatchesClass(obj, kong, false))
+		assert.False(t, IsIngressClassAnnotationConfigured(obj, ""))
+	}
+}
+
+func TestMatchesClassService(t *testing.T) {
+	kong := annotations.DefaultIngressClass
+	service := func(anns map[string]string) *corev1.Service {
+		return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: corev1.NamespaceDefault, Annotations: anns}}
+	}
+
+	t.Log("verifying that a classless service is claimed only when the class is the default")
+	assert.True(t, MatchesClass(service(nil), kong, true))
+	assert.False(t, MatchesClass(service(nil), kong, false))
+
+	t.Log("verifying that a service with a matching class annotation is claimed")
+	assert.True(t, MatchesClass(service(map[string]string{annotations.IngressClassKey: kong}), kong, false))
+
+	t.Log("verifying that a service with a mismatched class annotation is dropped, even for the default class")
+	assert.False(t, MatchesClass(service(map[string]string{annotations.IngressClassKey: "nginx"}), kong, true))
+	assert.False(t, MatchesIngressClassName(service(map[string]string{annotations.IngressClassKey: "nginx"}), kong))
+}
+
+func TestMatchesClassWithAnnotations(t *testing.T) {
+	objects := []client.Object{
+		&netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "no-class"}},
+		&netv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+			Name:        "kong-class",
+			Annotations: map[string]string{annotations.IngressClassKey: "kong"},
+		}},
+		&netv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+			Name:        "other-class",
+			Annotations: map[string]string{annotations.IngressClassKey: "nginx"},
+		}},
+		&netv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+			Name:        "knative-annotation-on-ingress",
+			Annotations: map[string]string{annotations.KnativeIngressClassKey: "kong"},
+		}},
+		&kongv1beta1.TCPIngress{ObjectMeta: metav1.ObjectMeta{
+			Name:        "tcp-kong-class",
+			Annotations: map[string]string{annotations.IngressClassKey: "kong"},
+		}},
+		&knative.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "knative-no-class"}},
+		&knative.Ingress{ObjectMeta: metav1.ObjectMeta{
+			Name:        "knative-kong-class",
+			Annotations: map[string]string{annotations.KnativeIngressClassKey: "kong"},
+		}},
+		&knative.Ingress{ObjectMeta: metav1.ObjectMeta{
+			Name:        "ingress-annotation-on-knative",
+			Annotations: map[string]string{annotations.IngressClassKey: "kong"},
+		}},
+	}
+
+	for _, obj := range objects {
+		for _, class := range []string{"kong", "nginx"} {
+			for _, isDefault := range []bool{true, false} {
+				_, isKnative := obj.(*knative.Ingress)
+				assert.Equal(t,
+					MatchesClass(obj, class, isDefault),
+					MatchesClassWithAnnotations(obj.GetAnnotations(), isKnative, class, isDefault),
+					"object %s, class %s, isDefault %t", obj.GetName(), class, isDefault,
+				)
+			}
+		}
+	}
+
+	t.Log("verifying the annotation based logic")
+	assert.True(t, MatchesClassWithAnnotations(map[string]string{annotations.IngressClassKey: "kong"}, false, "kong", false))
+	assert.False(t, MatchesClassWithAnnotations(map[string]string{annotations.IngressClassKey: "kong"}, true, "kong", false))
+	assert.True(t, MatchesClassWithAnnotations(map[string]string{annotations.KnativeIngressClassKey: "kong"}, true, "kong", false))
+	assert.True(t, MatchesClassWithAnnotations(nil, false, "kong", true))
+	assert.False(t, MatchesClassWithAnnotations(nil, false, "kong", false))
+}
+
+type fakeClassMatchRecorder struct {
+	counts map[string]int
+}
+
+func (r *fakeClassMatchRecorder) RecordClassMatch(kind, outcome string) {
+	r.counts[kind+"/"+outcome]++
+}
+
+type noopClassMatchRecorder struct{}
+
+func (noopClassMatchRecorder) RecordClassMatch(string, string) {}
+
+func TestSetClassMatchRecorderConcurrently(t *testing.T) {
+	previous := SetClassMatchRecorder(noopClassMatchRecorder{})
+	defer SetClassMatchRecorder(previous)
+
+	t.Log("verifying that recorders of different types can be swapped while classes are being matched")
+	obj := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{annotations.IngressClassKey: "kong"}}}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			SetClassMatchRecorder(noopClassMatchRecorder{})
+			SetClassMatchRecorder(metrics.ClassFilterRecorder{})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			assert.True(t, MatchesClass(obj, "kong", false))
+		}
+	}()
+	wg.Wait()
+	assert.Equal(t, metrics.ClassFilterRecorder{}, SetClassMatchRecorder(previous))
+}
+
+func TestClassMatchRecorder(t *testing.T) {
+	recorder := &fakeClassMatchRecorder{counts: map[string]int{}}
+	previous := SetClassMatchRecorder(recorder)
+	defer SetClassMatchRecorder(previous)
+
+	kong, nginx := "kong", "nginx"
+	ingress := func(class *string, anns map[string]string) *netv1.Ingress {
+		return &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "test", Annotations: anns},
+			Spec:       netv1.IngressSpec{IngressClassName: class},
+		}
+	}
+
+	t.Log("verifying that each MatchesClass outcome is recorded")
+	assert.True(t, MatchesClass(ingress(&kong, nil), kong, false))
+	assert.Equal(t, 1, recorder.counts["Ingress/"+metrics.ClassOutcomeMatched])
+	assert.False(t, MatchesClass(ingress(&nginx, nil), kong, true))
+	assert.Equal(t, 1, recorder.counts["Ingress/"+metrics.ClassOutcomeDroppedMismatch])
+	assert.True(t, MatchesClass(ingress(nil, nil), kong, true))
+	assert.Equal(t, 1, recorder.counts["Ingress/"+metrics.ClassOutcomeMatchedDefault])
+	assert.False(t, MatchesClass(ingress(nil, nil), kong, false))
+	assert.Equal(t, 1, recorder.counts["Ingress/"+metrics.ClassOutcomeDroppedEmpty])
+
+	t.Log("verifying that the kind from the TypeMeta is preferred over the Go type name")
+	typed := ingress(nil, map[string]string{annotations.IngressClassKey: kong})
+	typed.TypeMeta = metav1.TypeMeta{Kind: "CustomIngress"}
+	assert.True(t, MatchesClass(typed, kong, false))
+	assert.Equal(t, 1, recorder.counts["CustomIngress/"+metrics.ClassOutcomeMatched])
+
+	t.Log("verifying that the class filter predicate records its outcomes")
+	preds := GeneratePredicateFuncsForIngressClassFilter(kong, true, true, false, false)
+	assert.True(t, preds.Create(event.CreateEvent{Object: ingress(&kong, nil)}))
+	assert.Equal(t, 2, recorder.counts["Ingress/"+metrics.ClassOutcomeMatched])
+	assert.False(t, preds.Create(event.CreateEvent{Object: ingress(nil, map[string]string{annotations.IngressClassKey: nginx})}))
+	assert.Equal(t, 2, recorder.counts["Ingress/"+metrics.ClassOutcomeDroppedMismatch])
+	assert.False(t, preds.Delete(event.DeleteEvent{Object: ingress(nil, nil)}))
+	assert.Equal(t, 2, recorder.counts["Ingress/"+metrics.ClassOutcomeDroppedEmpty])
+}
+
+func TestGeneratePredicateFuncsForIngressClassFilterWithRecorder(t *testing.T) {
+	kong, nginx := "kong", "nginx"
+	ingress := func(class *string) *netv1.Ingress {
+		return &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "test"},
+			Spec:       netv1.IngressSpec{IngressClassName: class},
+		}
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	preds := GeneratePredicateFuncsForIngressClassFilterWithRecorder(kong, true, true, false, false, recorder)
+
+	t.Log("verifying that no event is recorded for matching objects")
+	assert.True(t, preds.Create(event.CreateEvent{Object: ingress(&kong)}))
+	assert.Len(t, recorder.Events, 0)
+
+	t.Log("verifying that no event is recorded for classless objects")
+	assert.False(t, preds.Create(event.CreateEvent{Object: ingress(nil)}))
+	assert.Len(t, recorder.Events, 0)
+
+	t.Log("verifying that an event is recorded for objects with another class")
+	assert.False(t, preds.Create(event.CreateEvent{Object: ingress(&nginx)}))
+	assert.Len(t, recorder.Events, 1)
+	assert.Equal(t, `Normal IngressClassMismatch object skipped: expected ingress class "kong", found "nginx"`, <-recorder.Events)
+
+	t.Log("verifying that a nil recorder is tolerated")
+	preds = GeneratePredicateFuncsForIngressClassFilterWithRecorder(kong, true, true, false, false, nil)
+	assert.False(t, preds.Create(event.CreateEvent{Object: ingress(&nginx)}))
+}
+
+func TestMatchesClassLabel(t *testing.T) {
+	service := func(labels, anns map[string]string) *corev1.Service {
+		return &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   corev1.NamespaceDefault,
+			Name:        "test",
+			Labels:      labels,
+			Annotations: anns,
+		}}
+	}
+	labeled := map[string]string{IngressClassLabel: "kong"}
+	annotated := map[string]string{annotations.IngressClassKey: "kong"}
+	annotatedOther := map[string]string{annotations.IngressClassKey: "nginx"}
+	nginx := "nginx"
+
+	t.Log("verifying that MatchesClassLabel only reads the class from labels")
+	assert.True(t, MatchesClassLabel(service(labeled, nil), "kong"))
+	assert.False(t, MatchesClassLabel(service(labeled, nil), "nginx"))
+	assert.False(t, MatchesClassLabel(service(nil, annotated), "kong"))
+	assert.False(t, MatchesClassLabel(service(map[string]string{IngressClassLabel: ""}, nil), ""))
+
+	for _, tt := range []struct {
+		name              string
+		obj               client.Object
+		labelCheckEnabled bool
+		expected          bool
+	}{
+		{name: "label-only object without the label check", obj: service(labeled, nil), expected: false},
+		{name: "label-only object", obj: service(labeled, nil), labelCheckEnabled: true, expected: true},
+		{name: "label-only object of another class", obj: service(map[string]string{IngressClassLabel: "nginx"}, nil), labelCheckEnabled: true, expected: false},
+		{name: "annotation-only object", obj: service(nil, annotated), labelCheckEnabled: true, expected: true},
+		{name: "object with both set", obj: service(labeled, annotated), labelCheckEnabled: true, expected: true},
+		{name: "annotation takes precedence over label", obj: service(labeled, annotatedOther), labelCheckEnabled: true, expected: false},
+		{name: "annotation takes precedence over label of another class", obj: service(map[string]string{IngressClassLabel: "nginx"}, annotated), labelCheckEnabled: true, expected: true},
+		{name: "spec takes precedence over label", obj: &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "test", Labels: labeled},
+			Spec:       netv1.IngressSpec{IngressClassName: &nginx},
+		}, labelCheckEnabled: true, expected: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			preds := GeneratePredicateFuncsForClassConfig(ClassConfig{
+				Name:                   "kong",
+				SpecCheckEnabled:       true,
+				AnnotationCheckEnabled: true,
+				LabelCheckEnabled:      tt.labelCheckEnabled,
+			}, false, nil)
+			assert.Equal(t, tt.expected, preds.Create(event.CreateEvent{Object: tt.obj}))
+		})
+	}
+
+	t.Log("verifying that objects labeled with another class don't match as classless objects of the default class")
+	assert.False(t, ShouldReconcile(service(map[string]string{IngressClassLabel: "nginx"}, nil),
+		ClassConfig{Name: "kong", LabelCheckEnabled: true, IsDefault: true}))
+	assert.True(t, ShouldReconcile(service(nil, nil),
+		ClassConfig{Name: "kong", LabelCheckEnabled: true, IsDefault: true}))
+}
+
+func TestDebugLogger(t *testing.T) {
+	var logged []string
+	log := funcr.New(func(prefix, args string) {
+		logged = append(logged, args)
+	}, funcr.Options{Verbosity: util.InfoLevel})
+
+	debugged := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+		Name:        "debugged",
+		Namespace:   corev1.NamespaceDefault,
+		Annotations: map[string]string{annotations.AnnotationPrefix + annotations.DebugKey: "true"},
+	}}
+	other := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: corev1.NamespaceDefault}}
+
+	t.Log("verifying that debug logs are only emitted for the annotated object")
+	DebugLogger(log, other).Info("reconciling resource", "name", other.Name)
+	DebugLogger(log, debugged).Info("reconciling resource", "name", debugged.Name)
+	assert.Len(t, logged, 1)
+	assert.Contains(t, logged[0], `"name"="debugged"`)
+}
+
+func TestEnqueueAllClassless(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, netv1.AddToScheme(scheme))
+	require.NoError(t, kongv1beta1.AddToScheme(scheme))
+
+	kong := annotations.DefaultIngressClass
+	meta := func(name string, anns map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: corev1.NamespaceDefault, Annotations: anns}
+	}
+	classed := map[string]string{annotations.IngressClassKey: kong}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&netv1.Ingress{ObjectMeta: meta("classless-ingress", nil)},
+		&netv1.Ingress{ObjectMeta: meta("annotated-ingress", classed)},
+		&netv1.Ingress{ObjectMeta: meta("spec-ingress", nil), Spec: netv1.IngressSpec{IngressClassName: &kong}},
+		&kongv1beta1.TCPIngress{ObjectMeta: meta("classless-tcpingress", nil)},
+		&kongv1beta1.TCPIngress{ObjectMeta: meta("classed-tcpingress", classed)},
+		&kongv1beta1.UDPIngress{ObjectMeta: meta("classless-udpingress", nil)},
+	).Build()
+
+	recs, err := EnqueueAllClassless(context.Background(), c, []schema.GroupVersionKind{
+		netv1.SchemeGroupVersion.WithKind("Ingress"),
+		kongv1beta1.SchemeGroupVersion.WithKind("TCPIngress"),
+		kongv1beta1.SchemeGroupVersion.WithKind("UDPIngress"),
+		knative.SchemeGroupVersion.WithKind("Ingress"), // not installed, skipped
+	})
+	require.NoError(t, err)
+
+	request := func(name string) reconcile.Request {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: corev1.NamespaceDefault, Name: name}}
+	}
+	assert.ElementsMatch(t, []reconcile.Request{
+		request("classless-ingress"),
+		request("classless-tcpingress"),
+		request("classless-udpingress"),
+	}, recs)
+}
+
+func TestIsIngressClassSpecConfiguredGateway(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		obj      client.Object
+		expected bool
+	}{
+		{
+			name:     "gateway with a matching class",
+			obj:      &gatewayv1alpha2.Gateway{Spec: gatewayv1alpha2.GatewaySpec{GatewayClassName: "kong"}},
+			expected: true,
+		},
+		{
+			name:     "gateway with another class",
+			obj:      &gatewayv1alpha2.Gateway{Spec: gatewayv1alpha2.GatewaySpec{GatewayClassName: "nginx"}},
+			expected: false,
+		},
+		{
+			name:     "gateway class with a matching name",
+			obj:      &gatewayv1alpha2.GatewayClass{ObjectMeta: metav1.ObjectMeta{Name: "kong"}},
+			expected: true,
+		},
+		{
+			name:     "gateway class with another name",
+			obj:      &gatewayv1alpha2.GatewayClass{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}},
+			expected: false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsIngressClassSpecConfigured(tt.obj, "kong"))
+		})
+	}
+
+	t.Log("verifying that the shared predicate generator filters gateways by class")
+	preds := GeneratePredicateFuncsForIngressClassFilter("kong", true, false, false, false)
+	assert.True(t, preds.Create(event.CreateEvent{Object: &gatewayv1alpha2.Gateway{Spec: gatewayv1alpha2.GatewaySpec{GatewayClassName: "kong"}}}))
+	assert.False(t, preds.Create(event.CreateEvent{Object: &gatewayv1alpha2.Gateway{Spec: gatewayv1alpha2.GatewaySpec{GatewayClassName: "nginx"}}}))
+}
+
+func TestKnativeIngressClass(t *testing.T) {
+	knativeIngress := func(anns map[string]string) *knative.Ingress {
+		return &knative.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "kn", Namespace: corev1.NamespaceDefault, Annotations: anns}}
+	}
+
+	t.Log("verifying that knative ingresses are classed by the knative class annotation")
+	obj := knativeIngress(map[string]string{annotations.KnativeIngressClassKey: "kong"})
+	assert.True(t, MatchesClass(obj, "kong", false))
+	assert.False(t, MatchesClass(obj, "nginx", true))
+	assert.True(t, IsIngressClassAnnotationConfigured(obj, "kong"))
+
+	t.Log("verifying that the knative class annotation takes precedence over the ingress class annotation")
+	obj = knativeIngress(map[string]string{annotations.KnativeIngressClassKey: "nginx", annotations.IngressClassKey: "kong"})
+	assert.False(t, MatchesClass(obj, "kong", true))
+
+	t.Log("verifying that knative ingresses are never considered to have a class configured in their .spec")
+	assert.False(t, IsIngressClassSpecConfigured(knativeIngress(map[string]string{annotations.KnativeIngressClassKey: "kong"}), "kong"))
+	assert.False(t, IsIngressClassSpecConfigured(knativeIngress(nil), "kong"))
+}
+
+func TestGeneratePredicateFuncsForIngressClassFilterChecks(t *testing.T) {
+	kong := annotations.DefaultIngressClass
+	specClassed := &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "spec", Namespace: corev1.NamespaceDefault},
+		Spec:       netv1.IngressSpec{IngressClassName: &kong},
+	}
+	annotationClassed := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+		Name:        "annotation",
+		Namespace:   corev1.NamespaceDefault,
+		Annotations: map[string]string{annotations.IngressClassKey: kong},
+	}}
+	classless := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "classless", Namespace: corev1.NamespaceDefault}}
+
+	for _, tt := range []struct {
+		specCheckEnabled, annotationCheckEnabled, rejectOnMisconfig bool
+		expectedSpec, expectedAnnotation                            bool
+	}{
+		{specCheckEnabled: true, annotationCheckEnabled: true, rejectOnMisconfig: false, expectedSpec: true, expectedAnnotation: true},
+		{specCheckEnabled: true, annotationCheckEnabled: true, rejectOnMisconfig: true, expectedSpec: true, expectedAnnotation: true},
+		{specCheckEnabled: true, annotationCheckEnabled: false, rejectOnMisconfig: false, expectedSpec: true, expectedAnnotation: false},
+		{specCheckEnabled: true, annotationCheckEnabled: false, rejectOnMisconfig: true, expectedSpec: true, expectedAnnotation: false},
+		{specCheckEnabled: false, annotationCheckEnabled: true, rejectOnMisconfig: false, expectedSpec: false, expectedAnnotation: true},
+		{specCheckEnabled: false, annotationCheckEnabled: true, rejectOnMisconfig: true, expectedSpec: false, expectedAnnotation: true},
+		{specCheckEnabled: false, annotationCheckEnabled: false, rejectOnMisconfig: false, expectedSpec: false, expectedAnnotation: false},
+		{specCheckEnabled: false, annotationCheckEnabled: false, rejectOnMisconfig: true, expectedSpec: false, expectedAnnotation: false},
+	} {
+		tt := tt
+		name := fmt.Sprintf("spec=%t annotation=%t rejectOnMisconfig=%t", tt.specCheckEnabled, tt.annotationCheckEnabled, tt.rejectOnMisconfig)
+		t.Run(name, func(t *testing.T) {
+			preds := GeneratePredicateFuncsForIngressClassFilter(kong, tt.specCheckEnabled, tt.annotationCheckEnabled, tt.rejectOnMisconfig, false)
+			assert.Equal(t, tt.expectedSpec, preds.Create(event.CreateEvent{Object: specClassed}))
+			assert.Equal(t, tt.expectedAnnotation, preds.Create(event.CreateEvent{Object: annotationClassed}))
+			assert.False(t, preds.Create(event.CreateEvent{Object: classless}))
+		})
+	}
+
+	t.Log("verifying that a predicate rejecting on misconfiguration with both checks disabled rejects updates as well")
+	preds := GeneratePredicateFuncsForIngressClassFilter(kong, false, false, true, false)
+	assert.False(t, preds.Update(event.UpdateEvent{ObjectOld: annotationClassed, ObjectNew: specClassed}))
+}
+
+func TestGeneratePredicateFuncsWithNamespaceFilter(t *testing.T) {
+	kong, nginx := annotations.DefaultIngressClass, "nginx"
+	ingress := func(namespace string, class *string) *netv1.Ingress {
+		return &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "test"},
+			Spec:       netv1.IngressSpec{IngressClassName: class},
+		}
+	}
+	clusterPlugin := &kongv1.KongClusterPlugin{ObjectMeta: metav1.ObjectMeta{
+		Name:        "test",
+		Annotations: map[string]string{annotations.IngressClassKey: kong},
+	}}
+
+	t.Log("verifying that objects are filtered by namespace before their class is checked")
+	preds := GeneratePredicateFuncsWithNamespaceFilter(kong, true, true, false, false, []string{"tenant-a", "tenant-b"})
+	assert.True(t, preds.Create(event.CreateEvent{Object: ingress("tenant-a", &kong)}))
+	assert.True(t, preds.Create(event.CreateEvent{Object: ingress("tenant-b", &kong)}))
+	assert.False(t, preds.Create(event.CreateEvent{Object: ingress("tenant-a", &nginx)}))
+	assert.False(t, preds.Create(event.CreateEvent{Object: ingress("tenant-c", &kong)}))
+	assert.False(t, preds.Delete(event.DeleteEvent{Object: ingress("tenant-c", &kong)}))
+	assert.False(t, preds.Generic(event.GenericEvent{Object: ingress("tenant-c", &kong)}))
+
+	t.Log("verifying that updates are filtered by the namespace of the new object")
+	assert.True(t, preds.Update(event.UpdateEvent{ObjectOld: ingress("tenant-a", &nginx), ObjectNew: ingress("tenant-a", &kong)}))
+	assert.False(t, preds.Update(event.UpdateEvent{ObjectOld: ingress("tenant-c", &kong), ObjectNew: ingress("tenant-c", &kong)}))
+
+	t.Log("verifying that cluster-scoped objects are only filtered by their class")
+	assert.True(t, preds.Create(event.CreateEvent{Object: clusterPlugin}))
+
+	t.Log("verifying that an empty allow-list allows all namespaces")
+	preds = GeneratePredicateFuncsWithNamespaceFilter(kong, true, true, false, false, nil)
+	assert.True(t, preds.Create(event.CreateEvent{Object: ingress("tenant-c", &kong)}))
+	assert.False(t, preds.Create(event.CreateEvent{Object: ingress("tenant-c", &nginx)}))
+}
+
+func TestGeneratePredicateFuncsForIngressClassFilterWithDenyList(t *testing.T) {
+	kong, nginx := annotations.DefaultIngressClass, "nginx"
+	ingress := func(specClass *string, annotationClass string) *netv1.Ingress {
+		obj := &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "test"},
+			Spec:       netv1.IngressSpec{IngressClassName: specClass},
+		}
+		if annotationClass != "" {
+			obj.Annotations = map[string]string{annotations.IngressClassKey: annotationClass}
+		}
+		return obj
+	}
+	denied := []string{nginx}
+
+	t.Log("verifying that objects with our class are still matched")
+	preds := GeneratePredicateFuncsForIngressClassFilterWithDenyList(kong, true, true, false, false, denied)
+	assert.True(t, preds.Create(event.CreateEvent{Object: ingress(&kong, "")}))
+	assert.True(t, preds.Create(event.CreateEvent{Object: ingress(nil, kong)}))
+
+	t.Log("verifying that objects with a denied class in their spec or annotation are filtered out")
+	assert.False(t, preds.Create(event.CreateEvent{Object: ingress(&nginx, "")}))
+	assert.False(t, preds.Create(event.CreateEvent{Object: ingress(nil, nginx)}))
+	assert.False(t, preds.Delete(event.DeleteEvent{Object: ingress(nil, nginx)}))
+	assert.False(t, preds.Generic(event.GenericEvent{Object: ingress(nil, nginx)}))
+	assert.False(t, preds.Update(event.UpdateEvent{ObjectOld: ingress(&kong, ""), ObjectNew: ingress(&nginx, "")}))
+
+	t.Log("verifying that a denied annotation class wins over a matching spec class")
+	assert.False(t, preds.Create(event.CreateEvent{Object: ingress(&kong, nginx)}))
+
+	t.Log("verifying that the deny-list does not apply to classless objects, which are left to the default class logic")
+	classless := ingress(nil, "")
+	assert.False(t, isIngressClassDenied(classless, denied))
+	withoutDenyList := GeneratePredicateFuncsForIngressClassFilter(kong, true, true, false, false)
+	assert.Equal(t, withoutDenyList.Create(event.CreateEvent{Object: classless}), preds.Create(event.CreateEvent{Object: classless}))
+	assert.True(t, ShouldReconcile(classless, ClassConfig{
+		Name: kong, SpecCheckEnabled: true, AnnotationCheckEnabled: true, IsDefault: true,
+	}))
+
+	t.Log("verifying that an empty deny-list denies nothing")
+	preds = GeneratePredicateFuncsForIngressClassFilterWithDenyList(kong, true, true, false, false, nil)
+	assert.True(t, preds.Create(event.CreateEvent{Object: ingress(&kong, nginx)}))
+}
+
+func TestGenerateClassTransitionPredicate(t *testing.T) {
+	kong, nginx := annotations.DefaultIngressClass, "nginx"
+	ingress := func(specClass *string, annotationClass string, labels map[string]string) *netv1.Ingress {
+		obj := &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "test", Labels: labels},
+			Spec:       netv1.IngressSpec{IngressClassName: specClass},
+		}
+		if annotationClass != "" {
+			obj.Annotations = map[string]string{annotations.IngressClassKey: annotationClass}
+		}
+		return obj
+	}
+	preds := GenerateClassTransitionPredicate(kong)
+
+	t.Log("verifying that create and delete events are filtered like the standard ingress class filter")
+	assert.True(t, preds.Create(event.CreateEvent{Object: ingress(&kong, "", nil)}))
+	assert.True(t, preds.Create(event.CreateEvent{Object: ingress(nil, kong, nil)}))
+	assert.False(t, preds.Create(event.CreateEvent{Object: ingress(&nginx, "", nil)}))
+	assert.True(t, preds.Delete(event.DeleteEvent{Object: ingress(&kong, "", nil)}))
+	assert.False(t, preds.Delete(event.DeleteEvent{Object: ingress(nil, nginx, nil)}))
+
+	for _, tt := range []struct {
+		name     string
+		old, new *netv1.Ingress
+		expected bool
+	}{
+		{
+			name:     "unrelated update of an object with our class",
+			old:      ingress(&kong, "", nil),
+			new:      ingress(&kong, "", map[string]string{"foo": "bar"}),
+			expected: false,
+		},
+		{
+			name:     "unrelated update of an object with our annotation class",
+			old:      ingress(nil, kong, nil),
+			new:      ingress(nil, kong, map[string]string{"foo": "bar"}),
+			expected: false,
+		},
+		{
+			name:     "class moved from the annotation to the spec",
+			old:      ingress(nil, kong, nil),
+			new:      ingress(&kong, "", nil),
+			expected: false,
+		},
+		{
+			name:     "spec class changed away from our class",
+			old:      ingress(&kong, "", nil),
+			new:      ingress(&nginx, "", nil),
+			expected: true,
+		},
+		{
+			name:     "annotation class changed to our class",
+			old:      ingress(nil, nginx, nil),
+			new:      ingress(nil, kong, nil),
+			expected: true,
+		},
+		{
+			name:     "class added to a classless object",
+			old:      ingress(nil, "", nil),
+			new:      ingress(&kong, "", nil),
+			expected: true,
+		},
+		{
+			name:     "class removed from an object",
+			old:      ingress(nil, kong, nil),
+			new:      ingress(nil, "", nil),
+			expected: true,
+		},
+		{
+			name:     "unrelated update of a classless object",
+			old:      ingress(nil, "", nil),
+			new:      ingress(nil, "", map[string]string{"foo": "bar"}),
+			expected: false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, preds.Update(event.UpdateEvent{ObjectOld: tt.old, ObjectNew: tt.new}))
+		})
+	}
+}
+
+func TestIgnoreDeletingPredicate(t *testing.T) {
+	ingress := func(deleting bool, finalizers ...string) *netv1.Ingress {
+		obj := &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "test", Finalizers: finalizers},
+		}
+		if deleting {
+			now := metav1.Now()
+			obj.DeletionTimestamp = &now
+		}
+		return obj
+	}
+	preds := IgnoreDeletingPredicate("konghq.com/cleanup")
+
+	for _, tt := range []struct {
+		name     string
+		new      *netv1.Ingress
+		expected bool
+	}{
+		{
+			name:     "update of an object which isn't being deleted",
+			new:      ingress(false, "example.com/backup"),
+			expected: true,
+		},
+		{
+			name:     "update of a deleting object waiting for unrelated finalizers",
+			new:      ingress(true, "example.com/backup"),
+			expected: false,
+		},
+		{
+			name:     "update of a deleting object waiting for our finalizer",
+			new:      ingress(true, "example.com/backup", "konghq.com/cleanup"),
+			expected: true,
+		},
+		{
+			name:     "update of a deleting object without finalizers",
+			new:      ingress(true),
+			expected: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, preds.Update(event.UpdateEvent{ObjectOld: ingress(false, tt.new.Finalizers...), ObjectNew: tt.new}))
+		})
+	}
+
+	t.Log("verifying that the delete event of a deleting object still passes")
+	assert.True(t, preds.Delete(event.DeleteEvent{Object: ingress(true, "example.com/backup")}))
+	assert.True(t, preds.Create(event.CreateEvent{Object: ingress(false)}))
+	assert.True(t, preds.Generic(event.GenericEvent{Object: ingress(true, "example.com/backup")}))
+}
+
+func TestShouldReconcile(t *testing.T) {
+	kong, nginx := annotations.DefaultIngressClass, "nginx"
+	meta := func(name string, anns map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: corev1.NamespaceDefault, Annotations: anns}
+	}
+	objs := []client.Object{
+		&netv1.Ingress{ObjectMeta: meta("spec", nil), Spec: netv1.IngressSpec{IngressClassName: &kong}},
+		&netv1.Ingress{ObjectMeta: meta("spec-mismatch", nil), Spec: netv1.IngressSpec{IngressClassName: &nginx}},
+		&netv1.Ingress{ObjectMeta: meta("annotation", map[string]string{annotations.IngressClassKey: kong})},
+		&netv1.Ingress{ObjectMeta: meta("annotation-mismatch", map[string]string{annotations.IngressClassKey: nginx})},
+		&netv1.Ingress{ObjectMeta: meta("classless", nil)},
+		&knative.Ingress{ObjectMeta: meta("knative", map[string]string{annotations.KnativeIngressClassKey: kong})},
+		&kongv1beta1.TCPIngress{ObjectMeta: meta("tcpingress", map[string]string{annotations.IngressClassKey: kong})},
+		&kongv1beta1.UDPIngress{ObjectMeta: meta("udpingress", nil)},
+	}
+
+	t.Log("verifying that the predicate and ShouldReconcile agree for every check combination")
+	for _, specCheckEnabled := range []bool{true, false} {
+		for _, annotationCheckEnabled := range []bool{true, false} {
+			cfg := ClassConfig{Name: kong, SpecCheckEnabled: specCheckEnabled, AnnotationCheckEnabled: annotationCheckEnabled}
+			preds := GeneratePredicateFuncsForIngressClassFilter(kong, specCheckEnabled, annotationCheckEnabled, false, false)
+			for _, obj := range objs {
+				assert.Equal(t, ShouldReconcile(obj, cfg), preds.Create(event.CreateEvent{Object: obj}),
+					"spec=%t annotation=%t object=%s", specCheckEnabled, annotationCheckEnabled, obj.GetName())
+				for _, old := range objs {
+					assert.Equal(t, ShouldReconcile(old, cfg) || ShouldReconcile(obj, cfg),
+						preds.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: obj}))
+				}
+			}
+		}
+	}
+
+	t.Log("verifying that classless objects are only reconciled for the default class")
+	cfg := ClassConfig{Name: kong, SpecCheckEnabled: true, AnnotationCheckEnabled: true}
+	assert.False(t, ShouldReconcile(objs[4], cfg))
+	assert.False(t, ShouldReconcile(objs[7], cfg))
+	cfg.IsDefault = true
+	assert.True(t, ShouldReconcile(objs[4], cfg))
+	assert.True(t, ShouldReconcile(objs[7], cfg))
+	assert.False(t, ShouldReconcile(objs[1], cfg))
+	assert.False(t, ShouldReconcile(objs[3], cfg))
+}
+
+func TestIgnoredIngress(t *testing.T) {
+	recorder := &fakeClassMatchRecorder{counts: map[string]int{}}
+	previous := SetClassMatchRecorder(recorder)
+	defer SetClassMatchRecorder(previous)
+
+	kong := annotations.DefaultIngressClass
+	ignore := map[string]string{annotations.AnnotationPrefix + annotations.IgnoreKey: "true"}
+	classless := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "classless", Annotations: ignore}}
+	classed := &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "classed", Annotations: ignore},
+		Spec:       netv1.IngressSpec{IngressClassName: &kong},
+	}
+	cfg := ClassConfig{Name: kong, SpecCheckEnabled: true, AnnotationCheckEnabled: true, IsDefault: true}
+
+	t.Log("verifying that ignored classless ingresses are skipped under the default class")
+	assert.False(t, ShouldReconcile(classless, cfg))
+	assert.False(t, MatchesClass(classless, kong, true))
+	preds := GeneratePredicateFuncsForClassConfig(cfg, false, nil)
+	assert.False(t, preds.Create(event.CreateEvent{Object: classless}))
+	assert.Equal(t, 2, recorder.counts["Ingress/"+metrics.ClassOutcomeDroppedIgnored])
+
+	t.Log("verifying that ignored ingresses are skipped even if their class matches")
+	assert.False(t, ShouldReconcile(classed, cfg))
+	assert.False(t, MatchesIngressClassName(classed, kong))
+
+	t.Log("verifying that ignored ingresses are removed once they're ignored")
+	reconciled := classed.DeepCopy()
+	reconciled.Annotations = nil
+	assert.True(t, preds.Update(event.UpdateEvent{ObjectOld: reconciled, ObjectNew: classed}))
+
+	t.Log("verifying that the annotation only excludes ingresses with a true value")
+	classless.Annotations[annotations.AnnotationPrefix+annotations.IgnoreKey] = "false"
+	assert.True(t, ShouldReconcile(classless, cfg))
+
+	t.Log("verifying that other objects are not excluded by the annotation")
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: ignore}}
+	assert.True(t, ShouldReconcile(service, cfg))
+}
+
+func TestSetLogger(t *testing.T) {
+	var logged []string
+	previous := SetLogger(funcr.New(func(prefix, args string) {
+		logged = append(logged, args)
+	}, funcr.Options{Verbosity: util.DebugLevel}))
+	defer SetLogger(previous)
+
+	kong, nginx := "kong", "nginx"
+	matching := &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "matching"},
+		Spec:       netv1.IngressSpec{IngressClassName: &kong},
+	}
+	mismatching := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   corev1.NamespaceDefault,
+		Name:        "mismatching",
+		Annotations: map[string]string{annotations.IngressClassKey: nginx},
+	}}
+
+	t.Log("verifying that the decisions are logged without affecting them")
+	assert.True(t, MatchesClass(matching, kong, false))
+	assert.False(t, MatchesClass(mismatching, kong, true))
+	require.Len(t, logged, 2)
+	assert.Contains(t, logged[0], `"namespace"="default" "name"="matching" "class"="kong" "outcome"="matched" "matched"=true`)
+	assert.Contains(t, logged[1], `"namespace"="default" "name"="mismatching" "class"="nginx" "outcome"="dropped-mismatch" "matched"=false`)
+
+	t.Log("verifying that predicate decisions are logged too")
+	preds := GeneratePredicateFuncsForIngressClassFilter(kong, true, true, false, false)
+	assert.True(t, preds.Create(event.CreateEvent{Object: matching}))
+	require.Len(t, logged, 3)
+	assert.Contains(t, logged[2], `"msg"="ingress class resolved"`)
+	assert.Contains(t, logged[2], `"name"="matching"`)
+}
+
+func TestSetLoggerConcurrently(t *testing.T) {
+	previous := SetLogger(logr.Discard())
+	defer SetLogger(previous)
+
+	t.Log("verifying that the logger can be swapped while classes are being matched")
+	obj := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{annotations.IngressClassKey: "kong"}}}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			SetLogger(funcr.New(func(prefix, args string) {}, funcr.Options{Verbosity: util.DebugLevel}))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			assert.True(t, MatchesClass(obj, "kong", false))
+		}
+	}()
+	wg.Wait()
+}
+
+func TestStrictClass(t *testing.T) {
+	kong, nginx := "kong", "nginx"
+	ingress := func(class *string) *netv1.Ingress {
+		return &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "test"},
+			Spec:       netv1.IngressSpec{IngressClassName: class},
+		}
+	}
+	cfg := ClassConfig{Name: kong, SpecCheckEnabled: true, AnnotationCheckEnabled: true, IsDefault: true}
+
+	t.Log("verifying that classless ingresses are accepted for the default class by default")
+	preds := GeneratePredicateFuncsForClassConfig(cfg, false, nil)
+	assert.True(t, preds.Create(event.CreateEvent{Object: ingress(nil)}))
+	assert.True(t, preds.Create(event.CreateEvent{Object: ingress(&kong)}))
+	assert.False(t, preds.Create(event.CreateEvent{Object: ingress(&nginx)}))
+
+	t.Log("verifying that strict mode drops classless ingresses but accepts explicitly classed ones")
+	cfg.StrictClass = true
+	preds = GeneratePredicateFuncsForClassConfig(cfg, false, nil)
+	assert.False(t, preds.Create(event.CreateEvent{Object: ingress(nil)}))
+	assert.False(t, preds.Update(event.UpdateEvent{ObjectOld: ingress(nil), ObjectNew: ingress(nil)}))
+	assert.True(t, preds.Create(event.CreateEvent{Object: ingress(&kong)}))
+	assert.True(t, preds.Create(event.CreateEvent{Object: &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   corev1.NamespaceDefault,
+		Name:        "annotated",
+		Annotations: map[string]string{annotations.IngressClassKey: kong},
+	}}}))
+	assert.False(t, preds.Create(event.CreateEvent{Object: ingress(&nginx)}))
+
+	t.Log("verifying that the predicate registry applies strict mode")
+	registry := NewPredicateRegistry(kong)
+	registry.IsDefault = true
+	ingressGVK := netv1.SchemeGroupVersion.WithKind("Ingress")
+	assert.True(t, registry.For(ingressGVK).Create(event.CreateEvent{Object: ingress(nil)}))
+	registry.StrictClass = true
+	assert.False(t, registry.For(ingressGVK).Create(event.CreateEvent{Object: ingress(nil)}))
+	assert.True(t, registry.For(ingressGVK).Create(event.CreateEvent{Object: ingress(&kong)}))
+}
+
+func TestGeneratePredicateFuncsForIngressClassFilterStrictClass(t *testing.T) {
+	kong, nginx := "kong", "nginx"
+	classless := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "classless"}}
+	emptyAnnotation := &kongv1beta1.TCPIngress{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   corev1.NamespaceDefault,
+		Name:        "empty-annotation",
+		Annotations: map[string]string{annotations.IngressClassKey: ""},
+	}}
+	spec := &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "spec"},
+		Spec:       netv1.IngressSpec{IngressClassName: &kong},
+	}
+	annotated := &kongv1beta1.TCPIngress{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   corev1.NamespaceDefault,
+		Name:        "annotated",
+		Annotations: map[string]string{annotations.IngressClassKey: kong},
+	}}
+	other := &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "other"},
+		Spec:       netv1.IngressSpec{IngressClassName: &nginx},
+	}
+
+	preds := GeneratePredicateFuncsForIngressClassFilter(kong, true, true, false, true)
+
+	t.Log("verifying that strict class mode drops classless objects")
+	for _, obj := range []client.Object{classless, emptyAnnotation} {
+		require.True(t, IsIngressClassEmpty(obj))
+		assert.False(t, preds.Create(event.CreateEvent{Object: obj}))
+		assert.False(t, preds.Delete(event.DeleteEvent{Object: obj}))
+		assert.False(t, preds.Generic(event.GenericEvent{Object: obj}))
+		assert.False(t, preds.Update(event.UpdateEvent{ObjectOld: obj, ObjectNew: obj}))
+	}
+
+	t.Log("verifying that strict class mode keeps explicitly classed objects")
+	for _, obj := range []client.Object{spec, annotated} {
+		assert.True(t, preds.Create(event.CreateEvent{Object: obj}))
+		assert.True(t, preds.Delete(event.DeleteEvent{Object: obj}))
+		assert.True(t, preds.Update(event.UpdateEvent{ObjectOld: obj, ObjectNew: obj}))
+	}
+	assert.False(t, preds.Create(event.CreateEvent{Object: other}))
+
+	t.Log("verifying that an object losing its class still passes so that it can be cleaned up")
+	assert.True(t, preds.Update(event.UpdateEvent{ObjectOld: spec, ObjectNew: classless}))
+}
+
+func TestRequireBoth(t *testing.T) {
+	kong, nginx := "kong", "nginx"
+	ingress := func(name string, specClass *string, annotationClass string) *netv1.Ingress {
+		ing := &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: name},
+			Spec:       netv1.IngressSpec{IngressClassName: specClass},
+		}
+		if annotationClass != "" {
+			ing.Annotations = map[string]string{annotations.IngressClassKey: annotationClass}
+		}
+		return ing
+	}
+	cfg := ClassConfig{Name: kong, SpecCheckEnabled: true, AnnotationCheckEnabled: true, IsDefault: true, RequireBoth: true}
+
+	for _, tt := range []struct {
+		obj      *netv1.Ingress
+		expected bool
+	}{
+		{obj: ingress("spec-only", &kong, ""), expected: false},
+		{obj: ingress("annotation-only", nil, kong), expected: false},
+		{obj: ingress("both", &kong, kong), expected: true},
+		{obj: ingress("annotation-mismatch", &kong, nginx), expected: false},
+		{obj: ingress("spec-mismatch", &nginx, kong), expected: false},
+		{obj: ingress("classless", nil, ""), expected: false},
+	} {
+		recorder := record.NewFakeRecorder(10)
+		preds := GeneratePredicateFuncsForClassConfig(cfg, false, recorder)
+		assert.Equal(t, tt.expected, ShouldReconcile(tt.obj, cfg), tt.obj.Name)
+		assert.Equal(t, tt.expected, preds.Create(event.CreateEvent{Object: tt.obj}), tt.obj.Name)
+		if !tt.expected && !IsIngressClassEmpty(tt.obj) {
+			require.Len(t, recorder.Events, 1, tt.obj.Name)
+			assert.Contains(t, <-recorder.Events, IngressClassMismatchReason)
+		}
+	}
+
+	t.Log("verifying that classless objects are rejected even if they are labeled with the ingress class")
+	cfg.LabelCheckEnabled = true
+	labeled := ingress("labeled", nil, "")
+	labeled.Labels = map[string]string{IngressClassLabel: kong}
+	assert.False(t, ShouldReconcile(labeled, cfg))
+
+	t.Log("verifying that the predicate registry only requires both for types with an ingress class in their .spec")
+	registry := NewPredicateRegistry(kong)
+	registry.IsDefault = true
+	registry.RequireBoth = true
+	ingressPreds := registry.For(netv1.SchemeGroupVersion.WithKind("Ingress"))
+	assert.True(t, ingressPreds.Create(event.CreateEvent{Object: ingress("both", &kong, kong)}))
+	assert.False(t, ingressPreds.Create(event.CreateEvent{Object: ingress("annotation-only", nil, kong)}))
+	tcpIngressPreds := registry.For(kongv1beta1.SchemeGroupVersion.WithKind("TCPIngress"))
+	assert.True(t, tcpIngressPreds.Create(event.CreateEvent{Object: &kongv1beta1.TCPIngress{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   corev1.NamespaceDefault,
+		Name:        "annotated",
+		Annotations: map[string]string{annotations.IngressClassKey: kong},
+	}}}))
+	assert.False(t, tcpIngressPreds.Create(event.CreateEvent{Object: &kongv1beta1.TCPIngress{ObjectMeta: metav1.ObjectMeta{
+		Namespace: corev1.NamespaceDefault,
+		Name:      "classless",
+	}}}))
+}
+
+func TestGVRForKind(t *testing.T) {
+	mapper := apimeta.NewDefaultRESTMapper([]schema.GroupVersion{kongv1.SchemeGroupVersion, kongv1beta1.SchemeGroupVersion})
+	for _, crd := range []struct {
+		gvk    schema.GroupVersionKind
+		plural string
+		scope  apimeta.RESTScope
+	}{
+		{kongv1beta1.SchemeGroupVersion.WithKind("TCPIngress"), "tcpingresses", apimeta.RESTScopeNamespace},
+		{kongv1beta1.SchemeGroupVersion.WithKind("UDPIngress"), "udpingresses", apimeta.RESTScopeNamespace},
+		{kongv1.SchemeGroupVersion.WithKind("KongIngress"), "kongingresses", apimeta.RESTScopeNamespace},
+		{kongv1.SchemeGroupVersion.WithKind("KongClusterPlugin"), "kongclusterplugins", apimeta.RESTScopeRoot},
+	} {
+		singular := crd.gvk.GroupVersion().WithResource(strings.ToLower(crd.gvk.Kind))
+		mapper.AddSpecific(crd.gvk, crd.gvk.GroupVersion().WithResource(crd.plural), singular, crd.scope)
+	}
+	c := fake.NewClientBuilder().WithRESTMapper(mapper).Build()
+
+	for _, tt := range []struct {
+		gvk      schema.GroupVersionKind
+		expected string
+	}{
+		{kongv1beta1.SchemeGroupVersion.WithKind("TCPIngress"), "tcpingresses"},
+		{kongv1beta1.SchemeGroupVersion.WithKind("UDPIngress"), "udpingresses"},
+		{kongv1.SchemeGroupVersion.WithKind("KongIngress"), "kongingresses"},
+		{kongv1.SchemeGroupVersion.WithKind("KongClusterPlugin"), "kongclusterplugins"},
+	} {
+		t.Run(tt.gvk.Kind, func(t *testing.T) {
+			gvr, err := GVRForKind(c, tt.gvk)
+			require.NoError(t, err)
+			assert.Equal(t, tt.gvk.GroupVersion().WithResource(tt.expected), gvr)
+			assert.True(t, CRDExists(c, gvr))
+		})
+	}
+
+	t.Log("verifying that kinds which aren't served are reported as no match errors")
+	_, err := GVRForKind(c, kongv1.SchemeGroupVersion.WithKind("KongUnknown"))
+	require.Error(t, err)
+	assert.True(t, apimeta.IsNoMatchError(err))
+}
+
+func TestSupportedIngressGVKs(t *testing.T) {
+	netv1GVK := netv1.SchemeGroupVersion.WithKind("Ingress")
+	tcpIngressGVK := kongv1beta1.SchemeGroupVersion.WithKind("TCPIngress")
+	mapper := apimeta.NewDefaultRESTMapper([]schema.GroupVersion{
+		netv1.SchemeGroupVersion,
+		netv1beta1.SchemeGroupVersion,
+		extv1beta1.SchemeGroupVersion,
+		knative.SchemeGroupVersion,
+		kongv1beta1.SchemeGroupVersion,
+	})
+	mapper.Add(netv1GVK, apimeta.RESTScopeNamespace)
+	mapper.Add(tcpIngressGVK, apimeta.RESTScopeNamespace)
+	c := fake.NewClientBuilder().WithRESTMapper(mapper).Build()
+
+	t.Log("verifying that only the kinds served by the apiserver are supported, in order of preference")
+	assert.Equal(t, []schema.GroupVersionKind{netv1GVK, tcpIngressGVK}, SupportedIngressGVKs(c))
+
+	t.Log("verifying that every kind is supported when the apiserver serves them all")
+	for _, gvk := range ingressGVKs {
+		mapper.Add(gvk, apimeta.RESTScopeNamespace)
+	}
+	assert.Equal(t, ingressGVKs, SupportedIngressGVKs(c))
+
+	t.Log("verifying that no kind is supported when the apiserver serves none")
+	c = fake.NewClientBuilder().WithRESTMapper(apimeta.NewDefaultRESTMapper(nil)).Build()
+	assert.Empty(t, SupportedIngressGVKs(c))
+}
@@ -1,13 +1,18 @@
 package dataplane
 
 import (
+	"bytes"
 	"fmt"
 	"net"
+	"sort"
 	"strings"
 	"sync"
 
 	corev1 "k8s.io/api/core/v1"
 	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
 )
 
 // -----------------------------------------------------------------------------
@@ -82,8 +87,74 @@ func (a *AddressFinder) GetLoadBalancerAddresses() ([]corev1.LoadBalancerIngress
 		return nil, err
 	}
 
+	return toLoadBalancerAddresses(addrs)
+}
+
+// GetLoadBalancerAddressesForObject provides the addresses which should be
+// reported in the status of the provided object. If the object has the
+// konghq.com/status-address annotation its addresses are used in lieu of the
+// data-plane addresses, otherwise this is equivalent to
+// GetLoadBalancerAddresses.
+func (a *AddressFinder) GetLoadBalancerAddressesForObject(obj client.Object) ([]corev1.LoadBalancerIngress, error) {
+	if addrs, ok := annotations.ExtractStatusAddresses(obj.GetAnnotations()); ok {
+		lbs, err := toLoadBalancerAddresses(addrs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: %w", annotations.AnnotationPrefix+annotations.StatusAddressKey, err)
+		}
+		return lbs, nil
+	}
+	return a.GetLoadBalancerAddresses()
+}
+
+// -----------------------------------------------------------------------------
+// AddressFinder - Public Functions
+// -----------------------------------------------------------------------------
+
+// PublishServiceAddresses provides the addresses of the data-plane published by
+// the provided Service: the IPs and hostnames of all the load balancer ingress
+// entries of LoadBalancer Services, e.g. both the IPv4 and the IPv6 address of
+// a dual-stack load balancer or the addresses of several load balancers, and
+// the cluster IPs of all the IP families of other Services, which headless
+// Services have none of.
+func PublishServiceAddresses(svc *corev1.Service) []string {
+	var addrs []string
+	switch svc.Spec.Type { //nolint:exhaustive
+	case corev1.ServiceTypeLoadBalancer:
+		for _, lbaddr := range svc.Status.LoadBalancer.Ingress {
+			if lbaddr.IP != "" {
+				addrs = append(addrs, lbaddr.IP)
+			}
+			if lbaddr.Hostname != "" {
+				addrs = append(addrs, lbaddr.Hostname)
+			}
+		}
+	default:
+		for _, ip := range svc.Spec.ClusterIPs {
+			if ip != corev1.ClusterIPNone {
+				addrs = append(addrs, ip)
+			}
+		}
+	}
+	return addrs
+}
+
+// -----------------------------------------------------------------------------
+// AddressFinder - Private Functions
+// -----------------------------------------------------------------------------
+
+// toLoadBalancerAddresses converts the provided addresses into load balancer
+// ingress entries. Repeated addresses are only listed once and the entries are
+// sorted so that the status of objects doesn't change with the order in which
+// the addresses were provided: IPs come first, IPv4 before IPv6, followed by
+// hostnames.
+func toLoadBalancerAddresses(addrs []string) ([]corev1.LoadBalancerIngress, error) {
 	var loadBalancerAddresses []corev1.LoadBalancerIngress
+	seen := make(map[string]struct{}, len(addrs))
 	for _, addr := range addrs {
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
 		ing := corev1.LoadBalancerIngress{}
 		if net.ParseIP(addr) != nil {
 			ing.IP = addr
@@ -96,12 +167,27 @@ func (a *AddressFinder) GetLoadBalancerAddresses() ([]corev1.LoadBalancerIngress
 		loadBalancerAddresses = append(loadBalancerAddresses, ing)
 	}
 
+	sort.SliceStable(loadBalancerAddresses, func(i, j int) bool {
+		return lessLoadBalancerAddress(loadBalancerAddresses[i], loadBalancerAddresses[j])
+	})
 	return loadBalancerAddresses, nil
 }
 
-// -----------------------------------------------------------------------------
-//
-// -----------------------------------------------------------------------------
+// lessLoadBalancerAddress orders load balancer ingress entries: IPs before
+// hostnames, IPv4 before IPv6 addresses, and then by value.
+func lessLoadBalancerAddress(a, b corev1.LoadBalancerIngress) bool {
+	if (a.IP != "") != (b.IP != "") {
+		return a.IP != ""
+	}
+	if a.IP == "" {
+		return a.Hostname < b.Hostname
+	}
+	ipA, ipB := net.ParseIP(a.IP), net.ParseIP(b.IP)
+	if isV4A, isV4B := ipA.To4() != nil, ipB.To4() != nil; isV4A != isV4B {
+		return isV4A
+	}
+	return bytes.Compare(ipA.To16(), ipB.To16()) < 0
+}
 
 func isValidHostname(hostname string) error {
 	if hostname == "" {
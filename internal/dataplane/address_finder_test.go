@@ -6,6 +6,8 @@ import (
 
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestAddressFinder(t *testing.T) {
@@ -74,3 +76,92 @@ func TestAddressFinder(t *testing.T) {
 	require.Empty(t, lbs)
 	require.Equal(t, fmt.Sprintf("%s is not a valid DNS hostname", invalidDNSAddrs[0]), err.Error())
 }
+
+func TestAddressFinderStatusAddressOverride(t *testing.T) {
+	t.Log("generating a new AddressFinder with default addresses")
+	finder := NewAddressFinder()
+	finder.SetOverrides([]string{"127.0.0.1"})
+
+	t.Log("generating Ingresses with and without the status address annotation")
+	overridden := &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dedicated-lb",
+			Namespace: corev1.NamespaceDefault,
+			Annotations: map[string]string{
+				"konghq.com/status-address": "192.168.1.10,lb.konghq.com",
+			},
+		},
+	}
+	regular := &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "shared-lb",
+			Namespace: corev1.NamespaceDefault,
+		},
+	}
+
+	t.Log("verifying that the annotated Ingress gets the overridden addresses")
+	lbs, err := finder.GetLoadBalancerAddressesForObject(overridden)
+	require.NoError(t, err)
+	require.Equal(t, []corev1.LoadBalancerIngress{{IP: "192.168.1.10"}, {Hostname: "lb.konghq.com"}}, lbs)
+
+	t.Log("verifying that other Ingresses still get the data-plane addresses")
+	lbs, err = finder.GetLoadBalancerAddressesForObject(regular)
+	require.NoError(t, err)
+	require.Equal(t, []corev1.LoadBalancerIngress{{IP: "127.0.0.1"}}, lbs)
+
+	t.Log("verifying that an invalid status address produces an error")
+	overridden.Annotations["konghq.com/status-address"] = "support@konghq.com"
+	lbs, err = finder.GetLoadBalancerAddressesForObject(overridden)
+	require.Error(t, err)
+	require.Empty(t, lbs)
+}
+
+func TestPublishServiceAddresses(t *testing.T) {
+	t.Log("verifying that every address of every load balancer of a LoadBalancer Service is published")
+	svc := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Type:       corev1.ServiceTypeLoadBalancer,
+			ClusterIPs: []string{"10.96.0.10"},
+		},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{
+					{IP: "2001:db8::1"},
+					{IP: "203.0.113.10", Hostname: "lb1.konghq.com"},
+					{IP: "198.51.100.7"},
+					{Hostname: "lb2.konghq.com"},
+					{IP: "203.0.113.10"},
+				},
+			},
+		},
+	}
+	addrs := PublishServiceAddresses(svc)
+	require.ElementsMatch(t, []string{"2001:db8::1", "203.0.113.10", "lb1.konghq.com", "198.51.100.7", "lb2.konghq.com", "203.0.113.10"}, addrs)
+
+	t.Log("verifying that the published addresses are deduplicated and sorted in the status")
+	finder := NewAddressFinder()
+	finder.SetGetter(func() ([]string, error) { return PublishServiceAddresses(svc), nil })
+	lbs, err := finder.GetLoadBalancerAddresses()
+	require.NoError(t, err)
+	require.Equal(t, []corev1.LoadBalancerIngress{
+		{IP: "198.51.100.7"},
+		{IP: "203.0.113.10"},
+		{IP: "2001:db8::1"},
+		{Hostname: "lb1.konghq.com"},
+		{Hostname: "lb2.konghq.com"},
+	}, lbs)
+
+	t.Log("verifying that the cluster IPs of every IP family of other Services are published")
+	svc = &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Type:       corev1.ServiceTypeClusterIP,
+			ClusterIP:  "10.96.0.10",
+			ClusterIPs: []string{"10.96.0.10", "fd00:10:96::a"},
+		},
+	}
+	require.Equal(t, []string{"10.96.0.10", "fd00:10:96::a"}, PublishServiceAddresses(svc))
+
+	t.Log("verifying that headless Services have no addresses")
+	svc = &corev1.Service{Spec: corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone, ClusterIPs: []string{corev1.ClusterIPNone}}}
+	require.Empty(t, PublishServiceAddresses(svc))
+}
@@ -0,0 +1,226 @@
+package dataplane
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/kong/go-kong/kong"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/kongstate"
+)
+
+// -----------------------------------------------------------------------------
+// Config Diff - Public Types
+// -----------------------------------------------------------------------------
+
+// ChangeAction is the way in which an entity changed between two states.
+type ChangeAction string
+
+const (
+	// ChangeActionAdded is the action of entities which only exist in the new state.
+	ChangeActionAdded ChangeAction = "added"
+	// ChangeActionRemoved is the action of entities which only exist in the old state.
+	ChangeActionRemoved ChangeAction = "removed"
+	// ChangeActionModified is the action of entities whose fields differ between the states.
+	ChangeActionModified ChangeAction = "modified"
+)
+
+// ChangeEntry is a change of a single Kong entity between two states.
+type ChangeEntry struct {
+	// Kind is the kind of the entity: "service", "route", "upstream" or "plugin".
+	Kind string
+	// Key identifies the entity within its kind: the name of services, routes
+	// and upstreams, and the name and scope of plugins.
+	Key string
+	// Action is the way in which the entity changed.
+	Action ChangeAction
+	// Fields are the names of the Kong fields which changed, as named by the
+	// Admin API, for modified entities.
+	Fields []string
+}
+
+// String provides a concise description of the change, e.g.
+// "modified route default.foo.00 (paths, strip_path)".
+func (e ChangeEntry) String() string {
+	if len(e.Fields) == 0 {
+		return fmt.Sprintf("%s %s %s", e.Action, e.Kind, e.Key)
+	}
+	return fmt.Sprintf("%s %s %s (%s)", e.Action, e.Kind, e.Key, strings.Join(e.Fields, ", "))
+}
+
+// -----------------------------------------------------------------------------
+// Config Diff - Public Functions
+// -----------------------------------------------------------------------------
+
+// ConfigDiff provides the services, routes, upstreams and plugins which were
+// added, removed or modified between the old and the new state. Entities are
+// matched by their key, so the diff doesn't depend on the order of the
+// entities in the states, and changes are sorted by kind and key. The targets
+// of upstreams aren't compared. A nil state has no entities.
+func ConfigDiff(oldState, newState *kongstate.KongState) []ChangeEntry {
+	var changes []ChangeEntry
+	for _, kind := range []struct {
+		name    string
+		entries func(*kongstate.KongState) map[string]interface{}
+	}{
+		{name: "service", entries: serviceEntries},
+		{name: "route", entries: routeEntries},
+		{name: "upstream", entries: upstreamEntries},
+		{name: "plugin", entries: pluginEntries},
+	} {
+		oldEntries, newEntries := kind.entries(oldState), kind.entries(newState)
+		for _, key := range sortedKeys(oldEntries, newEntries) {
+			oldEntry, inOld := oldEntries[key]
+			newEntry, inNew := newEntries[key]
+			switch {
+			case !inOld:
+				changes = append(changes, ChangeEntry{Kind: kind.name, Key: key, Action: ChangeActionAdded})
+			case !inNew:
+				changes = append(changes, ChangeEntry{Kind: kind.name, Key: key, Action: ChangeActionRemoved})
+			default:
+				if fields := changedFields(oldEntry, newEntry); len(fields) > 0 {
+					changes = append(changes, ChangeEntry{Kind: kind.name, Key: key, Action: ChangeActionModified, Fields: fields})
+				}
+			}
+		}
+	}
+	return changes
+}
+
+// SummarizeConfigDiff provides a concise summary of the provided changes with
+// the number of added, removed and modified entities of each kind, e.g.
+// "services +1 -0 ~2, routes +3 -0 ~0". Kinds without changes are omitted.
+func SummarizeConfigDiff(changes []ChangeEntry) string {
+	counts := map[string]map[ChangeAction]int{}
+	var kinds []string
+	for _, change := range changes {
+		if counts[change.Kind] == nil {
+			counts[change.Kind] = map[ChangeAction]int{}
+			kinds = append(kinds, change.Kind)
+		}
+		counts[change.Kind][change.Action]++
+	}
+	summary := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		summary = append(summary, fmt.Sprintf("%ss +%d -%d ~%d", kind,
+			counts[kind][ChangeActionAdded], counts[kind][ChangeActionRemoved], counts[kind][ChangeActionModified]))
+	}
+	return strings.Join(summary, ", ")
+}
+
+// -----------------------------------------------------------------------------
+// Config Diff - Private Functions
+// -----------------------------------------------------------------------------
+
+func serviceEntries(state *kongstate.KongState) map[string]interface{} {
+	entries := map[string]interface{}{}
+	if state == nil {
+		return entries
+	}
+	for _, service := range state.Services {
+		if service.Name != nil {
+			entries[*service.Name] = service.Service
+		}
+	}
+	return entries
+}
+
+func routeEntries(state *kongstate.KongState) map[string]interface{} {
+	entries := map[string]interface{}{}
+	if state == nil {
+		return entries
+	}
+	for _, service := range state.Services {
+		for _, route := range service.Routes {
+			if route.Name != nil {
+				entries[*route.Name] = route.Route
+			}
+		}
+	}
+	return entries
+}
+
+func upstreamEntries(state *kongstate.KongState) map[string]interface{} {
+	entries := map[string]interface{}{}
+	if state == nil {
+		return entries
+	}
+	for _, upstream := range state.Upstreams {
+		if upstream.Name != nil {
+			entries[*upstream.Name] = upstream.Upstream
+		}
+	}
+	return entries
+}
+
+func pluginEntries(state *kongstate.KongState) map[string]interface{} {
+	entries := map[string]interface{}{}
+	if state == nil {
+		return entries
+	}
+	for _, plugin := range state.Plugins {
+		if plugin.Name != nil {
+			entries[pluginKey(plugin.Plugin)] = plugin.Plugin
+		}
+	}
+	return entries
+}
+
+// pluginKey identifies a plugin by its name and the entities it's configured
+// for, e.g. "rate-limiting route=default.foo.00", or "rate-limiting global".
+func pluginKey(plugin kong.Plugin) string {
+	var scopes []string
+	if plugin.Route != nil && plugin.Route.ID != nil {
+		scopes = append(scopes, "route="+*plugin.Route.ID)
+	}
+	if plugin.Service != nil && plugin.Service.ID != nil {
+		scopes = append(scopes, "service="+*plugin.Service.ID)
+	}
+	if plugin.Consumer != nil && plugin.Consumer.ID != nil {
+		scopes = append(scopes, "consumer="+*plugin.Consumer.ID)
+	}
+	if len(scopes) == 0 {
+		scopes = append(scopes, "global")
+	}
+	return *plugin.Name + " " + strings.Join(scopes, " ")
+}
+
+// changedFields provides the names of the fields which differ between two
+// Kong entities of the same type, as named by their JSON tags, sorted.
+func changedFields(oldEntity, newEntity interface{}) []string {
+	oldValue, newValue := reflect.ValueOf(oldEntity), reflect.ValueOf(newEntity)
+	var fields []string
+	for i := 0; i < oldValue.NumField(); i++ {
+		field := oldValue.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func sortedKeys(entries ...map[string]interface{}) []string {
+	seen := map[string]struct{}{}
+	var keys []string
+	for _, m := range entries {
+		for key := range m {
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
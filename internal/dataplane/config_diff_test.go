@@ -0,0 +1,94 @@
+package dataplane
+
+import (
+	"testing"
+
+	"github.com/kong/go-kong/kong"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/kongstate"
+)
+
+func TestConfigDiff(t *testing.T) {
+	state := func() *kongstate.KongState {
+		return &kongstate.KongState{
+			Services: []kongstate.Service{
+				{
+					Service: kong.Service{Name: kong.String("default.foo.80"), Host: kong.String("foo.default.80.svc")},
+					Routes: []kongstate.Route{
+						{Route: kong.Route{Name: kong.String("default.foo.00"), Paths: kong.StringSlice("/foo")}},
+						{Route: kong.Route{Name: kong.String("default.foo.01"), Paths: kong.StringSlice("/bar")}},
+					},
+				},
+				{Service: kong.Service{Name: kong.String("default.bar.80"), Host: kong.String("bar.default.80.svc")}},
+			},
+			Upstreams: []kongstate.Upstream{
+				{
+					Upstream: kong.Upstream{Name: kong.String("foo.default.80.svc")},
+					Targets:  []kongstate.Target{{Target: kong.Target{Target: kong.String("10.0.0.1:80")}}},
+				},
+			},
+			Plugins: []kongstate.Plugin{
+				{Plugin: kong.Plugin{Name: kong.String("cors")}},
+				{Plugin: kong.Plugin{
+					Name:   kong.String("rate-limiting"),
+					Route:  &kong.Route{ID: kong.String("default.foo.00")},
+					Config: kong.Configuration{"minute": 5},
+				}},
+			},
+		}
+	}
+
+	t.Log("verifying that identical states have no changes")
+	assert.Empty(t, ConfigDiff(state(), state()))
+
+	t.Log("verifying that the diff doesn't depend on the order of the entities")
+	reordered := state()
+	reordered.Services[0], reordered.Services[1] = reordered.Services[1], reordered.Services[0]
+	routes := reordered.Services[1].Routes
+	routes[0], routes[1] = routes[1], routes[0]
+	reordered.Plugins[0], reordered.Plugins[1] = reordered.Plugins[1], reordered.Plugins[0]
+	assert.Empty(t, ConfigDiff(state(), reordered))
+
+	t.Log("verifying that every entity of a new configuration is added")
+	changes := ConfigDiff(nil, state())
+	require.Len(t, changes, 7)
+	for _, change := range changes {
+		assert.Equal(t, ChangeActionAdded, change.Action)
+	}
+	assert.Equal(t, "services +2 -0 ~0, routes +2 -0 ~0, upstreams +1 -0 ~0, plugins +2 -0 ~0", SummarizeConfigDiff(changes))
+
+	t.Log("verifying that additions, removals and field-level modifications are reported")
+	changed := state()
+	changed.Services[0].Host = kong.String("foo.other.80.svc")
+	changed.Services[0].Routes[0].Paths = kong.StringSlice("/foo", "/baz")
+	changed.Services[0].Routes[0].StripPath = kong.Bool(false)
+	changed.Services[0].Routes = append(changed.Services[0].Routes[:1],
+		kongstate.Route{Route: kong.Route{Name: kong.String("default.foo.02"), Paths: kong.StringSlice("/qux")}})
+	changed.Services = changed.Services[:1]
+	changed.Upstreams[0].Targets = nil
+	changed.Plugins[1].Config = kong.Configuration{"minute": 10}
+	assert.Equal(t, []ChangeEntry{
+		{Kind: "service", Key: "default.bar.80", Action: ChangeActionRemoved},
+		{Kind: "service", Key: "default.foo.80", Action: ChangeActionModified, Fields: []string{"host"}},
+		{Kind: "route", Key: "default.foo.00", Action: ChangeActionModified, Fields: []string{"paths", "strip_path"}},
+		{Kind: "route", Key: "default.foo.01", Action: ChangeActionRemoved},
+		{Kind: "route", Key: "default.foo.02", Action: ChangeActionAdded},
+		{Kind: "plugin", Key: "rate-limiting route=default.foo.00", Action: ChangeActionModified, Fields: []string{"config"}},
+	}, ConfigDiff(state(), changed))
+
+	t.Log("verifying that every entity of a removed configuration is removed")
+	changes = ConfigDiff(state(), &kongstate.KongState{})
+	require.Len(t, changes, 7)
+	for _, change := range changes {
+		assert.Equal(t, ChangeActionRemoved, change.Action)
+	}
+}
+
+func TestChangeEntryString(t *testing.T) {
+	assert.Equal(t, "added service default.foo.80",
+		ChangeEntry{Kind: "service", Key: "default.foo.80", Action: ChangeActionAdded}.String())
+	assert.Equal(t, "modified route default.foo.00 (paths, strip_path)",
+		ChangeEntry{Kind: "route", Key: "default.foo.00", Action: ChangeActionModified, Fields: []string{"paths", "strip_path"}}.String())
+}
@@ -0,0 +1,87 @@
+package dataplane
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kong/deck/file"
+)
+
+// -----------------------------------------------------------------------------
+// Config Publisher - Public Types
+// -----------------------------------------------------------------------------
+
+// DefaultConfigPublishTimeout is the maximum amount of time a ConfigPublisher
+// waits for the remote sink to accept a configuration.
+const DefaultConfigPublishTimeout = 10 * time.Second
+
+// ConfigPublisher publishes the configuration generated by the controller to a
+// remote URL (e.g. a GitOps diffing service) with an HTTP PUT request. Only
+// configuration which differs from the last successfully published
+// configuration is sent, so unchanged syncs don't generate requests.
+//
+// The configuration handed to the publisher is published as is: callers are
+// responsible for redacting any sensitive values before publishing.
+type ConfigPublisher struct {
+	url    string
+	client *http.Client
+
+	lock          sync.Mutex
+	lastConfigSHA []byte
+}
+
+// NewConfigPublisher provides a new ConfigPublisher which PUTs configuration
+// to the provided URL. A nil client uses an HTTP client with the
+// DefaultConfigPublishTimeout.
+func NewConfigPublisher(url string, client *http.Client) *ConfigPublisher {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultConfigPublishTimeout}
+	}
+	return &ConfigPublisher{url: url, client: client}
+}
+
+// -----------------------------------------------------------------------------
+// Config Publisher - Public Methods
+// -----------------------------------------------------------------------------
+
+// Publish sends the provided configuration to the remote URL as JSON, unless
+// it is identical to the last configuration which was published successfully.
+// It reports whether a request was sent and accepted.
+func (p *ConfigPublisher) Publish(ctx context.Context, config *file.Content) (bool, error) {
+	body, err := json.Marshal(config)
+	if err != nil {
+		return false, fmt.Errorf("could not marshal configuration: %w", err)
+	}
+	sha := sha256.Sum256(body)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if bytes.Equal(p.lastConfigSHA, sha[:]) {
+		return false, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, p.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("could not build publish request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("could not publish configuration: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return false, fmt.Errorf("could not publish configuration: %s responded with %s", p.url, resp.Status)
+	}
+
+	p.lastConfigSHA = sha[:]
+	return true, nil
+}
@@ -0,0 +1,86 @@
+package dataplane
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/kong/deck/file"
+	"github.com/kong/go-kong/kong"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigPublisher(t *testing.T) {
+	var lock sync.Mutex
+	var bodies [][]byte
+	status := http.StatusOK
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		lock.Lock()
+		defer lock.Unlock()
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+	}))
+	defer sink.Close()
+	published := func() int {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(bodies)
+	}
+
+	config := func(services ...string) *file.Content {
+		content := &file.Content{FormatVersion: "1.1"}
+		for _, name := range services {
+			content.Services = append(content.Services, file.FService{Service: kong.Service{Name: kong.String(name)}})
+		}
+		return content
+	}
+
+	ctx := context.Background()
+	p := NewConfigPublisher(sink.URL, nil)
+
+	t.Log("verifying that the first configuration is published")
+	ok, err := p.Publish(ctx, config("svc-a"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	require.Equal(t, 1, published())
+	var received file.Content
+	require.NoError(t, json.Unmarshal(bodies[0], &received))
+	require.Len(t, received.Services, 1)
+	assert.Equal(t, "svc-a", *received.Services[0].Name)
+
+	t.Log("verifying that an unchanged configuration is not published again")
+	ok, err = p.Publish(ctx, config("svc-a"))
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 1, published())
+
+	t.Log("verifying that a changed configuration is published")
+	ok, err = p.Publish(ctx, config("svc-a", "svc-b"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 2, published())
+
+	t.Log("verifying that a rejected configuration is retried on the next publish")
+	lock.Lock()
+	status = http.StatusInternalServerError
+	lock.Unlock()
+	_, err = p.Publish(ctx, config("svc-c"))
+	require.Error(t, err)
+	assert.Equal(t, 3, published())
+	lock.Lock()
+	status = http.StatusOK
+	lock.Unlock()
+	ok, err = p.Publish(ctx, config("svc-c"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 4, published())
+}
@@ -3,8 +3,10 @@ package deckgen
 import (
 	"bytes"
 	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/kong/deck/file"
 	"github.com/kong/go-kong/kong"
@@ -18,7 +20,7 @@ func GenerateSHA(targetContent *file.Content,
 
 	var buffer bytes.Buffer
 
-	jsonConfig, err := json.Marshal(targetContent)
+	jsonConfig, err := normalizedJSON(targetContent)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling Kong declarative configuration to JSON: %w", err)
 	}
@@ -32,6 +34,62 @@ func GenerateSHA(targetContent *file.Content,
 	return shaSum[:], nil
 }
 
+// ConfigHash generates a hex encoded SHA256 checksum of the provided Kong declarative configuration, with the purpose
+// of change detection. The checksum doesn't depend on the order of entities: object keys are sorted and lists of
+// entities which have a name are sorted by that name before hashing.
+func ConfigHash(config interface{}) (string, error) {
+	jsonConfig, err := normalizedJSON(config)
+	if err != nil {
+		return "", fmt.Errorf("marshaling Kong declarative configuration to JSON: %w", err)
+	}
+	shaSum := sha256.Sum256(jsonConfig)
+	return hex.EncodeToString(shaSum[:]), nil
+}
+
+// normalizedJSON marshals the provided value to JSON in which object keys are sorted and lists of named entities are
+// sorted by name, so that semantically identical configurations produce identical JSON.
+func normalizedJSON(config interface{}) ([]byte, error) {
+	jsonConfig, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonConfig))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	// encoding/json marshals map keys in sorted order, so only lists need sorting.
+	return json.Marshal(sortNamedEntities(generic))
+}
+
+// sortNamedEntities recursively sorts lists in which every element is an object with a string "name" field by that
+// name. The sort is stable, so entities sharing a name (e.g. plugins) keep their relative order.
+func sortNamedEntities(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, elem := range v {
+			v[key] = sortNamedEntities(elem)
+		}
+	case []interface{}:
+		named := 0
+		for i, elem := range v {
+			v[i] = sortNamedEntities(elem)
+			if obj, ok := v[i].(map[string]interface{}); ok {
+				if _, ok := obj["name"].(string); ok {
+					named++
+				}
+			}
+		}
+		if named == len(v) {
+			sort.SliceStable(v, func(i, j int) bool {
+				return v[i].(map[string]interface{})["name"].(string) < v[j].(map[string]interface{})["name"].(string)
+			})
+		}
+	}
+	return value
+}
+
 // CleanUpNullsInPluginConfigs modifies `state` by deleting plugin config map keys that have nil as their value.
 func CleanUpNullsInPluginConfigs(state *file.Content) {
 	for _, s := range state.Services {
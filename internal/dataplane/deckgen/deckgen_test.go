@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/kong/deck/file"
 	"github.com/kong/go-kong/kong"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -664,3 +666,78 @@ func TestFillReqeustTransformerNestedConfig(t *testing.T) {
 	assert.Equal(want, res)
 	assert.Nil(err)
 }
+
+func TestConfigHash(t *testing.T) {
+	service := func(name string, routes ...string) file.FService {
+		s := file.FService{Service: kong.Service{Name: kong.String(name), Host: kong.String(name + ".svc")}}
+		for _, route := range routes {
+			s.Routes = append(s.Routes, &file.FRoute{Route: kong.Route{
+				Name:  kong.String(route),
+				Paths: kong.StringSlice("/" + route),
+			}})
+		}
+		return s
+	}
+
+	config := &file.Content{
+		FormatVersion: "1.1",
+		Services:      []file.FService{service("foo", "foo-a", "foo-b"), service("bar", "bar-a")},
+		Consumers: []file.FConsumer{
+			{Consumer: kong.Consumer{Username: kong.String("alice")}},
+		},
+		Plugins: []file.FPlugin{
+			{Plugin: kong.Plugin{Name: kong.String("cors"), Config: kong.Configuration{"b": 1, "a": 2}}},
+			{Plugin: kong.Plugin{Name: kong.String("acl")}},
+		},
+	}
+	reordered := &file.Content{
+		FormatVersion: "1.1",
+		Services:      []file.FService{service("bar", "bar-a"), service("foo", "foo-b", "foo-a")},
+		Consumers: []file.FConsumer{
+			{Consumer: kong.Consumer{Username: kong.String("alice")}},
+		},
+		Plugins: []file.FPlugin{
+			{Plugin: kong.Plugin{Name: kong.String("acl")}},
+			{Plugin: kong.Plugin{Name: kong.String("cors"), Config: kong.Configuration{"a": 2, "b": 1}}},
+		},
+	}
+
+	hash, err := ConfigHash(config)
+	require.NoError(t, err)
+	assert.Len(t, hash, 64)
+
+	t.Log("verifying that the hash is deterministic")
+	again, err := ConfigHash(config)
+	require.NoError(t, err)
+	assert.Equal(t, hash, again)
+
+	t.Log("verifying that reordering semantically identical entities yields the same hash")
+	reorderedHash, err := ConfigHash(reordered)
+	require.NoError(t, err)
+	assert.Equal(t, hash, reorderedHash)
+
+	t.Log("verifying that a changed entity yields a different hash")
+	reordered.Services[1].Routes[0].Paths = kong.StringSlice("/changed")
+	changedHash, err := ConfigHash(reordered)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, changedHash)
+
+	t.Log("verifying that unnamed lists keep their order")
+	first, err := ConfigHash(map[string]interface{}{"paths": []string{"/a", "/b"}})
+	require.NoError(t, err)
+	second, err := ConfigHash(map[string]interface{}{"paths": []string{"/b", "/a"}})
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	t.Log("verifying that the change detection checksum ignores entity order")
+	sha, err := GenerateSHA(config, nil)
+	require.NoError(t, err)
+	reorderedSHA, err := GenerateSHA(&file.Content{
+		FormatVersion: "1.1",
+		Services:      []file.FService{config.Services[1], config.Services[0]},
+		Consumers:     config.Consumers,
+		Plugins:       []file.FPlugin{config.Plugins[1], config.Plugins[0]},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, sha, reorderedSHA)
+}
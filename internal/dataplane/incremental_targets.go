@@ -0,0 +1,143 @@
+package dataplane
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/kong/go-kong/kong"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/kongstate"
+)
+
+// -----------------------------------------------------------------------------
+// Incremental Target Updates - Public Types
+// -----------------------------------------------------------------------------
+
+// TargetClient is the part of the Kong Admin API which is used to update the
+// targets of upstreams incrementally, as implemented by the Targets service of
+// a *kong.Client.
+type TargetClient interface {
+	Create(ctx context.Context, upstreamNameOrID *string, target *kong.Target) (*kong.Target, error)
+	Delete(ctx context.Context, upstreamNameOrID *string, targetOrID *string) error
+}
+
+// TargetChange is the set of targets which have to be added to and removed
+// from a single upstream.
+type TargetChange struct {
+	Upstream string
+	Add      []kong.Target
+	Remove   []string
+}
+
+// -----------------------------------------------------------------------------
+// Incremental Target Updates - Public Functions
+// -----------------------------------------------------------------------------
+
+// IsStructuralChange indicates whether a change of the provided object can
+// change anything besides the targets of upstreams, i.e. whether it requires
+// a full sync of the configuration.
+func IsStructuralChange(obj client.Object) bool {
+	switch obj.(type) {
+	case *corev1.Endpoints, *discoveryv1.EndpointSlice:
+		return false
+	}
+	return true
+}
+
+// DiffTargets provides the target changes which turn the old state into the
+// new state. ok is false, meaning that a full sync is required, if there is
+// no old state or if the states differ in anything but the targets of their
+// upstreams. Changes are provided in the order of the upstreams of the new
+// state, and upstreams whose targets didn't change are omitted.
+func DiffTargets(oldState, newState *kongstate.KongState) (changes []TargetChange, ok bool) {
+	if oldState == nil || newState == nil || len(oldState.Upstreams) != len(newState.Upstreams) {
+		return nil, false
+	}
+	if !reflect.DeepEqual(withoutTargets(oldState), withoutTargets(newState)) {
+		return nil, false
+	}
+
+	for i, upstream := range newState.Upstreams {
+		oldTargets := targetsByAddress(oldState.Upstreams[i].Targets)
+		newTargets := targetsByAddress(upstream.Targets)
+
+		change := TargetChange{Upstream: *upstream.Name}
+		for _, address := range sortedAddresses(oldTargets) {
+			if newTarget, found := newTargets[address]; !found || !reflect.DeepEqual(newTarget.Weight, oldTargets[address].Weight) {
+				change.Remove = append(change.Remove, address)
+			}
+		}
+		for _, address := range sortedAddresses(newTargets) {
+			if oldTarget, found := oldTargets[address]; !found || !reflect.DeepEqual(oldTarget.Weight, newTargets[address].Weight) {
+				change.Add = append(change.Add, newTargets[address])
+			}
+		}
+		if len(change.Add) > 0 || len(change.Remove) > 0 {
+			changes = append(changes, change)
+		}
+	}
+	return changes, true
+}
+
+// ApplyTargetChanges applies the provided target changes with targeted Admin
+// API calls. Targets are removed before they are added so that targets whose
+// weight changed are re-created. The first error aborts the update, in which
+// case callers should fall back to a full sync.
+func ApplyTargetChanges(ctx context.Context, targets TargetClient, changes []TargetChange) error {
+	for _, change := range changes {
+		upstream := kong.String(change.Upstream)
+		for _, address := range change.Remove {
+			if err := targets.Delete(ctx, upstream, kong.String(address)); err != nil {
+				return fmt.Errorf("could not remove target %s from upstream %s: %w", address, change.Upstream, err)
+			}
+		}
+		for i := range change.Add {
+			target := change.Add[i]
+			if _, err := targets.Create(ctx, upstream, &target); err != nil {
+				return fmt.Errorf("could not add target %s to upstream %s: %w", *target.Target, change.Upstream, err)
+			}
+		}
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// Incremental Target Updates - Private Functions
+// -----------------------------------------------------------------------------
+
+// withoutTargets provides a shallow copy of the provided state in which the
+// upstreams have no targets.
+func withoutTargets(state *kongstate.KongState) kongstate.KongState {
+	copied := *state
+	copied.Upstreams = make([]kongstate.Upstream, len(state.Upstreams))
+	for i, upstream := range state.Upstreams {
+		upstream.Targets = nil
+		copied.Upstreams[i] = upstream
+	}
+	return copied
+}
+
+func targetsByAddress(targets []kongstate.Target) map[string]kong.Target {
+	byAddress := make(map[string]kong.Target, len(targets))
+	for _, target := range targets {
+		if target.Target.Target == nil {
+			continue
+		}
+		byAddress[*target.Target.Target] = target.Target
+	}
+	return byAddress
+}
+
+func sortedAddresses(targets map[string]kong.Target) []string {
+	addresses := make([]string, 0, len(targets))
+	for address := range targets {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
@@ -0,0 +1,321 @@
+package dataplane
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kong/go-kong/kong"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/kongstate"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/parser"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/sendconfig"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/metrics"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/store"
+)
+
+type fakeTargetClient struct {
+	lock    sync.Mutex
+	created []string
+	deleted []string
+}
+
+func (f *fakeTargetClient) Create(_ context.Context, upstream *string, target *kong.Target) (*kong.Target, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.created = append(f.created, *upstream+"/"+*target.Target)
+	return target, nil
+}
+
+func (f *fakeTargetClient) Delete(_ context.Context, upstream *string, target *string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.deleted = append(f.deleted, *upstream+"/"+*target)
+	return nil
+}
+
+// fakeKong is a DB-less Kong Admin API which keeps track of the targets of
+// upstreams, which are set by full syncs to /config and by target updates.
+type fakeKong struct {
+	lock    sync.Mutex
+	targets map[string]bool
+}
+
+func (f *fakeKong) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/config" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var config struct {
+		Upstreams []struct {
+			Name    string `json:"name"`
+			Targets []struct {
+				Target string `json:"target"`
+			} `json:"targets"`
+		} `json:"upstreams"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.targets = make(map[string]bool)
+	for _, upstream := range config.Upstreams {
+		for _, target := range upstream.Targets {
+			f.targets[upstream.Name+"/"+target.Target] = true
+		}
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (f *fakeKong) Create(_ context.Context, upstream *string, target *kong.Target) (*kong.Target, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.targets[*upstream+"/"+*target.Target] = true
+	return target, nil
+}
+
+func (f *fakeKong) Delete(_ context.Context, upstream *string, target *string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.targets, *upstream+"/"+*target)
+	return nil
+}
+
+func (f *fakeKong) configuredTargets() map[string]bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	targets := make(map[string]bool, len(f.targets))
+	for target := range f.targets {
+		targets[target] = true
+	}
+	return targets
+}
+
+func TestDiffTargets(t *testing.T) {
+	state := func(routePath string, targets ...string) *kongstate.KongState {
+		upstream := kongstate.Upstream{Upstream: kong.Upstream{Name: kong.String("svc.default.80.svc")}}
+		for _, target := range targets {
+			upstream.Targets = append(upstream.Targets, kongstate.Target{Target: kong.Target{Target: kong.String(target)}})
+		}
+		return &kongstate.KongState{
+			Services: []kongstate.Service{{
+				Service: kong.Service{Name: kong.String("default.svc.80")},
+				Routes:  []kongstate.Route{{Route: kong.Route{Paths: kong.StringSlice(routePath)}}},
+			}},
+			Upstreams: []kongstate.Upstream{upstream},
+		}
+	}
+
+	t.Log("verifying that a full sync is required without a previous state")
+	_, ok := DiffTargets(nil, state("/foo", "10.0.0.1:80"))
+	assert.False(t, ok)
+
+	t.Log("verifying that target-only changes are diffed")
+	changes, ok := DiffTargets(state("/foo", "10.0.0.1:80", "10.0.0.2:80"), state("/foo", "10.0.0.2:80", "10.0.0.3:80"))
+	require.True(t, ok)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "svc.default.80.svc", changes[0].Upstream)
+	assert.Equal(t, []string{"10.0.0.1:80"}, changes[0].Remove)
+	require.Len(t, changes[0].Add, 1)
+	assert.Equal(t, "10.0.0.3:80", *changes[0].Add[0].Target)
+
+	t.Log("verifying that unchanged targets produce no changes")
+	changes, ok = DiffTargets(state("/foo", "10.0.0.1:80"), state("/foo", "10.0.0.1:80"))
+	require.True(t, ok)
+	assert.Empty(t, changes)
+
+	t.Log("verifying that a full sync is required when anything but targets changed")
+	_, ok = DiffTargets(state("/foo", "10.0.0.1:80"), state("/bar", "10.0.0.2:80"))
+	assert.False(t, ok)
+}
+
+// incrementalTargetsObjects provides an Ingress of the provided class, its
+// backend Service, and a function providing the Endpoints of the Service.
+func incrementalTargetsObjects(className string) (*netv1.Ingress, *corev1.Service, func(...string) *corev1.Endpoints) {
+	ingress := &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "ing",
+			Namespace:   corev1.NamespaceDefault,
+			Annotations: map[string]string{annotations.IngressClassKey: className},
+		},
+		Spec: netv1.IngressSpec{
+			Rules: []netv1.IngressRule{{
+				IngressRuleValue: netv1.IngressRuleValue{
+					HTTP: &netv1.HTTPIngressRuleValue{
+						Paths: []netv1.HTTPIngressPath{{
+							Path: "/",
+							Backend: netv1.IngressBackend{
+								Service: &netv1.IngressServiceBackend{
+									Name: "svc",
+									Port: netv1.ServiceBackendPort{Number: 80},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: corev1.NamespaceDefault},
+		Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{
+			Name:       "http",
+			Protocol:   corev1.ProtocolTCP,
+			Port:       80,
+			TargetPort: intstr.FromInt(8080),
+		}}},
+	}
+	endpoints := func(ips ...string) *corev1.Endpoints {
+		subset := corev1.EndpointSubset{Ports: []corev1.EndpointPort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 8080}}}
+		for _, ip := range ips {
+			subset.Addresses = append(subset.Addresses, corev1.EndpointAddress{IP: ip})
+		}
+		return &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: corev1.NamespaceDefault},
+			Subsets:    []corev1.EndpointSubset{subset},
+		}
+	}
+	return ingress, service, endpoints
+}
+
+func TestKongClientIncrementalTargetUpdates(t *testing.T) {
+	className := annotations.DefaultIngressClass
+	ingress, service, endpoints := incrementalTargetsObjects(className)
+
+	cache := store.NewCacheStores()
+	targets := &fakeTargetClient{}
+	c := &KongClient{
+		logger:           logrus.New(),
+		ingressClass:     className,
+		requestTimeout:   time.Second,
+		cache:            &cache,
+		structuralChange: true,
+		targetClient:     targets,
+		prometheusMetrics: &metrics.CtrlFuncMetrics{
+			TranslationCount: prometheus.NewCounterVec(
+				prometheus.CounterOpts{Name: metrics.MetricNameTranslationCount},
+				[]string{metrics.SuccessKey},
+			),
+		},
+	}
+	require.NoError(t, c.UpdateObject(ingress))
+	require.NoError(t, c.UpdateObject(service))
+	require.NoError(t, c.UpdateObject(endpoints("10.0.0.1", "10.0.0.2")))
+
+	t.Log("verifying that structural changes require a full sync")
+	assert.True(t, c.takeStructuralChange())
+
+	t.Log("simulating a full sync of the initial state")
+	c.lastKongState = stateFor(t, c)
+	require.Len(t, c.lastKongState.Upstreams, 1)
+	upstream := *c.lastKongState.Upstreams[0].Name
+
+	t.Log("verifying that an Endpoints-only change issues targeted target updates and no full sync")
+	// the client has no Admin API client, so a full sync would fail
+	require.NoError(t, c.UpdateObject(endpoints("10.0.0.2", "10.0.0.3")))
+	require.NoError(t, c.Update(context.Background()))
+	assert.Equal(t, []string{upstream + "/10.0.0.1:8080"}, targets.deleted)
+	assert.Equal(t, []string{upstream + "/10.0.0.3:8080"}, targets.created)
+	require.Len(t, c.lastKongState.Upstreams, 1)
+	assert.Len(t, c.lastKongState.Upstreams[0].Targets, 2)
+
+	t.Log("verifying that a Service change requires a full sync")
+	service = service.DeepCopy()
+	service.Annotations = map[string]string{annotations.AnnotationPrefix + annotations.ProtocolKey: "https"}
+	require.NoError(t, c.UpdateObject(service))
+	assert.True(t, c.takeStructuralChange())
+
+	t.Log("verifying that targets are never updated incrementally in DB-less mode")
+	c.kongConfig.InMemory = true
+	require.NoError(t, c.UpdateObject(endpoints("10.0.0.4")))
+	assert.False(t, c.takeStructuralChange())
+	assert.False(t, c.updateTargetsIncrementally(context.Background(), stateFor(t, c)))
+	assert.Len(t, targets.created, 1)
+}
+
+func TestKongClientFullSyncAfterIncrementalTargetUpdates(t *testing.T) {
+	className := annotations.DefaultIngressClass
+	ingress, service, endpoints := incrementalTargetsObjects(className)
+
+	fake := &fakeKong{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	kongClient, err := kong.NewClient(kong.String(server.URL), server.Client())
+	require.NoError(t, err)
+
+	cache := store.NewCacheStores()
+	c := &KongClient{
+		logger:           logrus.New(),
+		ingressClass:     className,
+		requestTimeout:   time.Second,
+		cache:            &cache,
+		structuralChange: true,
+		targetClient:     fake,
+		kongConfig: sendconfig.Kong{
+			URL:      server.URL,
+			Client:   kongClient,
+			InMemory: true,
+		},
+		prometheusMetrics: &metrics.CtrlFuncMetrics{
+			ConfigPushCount: prometheus.NewCounterVec(
+				prometheus.CounterOpts{Name: metrics.MetricNameConfigPushCount},
+				[]string{metrics.SuccessKey, metrics.ProtocolKey},
+			),
+			TranslationCount: prometheus.NewCounterVec(
+				prometheus.CounterOpts{Name: metrics.MetricNameTranslationCount},
+				[]string{metrics.SuccessKey},
+			),
+			ConfigPushDuration: prometheus.NewHistogramVec(
+				prometheus.HistogramOpts{Name: metrics.MetricNameConfigPushDuration},
+				[]string{metrics.SuccessKey, metrics.ProtocolKey},
+			),
+		},
+	}
+	require.NoError(t, c.UpdateObject(ingress))
+	require.NoError(t, c.UpdateObject(service))
+	require.NoError(t, c.UpdateObject(endpoints("10.0.0.1")))
+
+	t.Log("performing a full sync of configuration A")
+	require.NoError(t, c.Update(context.Background()))
+	require.Len(t, c.lastKongState.Upstreams, 1)
+	upstream := *c.lastKongState.Upstreams[0].Name
+	configA := map[string]bool{upstream + "/10.0.0.1:8080": true}
+	require.Equal(t, configA, fake.configuredTargets())
+
+	t.Log("updating the targets incrementally to configuration B")
+	// incremental target updates are only performed in DB mode, the full
+	// syncs of this test use /config for the sake of a simpler fake Kong
+	c.kongConfig.InMemory = false
+	require.NoError(t, c.UpdateObject(endpoints("10.0.0.2")))
+	require.NoError(t, c.Update(context.Background()))
+	require.Equal(t, map[string]bool{upstream + "/10.0.0.2:8080": true}, fake.configuredTargets())
+
+	t.Log("verifying that a full sync of configuration A isn't skipped")
+	c.kongConfig.InMemory = true
+	require.NoError(t, c.UpdateObject(endpoints("10.0.0.1")))
+	c.recordStructuralChange()
+	require.NoError(t, c.Update(context.Background()))
+	assert.Equal(t, configA, fake.configuredTargets())
+}
+
+// stateFor builds the state which the client would sync for its cache.
+func stateFor(t *testing.T, c *KongClient) *kongstate.KongState {
+	state, err := parser.NewParser(c.logger, store.New(*c.cache, c.ingressClass, false, false, false, c.logger)).Build()
+	require.NoError(t, err)
+	return state
+}
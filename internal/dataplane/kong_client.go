@@ -3,6 +3,8 @@ package dataplane
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,13 +12,17 @@ import (
 	"github.com/kong/go-kong/kong"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/adminapi"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/deckgen"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/kongstate"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/parser"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/sendconfig"
 	k8sobj "github.com/kong/kubernetes-ingress-controller/v2/internal/kubernetes/object"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/kubernetes/object/status"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/manager/featuregates"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/metrics"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/store"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/util"
@@ -41,6 +47,57 @@ type KongClient struct {
 	// updates to the data-plane.
 	enableReverseSync bool
 
+	// dropRoutesWithoutTargets indicates that the routes of services without
+	// any targets should be dropped instead of routing to an empty upstream.
+	dropRoutesWithoutTargets bool
+
+	// maxPathsPerRoute is the maximum number of paths of a single route, routes
+	// with more paths are split. A value of 0 means that it's not limited.
+	maxPathsPerRoute int
+
+	// maxRoutesPerIngress is the maximum number of routes generated for a
+	// single Ingress. A value of 0 means that it's not limited.
+	maxRoutesPerIngress int
+
+	// sniStrictness is the way in which HTTPS routes whose hosts aren't
+	// covered by any certificate are handled.
+	sniStrictness parser.SNIStrictness
+
+	// failurePolicy is the way in which objects referencing plugins which
+	// don't exist or can't be translated are handled.
+	failurePolicy parser.FailurePolicy
+
+	// routeNamer names the routes generated for the rule paths of Ingresses,
+	// the parser's default naming is used if it's nil.
+	routeNamer parser.RouteNamer
+
+	// featureGates are the gated features which are translated.
+	featureGates featuregates.FeatureGates
+
+	// streamingPlugins are the names of the Kong plugins which stream request
+	// or response bodies, the buffering of their routes is disabled.
+	streamingPlugins []string
+
+	// eventRecorder records events on the Kubernetes objects which are
+	// translated into data-plane configuration.
+	eventRecorder record.EventRecorder
+
+	// configPublisher publishes the redacted configuration to a remote URL
+	// after each successful update when configured.
+	configPublisher *ConfigPublisher
+
+	// stableCertificateIDs indicates that the IDs of certificates are derived
+	// from the namespace, name and content of their Secrets.
+	stableCertificateIDs bool
+
+	// ingressClassTags indicates that the entities generated by the client are
+	// tagged with the ingress class it manages.
+	ingressClassTags bool
+
+	// classDefaultTimeouts are the default timeouts of the services generated
+	// by the client, keyed by ingress class.
+	classDefaultTimeouts map[string]parser.ServiceTimeouts
+
 	// requestTimeout is the maximum amount of time that should be waited for
 	// requests to the data-plane to receive a response.
 	requestTimeout time.Duration
@@ -55,9 +112,48 @@ type KongClient struct {
 	// dbmode indicates the current database mode of the backend Kong Admin API
 	dbmode string
 
+	// proxyTLSProtocols are the TLS protocols accepted by the Kong proxy, as
+	// configured by its ssl_protocols.
+	proxyTLSProtocols []string
+
 	// lastConfigSHA is a checksum of the last successful update to the data-plane
 	lastConfigSHA []byte
 
+	// targetClient updates the targets of upstreams when incremental target
+	// updates are enabled, nil otherwise.
+	targetClient TargetClient
+
+	// lastKongState is the state of the last successful update to the
+	// data-plane, which incremental target updates are diffed against.
+	lastKongState *kongstate.KongState
+
+	// structuralChangeLock is a mutex for thread-safety of structuralChange
+	// and changeNotifier, which is separate from lock so that cache updates
+	// don't wait for syncs.
+	structuralChangeLock sync.Mutex
+
+	// structuralChange indicates that objects whose changes can't be applied
+	// as incremental target updates changed since the last update.
+	structuralChange bool
+
+	// changeNotifier, if set, is called whenever an object is updated in or
+	// deleted from the cache.
+	changeNotifier func()
+
+	// workspaceResolver resolves the Kong Enterprise workspaces of namespaces
+	// when the configuration is synced to a workspace per namespace.
+	workspaceResolver *adminapi.WorkspaceResolver
+
+	// workspaceClientFor provides the Admin API client of a workspace.
+	workspaceClientFor func(ctx context.Context, workspace string) (*kong.Client, error)
+
+	// workspaceClients caches the Admin API clients of workspaces.
+	workspaceClients map[string]*kong.Client
+
+	// workspaceConfigSHAs are the checksums of the last successful updates to
+	// each workspace, keyed by workspace.
+	workspaceConfigSHAs map[string][]byte
+
 	// lock is used to ensure threadsafety of the KongClient object
 	lock sync.RWMutex
 
@@ -113,6 +209,7 @@ func NewKongClient(
 		prometheusMetrics: metrics.NewCtrlFuncMetrics(),
 		cache:             &cache,
 		kongConfig:        kongConfig,
+		structuralChange:  true,
 	}
 
 	// download the kong root configuration (and validate connectivity to the proxy API)
@@ -152,6 +249,7 @@ func NewKongClient(
 	// store the gathered configuration options
 	c.kongConfig.Version = proxySemver
 	c.dbmode = dbmode
+	c.proxyTLSProtocols = proxyTLSProtocols(proxyConfig["ssl_protocols"])
 
 	return c, nil
 }
@@ -164,6 +262,7 @@ func NewKongClient(
 // It will be asynchronously converted into the upstream Kong DSL and applied to the Kong Admin API.
 // A status will later be added to the object whether the configuration update succeeds or fails.
 func (c *KongClient) UpdateObject(obj client.Object) error {
+	c.recordChange(obj)
 	return c.cache.Add(obj)
 }
 
@@ -174,6 +273,7 @@ func (c *KongClient) UpdateObject(obj client.Object) error {
 // under the hood the cache implementation will ignore deletions on objects
 // that are not present in the cache, so in those cases this is a no-op.
 func (c *KongClient) DeleteObject(obj client.Object) error {
+	c.recordChange(obj)
 	return c.cache.Delete(obj)
 }
 
@@ -198,6 +298,162 @@ func (c *KongClient) RootWithTimeout() (map[string]interface{}, error) {
 	return c.kongConfig.Client.Root(ctx)
 }
 
+// EnableDropRoutesWithoutTargets configures the client to drop the routes of
+// services which have no targets (e.g. because the Deployment backing the
+// Service was scaled to zero) instead of routing them to an empty upstream.
+func (c *KongClient) EnableDropRoutesWithoutTargets() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.dropRoutesWithoutTargets = true
+}
+
+// SetMaxPathsPerRoute configures the maximum number of paths of a single route:
+// routes with more paths are split into multiple routes. A value of 0 means that
+// the number of paths is not limited.
+func (c *KongClient) SetMaxPathsPerRoute(maxPaths int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.maxPathsPerRoute = maxPaths
+}
+
+// SetMaxRoutesPerIngress configures the maximum number of routes generated for
+// a single Ingress: the routes beyond the limit are skipped and an event is
+// recorded on the Ingress. A value of 0 or less means that it's not limited.
+func (c *KongClient) SetMaxRoutesPerIngress(maxRoutes int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.maxRoutesPerIngress = maxRoutes
+}
+
+// SetSNIStrictness configures the way in which HTTPS routes whose hosts aren't
+// covered by the SNIs of any certificate are handled.
+func (c *KongClient) SetSNIStrictness(strictness parser.SNIStrictness) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.sniStrictness = strictness
+}
+
+// SetFailurePolicy configures the way in which objects referencing plugins
+// which don't exist or can't be translated are handled.
+func (c *KongClient) SetFailurePolicy(policy parser.FailurePolicy) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.failurePolicy = policy
+}
+
+// SetRouteNamer configures the way in which the routes generated for the rule
+// paths of Ingresses are named.
+func (c *KongClient) SetRouteNamer(namer parser.RouteNamer) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.routeNamer = namer
+}
+
+// SetFeatureGates configures the gated features which are translated.
+func (c *KongClient) SetFeatureGates(featureGates featuregates.FeatureGates) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.featureGates = featureGates
+}
+
+// SetStreamingPlugins configures the names of the Kong plugins which stream
+// request or response bodies, whose routes have their buffering disabled.
+func (c *KongClient) SetStreamingPlugins(names []string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.streamingPlugins = names
+}
+
+// SetChangeNotifier configures a function which is called whenever an object
+// is updated in or deleted from the configuration cache, e.g. to update the
+// data-plane as soon as the configuration changes. It must not block.
+func (c *KongClient) SetChangeNotifier(notify func()) {
+	c.structuralChangeLock.Lock()
+	defer c.structuralChangeLock.Unlock()
+	c.changeNotifier = notify
+}
+
+// SetEventRecorder configures the recorder of the events recorded on the
+// Kubernetes objects which are translated into data-plane configuration.
+func (c *KongClient) SetEventRecorder(recorder record.EventRecorder) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.eventRecorder = recorder
+}
+
+// SetConfigPublisher configures the client to publish the configuration it
+// generates, with sensitive values redacted, after each successful update.
+func (c *KongClient) SetConfigPublisher(publisher *ConfigPublisher) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.configPublisher = publisher
+}
+
+// EnableIncrementalTargetUpdates configures the client to update only the
+// targets of upstreams with targeted Admin API calls, instead of syncing the
+// whole configuration, when only Endpoints and EndpointSlices changed since
+// the last update. The whole configuration is still synced when any other
+// object changed, and always in DB-less mode, which only supports replacing
+// the whole configuration.
+func (c *KongClient) EnableIncrementalTargetUpdates() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.targetClient = c.kongConfig.Client.Targets
+}
+
+// EnableStableCertificateIDs configures the client to derive the IDs of
+// certificates from the namespace, name and content of their Secrets so that
+// unchanged certificates keep their IDs even if their Secrets are re-created.
+func (c *KongClient) EnableStableCertificateIDs() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.stableCertificateIDs = true
+}
+
+// EnableIngressClassTags configures the client to tag all the entities it
+// generates with the ingress class it manages (see IngressClassTag), so that
+// the entities of controllers of different classes sharing a Kong can be told
+// apart.
+func (c *KongClient) EnableIngressClassTags() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.ingressClassTags = true
+}
+
+// SetClassDefaultTimeouts configures the default timeouts of the services the
+// client generates, keyed by ingress class. Only the timeouts of the ingress
+// class managed by the client are used, which allows controllers of different
+// classes (e.g. internal low-latency and external long-polling classes) to
+// share the same configuration.
+func (c *KongClient) SetClassDefaultTimeouts(timeouts map[string]parser.ServiceTimeouts) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.classDefaultTimeouts = timeouts
+}
+
+// SetWorkspaceResolver configures the client to sync the configuration
+// generated from the objects of a namespace to the workspace the resolver
+// resolves for the namespace, using clientFor to get the Admin API clients of
+// workspaces. Configuration which resolves to the default workspace ("") is
+// synced with the client's own Admin API client.
+func (c *KongClient) SetWorkspaceResolver(
+	resolver *adminapi.WorkspaceResolver,
+	clientFor func(ctx context.Context, workspace string) (*kong.Client, error),
+) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.workspaceResolver = resolver
+	c.workspaceClientFor = clientFor
+	c.workspaceClients = map[string]*kong.Client{}
+	c.workspaceConfigSHAs = map[string][]byte{}
+}
+
+// IngressClassTag provides the tag of the entities generated for the provided
+// ingress class when ingress class tags are enabled.
+func IngressClassTag(ingressClass string) string {
+	return "ingress-class:" + ingressClass
+}
+
 // -----------------------------------------------------------------------------
 // Dataplane Client - Kong - Reporting
 // -----------------------------------------------------------------------------
@@ -257,6 +513,29 @@ func (c *KongClient) Update(ctx context.Context) error {
 	if c.AreKubernetesObjectReportsEnabled() {
 		p.EnableKubernetesObjectReports()
 	}
+	if c.dropRoutesWithoutTargets {
+		p.EnableDropRoutesWithoutTargets()
+	}
+	p.SetMaxPathsPerRoute(c.maxPathsPerRoute)
+	p.SetMaxRoutesPerIngress(c.maxRoutesPerIngress)
+	p.SetEventRecorder(c.eventRecorder)
+	p.SetSNIStrictness(c.sniStrictness)
+	p.SetFailurePolicy(c.failurePolicy)
+	p.SetProxyTLSProtocols(c.proxyTLSProtocols)
+	p.SetFeatureGates(c.featureGates)
+	p.SetStreamingPlugins(c.streamingPlugins)
+	if c.routeNamer != nil {
+		p.SetRouteNamer(c.routeNamer)
+	}
+	if c.stableCertificateIDs {
+		p.EnableStableCertificateIDs()
+	}
+	if c.ingressClassTags {
+		p.SetEntityTags([]string{IngressClassTag(c.ingressClass)})
+	}
+	if timeouts, ok := c.classDefaultTimeouts[c.ingressClass]; ok {
+		p.SetDefaultServiceTimeouts(timeouts)
+	}
 
 	// parse the Kubernetes objects from the storer into Kong configuration
 	kongstate, err := p.Build()
@@ -271,6 +550,12 @@ func (c *KongClient) Update(ctx context.Context) error {
 	}).Inc()
 	c.logger.Debug("successfully built data-plane configuration")
 
+	// update only the targets of upstreams if nothing else changed
+	structuralChange := c.takeStructuralChange()
+	if !structuralChange && c.updateTargetsIncrementally(ctx, kongstate) {
+		return nil
+	}
+
 	// generate the deck configuration to be applied to the admin API
 	c.logger.Debug("converting configuration to deck config")
 	targetConfig := deckgen.ToDeckContent(ctx,
@@ -279,17 +564,23 @@ func (c *KongClient) Update(ctx context.Context) error {
 		c.kongConfig.FilterTags,
 	)
 
+	// generate the redacted configuration if it's needed for diagnostics or
+	// publishing, the published configuration never includes sensitive values
+	var redactedConfig *file.Content
+	if (c.diagnostic != (util.ConfigDumpDiagnostic{}) && !c.diagnostic.DumpsIncludeSensitive) || c.configPublisher != nil {
+		redactedConfig = deckgen.ToDeckContent(ctx,
+			c.logger,
+			kongstate.SanitizedCopy(),
+			c.kongConfig.PluginSchemaStore,
+			c.kongConfig.FilterTags,
+		)
+	}
+
 	// generate diagnostic configuration if enabled
 	// "diagnostic" will be empty if --dump-config is not set
 	var diagnosticConfig *file.Content
 	if c.diagnostic != (util.ConfigDumpDiagnostic{}) {
 		if !c.diagnostic.DumpsIncludeSensitive {
-			redactedConfig := deckgen.ToDeckContent(ctx,
-				c.logger,
-				kongstate.SanitizedCopy(),
-				c.kongConfig.PluginSchemaStore,
-				c.kongConfig.FilterTags,
-			)
 			diagnosticConfig = redactedConfig
 		} else {
 			diagnosticConfig = targetConfig
@@ -300,18 +591,26 @@ func (c *KongClient) Update(ctx context.Context) error {
 	c.logger.Debug("sending configuration to Kong Admin API")
 	timedCtx, cancel := context.WithTimeout(ctx, c.requestTimeout)
 	defer cancel()
-	newConfigSHA, err := sendconfig.PerformUpdate(timedCtx,
-		c.logger,
-		&c.kongConfig,
-		c.kongConfig.InMemory,
-		c.enableReverseSync,
-		targetConfig,
-		c.kongConfig.FilterTags,
-		nil,
-		c.lastConfigSHA,
-		c.prometheusMetrics,
-	)
+	var newConfigSHA []byte
+	if c.workspaceResolver != nil {
+		newConfigSHA, err = c.performWorkspaceUpdates(timedCtx, kongstate)
+	} else {
+		newConfigSHA, err = sendconfig.PerformUpdate(timedCtx,
+			c.logger,
+			&c.kongConfig,
+			c.kongConfig.InMemory,
+			c.enableReverseSync,
+			targetConfig,
+			c.kongConfig.FilterTags,
+			nil,
+			c.lastConfigSHA,
+			c.prometheusMetrics,
+		)
+	}
 	if err != nil {
+		// the changes weren't applied, so the next update needs a full sync
+		c.recordStructuralChange()
+
 		// ship diagnostics if enabled
 		if c.diagnostic != (util.ConfigDumpDiagnostic{}) {
 			select {
@@ -334,6 +633,11 @@ func (c *KongClient) Update(ctx context.Context) error {
 		}
 	}
 
+	// publish the redacted configuration if enabled
+	if c.configPublisher != nil {
+		c.publishConfig(ctx, redactedConfig)
+	}
+
 	// report on configured Kubernetes objects if enabled
 	if c.AreKubernetesObjectReportsEnabled() {
 		if string(c.lastConfigSHA) != string(newConfigSHA) {
@@ -345,8 +649,12 @@ func (c *KongClient) Update(ctx context.Context) error {
 		}
 	}
 
+	// log the changes of the configuration for auditing
+	c.logConfigDiff(c.lastKongState, kongstate)
+
 	// update the lastConfigSHA with the new updated checksum
 	c.lastConfigSHA = newConfigSHA
+	c.lastKongState = kongstate
 	return nil
 }
 
@@ -354,6 +662,189 @@ func (c *KongClient) Update(ctx context.Context) error {
 // Dataplane Client - Kong - Private
 // -----------------------------------------------------------------------------
 
+// logConfigDiff logs a summary of the changes between the provided states of
+// the configuration, and every change at debug level.
+func (c *KongClient) logConfigDiff(oldState, newState *kongstate.KongState) {
+	changes := ConfigDiff(oldState, newState)
+	if len(changes) == 0 {
+		return
+	}
+	c.logger.Infof("applied configuration changes: %s", SummarizeConfigDiff(changes))
+	for _, change := range changes {
+		c.logger.Debugf("applied configuration change: %s", change)
+	}
+}
+
+// recordChange records that the provided object changed, marking the next
+// update as requiring a full sync unless only the targets of upstreams can
+// have changed, and calls the change notifier.
+func (c *KongClient) recordChange(obj client.Object) {
+	if IsStructuralChange(obj) {
+		c.recordStructuralChange()
+	}
+	c.structuralChangeLock.Lock()
+	notify := c.changeNotifier
+	c.structuralChangeLock.Unlock()
+	if notify != nil {
+		notify()
+	}
+}
+
+func (c *KongClient) recordStructuralChange() {
+	c.structuralChangeLock.Lock()
+	defer c.structuralChangeLock.Unlock()
+	c.structuralChange = true
+}
+
+// takeStructuralChange indicates whether a structural change was recorded
+// since it was last called, and resets it.
+func (c *KongClient) takeStructuralChange() bool {
+	c.structuralChangeLock.Lock()
+	defer c.structuralChangeLock.Unlock()
+	structuralChange := c.structuralChange
+	c.structuralChange = false
+	return structuralChange
+}
+
+// updateTargetsIncrementally applies the target changes between the last
+// synced state and the provided state with targeted Admin API calls, and
+// indicates whether it did so. It doesn't when incremental target updates are
+// disabled or unsupported, or when the states differ in anything but targets,
+// in which case callers must perform a full sync. A full sync is also required
+// if applying the changes fails part way through.
+func (c *KongClient) updateTargetsIncrementally(ctx context.Context, state *kongstate.KongState) bool {
+	if c.targetClient == nil || c.kongConfig.InMemory || c.workspaceResolver != nil {
+		return false
+	}
+	changes, ok := DiffTargets(c.lastKongState, state)
+	if !ok {
+		return false
+	}
+
+	c.logger.Debugf("updating the targets of %d upstreams incrementally", len(changes))
+	timedCtx, cancel := context.WithTimeout(ctx, c.requestTimeout)
+	defer cancel()
+	if err := ApplyTargetChanges(timedCtx, c.targetClient, changes); err != nil {
+		c.logger.WithError(err).Warn("incremental target update failed, falling back to a full sync")
+		return false
+	}
+
+	// the configured Kubernetes objects can't have changed, so no object
+	// report is triggered, but the published configuration includes targets
+	if c.configPublisher != nil {
+		c.publishConfig(ctx, deckgen.ToDeckContent(ctx,
+			c.logger,
+			state.SanitizedCopy(),
+			c.kongConfig.PluginSchemaStore,
+			c.kongConfig.FilterTags,
+		))
+	}
+	c.logConfigDiff(c.lastKongState, state)
+
+	// the configuration of Kong no longer has the checksum of the last full
+	// sync, which must not skip the next full sync if it restores that one
+	c.lastConfigSHA = nil
+	c.lastKongState = state
+	return true
+}
+
+// publishConfig publishes the provided redacted configuration. Failures to
+// publish are logged but don't fail updates as the data-plane is already
+// configured.
+func (c *KongClient) publishConfig(ctx context.Context, config *file.Content) {
+	publishCtx, cancel := context.WithTimeout(ctx, c.requestTimeout)
+	defer cancel()
+	published, err := c.configPublisher.Publish(publishCtx, config)
+	if err != nil {
+		c.logger.WithError(err).Error("failed to publish configuration")
+	} else if published {
+		c.logger.Debug("published configuration")
+	}
+}
+
+// performWorkspaceUpdates syncs the provided state to the Kong Enterprise
+// workspaces of the namespaces of its entities, and provides a checksum of the
+// configuration of all workspaces. Workspaces which were synced before but no
+// longer have any entities are synced with an empty configuration so that
+// their stale entities are removed.
+func (c *KongClient) performWorkspaceUpdates(ctx context.Context, state *kongstate.KongState) ([]byte, error) {
+	states, err := state.PartitionByWorkspace(func(namespace string) (string, error) {
+		return c.workspaceResolver.Resolve(ctx, namespace)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workspaces: %w", err)
+	}
+	for workspace := range c.workspaceConfigSHAs {
+		if _, ok := states[workspace]; !ok {
+			states[workspace] = &kongstate.KongState{Version: state.Version}
+		}
+	}
+
+	workspaces := make([]string, 0, len(states))
+	for workspace := range states {
+		workspaces = append(workspaces, workspace)
+	}
+	sort.Strings(workspaces)
+
+	var combinedSHA []byte
+	for _, workspace := range workspaces {
+		kongConfig := c.kongConfig
+		if workspace != "" {
+			client, err := c.workspaceClient(ctx, workspace)
+			if err != nil {
+				return nil, err
+			}
+			kongConfig.Client = client
+		}
+
+		targetConfig := deckgen.ToDeckContent(ctx,
+			c.logger, states[workspace],
+			c.kongConfig.PluginSchemaStore,
+			c.kongConfig.FilterTags,
+		)
+		c.logger.WithField("workspace", workspace).Debug("sending configuration to Kong Admin API")
+		newConfigSHA, err := sendconfig.PerformUpdate(ctx,
+			c.logger,
+			&kongConfig,
+			kongConfig.InMemory,
+			c.enableReverseSync,
+			targetConfig,
+			kongConfig.FilterTags,
+			nil,
+			c.workspaceConfigSHAs[workspace],
+			c.prometheusMetrics,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update workspace %q: %w", workspace, err)
+		}
+		c.workspaceConfigSHAs[workspace] = newConfigSHA
+		combinedSHA = append(combinedSHA, newConfigSHA...)
+	}
+
+	// workspaces which are now empty have been cleaned up and don't need to be
+	// synced again.
+	for workspace, ws := range states {
+		if workspace != "" && len(ws.Services) == 0 && len(ws.Upstreams) == 0 &&
+			len(ws.Consumers) == 0 && len(ws.Plugins) == 0 {
+			delete(c.workspaceConfigSHAs, workspace)
+		}
+	}
+	return combinedSHA, nil
+}
+
+// workspaceClient provides the Admin API client of the provided workspace.
+func (c *KongClient) workspaceClient(ctx context.Context, workspace string) (*kong.Client, error) {
+	if client, ok := c.workspaceClients[workspace]; ok {
+		return client, nil
+	}
+	client, err := c.workspaceClientFor(ctx, workspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Kong client for workspace %q: %w", workspace, err)
+	}
+	c.workspaceClients[workspace] = client
+	return client, nil
+}
+
 // triggerKubernetesObjectReport will update the KongClient with a set which
 // enables filtering for which objects are currently applied to the data-plane,
 // as well as updating the c.kubernetesObjectStatusQueue to queue those objects
@@ -384,3 +875,22 @@ func (c *KongClient) updateKubernetesObjectReportFilter(set k8sobj.Set) {
 	defer c.kubernetesObjectReportLock.Unlock()
 	c.kubernetesObjectReportsFilter = set
 }
+
+// proxyTLSProtocols provides the TLS protocols of the ssl_protocols setting of
+// the Kong proxy configuration, which the Admin API reports either as a space
+// separated string or as a list.
+func proxyTLSProtocols(setting interface{}) []string {
+	switch protocols := setting.(type) {
+	case string:
+		return strings.Fields(protocols)
+	case []interface{}:
+		result := make([]string, 0, len(protocols))
+		for _, protocol := range protocols {
+			if s, ok := protocol.(string); ok {
+				result = append(result, strings.Fields(s)...)
+			}
+		}
+		return result
+	}
+	return nil
+}
@@ -2,12 +2,15 @@ package kongstate
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/blang/semver/v4"
 	"github.com/kong/go-kong/kong"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/store"
@@ -49,6 +52,61 @@ func (ks *KongState) SanitizedCopy() *KongState {
 	}
 }
 
+// AddTags adds the provided tags to the entities of the state which are
+// translated into Kong entities: services, routes, upstreams, targets,
+// certificates, CA certificates, plugins and consumers. Tags which an entity
+// already has are not added again.
+func (ks *KongState) AddTags(tags ...string) {
+	if len(tags) == 0 {
+		return
+	}
+	for i := range ks.Services {
+		ks.Services[i].Tags = addTags(ks.Services[i].Tags, tags)
+		for j := range ks.Services[i].Routes {
+			ks.Services[i].Routes[j].Tags = addTags(ks.Services[i].Routes[j].Tags, tags)
+		}
+	}
+	for i := range ks.Upstreams {
+		ks.Upstreams[i].Tags = addTags(ks.Upstreams[i].Tags, tags)
+		for j := range ks.Upstreams[i].Targets {
+			ks.Upstreams[i].Targets[j].Tags = addTags(ks.Upstreams[i].Targets[j].Tags, tags)
+		}
+	}
+	for i := range ks.Certificates {
+		ks.Certificates[i].Tags = addTags(ks.Certificates[i].Tags, tags)
+	}
+	for i := range ks.CACertificates {
+		ks.CACertificates[i].Tags = addTags(ks.CACertificates[i].Tags, tags)
+	}
+	for i := range ks.Plugins {
+		ks.Plugins[i].Tags = addTags(ks.Plugins[i].Tags, tags)
+	}
+	for i := range ks.Consumers {
+		ks.Consumers[i].Tags = addTags(ks.Consumers[i].Tags, tags)
+	}
+}
+
+func addTags(existing []*string, tags []string) []*string {
+	// copy the existing tags so that entities sharing them aren't modified
+	result := make([]*string, 0, len(existing)+len(tags))
+	seen := make(map[string]struct{}, len(existing)+len(tags))
+	for _, tag := range existing {
+		if tag == nil {
+			continue
+		}
+		seen[*tag] = struct{}{}
+		result = append(result, tag)
+	}
+	for _, tag := range tags {
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		result = append(result, kong.String(tag))
+	}
+	return result
+}
+
 func (ks *KongState) FillConsumersAndCredentials(log logrus.FieldLogger, s store.Storer) {
 	consumerIndex := make(map[string]Consumer)
 
@@ -65,6 +123,9 @@ func (ks *KongState) FillConsumersAndCredentials(log logrus.FieldLogger, s store
 			c.CustomID = kong.String(consumer.CustomID)
 		}
 		c.K8sKongConsumer = *consumer
+		if tags := annotations.ExtractTags(consumer.Annotations); len(tags) > 0 {
+			c.Tags = addTags(c.Tags, tags)
+		}
 
 		log = log.WithFields(logrus.Fields{
 			"kongconsumer_name":      consumer.Name,
@@ -142,7 +203,7 @@ func (ks *KongState) FillOverrides(log logrus.FieldLogger, s store.Storer) {
 				"service_namespace": ks.Services[i].K8sService.Namespace,
 			}).Errorf("failed to fetch KongIngress resource for Service: %v", err)
 		}
-		ks.Services[i].override(kongIngress, anns)
+		ks.Services[i].override(log, kongIngress, anns)
 
 		// Routes
 		for j := 0; j < len(ks.Services[i].Routes); j++ {
@@ -210,7 +271,7 @@ func (ks *KongState) getPluginRelations() map[string]util.ForeignRelations {
 	for i := range ks.Services {
 		// service
 		svc := ks.Services[i].K8sService
-		pluginList := annotations.ExtractKongPluginsFromAnnotations(
+		pluginList := annotations.ExtractKongPluginsDedup(
 			svc.GetAnnotations())
 		for _, pluginName := range pluginList {
 			addServiceRelation(svc.Namespace, pluginName,
@@ -219,7 +280,7 @@ func (ks *KongState) getPluginRelations() map[string]util.ForeignRelations {
 		// route
 		for j := range ks.Services[i].Routes {
 			ingress := ks.Services[i].Routes[j].Ingress
-			pluginList := annotations.ExtractKongPluginsFromAnnotations(ingress.Annotations)
+			pluginList := annotations.ExtractKongPluginsDedup(ingress.Annotations)
 			for _, pluginName := range pluginList {
 				addRouteRelation(ingress.Namespace, pluginName, *ks.Services[i].Routes[j].Name)
 			}
@@ -227,7 +288,7 @@ func (ks *KongState) getPluginRelations() map[string]util.ForeignRelations {
 	}
 	// consumer
 	for _, c := range ks.Consumers {
-		pluginList := annotations.ExtractKongPluginsFromAnnotations(c.K8sKongConsumer.GetAnnotations())
+		pluginList := annotations.ExtractKongPluginsDedup(c.K8sKongConsumer.GetAnnotations())
 		for _, pluginName := range pluginList {
 			addConsumerRelation(c.K8sKongConsumer.Namespace, pluginName, *c.Username)
 		}
@@ -238,7 +299,16 @@ func (ks *KongState) getPluginRelations() map[string]util.ForeignRelations {
 func buildPlugins(log logrus.FieldLogger, s store.Storer, pluginRels map[string]util.ForeignRelations) []Plugin {
 	var plugins []Plugin
 
-	for pluginIdentifier, relations := range pluginRels {
+	// iterate over the relations in a stable order so that MergePlugins
+	// resolves duplicates the same way on every translation
+	pluginIdentifiers := make([]string, 0, len(pluginRels))
+	for pluginIdentifier := range pluginRels {
+		pluginIdentifiers = append(pluginIdentifiers, pluginIdentifier)
+	}
+	sort.Strings(pluginIdentifiers)
+
+	for _, pluginIdentifier := range pluginIdentifiers {
+		relations := pluginRels[pluginIdentifier]
 		identifier := strings.Split(pluginIdentifier, ":")
 		namespace, kongPluginName := identifier[0], identifier[1]
 		plugin, err := getPlugin(s, namespace, kongPluginName)
@@ -271,9 +341,77 @@ func buildPlugins(log logrus.FieldLogger, s store.Storer, pluginRels map[string]
 	if err != nil {
 		log.Errorf("failed to fetch global plugins: %v", err)
 	}
-	plugins = append(plugins, globalPlugins...)
 
-	return plugins
+	return MergePlugins(globalPlugins, plugins)
+}
+
+// MergePlugins merges the global plugins with the local plugins, which are
+// configured for services, routes or consumers, into a single list without
+// duplicates. Kong accepts a single instance of a plugin per scope, which is
+// the combination of the service, route and consumer which the instance is
+// configured for, so instances with the same plugin name and scope are
+// duplicates. Duplicates are resolved with the following precedence:
+//
+//   - a local instance overrides a global instance
+//   - among instances from the same list, the first instance is kept
+//
+// The merged list keeps the global instances in their order followed by the
+// local instances in their order, and an overriding local instance takes the
+// position of the global instance it overrides, so that the result is stable
+// for stable inputs.
+//
+// This doesn't affect the precedence between instances of a plugin with
+// different scopes (e.g. a global and a route instance), which Kong resolves
+// per request by executing the most specific instance.
+func MergePlugins(global, local []Plugin) []Plugin {
+	merged := make([]Plugin, 0, len(global)+len(local))
+	positions := make(map[string]int, len(global)+len(local))
+	isGlobal := make(map[string]bool, len(global))
+
+	for _, plugin := range global {
+		key := pluginScopeKey(plugin)
+		if _, ok := positions[key]; ok {
+			continue
+		}
+		positions[key] = len(merged)
+		isGlobal[key] = true
+		merged = append(merged, plugin)
+	}
+	for _, plugin := range local {
+		key := pluginScopeKey(plugin)
+		if i, ok := positions[key]; ok {
+			if isGlobal[key] {
+				merged[i] = plugin
+				isGlobal[key] = false
+			}
+			continue
+		}
+		positions[key] = len(merged)
+		merged = append(merged, plugin)
+	}
+	return merged
+}
+
+// pluginScopeKey returns the plugin name and the scope of a plugin instance.
+func pluginScopeKey(plugin Plugin) string {
+	id := func(id *string) string {
+		if id == nil {
+			return ""
+		}
+		return *id
+	}
+	name := id(plugin.Name)
+	var service, route, consumer string
+	if plugin.Service != nil {
+		service = id(plugin.Service.ID)
+	}
+	if plugin.Route != nil {
+		route = id(plugin.Route.ID)
+	}
+	if plugin.Consumer != nil {
+		consumer = id(plugin.Consumer.ID)
+	}
+	return strings.Join([]string{name, service, route, consumer}, "\x00")
 }
 
 func globalPlugins(log logrus.FieldLogger, s store.Storer) ([]Plugin, error) {
@@ -334,9 +472,146 @@ func globalPlugins(log logrus.FieldLogger, s store.Storer) ([]Plugin, error) {
 	for _, p := range res {
 		plugins = append(plugins, p)
 	}
+	sort.SliceStable(plugins, func(i, j int) bool {
+		return *plugins[i].Name < *plugins[j].Name
+	})
 	return plugins, nil
 }
 
+// PluginReference is a reference to a KongPlugin or KongClusterPlugin in the
+// konghq.com/plugins annotation of a Kubernetes object.
+type PluginReference struct {
+	// Object is the object with the annotation. It is nil for the objects of
+	// routes, which are only known by their kind, namespace and name.
+	Object    client.Object
+	Namespace string
+	Name      string
+	// GroupVersionKind is the kind of the object of routes.
+	GroupVersionKind schema.GroupVersionKind
+	// Plugin is the name of the referenced plugin.
+	Plugin string
+	// Err is the reason why the referenced plugin can't be used.
+	Err error
+}
+
+// BrokenPluginReferences returns the references of the services, routes and
+// consumers of the state to plugins which don't exist or can't be translated.
+// Plugins of broken references are skipped by FillPlugins.
+func (ks *KongState) BrokenPluginReferences(s store.Storer) []PluginReference {
+	var broken []PluginReference
+	seen := map[string]struct{}{}
+	check := func(obj client.Object, gvk schema.GroupVersionKind, namespace, name string, anns map[string]string) {
+		for _, pluginName := range annotations.ExtractKongPluginsDedup(anns) {
+			key := fmt.Sprintf("%T/%s/%s/%s/%s", obj, gvk, namespace, name, pluginName)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			if _, err := getPlugin(s, namespace, pluginName); err != nil {
+				broken = append(broken, PluginReference{
+					Object:           obj,
+					Namespace:        namespace,
+					Name:             name,
+					GroupVersionKind: gvk,
+					Plugin:           pluginName,
+					Err:              err,
+				})
+			}
+		}
+	}
+
+	for i := range ks.Services {
+		svc := &ks.Services[i].K8sService
+		check(svc, schema.GroupVersionKind{}, svc.Namespace, svc.Name, svc.GetAnnotations())
+		for _, route := range ks.Services[i].Routes {
+			check(nil, route.Ingress.GroupVersionKind, route.Ingress.Namespace, route.Ingress.Name, route.Ingress.Annotations)
+		}
+	}
+	for i := range ks.Consumers {
+		consumer := &ks.Consumers[i].K8sKongConsumer
+		check(consumer, schema.GroupVersionKind{}, consumer.Namespace, consumer.Name, consumer.GetAnnotations())
+	}
+	return broken
+}
+
 func (ks *KongState) FillPlugins(log logrus.FieldLogger, s store.Storer) {
 	ks.Plugins = buildPlugins(log, s, ks.getPluginRelations())
 }
+
+// rateLimitingPlugins are the names of the Kong plugins which limit the rate
+// of requests and of which only a single instance is executed per request.
+var rateLimitingPlugins = map[string]struct{}{
+	"rate-limiting":          {},
+	"rate-limiting-advanced": {},
+	"response-ratelimiting":  {},
+}
+
+// PluginScopeConflict is a rate limiting plugin configured for a consumer
+// which shadows instances of the same plugin configured for a route, a
+// service or globally: Kong executes the most specific instance of a plugin,
+// so the limits of the shadowed instances are not enforced for the consumer.
+type PluginScopeConflict struct {
+	// Plugin is the name of the Kong plugin, e.g. "rate-limiting".
+	Plugin string
+	// Consumer is the username of the consumer.
+	Consumer string
+	// Shadowed are the scopes of the shadowed instances, e.g. "route foo",
+	// "service bar" or "global".
+	Shadowed []string
+}
+
+// PluginScopeConflicts returns the rate limiting plugins configured for a
+// consumer alone which shadow instances of the same plugin configured for a
+// route, a service or globally, sorted by plugin and consumer.
+func (ks *KongState) PluginScopeConflicts() []PluginScopeConflict {
+	consumerScoped := map[string]map[string]struct{}{}
+	otherScopes := map[string][]string{}
+	for _, plugin := range ks.Plugins {
+		if plugin.Name == nil {
+			continue
+		}
+		name := *plugin.Name
+		if _, ok := rateLimitingPlugins[name]; !ok {
+			continue
+		}
+		switch {
+		case plugin.Consumer != nil:
+			if plugin.Route != nil || plugin.Service != nil || plugin.Consumer.ID == nil {
+				continue
+			}
+			if consumerScoped[name] == nil {
+				consumerScoped[name] = map[string]struct{}{}
+			}
+			consumerScoped[name][*plugin.Consumer.ID] = struct{}{}
+		case plugin.Route != nil && plugin.Route.ID != nil:
+			otherScopes[name] = append(otherScopes[name], "route "+*plugin.Route.ID)
+		case plugin.Service != nil && plugin.Service.ID != nil:
+			otherScopes[name] = append(otherScopes[name], "service "+*plugin.Service.ID)
+		default:
+			otherScopes[name] = append(otherScopes[name], "global")
+		}
+	}
+
+	var conflicts []PluginScopeConflict
+	for name, consumers := range consumerScoped {
+		shadowed := otherScopes[name]
+		if len(shadowed) == 0 {
+			continue
+		}
+		sort.Strings(shadowed)
+		for consumer := range consumers {
+			conflicts = append(conflicts, PluginScopeConflict{
+				Plugin:   name,
+				Consumer: consumer,
+				Shadowed: shadowed,
+			})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Plugin != conflicts[j].Plugin {
+			return conflicts[i].Plugin < conflicts[j].Plugin
+		}
+		return conflicts[i].Consumer < conflicts[j].Consumer
+	})
+	return conflicts
+}
@@ -54,6 +54,39 @@ func TestKongState_SanitizedCopy(t *testing.T) {
 	}
 }
 
+func TestKongState_AddTags(t *testing.T) {
+	sharedTags := kong.StringSlice("existing")
+	ks := KongState{
+		Services: []Service{{
+			Service: kong.Service{Name: kong.String("svc"), Tags: sharedTags},
+			Routes:  []Route{{Route: kong.Route{Name: kong.String("route"), Tags: sharedTags}}},
+		}},
+		Upstreams: []Upstream{{
+			Upstream: kong.Upstream{Name: kong.String("upstream")},
+			Targets:  []Target{{Target: kong.Target{Target: kong.String("10.0.0.1:80")}}},
+		}},
+		Certificates:   []Certificate{{Certificate: kong.Certificate{ID: kong.String("cert")}}},
+		CACertificates: []kong.CACertificate{{ID: kong.String("ca-cert")}},
+		Plugins:        []Plugin{{Plugin: kong.Plugin{Name: kong.String("key-auth")}}},
+		Consumers:      []Consumer{{Consumer: kong.Consumer{Username: kong.String("consumer")}}},
+	}
+
+	ks.AddTags("ingress-class:kong", "existing")
+	ks.AddTags("ingress-class:kong")
+
+	assert.Equal(t, kong.StringSlice("existing", "ingress-class:kong"), ks.Services[0].Tags)
+	assert.Equal(t, kong.StringSlice("existing", "ingress-class:kong"), ks.Services[0].Routes[0].Tags)
+	assert.Equal(t, kong.StringSlice("ingress-class:kong", "existing"), ks.Upstreams[0].Tags)
+	assert.Equal(t, kong.StringSlice("ingress-class:kong", "existing"), ks.Upstreams[0].Targets[0].Tags)
+	assert.Equal(t, kong.StringSlice("ingress-class:kong", "existing"), ks.Certificates[0].Tags)
+	assert.Equal(t, kong.StringSlice("ingress-class:kong", "existing"), ks.CACertificates[0].Tags)
+	assert.Equal(t, kong.StringSlice("ingress-class:kong", "existing"), ks.Plugins[0].Tags)
+	assert.Equal(t, kong.StringSlice("ingress-class:kong", "existing"), ks.Consumers[0].Tags)
+
+	t.Log("verifying that tags shared between entities were not modified in place")
+	assert.Equal(t, kong.StringSlice("existing"), sharedTags)
+}
+
 func Test_getPluginRelations(t *testing.T) {
 	type args struct {
 		state KongState
@@ -388,3 +421,115 @@ func Test_FillConsumersAndCredentials(t *testing.T) {
 		assert.Equal(t, want.Consumers[0].Oauth2Creds[0].RedirectURIs, state.Consumers[0].Oauth2Creds[0].RedirectURIs)
 	})
 }
+
+func TestFillConsumersAndCredentialsTags(t *testing.T) {
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		KongConsumers: []*configurationv1.KongConsumer{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+				Annotations: map[string]string{
+					annotations.IngressClassKey:                        annotations.DefaultIngressClass,
+					annotations.AnnotationPrefix + annotations.TagsKey: "team:payments,tier:gold",
+				},
+			},
+			Username: "foo",
+		}},
+	})
+	assert.NoError(t, err)
+
+	state := KongState{}
+	state.FillConsumersAndCredentials(logrus.New(), fakeStore)
+	assert.Len(t, state.Consumers, 1)
+	assert.Equal(t, kong.StringSlice("team:payments", "tier:gold"), state.Consumers[0].Tags)
+}
+
+func TestKongState_PluginScopeConflicts(t *testing.T) {
+	plugin := func(name string, consumer, route, service string) Plugin {
+		p := Plugin{Plugin: kong.Plugin{Name: kong.String(name)}}
+		if consumer != "" {
+			p.Consumer = &kong.Consumer{ID: kong.String(consumer)}
+		}
+		if route != "" {
+			p.Route = &kong.Route{ID: kong.String(route)}
+		}
+		if service != "" {
+			p.Service = &kong.Service{ID: kong.String(service)}
+		}
+		return p
+	}
+
+	t.Run("consumer rate limit shadowing route and global rate limits", func(t *testing.T) {
+		ks := KongState{Plugins: []Plugin{
+			plugin("rate-limiting", "gold", "", ""),
+			plugin("rate-limiting", "", "default.foo.00", ""),
+			plugin("rate-limiting", "", "", ""),
+			plugin("key-auth", "", "default.foo.00", ""),
+		}}
+		assert.Equal(t, []PluginScopeConflict{{
+			Plugin:   "rate-limiting",
+			Consumer: "gold",
+			Shadowed: []string{"global", "route default.foo.00"},
+		}}, ks.PluginScopeConflicts())
+	})
+
+	t.Run("no conflicts without a less specific instance of the same plugin", func(t *testing.T) {
+		ks := KongState{Plugins: []Plugin{
+			plugin("rate-limiting", "gold", "", ""),
+			plugin("response-ratelimiting", "", "", "default.foo.80"),
+			plugin("rate-limiting", "silver", "default.foo.00", ""),
+			plugin("key-auth", "gold", "", ""),
+			plugin("key-auth", "", "", ""),
+		}}
+		assert.Empty(t, ks.PluginScopeConflicts())
+	})
+}
+
+func TestMergePlugins(t *testing.T) {
+	plugin := func(name, route string, config kong.Configuration) Plugin {
+		p := Plugin{Plugin: kong.Plugin{Name: kong.String(name), Config: config}}
+		if route != "" {
+			p.Route = &kong.Route{ID: kong.String(route)}
+		}
+		return p
+	}
+	globalLimit := plugin("rate-limiting", "", kong.Configuration{"minute": 100})
+	globalCors := plugin("cors", "", nil)
+	localLimit := plugin("rate-limiting", "", kong.Configuration{"minute": 10})
+	routeLimit := plugin("rate-limiting", "default.foo.00", kong.Configuration{"minute": 5})
+	routeAuth := plugin("key-auth", "default.foo.00", nil)
+
+	for _, tt := range []struct {
+		name   string
+		global []Plugin
+		local  []Plugin
+		want   []Plugin
+	}{
+		{
+			name:   "global only",
+			global: []Plugin{globalLimit, globalCors, globalLimit},
+			want:   []Plugin{globalLimit, globalCors},
+		},
+		{
+			name:  "local only",
+			local: []Plugin{routeAuth, routeLimit, plugin("rate-limiting", "default.foo.00", nil)},
+			want:  []Plugin{routeAuth, routeLimit},
+		},
+		{
+			name:   "local overrides global with the same name and scope",
+			global: []Plugin{globalLimit, globalCors},
+			local:  []Plugin{routeAuth, localLimit},
+			want:   []Plugin{localLimit, globalCors, routeAuth},
+		},
+		{
+			name:   "instances with different scopes are kept",
+			global: []Plugin{globalLimit},
+			local:  []Plugin{routeLimit},
+			want:   []Plugin{globalLimit, routeLimit},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MergePlugins(tt.global, tt.local))
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
@@ -11,6 +12,9 @@ import (
 	configurationv1 "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1"
 )
 
+// AppProtocolH2C is the appProtocol of Kubernetes Service ports which serve HTTP/2 over cleartext (h2c).
+const AppProtocolH2C = "kubernetes.io/h2c"
+
 // Service represents a service in Kong and holds routes associated with the
 // service and other k8s metadata.
 type Service struct {
@@ -63,36 +67,101 @@ func (s *Service) overridePath(anns map[string]string) {
 	s.Path = kong.String(path)
 }
 
-func (s *Service) overrideProtocol(anns map[string]string) {
+// overrideProtocol sets the Service protocol from the konghq.com/protocol annotation, or else from the first protocol
+// of the konghq.com/protocols annotation, as Kong services connect to their upstream with a single protocol. The latter
+// is primarily the annotation of Ingresses setting the protocols of routes, and is ignored with konghq.com/protocol.
+// Invalid konghq.com/protocols values, and the protocols after the first one, are logged and ignored.
+func (s *Service) overrideProtocol(log logrus.FieldLogger, anns map[string]string) {
 	if s == nil {
 		return
 	}
 	protocol := annotations.ExtractProtocolName(anns)
-	if protocol == "" || !util.ValidateProtocol(protocol) {
+	if protocol == "" {
+		protocols, ok := annotations.ExtractProtocols(anns)
+		if !ok {
+			return
+		}
+		log = log.WithFields(logrus.Fields{
+			"service_name":      s.K8sService.Name,
+			"service_namespace": s.K8sService.Namespace,
+		})
+		for _, protocol := range protocols {
+			if !util.ValidateServiceProtocol(protocol) {
+				log.Errorf("ignoring annotation %s%s: invalid protocol %q", annotations.AnnotationPrefix, annotations.ProtocolsKey, protocol)
+				return
+			}
+		}
+		if len(protocols) > 1 {
+			log.Warnf("annotation %s%s: using protocol %q and ignoring %s, as services use a single protocol",
+				annotations.AnnotationPrefix, annotations.ProtocolsKey, protocols[0], strings.Join(protocols[1:], ","))
+		}
+		s.Protocol = kong.String(protocols[0])
+		return
+	}
+	if !util.ValidateProtocol(protocol) {
 		return
 	}
 	s.Protocol = kong.String(protocol)
 }
 
+// overrideByAppProtocol sets the Service protocol from the appProtocol of the Kubernetes Service port it points to.
+// Kong only speaks HTTP/2 over cleartext to upstreams when proxying gRPC, so h2c ports result in grpc Services.
+func (s *Service) overrideByAppProtocol() {
+	if s == nil {
+		return
+	}
+	port := s.k8sServicePort()
+	if port == nil || port.AppProtocol == nil {
+		return
+	}
+	if *port.AppProtocol == AppProtocolH2C {
+		s.Protocol = kong.String("grpc")
+	}
+}
+
+// k8sServicePort returns the port of the Kubernetes Service which the Service's backend points to, if any.
+func (s *Service) k8sServicePort() *corev1.ServicePort {
+	ports := s.K8sService.Spec.Ports
+	for i, port := range ports {
+		switch s.Backend.Port.Mode {
+		case PortModeByNumber:
+			if port.Port == s.Backend.Port.Number {
+				return &ports[i]
+			}
+		case PortModeByName:
+			if port.Name == s.Backend.Port.Name {
+				return &ports[i]
+			}
+		case PortModeImplicit:
+			if len(ports) == 1 {
+				return &ports[i]
+			}
+		}
+	}
+	return nil
+}
+
 // overrideByAnnotation modifies the Kong service based on annotations
 // on the Kubernetes service.
-func (s *Service) overrideByAnnotation(anns map[string]string) {
+func (s *Service) overrideByAnnotation(log logrus.FieldLogger, anns map[string]string) {
 	if s == nil {
 		return
 	}
-	s.overrideProtocol(anns)
+	s.overrideProtocol(log, anns)
 	s.overridePath(anns)
 }
 
-// override sets Service fields by KongIngress first, then by annotation
-func (s *Service) override(kongIngress *configurationv1.KongIngress,
+// override sets Service fields by the appProtocol of the Kubernetes Service port first, then by KongIngress, then by
+// annotation
+func (s *Service) override(log logrus.FieldLogger, kongIngress *configurationv1.KongIngress,
 	anns map[string]string) {
 	if s == nil {
 		return
 	}
 
+	s.overrideByAppProtocol()
 	s.overrideByKongIngress(kongIngress)
-	s.overrideByAnnotation(anns)
+	s.overrideByAnnotation(log, anns)
 
 	if *s.Protocol == "grpc" || *s.Protocol == "grpcs" {
 		// grpc(s) doesn't accept a path
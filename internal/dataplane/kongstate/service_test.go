@@ -1,10 +1,12 @@
 package kongstate
 
 import (
+	"bytes"
 	"reflect"
 	"testing"
 
 	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 
 	configurationv1 "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1"
@@ -361,13 +363,13 @@ func TestOverrideService(t *testing.T) {
 	}
 
 	for _, testcase := range testTable {
-		testcase.inService.override(&testcase.inKongIngresss, testcase.inAnnotation)
+		testcase.inService.override(logrus.New(), &testcase.inKongIngresss, testcase.inAnnotation)
 		assert.Equal(testcase.inService, testcase.outService)
 	}
 
 	assert.NotPanics(func() {
 		var nilService *Service
-		nilService.override(nil, nil)
+		nilService.override(logrus.New(), nil, nil)
 	})
 }
 
@@ -433,3 +435,55 @@ func Test_overrideServicePath(t *testing.T) {
 		})
 	}
 }
+
+func Test_overrideServiceProtocol(t *testing.T) {
+	tests := []struct {
+		name    string
+		service Service
+		anns    map[string]string
+		want    Service
+		wantLog string
+	}{
+		{name: "absent annotations leave the protocol unset"},
+		{
+			name:    "uses the first valid protocol of konghq.com/protocols",
+			anns:    map[string]string{"konghq.com/protocols": "grpcs, grpc"},
+			want:    Service{Service: kong.Service{Protocol: kong.String("grpcs")}},
+			wantLog: `using protocol \"grpcs\" and ignoring grpc`,
+		},
+		{
+			name: "accepts websocket protocols",
+			anns: map[string]string{"konghq.com/protocols": "wss"},
+			want: Service{Service: kong.Service{Protocol: kong.String("wss")}},
+		},
+		{
+			name:    "invalid konghq.com/protocols leave the protocol unchanged",
+			service: Service{Service: kong.Service{Protocol: kong.String("http")}},
+			anns:    map[string]string{"konghq.com/protocols": "https,udp"},
+			want:    Service{Service: kong.Service{Protocol: kong.String("http")}},
+			wantLog: `invalid protocol \"udp\"`,
+		},
+		{
+			name: "konghq.com/protocol takes precedence over konghq.com/protocols",
+			anns: map[string]string{
+				"konghq.com/protocol":  "https",
+				"konghq.com/protocols": "ws",
+			},
+			want: Service{Service: kong.Service{Protocol: kong.String("https")}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			log := logrus.New()
+			log.SetOutput(&out)
+			tt.service.overrideProtocol(log, tt.anns)
+			assert.Equal(t, tt.want, tt.service)
+			if tt.wantLog == "" {
+				assert.Empty(t, out.String())
+			} else {
+				assert.Contains(t, out.String(), tt.wantLog)
+			}
+		})
+	}
+}
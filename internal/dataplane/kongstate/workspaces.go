@@ -0,0 +1,98 @@
+package kongstate
+
+// PartitionByWorkspace splits the state into the states of the Kong Enterprise
+// workspaces its entities belong to, using workspaceOf to resolve the workspace
+// of a Kubernetes namespace. Services are assigned the workspace of their
+// namespace, upstreams follow their service, consumers follow the namespace of
+// their KongConsumer and plugins follow the service, route or consumer they are
+// attached to. Certificates, CA certificates and global plugins are not tied to
+// a namespace and are kept in the default workspace, which is keyed by "".
+//
+// The default workspace is always present in the result so that its stale
+// configuration is removed when all entities move to other workspaces.
+func (ks *KongState) PartitionByWorkspace(workspaceOf func(namespace string) (string, error)) (map[string]*KongState, error) {
+	states := map[string]*KongState{}
+	stateFor := func(workspace string) *KongState {
+		state, ok := states[workspace]
+		if !ok {
+			state = &KongState{Version: ks.Version}
+			states[workspace] = state
+		}
+		return state
+	}
+	defaultState := stateFor("")
+	defaultState.Certificates = ks.Certificates
+	defaultState.CACertificates = ks.CACertificates
+
+	resolved := map[string]string{}
+	resolve := func(namespace string) (string, error) {
+		if workspace, ok := resolved[namespace]; ok {
+			return workspace, nil
+		}
+		workspace, err := workspaceOf(namespace)
+		if err != nil {
+			return "", err
+		}
+		resolved[namespace] = workspace
+		return workspace, nil
+	}
+
+	serviceWorkspaces := map[string]string{}
+	routeWorkspaces := map[string]string{}
+	for _, service := range ks.Services {
+		workspace, err := resolve(service.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		state := stateFor(workspace)
+		state.Services = append(state.Services, service)
+		if service.Name != nil {
+			serviceWorkspaces[*service.Name] = workspace
+		}
+		for _, route := range service.Routes {
+			if route.Name != nil {
+				routeWorkspaces[*route.Name] = workspace
+			}
+		}
+	}
+
+	for _, upstream := range ks.Upstreams {
+		workspace, err := resolve(upstream.Service.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		state := stateFor(workspace)
+		state.Upstreams = append(state.Upstreams, upstream)
+	}
+
+	consumerWorkspaces := map[string]string{}
+	for _, consumer := range ks.Consumers {
+		workspace, err := resolve(consumer.K8sKongConsumer.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		state := stateFor(workspace)
+		state.Consumers = append(state.Consumers, consumer)
+		if consumer.Username != nil {
+			consumerWorkspaces[*consumer.Username] = workspace
+		}
+	}
+
+	for _, plugin := range ks.Plugins {
+		// plugins refer to the entities they are attached to by name, see
+		// buildPlugins for details.
+		workspace := ""
+		switch {
+		case plugin.Service != nil && plugin.Service.ID != nil:
+			workspace = serviceWorkspaces[*plugin.Service.ID]
+		case plugin.Route != nil && plugin.Route.ID != nil:
+			workspace = routeWorkspaces[*plugin.Route.ID]
+		case plugin.Consumer != nil && plugin.Consumer.ID != nil:
+			workspace = consumerWorkspaces[*plugin.Consumer.ID]
+		}
+		state := stateFor(workspace)
+		state.Plugins = append(state.Plugins, plugin)
+	}
+
+	return states, nil
+}
@@ -0,0 +1,84 @@
+package kongstate
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kong/go-kong/kong"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configurationv1 "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1"
+)
+
+func TestKongState_PartitionByWorkspace(t *testing.T) {
+	ks := &KongState{
+		Services: []Service{
+			{
+				Service:   kong.Service{Name: kong.String("team-a.svc.80")},
+				Namespace: "team-a",
+				Routes:    []Route{{Route: kong.Route{Name: kong.String("team-a.ing.00")}}},
+			},
+			{
+				Service:   kong.Service{Name: kong.String("other.svc.80")},
+				Namespace: "other",
+			},
+		},
+		Upstreams: []Upstream{
+			{Upstream: kong.Upstream{Name: kong.String("a.upstream")}, Service: Service{Namespace: "team-a"}},
+			{Upstream: kong.Upstream{Name: kong.String("other.upstream")}, Service: Service{Namespace: "other"}},
+		},
+		Certificates: []Certificate{{Certificate: kong.Certificate{ID: kong.String("cert")}}},
+		Consumers: []Consumer{{
+			Consumer:        kong.Consumer{Username: kong.String("alice")},
+			K8sKongConsumer: configurationv1.KongConsumer{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"}},
+		}},
+		Plugins: []Plugin{
+			{kong.Plugin{Name: kong.String("on-service"), Service: &kong.Service{ID: kong.String("team-a.svc.80")}}},
+			{kong.Plugin{Name: kong.String("on-route"), Route: &kong.Route{ID: kong.String("team-a.ing.00")}}},
+			{kong.Plugin{Name: kong.String("on-consumer"), Consumer: &kong.Consumer{ID: kong.String("alice")}}},
+			{kong.Plugin{Name: kong.String("global")}},
+		},
+	}
+	workspaceOf := func(namespace string) (string, error) {
+		if namespace == "team-a" {
+			return "workspace-a", nil
+		}
+		return "", nil
+	}
+
+	states, err := ks.PartitionByWorkspace(workspaceOf)
+	require.NoError(t, err)
+	require.Len(t, states, 2)
+
+	t.Log("verifying that the entities of the namespace are routed to its workspace")
+	a := states["workspace-a"]
+	require.NotNil(t, a)
+	require.Len(t, a.Services, 1)
+	assert.Equal(t, "team-a.svc.80", *a.Services[0].Name)
+	require.Len(t, a.Upstreams, 1)
+	assert.Equal(t, "a.upstream", *a.Upstreams[0].Name)
+	require.Len(t, a.Consumers, 1)
+	require.Len(t, a.Plugins, 3)
+	assert.Empty(t, a.Certificates)
+
+	t.Log("verifying that other entities fall back to the default workspace")
+	def := states[""]
+	require.NotNil(t, def)
+	require.Len(t, def.Services, 1)
+	assert.Equal(t, "other.svc.80", *def.Services[0].Name)
+	require.Len(t, def.Upstreams, 1)
+	assert.Equal(t, ks.Certificates, def.Certificates)
+	require.Len(t, def.Plugins, 1)
+	assert.Equal(t, "global", *def.Plugins[0].Name)
+
+	t.Log("verifying that the default workspace is present even when empty")
+	states, err = (&KongState{}).PartitionByWorkspace(workspaceOf)
+	require.NoError(t, err)
+	assert.Contains(t, states, "")
+
+	t.Log("verifying that resolution errors are returned")
+	_, err = ks.PartitionByWorkspace(func(string) (string, error) { return "", fmt.Errorf("boom") })
+	assert.Error(t, err)
+}
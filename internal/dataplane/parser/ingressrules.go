@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"strings"
+
 	"github.com/kong/go-kong/kong"
 	"github.com/sirupsen/logrus"
 	networkingv1 "k8s.io/api/networking/v1"
@@ -108,6 +110,8 @@ func (m SecretNameToSNIs) addFromIngressV1TLS(tlsSections []networkingv1.Ingress
 	}
 }
 
+// filterHosts normalizes the provided hosts to lowercase, as SNIs are case-insensitive, and drops any host
+// which is already associated with a Secret or which appears more than once.
 func (m SecretNameToSNIs) filterHosts(hosts []string) []string {
 	hostsToAdd := []string{}
 	seenHosts := map[string]bool{}
@@ -117,7 +121,9 @@ func (m SecretNameToSNIs) filterHosts(hosts []string) []string {
 		}
 	}
 	for _, host := range hosts {
+		host = strings.ToLower(host)
 		if !seenHosts[host] {
+			seenHosts[host] = true
 			hostsToAdd = append(hostsToAdd, host)
 		}
 	}
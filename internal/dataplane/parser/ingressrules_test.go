@@ -162,6 +162,32 @@ func Test_addFromIngressV1beta1TLS(t *testing.T) {
 				"foo/sooper-secret2": {"3.example.com", "4.example.com"},
 			},
 		},
+		{
+			name: "mixed case hosts are normalized to a single lowercase SNI",
+			args: args{
+				tlsSections: []networking.IngressTLS{
+					{
+						Hosts: []string{
+							"Example.COM",
+							"example.com",
+						},
+						SecretName: "sooper-secret",
+					},
+					{
+						Hosts: []string{
+							"EXAMPLE.com",
+							"Other.Example.com",
+						},
+						SecretName: "sooper-secret2",
+					},
+				},
+				namespace: "foo",
+			},
+			want: SecretNameToSNIs{
+				"foo/sooper-secret":  {"example.com"},
+				"foo/sooper-secret2": {"other.example.com"},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
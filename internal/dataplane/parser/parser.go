@@ -2,25 +2,33 @@ package parser
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/pem"
+	"errors"
 	"fmt"
-	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/kong/go-kong/kong"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networking "k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	knative "knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/kongstate"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/manager/featuregates"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/store"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/util"
 	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1beta1"
@@ -38,6 +46,151 @@ type Parser struct {
 	storer                            store.Storer
 	reportConfiguredKubernetesObjects bool
 	configuredKubernetesObjects       []client.Object
+	dropRoutesWithoutTargets          bool
+	maxPathsPerRoute                  int
+	maxRoutesPerIngress               int
+	stableCertificateIDs              bool
+	entityTags                        []string
+	defaultTimeouts                   ServiceTimeouts
+	eventRecorder                     record.EventRecorder
+	sniStrictness                     SNIStrictness
+	failurePolicy                     FailurePolicy
+	proxyTLSProtocols                 []string
+	routeNamer                        RouteNamer
+	featureGates                      featuregates.FeatureGates
+	streamingPlugins                  map[string]struct{}
+
+	// routeObjects are the objects which routes are generated for, indexed
+	// on first use during each Build.
+	routeObjects map[routeObjectKey]client.Object
+}
+
+// FailurePolicy is the way in which objects referencing plugins which don't
+// exist or can't be translated are handled.
+type FailurePolicy string
+
+const (
+	// FailurePolicySkipBroken skips the broken plugin references, records a
+	// PluginReferenceBrokenReason event on the objects with such references
+	// and translates the rest of the configuration.
+	FailurePolicySkipBroken FailurePolicy = "skip-broken"
+	// FailurePolicyFailAll fails the whole translation if any plugin
+	// reference is broken, so that no configuration is pushed.
+	FailurePolicyFailAll FailurePolicy = "fail-all"
+)
+
+// ParseFailurePolicy parses the provided failure policy, which must be either
+// "skip-broken" or "fail-all".
+func ParseFailurePolicy(val string) (FailurePolicy, error) {
+	switch policy := FailurePolicy(val); policy {
+	case FailurePolicySkipBroken, FailurePolicyFailAll:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid failure policy %q: expected %q or %q", val, FailurePolicySkipBroken, FailurePolicyFailAll)
+	}
+}
+
+// InvalidAnnotationReason is the reason of the events recorded on objects
+// with annotations whose values can't be parsed.
+const InvalidAnnotationReason = "KongInvalidAnnotation"
+
+// PluginScopeConflictReason is the reason of the events recorded on
+// KongConsumers with rate limiting plugins which shadow the same plugins
+// configured for routes, services or globally.
+const PluginScopeConflictReason = "KongPluginScopeConflict"
+
+// PluginReferenceBrokenReason is the reason of the events recorded on objects
+// referencing plugins which don't exist or can't be translated.
+const PluginReferenceBrokenReason = "KongPluginReferenceBroken"
+
+// SNIStrictness is the way in which HTTPS routes whose hosts aren't covered by
+// the SNIs of any certificate are handled.
+type SNIStrictness string
+
+const (
+	// SNIStrictnessLenient keeps HTTPS routes whose hosts aren't covered by
+	// any certificate, Kong serves its default certificate for them.
+	SNIStrictnessLenient SNIStrictness = "lenient"
+	// SNIStrictnessStrict rejects HTTPS routes whose hosts aren't covered by
+	// any certificate and records an UncoveredSNIReason event on the objects
+	// they were generated for.
+	SNIStrictnessStrict SNIStrictness = "strict"
+)
+
+// ParseSNIStrictness parses the provided SNI strictness, which must be either
+// "strict" or "lenient".
+func ParseSNIStrictness(val string) (SNIStrictness, error) {
+	switch strictness := SNIStrictness(val); strictness {
+	case SNIStrictnessStrict, SNIStrictnessLenient:
+		return strictness, nil
+	default:
+		return "", fmt.Errorf("invalid SNI strictness %q: expected %q or %q", val, SNIStrictnessStrict, SNIStrictnessLenient)
+	}
+}
+
+// UncoveredSNIReason is the reason of the events recorded on Ingresses whose
+// HTTPS routes were rejected because their hosts aren't covered by the SNIs
+// of any certificate.
+const UncoveredSNIReason = "KongUncoveredSNI"
+
+// TLSMinVersionUnsupportedReason is the reason of the events recorded on
+// Ingresses whose routes were rejected because the minimum TLS version
+// required by their konghq.com/tls-min-version annotation can't be enforced.
+const TLSMinVersionUnsupportedReason = "KongTLSMinVersionUnsupported"
+
+// CACertificateInvalidReason is the reason of the events recorded on Services
+// whose konghq.com/ca-certificates annotation references Secrets which don't
+// exist or don't hold valid PEM-encoded CA certificates.
+const CACertificateInvalidReason = "KongCACertificateInvalid"
+
+// BufferingDisabledReason is the reason of the events recorded on Ingresses
+// whose routes had their request and response buffering disabled because a
+// streaming plugin is attached to them.
+const BufferingDisabledReason = "KongBufferingDisabled"
+
+// RouteLimitExceededReason is the reason of the events recorded on Ingresses
+// whose routes were skipped because they exceed the maximum number of routes
+// of a single Ingress.
+const RouteLimitExceededReason = "KongRouteLimitExceeded"
+
+// InvalidRoutePathReason is the reason of the events recorded on Ingresses
+// whose route paths were skipped because Kong would reject them.
+const InvalidRoutePathReason = "KongInvalidRoutePath"
+
+// DuplicateRoutePathReason is the reason of the events recorded on Ingresses
+// whose rules list the same path more than once, whose duplicates were
+// skipped.
+const DuplicateRoutePathReason = "KongDuplicateRoutePath"
+
+// ServiceTimeouts are the connect, read and write timeouts of Kong services,
+// in milliseconds. Zero values leave the corresponding timeout unchanged.
+type ServiceTimeouts struct {
+	Connect int
+	Read    int
+	Write   int
+}
+
+// ParseServiceTimeouts parses service timeouts in the "connect,read,write"
+// format, in milliseconds. Empty values leave the corresponding timeout
+// unchanged, e.g. ",300000," only changes the read timeout.
+func ParseServiceTimeouts(val string) (ServiceTimeouts, error) {
+	parts := strings.Split(val, ",")
+	if len(parts) != 3 {
+		return ServiceTimeouts{}, fmt.Errorf("invalid service timeouts %q: expected connect,read,write", val)
+	}
+	var timeouts [3]int
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		timeout, err := strconv.Atoi(part)
+		if err != nil || timeout <= 0 {
+			return ServiceTimeouts{}, fmt.Errorf("invalid service timeouts %q: %q is not a positive number of milliseconds", val, part)
+		}
+		timeouts[i] = timeout
+	}
+	return ServiceTimeouts{Connect: timeouts[0], Read: timeouts[1], Write: timeouts[2]}, nil
 }
 
 // NewParser produces a new Parser object provided a logging mechanism
@@ -47,8 +200,9 @@ func NewParser(
 	storer store.Storer,
 ) *Parser {
 	return &Parser{
-		logger: logger,
-		storer: storer,
+		logger:     logger,
+		storer:     storer,
+		routeNamer: DefaultRouteNamer{},
 	}
 }
 
@@ -60,6 +214,8 @@ func NewParser(
 // defined in Kuberentes.
 // It throws an error if there is an error returned from client-go.
 func (p *Parser) Build() (*kongstate.KongState, error) {
+	p.routeObjects = nil
+
 	// parse and merge all rules together from all Kubernetes API sources
 	ingressRules := mergeIngressRules(
 		p.ingressRulesFromIngressV1beta1(),
@@ -67,8 +223,14 @@ func (p *Parser) Build() (*kongstate.KongState, error) {
 		p.ingressRulesFromTCPIngressV1beta1(),
 		p.ingressRulesFromUDPIngressV1beta1(),
 		p.ingressRulesFromKnativeIngress(),
-		p.ingressRulesFromHTTPRoutes(),
 	)
+	if p.featureGates.Enabled(featuregates.GatewayFeature) {
+		ingressRules = mergeIngressRules(
+			ingressRules,
+			p.ingressRulesFromHTTPRoutes(),
+			p.ingressRulesFromGatewayCertificates(),
+		)
+	}
 
 	// populate any Kubernetes Service objects relevant objects
 	ingressRules.populateServices(p.logger, p.storer)
@@ -76,23 +238,42 @@ func (p *Parser) Build() (*kongstate.KongState, error) {
 	// add the routes and services to the state
 	var result kongstate.KongState
 	for _, service := range ingressRules.ServiceNameToServices {
+		service.Routes = p.skipInvalidRoutePaths(service.Routes)
+		if p.maxPathsPerRoute > 0 {
+			service.Routes = splitRoutesByPathCount(service.Routes, p.maxPathsPerRoute)
+		}
+		applyServiceTimeouts(&service.Service, p.defaultTimeouts)
 		result.Services = append(result.Services, service)
 	}
 
 	// generate Upstreams and Targets from service defs
 	result.Upstreams = getUpstreams(p.logger, p.storer, ingressRules.ServiceNameToServices)
+	if p.dropRoutesWithoutTargets {
+		dropRoutesWithoutTargets(p.logger, &result)
+	}
 
 	// merge KongIngress with Routes, Services and Upstream
 	result.FillOverrides(p.logger, p.storer)
+	p.reportAnnotationErrors(overrideServiceTimeoutsByAnnotations(&result))
+	p.reportAnnotationErrors(overrideUpstreamHealthchecksByAnnotations(&result))
+
+	// generate Certificates and SNIs
+	result.Certificates = getCerts(p.logger, p.storer, ingressRules.SecretNameToSNIs, p.stableCertificateIDs)
+	if p.sniStrictness == SNIStrictnessStrict {
+		p.rejectRoutesWithUncoveredSNIs(&result)
+	}
+	p.rejectRoutesWithUnenforceableTLSMinVersion(&result)
 
 	// generate consumers and credentials
 	result.FillConsumersAndCredentials(p.logger, p.storer)
 
 	// process annotation plugins
+	if err := p.handleBrokenPluginReferences(&result); err != nil {
+		return nil, err
+	}
 	result.FillPlugins(p.logger, p.storer)
-
-	// generate Certificates and SNIs
-	result.Certificates = getCerts(p.logger, p.storer, ingressRules.SecretNameToSNIs)
+	p.reportPluginScopeConflicts(&result)
+	p.disableBufferingForStreamingPlugins(&result)
 
 	// populate CA certificates in Kong
 	var err error
@@ -101,10 +282,110 @@ func (p *Parser) Build() (*kongstate.KongState, error) {
 		return nil, err
 	}
 	result.CACertificates = toCACerts(p.logger, caCertSecrets)
+	p.fillServiceCACertificates(&result)
+
+	// tag the generated entities
+	result.AddTags(p.entityTags...)
 
 	return &result, nil
 }
 
+// EnableDropRoutesWithoutTargets configures the parser to drop the routes of
+// services whose upstreams have no targets (e.g. because the workload backing
+// the Service was scaled to zero) instead of routing to an empty upstream, so
+// that requests can fall through to other routing.
+func (p *Parser) EnableDropRoutesWithoutTargets() {
+	p.dropRoutesWithoutTargets = true
+}
+
+// SetMaxPathsPerRoute configures the maximum number of paths of a single
+// route: routes with more paths are split into multiple routes. A value of 0
+// or less means that the number of paths is not limited.
+func (p *Parser) SetMaxPathsPerRoute(maxPaths int) {
+	p.maxPathsPerRoute = maxPaths
+}
+
+// SetMaxRoutesPerIngress configures the maximum number of routes generated
+// for a single Ingress, i.e. of its host and path combinations: the routes of
+// an Ingress beyond the limit are skipped and a RouteLimitExceededReason
+// event is recorded on the Ingress. A value of 0 or less means that the number
+// of routes is not limited.
+func (p *Parser) SetMaxRoutesPerIngress(maxRoutes int) {
+	p.maxRoutesPerIngress = maxRoutes
+}
+
+// SetSNIStrictness configures the way in which HTTPS routes whose hosts aren't
+// covered by the SNIs of any certificate are handled. Such routes are kept by
+// default, as with SNIStrictnessLenient.
+func (p *Parser) SetSNIStrictness(strictness SNIStrictness) {
+	p.sniStrictness = strictness
+}
+
+// SetProxyTLSProtocols configures the TLS protocols accepted by the Kong proxy
+// (its ssl_protocols, e.g. "TLSv1.2"), against which the minimum TLS versions
+// required by konghq.com/tls-min-version annotations are checked.
+func (p *Parser) SetProxyTLSProtocols(protocols []string) {
+	p.proxyTLSProtocols = protocols
+}
+
+// SetStreamingPlugins configures the names of the Kong plugins which stream
+// request or response bodies. The request and response buffering of the
+// routes which such a plugin is attached to, directly or through their
+// service, is disabled.
+func (p *Parser) SetStreamingPlugins(names []string) {
+	p.streamingPlugins = make(map[string]struct{}, len(names))
+	for _, name := range names {
+		p.streamingPlugins[name] = struct{}{}
+	}
+}
+
+// SetRouteNamer configures the way in which the routes generated for the rule
+// paths of Ingresses are named, as with DefaultRouteNamer by default.
+func (p *Parser) SetRouteNamer(namer RouteNamer) {
+	p.routeNamer = namer
+}
+
+// SetFeatureGates configures the gated features which are translated, gated
+// features are translated according to their defaults if unset.
+func (p *Parser) SetFeatureGates(featureGates featuregates.FeatureGates) {
+	p.featureGates = featureGates
+}
+
+// SetFailurePolicy configures the way in which objects referencing plugins
+// which don't exist or can't be translated are handled. Broken references are
+// skipped by default, as with FailurePolicySkipBroken.
+func (p *Parser) SetFailurePolicy(policy FailurePolicy) {
+	p.failurePolicy = policy
+}
+
+// SetEventRecorder configures the recorder of the events the parser records
+// on the Kubernetes objects it translates.
+func (p *Parser) SetEventRecorder(recorder record.EventRecorder) {
+	p.eventRecorder = recorder
+}
+
+// EnableStableCertificateIDs configures the parser to derive the IDs of the
+// certificates it generates from the namespace, name and content of their
+// Secrets rather than from the UIDs of the Secrets, so that the IDs don't
+// change when a Secret is re-created with the same content and unchanged
+// certificates aren't rewritten.
+func (p *Parser) EnableStableCertificateIDs() {
+	p.stableCertificateIDs = true
+}
+
+// SetEntityTags configures tags which are added to all the entities the
+// parser generates, in addition to the tags which they are configured with.
+func (p *Parser) SetEntityTags(tags []string) {
+	p.entityTags = tags
+}
+
+// SetDefaultServiceTimeouts configures the parser to use the provided timeouts
+// instead of DefaultServiceTimeout for the services it generates. The timeouts
+// of KongIngresses and timeout annotations still take precedence.
+func (p *Parser) SetDefaultServiceTimeouts(timeouts ServiceTimeouts) {
+	p.defaultTimeouts = timeouts
+}
+
 // -----------------------------------------------------------------------------
 // Parser - Public Methods - Kubernetes Object Reporting
 // -----------------------------------------------------------------------------
@@ -262,7 +543,7 @@ func getUpstreams(
 	var empty struct{}
 	upstreams := make([]kongstate.Upstream, 0, len(serviceMap))
 	for _, service := range serviceMap {
-		name := fmt.Sprintf("%s.%s.%s.svc", service.Backend.Name, service.Namespace, service.Backend.Port.CanonicalString())
+		name := upstreamName(service)
 		if _, exists := upstreamDedup[name]; !exists {
 			var targets []kongstate.Target
 			port, err := findPort(&service.K8sService, service.Backend.Port)
@@ -271,6 +552,11 @@ func getUpstreams(
 			} else {
 				log.WithField("service_name", *service.Name).Warnf("skipping service - getServiceEndpoints failed: %v", err)
 			}
+			targets = applyUpstreamWeights(log, s, service, targets)
+			targets = appendFallbackTargets(log, s, service.K8sService, targets)
+			if len(targets) == 0 {
+				targets = getFallbackTargets(log, s, service)
+			}
 
 			upstream := kongstate.Upstream{
 				Upstream: kong.Upstream{
@@ -286,6 +572,829 @@ func getUpstreams(
 	return upstreams
 }
 
+// splitRoutesByPathCount splits the routes which have more than maxPaths paths
+// into multiple routes of at most maxPaths paths each. Split routes retain all
+// other attributes of the original route, including attached plugins; the first
+// one keeps the name of the original route and the others are suffixed with
+// their index.
+func splitRoutesByPathCount(routes []kongstate.Route, maxPaths int) []kongstate.Route {
+	result := make([]kongstate.Route, 0, len(routes))
+	for _, route := range routes {
+		if len(route.Paths) <= maxPaths {
+			result = append(result, route)
+			continue
+		}
+		for i := 0; i*maxPaths < len(route.Paths); i++ {
+			end := (i + 1) * maxPaths
+			if end > len(route.Paths) {
+				end = len(route.Paths)
+			}
+			split := route
+			split.Route = *route.Route.DeepCopy()
+			split.Paths = split.Paths[i*maxPaths : end]
+			if i > 0 && route.Name != nil {
+				split.Name = kong.String(fmt.Sprintf("%s.%d", *route.Name, i))
+			}
+			if route.Plugins != nil {
+				split.Plugins = append([]kong.Plugin{}, route.Plugins...)
+			}
+			result = append(result, split)
+		}
+	}
+	return result
+}
+
+// upstreamName returns the name of the upstream of the provided service.
+func upstreamName(service kongstate.Service) string {
+	return fmt.Sprintf("%s.%s.%s.svc", service.Backend.Name, service.Namespace, service.Backend.Port.CanonicalString())
+}
+
+// dropRoutesWithoutTargets removes the routes of the services whose upstreams have no targets.
+func dropRoutesWithoutTargets(log logrus.FieldLogger, state *kongstate.KongState) {
+	withoutTargets := make(map[string]bool)
+	for _, upstream := range state.Upstreams {
+		if len(upstream.Targets) == 0 {
+			withoutTargets[*upstream.Name] = true
+		}
+	}
+	for i, service := range state.Services {
+		if len(service.Routes) > 0 && withoutTargets[upstreamName(service)] {
+			log.WithFields(logrus.Fields{
+				"service_name":      service.Backend.Name,
+				"service_namespace": service.Namespace,
+			}).Warnf("dropping %d route(s): service has no targets", len(service.Routes))
+			state.Services[i].Routes = nil
+		}
+	}
+}
+
+// handleBrokenPluginReferences fails with an error listing the broken plugin
+// references of the state if the failure policy is FailurePolicyFailAll, and
+// otherwise logs them and records events on the objects with the references.
+func (p *Parser) handleBrokenPluginReferences(state *kongstate.KongState) error {
+	broken := state.BrokenPluginReferences(p.storer)
+	if len(broken) == 0 {
+		return nil
+	}
+
+	if p.failurePolicy == FailurePolicyFailAll {
+		msgs := make([]string, 0, len(broken))
+		for _, ref := range broken {
+			msgs = append(msgs, fmt.Sprintf("%s/%s references plugin %s: %v", ref.Namespace, ref.Name, ref.Plugin, ref.Err))
+		}
+		return fmt.Errorf("broken plugin references: %s", strings.Join(msgs, "; "))
+	}
+
+	for _, ref := range broken {
+		msg := fmt.Sprintf("plugin %s skipped: %v", ref.Plugin, ref.Err)
+		p.logger.WithFields(logrus.Fields{
+			"object_namespace": ref.Namespace,
+			"object_name":      ref.Name,
+		}).Error(msg)
+		obj := ref.Object
+		if obj == nil {
+			obj = p.routeObject(util.K8sObjectInfo{GroupVersionKind: ref.GroupVersionKind, Namespace: ref.Namespace, Name: ref.Name})
+		}
+		if obj != nil && p.eventRecorder != nil {
+			p.eventRecorder.Event(obj, corev1.EventTypeWarning, PluginReferenceBrokenReason, msg)
+		}
+	}
+	return nil
+}
+
+// reportPluginScopeConflicts warns about the rate limiting plugins of
+// consumers which shadow the same plugins configured for routes, services or
+// globally, and records events on the KongConsumers with such plugins.
+func (p *Parser) reportPluginScopeConflicts(state *kongstate.KongState) {
+	for _, conflict := range state.PluginScopeConflicts() {
+		msg := fmt.Sprintf("%s plugin of consumer %s takes precedence over the %s plugins of %s, whose limits are not enforced for the consumer",
+			conflict.Plugin, conflict.Consumer, conflict.Plugin, strings.Join(conflict.Shadowed, ", "))
+		p.logger.WithField("consumer", conflict.Consumer).Warn(msg)
+		if p.eventRecorder == nil {
+			continue
+		}
+		for i := range state.Consumers {
+			if username := state.Consumers[i].Username; username != nil && *username == conflict.Consumer {
+				p.eventRecorder.Event(&state.Consumers[i].K8sKongConsumer, corev1.EventTypeWarning, PluginScopeConflictReason, msg)
+			}
+		}
+	}
+}
+
+// disableBufferingForStreamingPlugins disables the request and response
+// buffering of the routes which a streaming plugin is attached to, directly or
+// through their service, as buffering conflicts with streaming the bodies.
+// A BufferingDisabledReason event is recorded on the Ingresses of the routes
+// whose buffering was changed.
+func (p *Parser) disableBufferingForStreamingPlugins(state *kongstate.KongState) {
+	if len(p.streamingPlugins) == 0 {
+		return
+	}
+	routePlugins := map[string]string{}
+	servicePlugins := map[string]string{}
+	for _, plugin := range state.Plugins {
+		if plugin.Name == nil {
+			continue
+		}
+		if _, ok := p.streamingPlugins[*plugin.Name]; !ok {
+			continue
+		}
+		switch {
+		case plugin.Route != nil && plugin.Route.ID != nil:
+			routePlugins[*plugin.Route.ID] = *plugin.Name
+		case plugin.Service != nil && plugin.Service.ID != nil:
+			servicePlugins[*plugin.Service.ID] = *plugin.Name
+		}
+	}
+
+	for i := range state.Services {
+		service := &state.Services[i]
+		for j := range service.Routes {
+			route := &service.Routes[j]
+			plugin, ok := routePlugins[*route.Name]
+			if !ok && service.Name != nil {
+				plugin, ok = servicePlugins[*service.Name]
+			}
+			if !ok {
+				continue
+			}
+			if route.RequestBuffering != nil && !*route.RequestBuffering &&
+				route.ResponseBuffering != nil && !*route.ResponseBuffering {
+				continue
+			}
+			route.RequestBuffering = kong.Bool(false)
+			route.ResponseBuffering = kong.Bool(false)
+			msg := fmt.Sprintf("request and response buffering of route %s disabled: streaming plugin %s is attached", *route.Name, plugin)
+			p.logger.WithFields(logrus.Fields{
+				"ingress_namespace": route.Ingress.Namespace,
+				"ingress_name":      route.Ingress.Name,
+			}).Info(msg)
+			if obj := p.routeObject(route.Ingress); obj != nil && p.eventRecorder != nil {
+				p.eventRecorder.Event(obj, corev1.EventTypeNormal, BufferingDisabledReason, msg)
+			}
+		}
+	}
+}
+
+// rejectRoutesWithUncoveredSNIs removes the HTTPS-only routes with hosts which
+// aren't covered by the SNIs of any certificate of the state, and records an
+// UncoveredSNIReason event naming the uncovered host on the Ingresses they
+// were generated for.
+func (p *Parser) rejectRoutesWithUncoveredSNIs(state *kongstate.KongState) {
+	var snis []string
+	for _, cert := range state.Certificates {
+		for _, sni := range cert.SNIs {
+			snis = append(snis, *sni)
+		}
+	}
+
+	for i, service := range state.Services {
+		routes := make([]kongstate.Route, 0, len(service.Routes))
+		for _, route := range service.Routes {
+			host, uncovered := uncoveredHTTPSHost(route, snis)
+			if !uncovered {
+				routes = append(routes, route)
+				continue
+			}
+			msg := fmt.Sprintf("HTTPS route %s rejected: host %s is not covered by any certificate", *route.Name, host)
+			p.logger.WithFields(logrus.Fields{
+				"ingress_namespace": route.Ingress.Namespace,
+				"ingress_name":      route.Ingress.Name,
+			}).Error(msg)
+			if obj := p.routeObject(route.Ingress); obj != nil && p.eventRecorder != nil {
+				p.eventRecorder.Event(obj, corev1.EventTypeWarning, UncoveredSNIReason, msg)
+			}
+		}
+		state.Services[i].Routes = routes
+	}
+}
+
+// rejectRoutesWithUnenforceableTLSMinVersion removes the routes whose
+// konghq.com/tls-min-version annotation can't be enforced and records a
+// TLSMinVersionUnsupportedReason event on the objects they were generated for.
+// Kong doesn't support a minimum TLS version per route, so the minimum is
+// only enforced if the lowest TLS protocol accepted by the Kong proxy already
+// satisfies it. Routes with an unknown version, or whose minimum can't be
+// checked because the protocols of the proxy are unknown, are rejected too,
+// so that routes are never served with less than the required TLS version.
+func (p *Parser) rejectRoutesWithUnenforceableTLSMinVersion(state *kongstate.KongState) {
+	proxyMinVersion := -1
+	for _, protocol := range p.proxyTLSProtocols {
+		if i := tlsVersionIndex(protocol); i >= 0 && (proxyMinVersion < 0 || i < proxyMinVersion) {
+			proxyMinVersion = i
+		}
+	}
+
+	for i, service := range state.Services {
+		routes := make([]kongstate.Route, 0, len(service.Routes))
+		for _, route := range service.Routes {
+			version, ok, err := annotations.ExtractTLSMinVersion(route.Ingress.Namespace, route.Ingress.Name, route.Ingress.Annotations)
+			var reason string
+			switch {
+			case err != nil:
+				reason = err.Error()
+			case !ok:
+			case proxyMinVersion < 0:
+				reason = fmt.Sprintf("minimum TLS version %s can't be enforced: the TLS protocols accepted by the Kong proxy are unknown", version)
+			case proxyMinVersion < tlsVersionIndex(version):
+				reason = fmt.Sprintf("minimum TLS version %s can't be enforced: Kong doesn't support a minimum TLS version per route "+
+					"and the Kong proxy accepts %s, raise its ssl_protocols instead", version, annotations.TLSVersions[proxyMinVersion])
+			}
+			if reason == "" {
+				routes = append(routes, route)
+				continue
+			}
+			msg := fmt.Sprintf("route %s rejected: %s", *route.Name, reason)
+			p.logger.WithFields(logrus.Fields{
+				"ingress_namespace": route.Ingress.Namespace,
+				"ingress_name":      route.Ingress.Name,
+			}).Error(msg)
+			if obj := p.routeObject(route.Ingress); obj != nil && p.eventRecorder != nil {
+				p.eventRecorder.Event(obj, corev1.EventTypeWarning, TLSMinVersionUnsupportedReason, msg)
+			}
+		}
+		state.Services[i].Routes = routes
+	}
+}
+
+// skipInvalidRoutePaths removes the paths of the provided routes which Kong
+// would reject, so that a single invalid path doesn't fail the whole sync, and
+// records an InvalidRoutePathReason event on the Ingresses they were generated
+// for. Routes whose paths are all invalid are removed, as a route without
+// paths would match every path.
+func (p *Parser) skipInvalidRoutePaths(routes []kongstate.Route) []kongstate.Route {
+	result := make([]kongstate.Route, 0, len(routes))
+	for _, route := range routes {
+		if len(route.Paths) == 0 {
+			result = append(result, route)
+			continue
+		}
+		paths := make([]*string, 0, len(route.Paths))
+		for _, path := range route.Paths {
+			err := validateRoutePath(*path)
+			if err == nil {
+				paths = append(paths, path)
+				continue
+			}
+			msg := fmt.Sprintf("path %q of route %s skipped: %s", *path, *route.Name, err)
+			p.logger.WithFields(logrus.Fields{
+				"ingress_namespace": route.Ingress.Namespace,
+				"ingress_name":      route.Ingress.Name,
+			}).Error(msg)
+			if obj := p.routeObject(route.Ingress); obj != nil && p.eventRecorder != nil {
+				p.eventRecorder.Event(obj, corev1.EventTypeWarning, InvalidRoutePathReason, msg)
+			}
+		}
+		if len(paths) == 0 {
+			continue
+		}
+		route.Paths = paths
+		result = append(result, route)
+	}
+	return result
+}
+
+// validateRoutePath checks that the provided route path is accepted by Kong:
+// it must start with a slash, must not have empty segments and must not
+// contain whitespace or control characters. Paths made of the characters
+// allowed by RFC 3986 must be properly percent-encoded; Kong interprets other
+// paths as regular expressions, which are left for Kong to compile.
+func validateRoutePath(path string) error {
+	if !strings.HasPrefix(path, "/") {
+		return errors.New("must start with /")
+	}
+	if strings.Contains(path, "//") {
+		return errors.New("must not have empty segments")
+	}
+	plain := true
+	for _, r := range path {
+		switch {
+		case r <= ' ' || r == 0x7f:
+			return fmt.Errorf("invalid character %q", r)
+		case !isRFC3986PathCharacter(r):
+			plain = false
+		}
+	}
+	if !plain {
+		return nil
+	}
+	for i := 0; i < len(path); i++ {
+		if path[i] != '%' {
+			continue
+		}
+		if i+2 >= len(path) || !isHexDigit(path[i+1]) || !isHexDigit(path[i+2]) {
+			return fmt.Errorf("invalid percent-encoding at offset %d", i)
+		}
+		i += 2
+	}
+	return nil
+}
+
+// isRFC3986PathCharacter reports whether the provided character may appear in
+// an RFC 3986 path: unreserved characters, sub-delims, ":", "@", "/" and the
+// "%" of percent-encodings.
+func isRFC3986PathCharacter(r rune) bool {
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		return true
+	default:
+		return strings.ContainsRune("-._~!$&'()*+,;=:@/%", r)
+	}
+}
+
+func isHexDigit(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+// caCertificateIDNamespace is the UUID namespace of the IDs of the CA
+// certificates generated by fillServiceCACertificates.
+var caCertificateIDNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://konghq.com/ca-certificates"))
+
+// fillServiceCACertificates generates the CA certificates listed by the
+// konghq.com/ca-certificates annotation of the Kubernetes Services of the
+// Kong services and makes the Kong services verify the TLS certificates of
+// their upstreams with them. The Secrets hold PEM-encoded CA certificates in
+// their "ca.crt" or "cert" key. Secrets which don't exist or don't hold valid
+// CA certificates are skipped and a warning event is recorded on the Service.
+func (p *Parser) fillServiceCACertificates(state *kongstate.KongState) {
+	// CA certificates are unique by content in Kong, reuse the IDs of the
+	// certificates which are already configured
+	ids := make(map[[sha256.Size]byte]string, len(state.CACertificates))
+	for _, caCert := range state.CACertificates {
+		if block, _ := pem.Decode([]byte(*caCert.Cert)); block != nil {
+			ids[sha256.Sum256(block.Bytes)] = *caCert.ID
+		}
+	}
+
+	reported := make(map[string]struct{})
+	for i := range state.Services {
+		service := &state.Services[i]
+		var serviceCACertIDs []*string
+		for _, secretName := range annotations.ExtractCACertificates(service.K8sService.Annotations) {
+			certs, err := p.serviceCACertificates(service.K8sService.Namespace, secretName)
+			if err != nil {
+				// Kubernetes Services with multiple ports generate multiple
+				// Kong services, report their errors once
+				key := service.K8sService.Namespace + "/" + service.K8sService.Name + "/" + secretName
+				if _, ok := reported[key]; ok {
+					continue
+				}
+				reported[key] = struct{}{}
+				msg := fmt.Sprintf("CA certificates of Secret %s/%s skipped: %v", service.K8sService.Namespace, secretName, err)
+				p.logger.WithFields(logrus.Fields{
+					"service_namespace": service.K8sService.Namespace,
+					"service_name":      service.K8sService.Name,
+				}).Error(msg)
+				if p.eventRecorder != nil {
+					p.eventRecorder.Event(&service.K8sService, corev1.EventTypeWarning, CACertificateInvalidReason, msg)
+				}
+				continue
+			}
+			for _, cert := range certs {
+				digest := sha256.Sum256(cert.Raw)
+				id, ok := ids[digest]
+				if !ok {
+					id = uuid.NewSHA1(caCertificateIDNamespace, []byte(hex.EncodeToString(digest[:]))).String()
+					ids[digest] = id
+					state.CACertificates = append(state.CACertificates, kong.CACertificate{
+						ID:   kong.String(id),
+						Cert: kong.String(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))),
+					})
+				}
+				serviceCACertIDs = append(serviceCACertIDs, kong.String(id))
+			}
+		}
+		if len(serviceCACertIDs) > 0 {
+			service.CACertificates = serviceCACertIDs
+			service.TLSVerify = kong.Bool(true)
+		}
+	}
+}
+
+// serviceCACertificates returns the CA certificates held by the Secret with
+// the provided namespace and name.
+func (p *Parser) serviceCACertificates(namespace, name string) ([]*x509.Certificate, error) {
+	secret, err := p.storer.GetSecret(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := secret.Data["ca.crt"]
+	if !ok {
+		data = secret.Data["cert"]
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, errors.New("no PEM data in the 'ca.crt' or 'cert' key")
+	}
+
+	var certs []*x509.Certificate
+	for rest := data; len(bytes.TrimSpace(rest)) > 0; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, errors.New("invalid PEM data")
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		if !cert.IsCA {
+			return nil, fmt.Errorf("certificate %s is missing the 'CA' basic constraint", cert.Subject)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM-encoded certificates")
+	}
+	return certs, nil
+}
+
+// tlsVersionIndex returns the index of the provided TLS version in
+// annotations.TLSVersions, or -1 if it's unknown.
+func tlsVersionIndex(version string) int {
+	for i, known := range annotations.TLSVersions {
+		if version == known {
+			return i
+		}
+	}
+	return -1
+}
+
+// uncoveredHTTPSHost returns the first host of the provided route which isn't
+// covered by the provided SNIs if the route only accepts HTTPS (or gRPCs)
+// traffic.
+func uncoveredHTTPSHost(route kongstate.Route, snis []string) (string, bool) {
+	for _, protocol := range route.Protocols {
+		if protocol == nil || (*protocol != "https" && *protocol != "grpcs") {
+			return "", false
+		}
+	}
+	if len(route.Protocols) == 0 {
+		return "", false
+	}
+	for _, host := range route.Hosts {
+		if host != nil && !sniCovers(snis, *host) {
+			return *host, true
+		}
+	}
+	return "", false
+}
+
+// sniCovers reports whether the provided host is matched by one of the
+// provided SNIs, which may have a leading wildcard label.
+func sniCovers(snis []string, host string) bool {
+	host = strings.ToLower(host)
+	for _, sni := range snis {
+		if sni == host {
+			return true
+		}
+		if strings.HasPrefix(sni, "*.") {
+			if i := strings.Index(host, "."); i > 0 && host[i:] == sni[1:] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// routeObjectKey identifies an object which routes are generated for.
+type routeObjectKey struct {
+	gvk             schema.GroupVersionKind
+	namespace, name string
+}
+
+// routeObject returns the object which routes are generated for described by
+// the provided information, or nil if there is no such object.
+func (p *Parser) routeObject(info util.K8sObjectInfo) client.Object {
+	if p.routeObjects == nil {
+		p.routeObjects = p.indexRouteObjects()
+	}
+	return p.routeObjects[routeObjectKey{gvk: info.GroupVersionKind, namespace: info.Namespace, name: info.Name}]
+}
+
+// indexRouteObjects indexes the objects of every kind which routes are
+// generated for by their kind, namespace and name. Kinds which can't be
+// listed are skipped.
+func (p *Parser) indexRouteObjects() map[routeObjectKey]client.Object {
+	objects := make(map[routeObjectKey]client.Object)
+	add := func(gvk schema.GroupVersionKind, obj client.Object) {
+		objects[routeObjectKey{gvk: gvk, namespace: obj.GetNamespace(), name: obj.GetName()}] = obj
+	}
+	for _, ingress := range p.storer.ListIngressesV1() {
+		add(ingressV1GVK, ingress)
+	}
+	for _, ingress := range p.storer.ListIngressesV1beta1() {
+		add(ingressV1beta1GVK, ingress)
+	}
+	if ingresses, err := p.storer.ListTCPIngresses(); err == nil {
+		for _, ingress := range ingresses {
+			add(tcpIngressGVK, ingress)
+		}
+	}
+	if ingresses, err := p.storer.ListUDPIngresses(); err == nil {
+		for _, ingress := range ingresses {
+			add(udpIngressGVK, ingress)
+		}
+	}
+	if ingresses, err := p.storer.ListKnativeIngresses(); err == nil {
+		for _, ingress := range ingresses {
+			add(knativeIngressGVK, ingress)
+		}
+	}
+	if p.featureGates.Enabled(featuregates.GatewayFeature) {
+		if httproutes, err := p.storer.ListHTTPRoutes(); err == nil {
+			for _, httproute := range httproutes {
+				add(httpRouteGVK, httproute)
+			}
+		}
+	}
+	return objects
+}
+
+// routeObjectError is an error about the object which a route is generated
+// for, which allows events to be recorded on that object.
+type routeObjectError struct {
+	object util.K8sObjectInfo
+	err    error
+}
+
+func (e routeObjectError) Error() string { return e.err.Error() }
+
+func (e routeObjectError) Unwrap() error { return e.err }
+
+// applyServiceTimeouts sets the non-zero timeouts of the provided ServiceTimeouts on a Kong service.
+func applyServiceTimeouts(service *kong.Service, timeouts ServiceTimeouts) {
+	if timeouts.Connect > 0 {
+		service.ConnectTimeout = kong.Int(timeouts.Connect)
+	}
+	if timeouts.Read > 0 {
+		service.ReadTimeout = kong.Int(timeouts.Read)
+	}
+	if timeouts.Write > 0 {
+		service.WriteTimeout = kong.Int(timeouts.Write)
+	}
+}
+
+// overrideServiceTimeoutsByAnnotations sets the timeouts of services from the konghq.com/connect-timeout,
+// konghq.com/read-timeout and konghq.com/write-timeout annotations of the objects routing to them. For each timeout,
+// the first route with the annotation wins. The errors of malformed annotations, which are ignored, are returned.
+func overrideServiceTimeoutsByAnnotations(state *kongstate.KongState) []error {
+	var errs []error
+	for i := range state.Services {
+		var timeouts ServiceTimeouts
+		for _, route := range state.Services[i].Routes {
+			ns, name, anns := route.Ingress.Namespace, route.Ingress.Name, route.Ingress.Annotations
+			for _, extract := range []struct {
+				fn      func(string, string, map[string]string) (int, bool, error)
+				timeout *int
+			}{
+				{fn: annotations.ExtractConnectTimeout, timeout: &timeouts.Connect},
+				{fn: annotations.ExtractReadTimeout, timeout: &timeouts.Read},
+				{fn: annotations.ExtractWriteTimeout, timeout: &timeouts.Write},
+			} {
+				timeout, ok, err := extract.fn(ns, name, anns)
+				if err != nil {
+					errs = append(errs, routeObjectError{object: route.Ingress, err: err})
+					continue
+				}
+				if ok && *extract.timeout == 0 {
+					*extract.timeout = timeout
+				}
+			}
+		}
+		applyServiceTimeouts(&state.Services[i].Service, timeouts)
+	}
+	return errs
+}
+
+// overrideUpstreamHealthchecksByAnnotations sets the active health check
+// concurrency of the upstreams from the konghq.com/healthcheck-active-concurrency
+// annotation of the Ingresses routed to them, and returns the errors of the
+// malformed annotations. If several Ingresses set the annotation, the first
+// valid value wins.
+func overrideUpstreamHealthchecksByAnnotations(state *kongstate.KongState) []error {
+	var errs []error
+	concurrencies := make(map[string]int)
+	for _, service := range state.Services {
+		name := upstreamName(service)
+		for _, route := range service.Routes {
+			concurrency, ok, err := annotations.ExtractHealthcheckActiveConcurrency(
+				route.Ingress.Namespace, route.Ingress.Name, route.Ingress.Annotations)
+			if err != nil {
+				errs = append(errs, routeObjectError{object: route.Ingress, err: err})
+				continue
+			}
+			if _, set := concurrencies[name]; ok && !set {
+				concurrencies[name] = concurrency
+			}
+		}
+	}
+	for i := range state.Upstreams {
+		concurrency, ok := concurrencies[*state.Upstreams[i].Name]
+		if !ok {
+			continue
+		}
+		upstream := &state.Upstreams[i].Upstream
+		if upstream.Healthchecks == nil {
+			upstream.Healthchecks = &kong.Healthcheck{}
+		}
+		if upstream.Healthchecks.Active == nil {
+			upstream.Healthchecks.Active = &kong.ActiveHealthcheck{}
+		}
+		upstream.Healthchecks.Active.Concurrency = kong.Int(concurrency)
+	}
+	return errs
+}
+
+// reportAnnotationErrors logs the provided errors of malformed annotations and
+// records InvalidAnnotationReason events on the objects with the annotations,
+// which routeObjectErrors identify.
+func (p *Parser) reportAnnotationErrors(errs []error) {
+	reported := make(map[string]struct{}, len(errs))
+	for _, err := range errs {
+		var annErr *annotations.AnnotationError
+		if !errors.As(err, &annErr) {
+			p.logger.Error(err)
+			continue
+		}
+		var objErr routeObjectError
+		errors.As(err, &objErr)
+		// objects with multiple routes are reported once per annotation
+		key := objErr.object.GroupVersionKind.String() + "/" + annErr.Namespace + "/" + annErr.Name + "/" + annErr.Key
+		if _, ok := reported[key]; ok {
+			continue
+		}
+		reported[key] = struct{}{}
+
+		p.logger.WithFields(logrus.Fields{
+			"object_namespace": annErr.Namespace,
+			"object_name":      annErr.Name,
+			"annotation":       annErr.Key,
+		}).Errorf("ignoring malformed annotation: %v", annErr.Err)
+		if p.eventRecorder == nil {
+			continue
+		}
+		if obj := p.routeObject(objErr.object); obj != nil {
+			p.eventRecorder.Event(obj, corev1.EventTypeWarning, InvalidAnnotationReason, annErr.Error())
+		}
+	}
+}
+
+// applyUpstreamWeights weights the targets of the provided Service according to the konghq.com/upstream-weight
+// annotation of the objects routing to it and appends the endpoints of the other Services listed in the annotation,
+// using the same port definition, with their own weights. This allows canary rollouts in which a share of the traffic
+// of a route is sent to another Service. Weights apply to each target of a Service, and the targets of Services
+// without a weight keep Kong's default weight. The first route with the annotation wins.
+func applyUpstreamWeights(
+	log logrus.FieldLogger,
+	s store.Storer,
+	service kongstate.Service,
+	targets []kongstate.Target,
+) []kongstate.Target {
+	var weights map[string]int
+	for _, route := range service.Routes {
+		if weights = annotations.ExtractUpstreamWeights(route.Ingress.Annotations); weights != nil {
+			break
+		}
+	}
+	if weights == nil {
+		return targets
+	}
+
+	if weight, ok := weights[service.Backend.Name]; ok {
+		for i := range targets {
+			targets[i].Weight = kong.Int(weight)
+		}
+	}
+
+	names := make([]string, 0, len(weights))
+	for name := range weights {
+		if name != service.Backend.Name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	existing := make(map[string]struct{}, len(targets))
+	for _, target := range targets {
+		existing[*target.Target.Target] = struct{}{}
+	}
+	for _, name := range names {
+		log := log.WithFields(logrus.Fields{
+			"service_name":          service.Backend.Name,
+			"service_namespace":     service.Namespace,
+			"weighted_service_name": name,
+		})
+		weighted, err := s.GetService(service.Namespace, name)
+		if err != nil {
+			log.Errorf("failed to fetch weighted service: %v", err)
+			continue
+		}
+		port, err := findPort(weighted, service.Backend.Port)
+		if err != nil {
+			log.Errorf("failed to find port of weighted service: %v", err)
+			continue
+		}
+		for _, target := range getServiceEndpoints(log, s, *weighted, port) {
+			if _, ok := existing[*target.Target.Target]; ok {
+				continue
+			}
+			existing[*target.Target.Target] = struct{}{}
+			target.Weight = kong.Int(weights[name])
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// appendFallbackTargets appends the endpoints of the fallback Service configured for the provided Service by the
+// konghq.com/upstream-fallback-service annotation (if any) to the provided targets. The fallback targets are given a
+// low weight so that they only receive a marginal share of the traffic while the primary targets are healthy, and all
+// of it once the primary targets are ejected by Kong's health checks.
+func appendFallbackTargets(log logrus.FieldLogger, s store.Storer, svc corev1.Service, targets []kongstate.Target) []kongstate.Target {
+	name, port, ok := annotations.ExtractUpstreamFallbackService(svc.Annotations)
+	if !ok {
+		return targets
+	}
+	log = log.WithFields(logrus.Fields{
+		"service_name":          svc.Name,
+		"service_namespace":     svc.Namespace,
+		"fallback_service_name": name,
+	})
+
+	fallback, err := s.GetService(svc.Namespace, name)
+	if err != nil {
+		log.Errorf("failed to fetch fallback service: %v", err)
+		return targets
+	}
+	fallbackPort, err := findPort(fallback, portDefFromString(port))
+	if err != nil {
+		log.Errorf("failed to find port of fallback service: %v", err)
+		return targets
+	}
+
+	existing := make(map[string]struct{}, len(targets))
+	for _, target := range targets {
+		existing[*target.Target.Target] = struct{}{}
+	}
+	for _, target := range getServiceEndpoints(log, s, *fallback, fallbackPort) {
+		if _, ok := existing[*target.Target.Target]; ok {
+			continue
+		}
+		target.Weight = kong.Int(FallbackTargetWeight)
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// getFallbackTargets returns the endpoints of the fallback Service of the objects routing to the provided Service. It
+// is used when the Service is missing or none of its endpoints are ready, e.g. because all its Pods failed their
+// readiness probes.
+//
+// A fallback Service is configured by the konghq.com/fallback-service annotation of the objects routing to the
+// Service. The annotations are tried in order, and the first fallback Service with ready endpoints is used.
+func getFallbackTargets(log logrus.FieldLogger, s store.Storer, service kongstate.Service) []kongstate.Target {
+	for _, route := range service.Routes {
+		name, port, ok := annotations.ExtractFallbackService(route.Ingress.Annotations)
+		if !ok {
+			continue
+		}
+		log := log.WithFields(logrus.Fields{
+			"service_name":          service.Backend.Name,
+			"service_namespace":     service.Namespace,
+			"fallback_service_name": name,
+		})
+		fallback, err := s.GetService(route.Ingress.Namespace, name)
+		if err != nil {
+			log.Errorf("failed to fetch fallback service: %v", err)
+			continue
+		}
+		fallbackPort, err := findPort(fallback, portDefFromString(port))
+		if err != nil {
+			log.Errorf("failed to find port of fallback service: %v", err)
+			continue
+		}
+		if targets := getServiceEndpoints(log, s, *fallback, fallbackPort); len(targets) > 0 {
+			log.Debugf("no endpoints ready, routing to fallback service")
+			return targets
+		}
+	}
+	return nil
+}
+
+// portDefFromString converts a port number or name into a PortDef. An empty string results in an implicit port.
+func portDefFromString(port string) kongstate.PortDef {
+	if port == "" {
+		return kongstate.PortDef{Mode: kongstate.PortModeImplicit}
+	}
+	if number, err := strconv.ParseInt(port, 10, 32); err == nil {
+		return kongstate.PortDef{Mode: kongstate.PortModeByNumber, Number: int32(number)}
+	}
+	return kongstate.PortDef{Mode: kongstate.PortModeByName, Name: port}
+}
+
 func getCertFromSecret(secret *corev1.Secret) (string, string, error) {
 	certData, okcert := secret.Data[corev1.TLSCertKey]
 	keyData, okkey := secret.Data[corev1.TLSPrivateKeyKey]
@@ -307,7 +1416,7 @@ func getCertFromSecret(secret *corev1.Secret) (string, string, error) {
 	return cert, key, nil
 }
 
-func getCerts(log logrus.FieldLogger, s store.Storer, secretsToSNIs map[string][]string) []kongstate.Certificate {
+func getCerts(log logrus.FieldLogger, s store.Storer, secretsToSNIs map[string][]string, stableIDs bool) []kongstate.Certificate {
 	snisAdded := make(map[string]bool)
 	// map of cert public key + private key to certificate
 	type certWrapper struct {
@@ -334,18 +1443,21 @@ func getCerts(log logrus.FieldLogger, s store.Storer, secretsToSNIs map[string][
 			}).Logger.Errorf("failed to construct certificate from secret: %v", err)
 			continue
 		}
+		secretID := string(secret.UID)
+		if stableIDs {
+			secretID = stableCertificateID(secret.Namespace, secret.Name, cert, key)
+		}
 		kongCert, ok := certs[cert+key]
 		if !ok {
 			kongCert = certWrapper{
 				cert: kong.Certificate{
-					ID:   kong.String(string(secret.UID)),
+					ID:   kong.String(secretID),
 					Cert: kong.String(cert),
 					Key:  kong.String(key),
 				},
 				CreationTimestamp: secret.CreationTimestamp,
 			}
 		} else {
-			secretID := string(secret.UID)
 			if kongCert.CreationTimestamp.After(secret.CreationTimestamp.Time) {
 				kongCert.cert.ID = kong.String(secretID)
 				kongCert.CreationTimestamp = secret.CreationTimestamp
@@ -356,6 +1468,7 @@ func getCerts(log logrus.FieldLogger, s store.Storer, secretsToSNIs map[string][
 		}
 
 		for _, sni := range SNIs {
+			sni = strings.ToLower(sni)
 			if !snisAdded[sni] {
 				snisAdded[sni] = true
 				kongCert.cert.SNIs = append(kongCert.cert.SNIs, kong.String(sni))
@@ -373,6 +1486,19 @@ func getCerts(log logrus.FieldLogger, s store.Storer, secretsToSNIs map[string][
 	return res
 }
 
+// stableCertificateIDNamespace is the UUID namespace of the IDs generated by
+// stableCertificateID.
+var stableCertificateIDNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://konghq.com/certificates"))
+
+// stableCertificateID returns a certificate ID which only depends on the
+// namespace and name of the Secret holding the certificate and on the content
+// of the certificate and its key.
+func stableCertificateID(namespace, name, cert, key string) string {
+	contentHash := sha256.Sum256([]byte(cert + key))
+	data := fmt.Sprintf("%s/%s/%s", namespace, name, hex.EncodeToString(contentHash[:]))
+	return uuid.NewSHA1(stableCertificateIDNamespace, []byte(data)).String()
+}
+
 func getServiceEndpoints(log logrus.FieldLogger, s store.Storer, svc corev1.Service,
 	servicePort *corev1.ServicePort) []kongstate.Target {
 
@@ -390,7 +1516,7 @@ func getServiceEndpoints(log logrus.FieldLogger, s store.Storer, svc corev1.Serv
 	// check all protocols for associated endpoints
 	endpoints := []util.Endpoint{}
 	for protocol := range protocols {
-		newEndpoints := getEndpoints(log, &svc, servicePort, protocol, s.GetEndpointsForService)
+		newEndpoints := getEndpoints(log, &svc, servicePort, protocol, s.GetEndpointsForService, s.ListEndpointSlicesForService)
 		if len(newEndpoints) > 0 {
 			endpoints = append(endpoints, newEndpoints...)
 		}
@@ -409,6 +1535,7 @@ func getEndpoints(
 	port *corev1.ServicePort,
 	proto corev1.Protocol,
 	getEndpoints func(string, string) (*corev1.Endpoints, error),
+	listEndpointSlices func(string, string) ([]*discoveryv1.EndpointSlice, error),
 ) []util.Endpoint {
 
 	upsServers := []util.Endpoint{}
@@ -423,11 +1550,6 @@ func getEndpoints(
 		"service_port":      port.String(),
 	})
 
-	// avoid duplicated upstream servers when the service
-	// contains multiple port definitions sharing the same
-	// targetport.
-	adus := make(map[string]bool)
-
 	// ExternalName services
 	if s.Spec.Type == corev1.ServiceTypeExternalName {
 		log.Debug("found service of type=ExternalName")
@@ -444,56 +1566,14 @@ func getEndpoints(
 			Port:    fmt.Sprintf("%v", targetPort),
 		})
 	}
-	if annotations.HasServiceUpstreamAnnotation(s.Annotations) {
-		return append(upsServers, util.Endpoint{
-			Address: s.Name + "." + s.Namespace + ".svc",
-			Port:    fmt.Sprintf("%v", port.Port),
-		})
-
-	}
 
-	log.Debugf("fetching endpoints")
-	ep, err := getEndpoints(s.Namespace, s.Name)
+	log.Debugf("resolving upstream targets")
+	endpoints, err := TargetResolverFor(s, getEndpoints, listEndpointSlices).ResolveTargets(s, port, proto)
 	if err != nil {
-		log.Errorf("failed to fetch endpoints: %v", err)
+		log.Errorf("failed to resolve upstream targets: %v", err)
 		return upsServers
 	}
-
-	for _, ss := range ep.Subsets {
-		for _, epPort := range ss.Ports {
-
-			if !reflect.DeepEqual(epPort.Protocol, proto) {
-				continue
-			}
-
-			var targetPort int32
-
-			if port.Name == "" {
-				// port.Name is optional if there is only one port
-				targetPort = epPort.Port
-			} else if port.Name == epPort.Name {
-				targetPort = epPort.Port
-			}
-
-			// check for invalid port value
-			if targetPort <= 0 {
-				continue
-			}
-
-			for _, epAddress := range ss.Addresses {
-				ep := fmt.Sprintf("%v:%v", epAddress.IP, targetPort)
-				if _, exists := adus[ep]; exists {
-					continue
-				}
-				ups := util.Endpoint{
-					Address: epAddress.IP,
-					Port:    fmt.Sprintf("%v", targetPort),
-				}
-				upsServers = append(upsServers, ups)
-				adus[ep] = true
-			}
-		}
-	}
+	upsServers = append(upsServers, endpoints...)
 
 	log.Debugf("found endpoints: %v", upsServers)
 	return upsServers
@@ -8,16 +8,20 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/kong/go-kong/kong"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	knative "knative.dev/networking/pkg/apis/networking/v1alpha1"
 
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
@@ -964,6 +968,120 @@ func TestCACertificate(t *testing.T) {
 	})
 }
 
+func TestServiceCACertificates(t *testing.T) {
+	ingress := &networkingv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Namespace:   "default",
+			Annotations: map[string]string{annotations.IngressClassKey: annotations.DefaultIngressClass},
+		},
+		Spec: networkingv1beta1.IngressSpec{
+			Rules: []networkingv1beta1.IngressRule{{
+				Host: "example.com",
+				IngressRuleValue: networkingv1beta1.IngressRuleValue{
+					HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+						Paths: []networkingv1beta1.HTTPIngressPath{{
+							Path: "/",
+							Backend: networkingv1beta1.IngressBackend{
+								ServiceName: "foo-svc",
+								ServicePort: intstr.FromInt(80),
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo-svc",
+			Namespace:   "default",
+			Annotations: map[string]string{"konghq.com/ca-certificates": "root-ca"},
+		},
+	}
+	build := func(secrets ...*corev1.Secret) (*kongstate.KongState, []string) {
+		fakeStore, err := store.NewFakeStore(store.FakeObjects{
+			IngressesV1beta1: []*networkingv1beta1.Ingress{ingress},
+			Services:         []*corev1.Service{service},
+			Secrets:          secrets,
+		})
+		require.NoError(t, err)
+		recorder := record.NewFakeRecorder(10)
+		p := NewParser(logrus.New(), fakeStore)
+		p.SetEventRecorder(recorder)
+		state, err := p.Build()
+		require.NoError(t, err)
+		require.Len(t, state.Services, 1)
+
+		close(recorder.Events)
+		var events []string
+		for event := range recorder.Events {
+			events = append(events, event)
+		}
+		return state, events
+	}
+	secret := func(data string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "root-ca", Namespace: "default"},
+			Data:       map[string][]byte{"ca.crt": []byte(data)},
+		}
+	}
+
+	t.Log("verifying that the CA certificates of a referenced Secret verify the upstream TLS certificates")
+	state, events := build(secret(caCert1 + "\n" + caCert2))
+	assert.Empty(t, events)
+	require.Len(t, state.CACertificates, 2)
+	kongService := state.Services[0]
+	require.Len(t, kongService.CACertificates, 2)
+	assert.Equal(t, *state.CACertificates[0].ID, *kongService.CACertificates[0])
+	assert.Equal(t, *state.CACertificates[1].ID, *kongService.CACertificates[1])
+	assert.True(t, *kongService.TLSVerify)
+
+	t.Log("verifying that the IDs of the generated CA certificates are stable")
+	again, _ := build(secret(caCert1 + "\n" + caCert2))
+	assert.Equal(t, state.CACertificates, again.CACertificates)
+
+	t.Log("verifying that CA certificates which are already configured are reused")
+	state, events = build(secret(caCert1), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "labeled-ca",
+			Namespace:   "default",
+			Labels:      map[string]string{"konghq.com/ca-cert": "true"},
+			Annotations: map[string]string{annotations.IngressClassKey: annotations.DefaultIngressClass},
+		},
+		Data: map[string][]byte{
+			"id":   []byte("8214a145-a328-4c56-ab72-2973a56d4eae"),
+			"cert": []byte(caCert1),
+		},
+	})
+	assert.Empty(t, events)
+	require.Len(t, state.CACertificates, 1)
+	assert.Equal(t, []*string{kong.String("8214a145-a328-4c56-ab72-2973a56d4eae")}, state.Services[0].CACertificates)
+
+	t.Log("verifying that a missing Secret is reported and skipped")
+	state, events = build()
+	assert.Empty(t, state.CACertificates)
+	assert.Nil(t, state.Services[0].CACertificates)
+	assert.Nil(t, state.Services[0].TLSVerify)
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0], CACertificateInvalidReason)
+	assert.Contains(t, events[0], "default/root-ca")
+
+	t.Log("verifying that Secrets with malformed PEM data are reported and skipped")
+	for _, data := range []string{
+		"",
+		"not a certificate",
+		"-----BEGIN CERTIFICATE-----\nbm90IGEgY2VydGlmaWNhdGU=\n-----END CERTIFICATE-----\n",
+		tlsPairs[0].Key,
+	} {
+		state, events = build(secret(data))
+		assert.Empty(t, state.CACertificates, data)
+		assert.Nil(t, state.Services[0].CACertificates, data)
+		require.Len(t, events, 1, data)
+		assert.Contains(t, events[0], CACertificateInvalidReason, data)
+	}
+}
+
 func TestServiceClientCertificate(t *testing.T) {
 	assert := assert.New(t)
 	t.Run("valid client-cert annotation", func(t *testing.T) {
@@ -2540,6 +2658,102 @@ func TestKongServiceAnnotations(t *testing.T) {
 		}, state.Services[0].Routes[0].Route)
 	})
 
+	t.Run("h2c appProtocol is correctly processed", func(t *testing.T) {
+		ingresses := []*networkingv1beta1.Ingress{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "bar",
+					Namespace: "default",
+					Annotations: map[string]string{
+						annotations.IngressClassKey: annotations.DefaultIngressClass,
+					},
+				},
+				Spec: networkingv1beta1.IngressSpec{
+					Rules: []networkingv1beta1.IngressRule{
+						{
+							Host: "example.com",
+							IngressRuleValue: networkingv1beta1.IngressRuleValue{
+								HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+									Paths: []networkingv1beta1.HTTPIngressPath{
+										{
+											Path: "/",
+											Backend: networkingv1beta1.IngressBackend{
+												ServiceName: "foo-svc",
+												ServicePort: intstr.FromInt(80),
+											},
+										},
+										{
+											Path: "/plain",
+											Backend: networkingv1beta1.IngressBackend{
+												ServiceName: "foo-svc",
+												ServicePort: intstr.FromInt(8080),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		services := []*corev1.Service{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo-svc",
+					Namespace: "default",
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{Name: "h2c", Port: 80, AppProtocol: kong.String(kongstate.AppProtocolH2C)},
+						{Name: "http", Port: 8080},
+					},
+				},
+			},
+		}
+		fakeStore, err := store.NewFakeStore(store.FakeObjects{
+			IngressesV1beta1: ingresses,
+			Services:         services,
+		})
+		assert.Nil(err)
+		p := NewParser(logrus.New(), fakeStore)
+		state, err := p.Build()
+		assert.Nil(err)
+		assert.NotNil(state)
+
+		assert.Equal(2, len(state.Services),
+			"expected two services to be rendered")
+		sort.SliceStable(state.Services, func(i, j int) bool {
+			return *state.Services[i].Name < *state.Services[j].Name
+		})
+		assert.Equal(kong.Service{
+			Name:           kong.String("default.foo-svc.80"),
+			Host:           kong.String("foo-svc.default.80.svc"),
+			Port:           kong.Int(80),
+			ConnectTimeout: kong.Int(60000),
+			ReadTimeout:    kong.Int(60000),
+			WriteTimeout:   kong.Int(60000),
+			Retries:        kong.Int(5),
+			Protocol:       kong.String("grpc"),
+		}, state.Services[0].Service)
+		assert.Equal("http", *state.Services[1].Protocol,
+			"ports without the h2c appProtocol should keep the default protocol")
+
+		t.Log("verifying that the protocol annotation takes precedence over the appProtocol")
+		services[0].Annotations = map[string]string{annotations.AnnotationPrefix + annotations.ProtocolKey: "https"}
+		fakeStore, err = store.NewFakeStore(store.FakeObjects{
+			IngressesV1beta1: ingresses,
+			Services:         services,
+		})
+		assert.Nil(err)
+		state, err = NewParser(logrus.New(), fakeStore).Build()
+		assert.Nil(err)
+		for _, service := range state.Services {
+			assert.Equal("https", *service.Protocol)
+		}
+	})
+
 	t.Run("host-header annotation is correctly processed", func(t *testing.T) {
 		ingresses := []*networkingv1beta1.Ingress{
 			{
@@ -4171,11 +4385,119 @@ func TestGetEndpoints(t *testing.T) {
 				},
 			},
 		},
+		{
+			"should not return not ready Addresses when the service does not publish them",
+			&corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Type:                     corev1.ServiceTypeClusterIP,
+					ClusterIP:                "1.1.1.1",
+					PublishNotReadyAddresses: false,
+					Ports: []corev1.ServicePort{
+						{
+							Name:       "default",
+							TargetPort: intstr.FromInt(80),
+						},
+					},
+				},
+			},
+			&corev1.ServicePort{
+				Name:       "default",
+				TargetPort: intstr.FromInt(80),
+			},
+			corev1.ProtocolTCP,
+			func(string, string) (*corev1.Endpoints, error) {
+				return &corev1.Endpoints{
+					Subsets: []corev1.EndpointSubset{
+						{
+							Addresses: []corev1.EndpointAddress{
+								{
+									IP: "1.1.1.1",
+								},
+							},
+							NotReadyAddresses: []corev1.EndpointAddress{
+								{
+									IP: "2.2.2.2",
+								},
+							},
+							Ports: []corev1.EndpointPort{
+								{
+									Name:     "default",
+									Protocol: corev1.ProtocolTCP,
+									Port:     80,
+								},
+							},
+						},
+					},
+				}, nil
+			},
+			[]util.Endpoint{
+				{
+					Address: "1.1.1.1",
+					Port:    "80",
+				},
+			},
+		},
+		{
+			"should return not ready Addresses when the service publishes them",
+			&corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Type:                     corev1.ServiceTypeClusterIP,
+					ClusterIP:                "1.1.1.1",
+					PublishNotReadyAddresses: true,
+					Ports: []corev1.ServicePort{
+						{
+							Name:       "default",
+							TargetPort: intstr.FromInt(80),
+						},
+					},
+				},
+			},
+			&corev1.ServicePort{
+				Name:       "default",
+				TargetPort: intstr.FromInt(80),
+			},
+			corev1.ProtocolTCP,
+			func(string, string) (*corev1.Endpoints, error) {
+				return &corev1.Endpoints{
+					Subsets: []corev1.EndpointSubset{
+						{
+							Addresses: []corev1.EndpointAddress{
+								{
+									IP: "1.1.1.1",
+								},
+							},
+							NotReadyAddresses: []corev1.EndpointAddress{
+								{
+									IP: "2.2.2.2",
+								},
+							},
+							Ports: []corev1.EndpointPort{
+								{
+									Name:     "default",
+									Protocol: corev1.ProtocolTCP,
+									Port:     80,
+								},
+							},
+						},
+					},
+				}, nil
+			},
+			[]util.Endpoint{
+				{
+					Address: "1.1.1.1",
+					Port:    "80",
+				},
+				{
+					Address: "2.2.2.2",
+					Port:    "80",
+				},
+			},
+		},
 	}
 
 	for _, testCase := range tests {
 		t.Run(testCase.name, func(t *testing.T) {
-			result := getEndpoints(logrus.New(), testCase.svc, testCase.port, testCase.proto, testCase.fn)
+			result := getEndpoints(logrus.New(), testCase.svc, testCase.port, testCase.proto, testCase.fn, nil)
 			if len(testCase.result) != len(result) {
 				t.Errorf("expected %v Endpoints but got %v", testCase.result, len(result))
 			}
@@ -4778,4 +5100,1598 @@ func TestCertificate(t *testing.T) {
 		assert.Equal(1, len(state.Certificates))
 		assert.Equal(state.Certificates[0], fooCertificate)
 	})
+	t.Run("mixed case hosts produce a single lowercase SNI", func(t *testing.T) {
+		ingresses := []*networkingv1beta1.Ingress{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo1",
+					Namespace: "ns1",
+					Annotations: map[string]string{
+						annotations.IngressClassKey: annotations.DefaultIngressClass,
+					},
+				},
+				Spec: networkingv1beta1.IngressSpec{
+					TLS: []networkingv1beta1.IngressTLS{
+						{
+							SecretName: "secret",
+							Hosts:      []string{"Foo.Example.COM", "foo.example.com"},
+						},
+					},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo2",
+					Namespace: "ns1",
+					Annotations: map[string]string{
+						annotations.IngressClassKey: annotations.DefaultIngressClass,
+					},
+				},
+				Spec: networkingv1beta1.IngressSpec{
+					TLS: []networkingv1beta1.IngressTLS{
+						{
+							SecretName: "secret",
+							Hosts:      []string{"FOO.example.com"},
+						},
+					},
+				},
+			},
+		}
+
+		secrets := []*corev1.Secret{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					UID:       types.UID("7428fb98-180b-4702-a91f-61351a33c6e4"),
+					Name:      "secret",
+					Namespace: "ns1",
+				},
+				Data: map[string][]byte{
+					"tls.crt": []byte(tlsPairs[0].Cert),
+					"tls.key": []byte(tlsPairs[0].Key),
+				},
+			},
+		}
+		fooCertificate := kongstate.Certificate{
+			Certificate: kong.Certificate{
+				ID:   kong.String("7428fb98-180b-4702-a91f-61351a33c6e4"),
+				Cert: kong.String(tlsPairs[0].Cert),
+				Key:  kong.String(tlsPairs[0].Key),
+				SNIs: []*string{kong.String("foo.example.com")},
+			},
+		}
+		store, err := store.NewFakeStore(store.FakeObjects{
+			IngressesV1beta1: ingresses,
+			Secrets:          secrets,
+		})
+		assert.Nil(err)
+		p := NewParser(logrus.New(), store)
+		state, err := p.Build()
+		assert.Nil(err)
+		assert.NotNil(state)
+		assert.Equal(1, len(state.Certificates))
+		assert.Equal(state.Certificates[0], fooCertificate)
+	})
+	t.Run("stable certificate IDs don't change across rebuilds of an unchanged secret", func(t *testing.T) {
+		ingresses := []*networkingv1beta1.Ingress{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "ns1",
+					Annotations: map[string]string{
+						annotations.IngressClassKey: annotations.DefaultIngressClass,
+					},
+				},
+				Spec: networkingv1beta1.IngressSpec{
+					TLS: []networkingv1beta1.IngressTLS{
+						{
+							SecretName: "secret",
+							Hosts:      []string{"foo.example.com"},
+						},
+					},
+				},
+			},
+		}
+		buildCertificateID := func(uid types.UID, pair TLSPair) string {
+			secrets := []*corev1.Secret{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						UID:       uid,
+						Name:      "secret",
+						Namespace: "ns1",
+					},
+					Data: map[string][]byte{
+						"tls.crt": []byte(pair.Cert),
+						"tls.key": []byte(pair.Key),
+					},
+				},
+			}
+			store, err := store.NewFakeStore(store.FakeObjects{
+				IngressesV1beta1: ingresses,
+				Secrets:          secrets,
+			})
+			assert.Nil(err)
+			p := NewParser(logrus.New(), store)
+			p.EnableStableCertificateIDs()
+			state, err := p.Build()
+			assert.Nil(err)
+			assert.NotNil(state)
+			assert.Equal(1, len(state.Certificates))
+			return *state.Certificates[0].ID
+		}
+
+		id := buildCertificateID("7428fb98-180b-4702-a91f-61351a33c6e4", tlsPairs[0])
+		assert.NotEqual("7428fb98-180b-4702-a91f-61351a33c6e4", id)
+		_, err := uuid.Parse(id)
+		assert.NoError(err)
+
+		t.Log("verifying that the ID is the same when the parser is rebuilt")
+		assert.Equal(id, buildCertificateID("7428fb98-180b-4702-a91f-61351a33c6e4", tlsPairs[0]))
+
+		t.Log("verifying that the ID is the same when the secret is re-created with the same content")
+		assert.Equal(id, buildCertificateID("8e1a3c2b-180b-4702-a91f-61351a33c6e4", tlsPairs[0]))
+
+		t.Log("verifying that the ID changes when the content of the secret changes")
+		assert.NotEqual(id, buildCertificateID("7428fb98-180b-4702-a91f-61351a33c6e4", tlsPairs[1]))
+	})
+}
+
+func TestUpstreamFallbackService(t *testing.T) {
+	ingresses := []*networkingv1beta1.Ingress{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+				Annotations: map[string]string{
+					annotations.IngressClassKey: annotations.DefaultIngressClass,
+				},
+			},
+			Spec: networkingv1beta1.IngressSpec{
+				Backend: &networkingv1beta1.IngressBackend{
+					ServiceName: "foo-svc",
+					ServicePort: intstr.FromInt(80),
+				},
+			},
+		},
+	}
+	services := []*corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-svc",
+				Namespace: "default",
+				Annotations: map[string]string{
+					"konghq.com/upstream-fallback-service": "maintenance-svc:http",
+				},
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "maintenance-svc",
+				Namespace: "default",
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Name: "http", Port: 8080}},
+			},
+		},
+	}
+	endpoints := []*corev1.Endpoints{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-svc",
+				Namespace: "default",
+			},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}},
+				Ports:     []corev1.EndpointPort{{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP}},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "maintenance-svc",
+				Namespace: "default",
+			},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: "10.0.1.1"}},
+				Ports:     []corev1.EndpointPort{{Name: "http", Port: 8080, Protocol: corev1.ProtocolTCP}},
+			}},
+		},
+	}
+
+	t.Log("building the configuration for an Ingress whose backend Service has a fallback Service")
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		IngressesV1beta1: ingresses,
+		Services:         services,
+		Endpoints:        endpoints,
+	})
+	assert.NoError(t, err)
+	p := NewParser(logrus.New(), fakeStore)
+	state, err := p.Build()
+	assert.NoError(t, err)
+	assert.Len(t, state.Upstreams, 1)
+
+	t.Log("verifying that the fallback targets are appended to the primary targets with a low weight")
+	targets := state.Upstreams[0].Targets
+	assert.Len(t, targets, 3)
+	assert.Equal(t, "10.0.0.1:80", *targets[0].Target.Target)
+	assert.Nil(t, targets[0].Weight)
+	assert.Equal(t, "10.0.0.2:80", *targets[1].Target.Target)
+	assert.Nil(t, targets[1].Weight)
+	assert.Equal(t, "10.0.1.1:8080", *targets[2].Target.Target)
+	assert.Equal(t, kong.Int(FallbackTargetWeight), targets[2].Weight)
+
+	t.Log("verifying that the fallback targets keep their low weight when no primary endpoint is ready")
+	fakeStore, err = store.NewFakeStore(store.FakeObjects{
+		IngressesV1beta1: ingresses,
+		Services:         services,
+		Endpoints:        endpoints[1:],
+	})
+	assert.NoError(t, err)
+	state, err = NewParser(logrus.New(), fakeStore).Build()
+	assert.NoError(t, err)
+	assert.Len(t, state.Upstreams, 1)
+	targets = state.Upstreams[0].Targets
+	assert.Len(t, targets, 1)
+	assert.Equal(t, "10.0.1.1:8080", *targets[0].Target.Target)
+	assert.Equal(t, kong.Int(FallbackTargetWeight), targets[0].Weight)
+
+	t.Log("verifying that a missing fallback Service leaves the primary targets untouched")
+	services[0].Annotations["konghq.com/upstream-fallback-service"] = "missing-svc"
+	fakeStore, err = store.NewFakeStore(store.FakeObjects{
+		IngressesV1beta1: ingresses,
+		Services:         services,
+		Endpoints:        endpoints,
+	})
+	assert.NoError(t, err)
+	state, err = NewParser(logrus.New(), fakeStore).Build()
+	assert.NoError(t, err)
+	assert.Len(t, state.Upstreams, 1)
+	assert.Len(t, state.Upstreams[0].Targets, 2)
+}
+
+func TestUpstreamWeights(t *testing.T) {
+	ingress := func(weights string) []*networkingv1beta1.Ingress {
+		anns := map[string]string{annotations.IngressClassKey: annotations.DefaultIngressClass}
+		if weights != "" {
+			anns["konghq.com/upstream-weight"] = weights
+		}
+		return []*networkingv1beta1.Ingress{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "foo",
+				Namespace:   "default",
+				Annotations: anns,
+			},
+			Spec: networkingv1beta1.IngressSpec{
+				Backend: &networkingv1beta1.IngressBackend{
+					ServiceName: "app",
+					ServicePort: intstr.FromInt(80),
+				},
+			},
+		}}
+	}
+	services := []*corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-canary", Namespace: "default"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}},
+		},
+	}
+	endpoints := []*corev1.Endpoints{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}},
+				Ports:     []corev1.EndpointPort{{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP}},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-canary", Namespace: "default"},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: "10.0.1.1"}},
+				Ports:     []corev1.EndpointPort{{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP}},
+			}},
+		},
+	}
+	buildTargets := func(t *testing.T, weights string) []kongstate.Target {
+		fakeStore, err := store.NewFakeStore(store.FakeObjects{
+			IngressesV1beta1: ingress(weights),
+			Services:         services,
+			Endpoints:        endpoints,
+		})
+		require.NoError(t, err)
+		state, err := NewParser(logrus.New(), fakeStore).Build()
+		require.NoError(t, err)
+		require.Len(t, state.Upstreams, 1)
+		return state.Upstreams[0].Targets
+	}
+
+	t.Run("targets carry the weights of their services", func(t *testing.T) {
+		targets := buildTargets(t, "app=90,app-canary=10")
+		require.Len(t, targets, 3)
+		assert.Equal(t, "10.0.0.1:80", *targets[0].Target.Target)
+		assert.Equal(t, kong.Int(90), targets[0].Weight)
+		assert.Equal(t, "10.0.0.2:80", *targets[1].Target.Target)
+		assert.Equal(t, kong.Int(90), targets[1].Weight)
+		assert.Equal(t, "10.0.1.1:80", *targets[2].Target.Target)
+		assert.Equal(t, kong.Int(10), targets[2].Weight)
+	})
+
+	t.Run("services with a missing or zero weight keep the default weight", func(t *testing.T) {
+		for _, weights := range []string{"app-canary=10", "app=0,app-canary=10"} {
+			targets := buildTargets(t, weights)
+			require.Len(t, targets, 3)
+			assert.Nil(t, targets[0].Weight)
+			assert.Nil(t, targets[1].Weight)
+			assert.Equal(t, kong.Int(10), targets[2].Weight)
+		}
+	})
+
+	t.Run("targets are unchanged without the annotation", func(t *testing.T) {
+		targets := buildTargets(t, "")
+		require.Len(t, targets, 2)
+		assert.Nil(t, targets[0].Weight)
+		assert.Nil(t, targets[1].Weight)
+	})
+}
+
+func TestFallbackService(t *testing.T) {
+	ingresses := []*networkingv1beta1.Ingress{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+				Annotations: map[string]string{
+					annotations.IngressClassKey:   annotations.DefaultIngressClass,
+					"konghq.com/fallback-service": "maintenance-svc:8080",
+				},
+			},
+			Spec: networkingv1beta1.IngressSpec{
+				Backend: &networkingv1beta1.IngressBackend{
+					ServiceName: "foo-svc",
+					ServicePort: intstr.FromInt(80),
+				},
+			},
+		},
+	}
+	primary := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo-svc",
+			Namespace: "default",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+		},
+	}
+	fallback := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "maintenance-svc",
+			Namespace: "default",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Port: 8080}},
+		},
+	}
+	fallbackEndpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "maintenance-svc",
+			Namespace: "default",
+		},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "10.0.1.1"}},
+			Ports:     []corev1.EndpointPort{{Name: "http", Port: 8080, Protocol: corev1.ProtocolTCP}},
+		}},
+	}
+	primaryEndpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo-svc",
+			Namespace: "default",
+		},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+			Ports:     []corev1.EndpointPort{{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP}},
+		}},
+	}
+
+	for _, tt := range []struct {
+		name            string
+		services        []*corev1.Service
+		endpoints       []*corev1.Endpoints
+		expectedTargets []string
+	}{
+		{
+			name:            "primary service has endpoints",
+			services:        []*corev1.Service{primary, fallback},
+			endpoints:       []*corev1.Endpoints{primaryEndpoints, fallbackEndpoints},
+			expectedTargets: []string{"10.0.0.1:80"},
+		},
+		{
+			name:            "primary service has no endpoints",
+			services:        []*corev1.Service{primary, fallback},
+			endpoints:       []*corev1.Endpoints{fallbackEndpoints},
+			expectedTargets: []string{"10.0.1.1:8080"},
+		},
+		{
+			name:            "primary service is missing",
+			services:        []*corev1.Service{fallback},
+			endpoints:       []*corev1.Endpoints{fallbackEndpoints},
+			expectedTargets: []string{"10.0.1.1:8080"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStore, err := store.NewFakeStore(store.FakeObjects{
+				IngressesV1beta1: ingresses,
+				Services:         tt.services,
+				Endpoints:        tt.endpoints,
+			})
+			assert.NoError(t, err)
+			state, err := NewParser(logrus.New(), fakeStore).Build()
+			assert.NoError(t, err)
+			assert.Len(t, state.Upstreams, 1)
+
+			var targets []string
+			for _, target := range state.Upstreams[0].Targets {
+				targets = append(targets, *target.Target.Target)
+			}
+			assert.Equal(t, tt.expectedTargets, targets)
+		})
+	}
+}
+
+func TestGetFallbackTargets(t *testing.T) {
+	fallbackService := func(name string, port int32) (*corev1.Service, *corev1.Endpoints) {
+		meta := metav1.ObjectMeta{Name: name, Namespace: "default"}
+		return &corev1.Service{ObjectMeta: meta, Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: port}}}},
+			&corev1.Endpoints{ObjectMeta: meta, Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: "10.0.1.1"}},
+				Ports:     []corev1.EndpointPort{{Name: "http", Port: port, Protocol: corev1.ProtocolTCP}},
+			}}}
+	}
+	serviceFallback, serviceFallbackEndpoints := fallbackService("service-fallback", 8080)
+	ingressFallback, ingressFallbackEndpoints := fallbackService("ingress-fallback", 9090)
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		Services:  []*corev1.Service{serviceFallback, ingressFallback},
+		Endpoints: []*corev1.Endpoints{serviceFallbackEndpoints, ingressFallbackEndpoints},
+	})
+	require.NoError(t, err)
+
+	service := func(serviceAnnotation string, routeAnnotations ...string) kongstate.Service {
+		svc := kongstate.Service{Backend: kongstate.ServiceBackend{Name: "foo-svc"}, Namespace: "default"}
+		if serviceAnnotation != "" {
+			svc.K8sService.Annotations = map[string]string{"konghq.com/upstream-fallback-service": serviceAnnotation}
+		}
+		for _, ann := range routeAnnotations {
+			svc.Routes = append(svc.Routes, kongstate.Route{Ingress: util.K8sObjectInfo{
+				Namespace:   "default",
+				Annotations: map[string]string{"konghq.com/fallback-service": ann},
+			}})
+		}
+		return svc
+	}
+	targetsOf := func(targets []kongstate.Target) []string {
+		var addrs []string
+		for _, target := range targets {
+			addrs = append(addrs, *target.Target.Target)
+		}
+		return addrs
+	}
+
+	t.Log("verifying that the upstream fallback Service of the backend Service is left to appendFallbackTargets")
+	assert.Equal(t, []string{"10.0.1.1:9090"},
+		targetsOf(getFallbackTargets(logrus.New(), fakeStore, service("service-fallback", "ingress-fallback"))))
+	assert.Empty(t, getFallbackTargets(logrus.New(), fakeStore, service("service-fallback")))
+
+	t.Log("verifying that fallback Services which can't be resolved are skipped")
+	assert.Equal(t, []string{"10.0.1.1:9090"},
+		targetsOf(getFallbackTargets(logrus.New(), fakeStore, service("missing", "missing", "ingress-fallback:https", "ingress-fallback"))))
+
+	t.Log("verifying that no targets are returned without a fallback Service")
+	assert.Empty(t, getFallbackTargets(logrus.New(), fakeStore, service("")))
+}
+
+func TestDropRoutesWithoutTargets(t *testing.T) {
+	ingresses := []*networkingv1beta1.Ingress{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+				Annotations: map[string]string{
+					annotations.IngressClassKey: annotations.DefaultIngressClass,
+				},
+			},
+			Spec: networkingv1beta1.IngressSpec{
+				Backend: &networkingv1beta1.IngressBackend{
+					ServiceName: "foo-svc",
+					ServicePort: intstr.FromInt(80),
+				},
+			},
+		},
+	}
+	services := []*corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-svc",
+				Namespace: "default",
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+			},
+		},
+	}
+	endpoints := []*corev1.Endpoints{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-svc",
+				Namespace: "default",
+			},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+				Ports:     []corev1.EndpointPort{{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP}},
+			}},
+		},
+	}
+
+	for _, tt := range []struct {
+		name           string
+		endpoints      []*corev1.Endpoints
+		dropRoutes     bool
+		expectedRoutes int
+	}{
+		{
+			name:           "routes to a service without targets are kept by default",
+			dropRoutes:     false,
+			expectedRoutes: 1,
+		},
+		{
+			name:           "routes to a service without targets are dropped when enabled",
+			dropRoutes:     true,
+			expectedRoutes: 0,
+		},
+		{
+			name:           "routes to a service with targets are kept when enabled",
+			endpoints:      endpoints,
+			dropRoutes:     true,
+			expectedRoutes: 1,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStore, err := store.NewFakeStore(store.FakeObjects{
+				IngressesV1beta1: ingresses,
+				Services:         services,
+				Endpoints:        tt.endpoints,
+			})
+			assert.NoError(t, err)
+			p := NewParser(logrus.New(), fakeStore)
+			if tt.dropRoutes {
+				p.EnableDropRoutesWithoutTargets()
+			}
+			state, err := p.Build()
+			assert.NoError(t, err)
+			assert.Len(t, state.Services, 1)
+			assert.Len(t, state.Services[0].Routes, tt.expectedRoutes)
+		})
+	}
+}
+
+func TestMaxPathsPerRoute(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	ingresses := []*networkingv1.Ingress{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+				Annotations: map[string]string{
+					annotations.IngressClassKey: annotations.DefaultIngressClass,
+				},
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					Host: "example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{{
+								Path:     "/foo",
+								PathType: &prefix,
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{
+										Name: "foo-svc",
+										Port: networkingv1.ServiceBackendPort{Number: 80},
+									},
+								},
+							}},
+						},
+					},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		name          string
+		maxPaths      int
+		expectedPaths map[string][]string
+	}{
+		{
+			name:     "paths are not limited by default",
+			maxPaths: 0,
+			expectedPaths: map[string][]string{
+				"default.foo.00": {"/foo$", "/foo/"},
+			},
+		},
+		{
+			name:     "routes within the limit are not split",
+			maxPaths: 2,
+			expectedPaths: map[string][]string{
+				"default.foo.00": {"/foo$", "/foo/"},
+			},
+		},
+		{
+			name:     "routes exceeding the limit are split",
+			maxPaths: 1,
+			expectedPaths: map[string][]string{
+				"default.foo.00":   {"/foo$"},
+				"default.foo.00.1": {"/foo/"},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStore, err := store.NewFakeStore(store.FakeObjects{IngressesV1: ingresses})
+			assert.NoError(t, err)
+			p := NewParser(logrus.New(), fakeStore)
+			p.SetMaxPathsPerRoute(tt.maxPaths)
+			state, err := p.Build()
+			assert.NoError(t, err)
+			assert.Len(t, state.Services, 1)
+
+			paths := make(map[string][]string)
+			for _, route := range state.Services[0].Routes {
+				assert.Equal(t, kong.StringSlice("example.com"), route.Hosts)
+				for _, path := range route.Paths {
+					paths[*route.Name] = append(paths[*route.Name], *path)
+				}
+			}
+			assert.Equal(t, tt.expectedPaths, paths)
+		})
+	}
+}
+
+func TestSplitRoutesByPathCount(t *testing.T) {
+	plugins := []kong.Plugin{{Name: kong.String("key-auth")}}
+	routes := []kongstate.Route{
+		{
+			Route: kong.Route{
+				Name:  kong.String("default.foo.00"),
+				Paths: kong.StringSlice("/a", "/b", "/c", "/d", "/e"),
+			},
+			Plugins: plugins,
+		},
+		{
+			Route: kong.Route{
+				Name:  kong.String("default.bar.00"),
+				Paths: kong.StringSlice("/bar"),
+			},
+		},
+	}
+
+	split := splitRoutesByPathCount(routes, 2)
+	assert.Len(t, split, 4)
+	for i, expected := range []struct {
+		name  string
+		paths []*string
+	}{
+		{"default.foo.00", kong.StringSlice("/a", "/b")},
+		{"default.foo.00.1", kong.StringSlice("/c", "/d")},
+		{"default.foo.00.2", kong.StringSlice("/e")},
+		{"default.bar.00", kong.StringSlice("/bar")},
+	} {
+		assert.Equal(t, expected.name, *split[i].Name)
+		assert.Equal(t, expected.paths, split[i].Paths)
+	}
+	for _, route := range split[:3] {
+		assert.Equal(t, plugins, route.Plugins)
+	}
+	assert.Equal(t, "default.foo.00", *routes[0].Name, "the original route must not be modified")
+}
+
+func TestSetEntityTags(t *testing.T) {
+	ingresses := []*networkingv1beta1.Ingress{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+				Annotations: map[string]string{
+					annotations.IngressClassKey: annotations.DefaultIngressClass,
+				},
+			},
+			Spec: networkingv1beta1.IngressSpec{
+				TLS: []networkingv1beta1.IngressTLS{{
+					SecretName: "secret",
+					Hosts:      []string{"example.com"},
+				}},
+				Backend: &networkingv1beta1.IngressBackend{
+					ServiceName: "foo-svc",
+					ServicePort: intstr.FromInt(80),
+				},
+			},
+		},
+	}
+	services := []*corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-svc",
+				Namespace: "default",
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+			},
+		},
+	}
+	endpoints := []*corev1.Endpoints{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-svc",
+				Namespace: "default",
+			},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+				Ports:     []corev1.EndpointPort{{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP}},
+			}},
+		},
+	}
+	secrets := []*corev1.Secret{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				UID:       types.UID("7428fb98-180b-4702-a91f-61351a33c6e4"),
+				Name:      "secret",
+				Namespace: "default",
+			},
+			Data: map[string][]byte{
+				"tls.crt": []byte(tlsPairs[0].Cert),
+				"tls.key": []byte(tlsPairs[0].Key),
+			},
+		},
+	}
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		IngressesV1beta1: ingresses,
+		Services:         services,
+		Endpoints:        endpoints,
+		Secrets:          secrets,
+	})
+	assert.NoError(t, err)
+
+	t.Log("verifying that entities are not tagged by default")
+	state, err := NewParser(logrus.New(), fakeStore).Build()
+	assert.NoError(t, err)
+	assert.Len(t, state.Services, 1)
+	assert.Empty(t, state.Services[0].Tags)
+
+	t.Log("verifying that the configured tags appear on the generated entities")
+	p := NewParser(logrus.New(), fakeStore)
+	p.SetEntityTags([]string{"ingress-class:kong"})
+	state, err = p.Build()
+	assert.NoError(t, err)
+	expectedTags := kong.StringSlice("ingress-class:kong")
+	assert.Len(t, state.Services, 1)
+	assert.Equal(t, expectedTags, state.Services[0].Tags)
+	assert.Len(t, state.Services[0].Routes, 1)
+	assert.Equal(t, expectedTags, state.Services[0].Routes[0].Tags)
+	assert.Len(t, state.Upstreams, 1)
+	assert.Equal(t, expectedTags, state.Upstreams[0].Tags)
+	assert.Len(t, state.Upstreams[0].Targets, 1)
+	assert.Equal(t, expectedTags, state.Upstreams[0].Targets[0].Tags)
+	assert.Len(t, state.Certificates, 1)
+	assert.Equal(t, expectedTags, state.Certificates[0].Tags)
+}
+
+func TestSetDefaultServiceTimeouts(t *testing.T) {
+	ingress := func(anns map[string]string) *networkingv1beta1.Ingress {
+		anns[annotations.IngressClassKey] = annotations.DefaultIngressClass
+		return &networkingv1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "foo",
+				Namespace:   "default",
+				Annotations: anns,
+			},
+			Spec: networkingv1beta1.IngressSpec{
+				Backend: &networkingv1beta1.IngressBackend{
+					ServiceName: "foo-svc",
+					ServicePort: intstr.FromInt(80),
+				},
+			},
+		}
+	}
+	services := []*corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-svc",
+				Namespace: "default",
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+			},
+		},
+	}
+	build := func(t *testing.T, anns map[string]string, timeouts *ServiceTimeouts) kong.Service {
+		fakeStore, err := store.NewFakeStore(store.FakeObjects{
+			IngressesV1beta1: []*networkingv1beta1.Ingress{ingress(anns)},
+			Services:         services,
+		})
+		require.NoError(t, err)
+		p := NewParser(logrus.New(), fakeStore)
+		if timeouts != nil {
+			p.SetDefaultServiceTimeouts(*timeouts)
+		}
+		state, err := p.Build()
+		require.NoError(t, err)
+		require.Len(t, state.Services, 1)
+		return state.Services[0].Service
+	}
+
+	t.Log("verifying that services use the default timeouts when no class defaults are configured")
+	service := build(t, map[string]string{}, nil)
+	assert.Equal(t, kong.Int(DefaultServiceTimeout), service.ConnectTimeout)
+	assert.Equal(t, kong.Int(DefaultServiceTimeout), service.ReadTimeout)
+	assert.Equal(t, kong.Int(DefaultServiceTimeout), service.WriteTimeout)
+
+	t.Log("verifying that the class default timeouts apply to generated services")
+	classDefaults, err := ParseServiceTimeouts("1000,,2000")
+	require.NoError(t, err)
+	service = build(t, map[string]string{}, &classDefaults)
+	assert.Equal(t, kong.Int(1000), service.ConnectTimeout)
+	assert.Equal(t, kong.Int(DefaultServiceTimeout), service.ReadTimeout)
+	assert.Equal(t, kong.Int(2000), service.WriteTimeout)
+
+	t.Log("verifying that the timeout annotations of an Ingress override the class default timeouts")
+	service = build(t, map[string]string{
+		"konghq.com/connect-timeout": "500",
+		"konghq.com/read-timeout":    "300000",
+	}, &classDefaults)
+	assert.Equal(t, kong.Int(500), service.ConnectTimeout)
+	assert.Equal(t, kong.Int(300000), service.ReadTimeout)
+	assert.Equal(t, kong.Int(2000), service.WriteTimeout)
+}
+
+func TestParseServiceTimeouts(t *testing.T) {
+	timeouts, err := ParseServiceTimeouts("1000, 300000, 5000")
+	require.NoError(t, err)
+	assert.Equal(t, ServiceTimeouts{Connect: 1000, Read: 300000, Write: 5000}, timeouts)
+
+	timeouts, err = ParseServiceTimeouts(",300000,")
+	require.NoError(t, err)
+	assert.Equal(t, ServiceTimeouts{Read: 300000}, timeouts)
+
+	for _, invalid := range []string{"", "1000", "1000,2000", "1000,2000,3000,4000", "1000,fast,3000", "0,1,1"} {
+		_, err = ParseServiceTimeouts(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestMalformedTimeoutAnnotations(t *testing.T) {
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		IngressesV1beta1: []*networkingv1beta1.Ingress{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+				Annotations: map[string]string{
+					annotations.IngressClassKey:  annotations.DefaultIngressClass,
+					"konghq.com/connect-timeout": "fast",
+					"konghq.com/read-timeout":    "300000",
+				},
+			},
+			Spec: networkingv1beta1.IngressSpec{
+				Backend: &networkingv1beta1.IngressBackend{
+					ServiceName: "foo-svc",
+					ServicePort: intstr.FromInt(80),
+				},
+			},
+		}},
+		Services: []*corev1.Service{{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo-svc", Namespace: "default"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}},
+		}},
+	})
+	require.NoError(t, err)
+
+	recorder := record.NewFakeRecorder(10)
+	p := NewParser(logrus.New(), fakeStore)
+	p.SetEventRecorder(recorder)
+	state, err := p.Build()
+	require.NoError(t, err)
+	require.Len(t, state.Services, 1)
+
+	t.Log("verifying that the malformed annotation is ignored and the valid one applied")
+	assert.Equal(t, kong.Int(DefaultServiceTimeout), state.Services[0].ConnectTimeout)
+	assert.Equal(t, kong.Int(300000), state.Services[0].ReadTimeout)
+
+	t.Log("verifying that an event referencing the annotation is recorded on the Ingress")
+	require.Len(t, recorder.Events, 1)
+	event := <-recorder.Events
+	assert.Contains(t, event, InvalidAnnotationReason)
+	assert.Contains(t, event, "konghq.com/connect-timeout")
+	assert.Contains(t, event, "default/foo")
+}
+
+func TestSNIStrictness(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	rule := func(host string) networkingv1.IngressRule {
+		return networkingv1.IngressRule{
+			Host: host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     "/",
+						PathType: &prefix,
+						Backend: networkingv1.IngressBackend{
+							Service: &networkingv1.IngressServiceBackend{
+								Name: "foo-svc",
+								Port: networkingv1.ServiceBackendPort{Number: 80},
+							},
+						},
+					}},
+				},
+			},
+		}
+	}
+	objects := store.FakeObjects{
+		IngressesV1: []*networkingv1.Ingress{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+				Annotations: map[string]string{
+					annotations.IngressClassKey:                             annotations.DefaultIngressClass,
+					annotations.AnnotationPrefix + annotations.ProtocolsKey: "https",
+				},
+			},
+			Spec: networkingv1.IngressSpec{
+				TLS:   []networkingv1.IngressTLS{{SecretName: "secret1", Hosts: []string{"covered.example.com"}}},
+				Rules: []networkingv1.IngressRule{rule("covered.example.com"), rule("uncovered.example.com")},
+			},
+		}},
+		Secrets: []*corev1.Secret{{
+			ObjectMeta: metav1.ObjectMeta{
+				UID:       types.UID("7428fb98-180b-4702-a91f-61351a33c6e4"),
+				Name:      "secret1",
+				Namespace: "default",
+			},
+			Data: map[string][]byte{
+				"tls.crt": []byte(tlsPairs[0].Cert),
+				"tls.key": []byte(tlsPairs[0].Key),
+			},
+		}},
+	}
+	routeHosts := func(state *kongstate.KongState) []string {
+		var hosts []string
+		for _, service := range state.Services {
+			for _, route := range service.Routes {
+				for _, host := range route.Hosts {
+					hosts = append(hosts, *host)
+				}
+			}
+		}
+		sort.Strings(hosts)
+		return hosts
+	}
+
+	t.Run("lenient mode keeps HTTPS routes with uncovered hosts", func(t *testing.T) {
+		fakeStore, err := store.NewFakeStore(objects)
+		require.NoError(t, err)
+		recorder := record.NewFakeRecorder(10)
+		p := NewParser(logrus.New(), fakeStore)
+		p.SetSNIStrictness(SNIStrictnessLenient)
+		p.SetEventRecorder(recorder)
+		state, err := p.Build()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"covered.example.com", "uncovered.example.com"}, routeHosts(state))
+		assert.Empty(t, recorder.Events)
+	})
+
+	t.Run("strict mode rejects HTTPS routes with uncovered hosts", func(t *testing.T) {
+		fakeStore, err := store.NewFakeStore(objects)
+		require.NoError(t, err)
+		recorder := record.NewFakeRecorder(10)
+		p := NewParser(logrus.New(), fakeStore)
+		p.SetSNIStrictness(SNIStrictnessStrict)
+		p.SetEventRecorder(recorder)
+		state, err := p.Build()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"covered.example.com"}, routeHosts(state))
+		require.Len(t, recorder.Events, 1)
+		event := <-recorder.Events
+		assert.Contains(t, event, UncoveredSNIReason)
+		assert.Contains(t, event, "uncovered.example.com")
+	})
+}
+
+func TestParseSNIStrictness(t *testing.T) {
+	strictness, err := ParseSNIStrictness("strict")
+	assert.NoError(t, err)
+	assert.Equal(t, SNIStrictnessStrict, strictness)
+	strictness, err = ParseSNIStrictness("lenient")
+	assert.NoError(t, err)
+	assert.Equal(t, SNIStrictnessLenient, strictness)
+	_, err = ParseSNIStrictness("loose")
+	assert.Error(t, err)
+}
+
+func TestTLSMinVersion(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	ingress := func(minVersion string) *networkingv1.Ingress {
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+				Annotations: map[string]string{
+					annotations.IngressClassKey:                                 annotations.DefaultIngressClass,
+					annotations.AnnotationPrefix + annotations.TLSMinVersionKey: minVersion,
+				},
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					Host: "example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{{
+								Path:     "/",
+								PathType: &prefix,
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{
+										Name: "foo-svc",
+										Port: networkingv1.ServiceBackendPort{Number: 80},
+									},
+								},
+							}},
+						},
+					},
+				}},
+			},
+		}
+	}
+	build := func(minVersion string, proxyProtocols ...string) (int, []string) {
+		fakeStore, err := store.NewFakeStore(store.FakeObjects{IngressesV1: []*networkingv1.Ingress{ingress(minVersion)}})
+		require.NoError(t, err)
+		recorder := record.NewFakeRecorder(10)
+		p := NewParser(logrus.New(), fakeStore)
+		p.SetEventRecorder(recorder)
+		p.SetProxyTLSProtocols(proxyProtocols)
+		state, err := p.Build()
+		require.NoError(t, err)
+
+		routes := 0
+		for _, service := range state.Services {
+			routes += len(service.Routes)
+		}
+		close(recorder.Events)
+		var events []string
+		for event := range recorder.Events {
+			events = append(events, event)
+		}
+		return routes, events
+	}
+
+	t.Log("verifying that a minimum satisfied by the proxy's TLS protocols is kept")
+	routes, events := build("1.2", "TLSv1.2", "TLSv1.3")
+	assert.Equal(t, 1, routes)
+	assert.Empty(t, events)
+
+	t.Log("verifying that a minimum above the proxy's lowest TLS protocol is rejected")
+	routes, events = build("TLSv1.3", "TLSv1.2", "TLSv1.3")
+	assert.Equal(t, 0, routes)
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0], TLSMinVersionUnsupportedReason)
+	assert.Contains(t, events[0], "TLSv1.3")
+
+	t.Log("verifying that an unknown minimum is rejected")
+	routes, events = build("SSLv3", "TLSv1.2", "TLSv1.3")
+	assert.Equal(t, 0, routes)
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0], TLSMinVersionUnsupportedReason)
+
+	t.Log("verifying that a minimum is rejected when the proxy's TLS protocols are unknown")
+	routes, events = build("TLSv1.2")
+	assert.Equal(t, 0, routes)
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0], TLSMinVersionUnsupportedReason)
+}
+
+func TestFailurePolicy(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	objects := store.FakeObjects{
+		IngressesV1: []*networkingv1.Ingress{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+				Annotations: map[string]string{
+					annotations.IngressClassKey:                           annotations.DefaultIngressClass,
+					annotations.AnnotationPrefix + annotations.PluginsKey: "valid-plugin,dangling-plugin",
+				},
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					Host: "example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{{
+								Path:     "/",
+								PathType: &prefix,
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{
+										Name: "foo-svc",
+										Port: networkingv1.ServiceBackendPort{Number: 80},
+									},
+								},
+							}},
+						},
+					},
+				}},
+			},
+		}},
+		KongPlugins: []*configurationv1.KongPlugin{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "valid-plugin",
+				Namespace: "default",
+			},
+			PluginName: "key-auth",
+		}},
+	}
+
+	t.Run("skip-broken skips the dangling plugin and records an event", func(t *testing.T) {
+		fakeStore, err := store.NewFakeStore(objects)
+		require.NoError(t, err)
+		recorder := record.NewFakeRecorder(10)
+		p := NewParser(logrus.New(), fakeStore)
+		p.SetFailurePolicy(FailurePolicySkipBroken)
+		p.SetEventRecorder(recorder)
+		state, err := p.Build()
+		require.NoError(t, err)
+		require.Len(t, state.Services, 1)
+		require.Len(t, state.Plugins, 1)
+		assert.Equal(t, "key-auth", *state.Plugins[0].Name)
+		require.Len(t, recorder.Events, 1)
+		event := <-recorder.Events
+		assert.Contains(t, event, PluginReferenceBrokenReason)
+		assert.Contains(t, event, "dangling-plugin")
+	})
+
+	t.Run("fail-all fails the translation", func(t *testing.T) {
+		fakeStore, err := store.NewFakeStore(objects)
+		require.NoError(t, err)
+		recorder := record.NewFakeRecorder(10)
+		p := NewParser(logrus.New(), fakeStore)
+		p.SetFailurePolicy(FailurePolicyFailAll)
+		p.SetEventRecorder(recorder)
+		state, err := p.Build()
+		require.Error(t, err)
+		assert.Nil(t, state)
+		assert.Contains(t, err.Error(), "dangling-plugin")
+		assert.NotContains(t, err.Error(), "valid-plugin")
+		assert.Empty(t, recorder.Events)
+	})
+}
+
+func TestParseFailurePolicy(t *testing.T) {
+	policy, err := ParseFailurePolicy("skip-broken")
+	assert.NoError(t, err)
+	assert.Equal(t, FailurePolicySkipBroken, policy)
+	policy, err = ParseFailurePolicy("fail-all")
+	assert.NoError(t, err)
+	assert.Equal(t, FailurePolicyFailAll, policy)
+	_, err = ParseFailurePolicy("ignore")
+	assert.Error(t, err)
+}
+
+func TestPluginScopeConflictWarnings(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		IngressesV1: []*networkingv1.Ingress{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+				Annotations: map[string]string{
+					annotations.IngressClassKey:                           annotations.DefaultIngressClass,
+					annotations.AnnotationPrefix + annotations.PluginsKey: "route-rate-limit",
+				},
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					Host: "example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{{
+								Path:     "/",
+								PathType: &prefix,
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{
+										Name: "foo-svc",
+										Port: networkingv1.ServiceBackendPort{Number: 80},
+									},
+								},
+							}},
+						},
+					},
+				}},
+			},
+		}},
+		KongConsumers: []*configurationv1.KongConsumer{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "gold",
+				Namespace: "default",
+				Annotations: map[string]string{
+					annotations.IngressClassKey:                           annotations.DefaultIngressClass,
+					annotations.AnnotationPrefix + annotations.PluginsKey: "consumer-rate-limit",
+				},
+			},
+			Username: "gold",
+		}},
+		KongPlugins: []*configurationv1.KongPlugin{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "route-rate-limit", Namespace: "default"},
+				PluginName: "rate-limiting",
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "consumer-rate-limit", Namespace: "default"},
+				PluginName: "rate-limiting",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	recorder := record.NewFakeRecorder(10)
+	p := NewParser(logrus.New(), fakeStore)
+	p.SetEventRecorder(recorder)
+	state, err := p.Build()
+	require.NoError(t, err)
+	assert.Len(t, state.Plugins, 2)
+	require.Len(t, recorder.Events, 1)
+	event := <-recorder.Events
+	assert.Contains(t, event, PluginScopeConflictReason)
+	assert.Contains(t, event, "consumer gold")
+}
+
+func TestStreamingPluginsDisableBuffering(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	ingress := func(name, backend string, anns map[string]string) *networkingv1.Ingress {
+		anns[annotations.IngressClassKey] = annotations.DefaultIngressClass
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: anns},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					Host: name + ".example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{{
+								Path:     "/",
+								PathType: &prefix,
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{
+										Name: backend,
+										Port: networkingv1.ServiceBackendPort{Number: 80},
+									},
+								},
+							}},
+						},
+					},
+				}},
+			},
+		}
+	}
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		IngressesV1: []*networkingv1.Ingress{
+			ingress("streamed", "foo-svc", map[string]string{annotations.AnnotationPrefix + annotations.PluginsKey: "streamer"}),
+			ingress("buffered", "foo-svc", map[string]string{annotations.AnnotationPrefix + annotations.PluginsKey: "limiter"}),
+			ingress("service-streamed", "bar-svc", map[string]string{}),
+		},
+		Services: []*corev1.Service{
+			{ObjectMeta: metav1.ObjectMeta{Name: "foo-svc", Namespace: "default"}},
+			{ObjectMeta: metav1.ObjectMeta{
+				Name:        "bar-svc",
+				Namespace:   "default",
+				Annotations: map[string]string{annotations.AnnotationPrefix + annotations.PluginsKey: "streamer"},
+			}},
+		},
+		KongPlugins: []*configurationv1.KongPlugin{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "streamer", Namespace: "default"},
+				PluginName: "body-streamer",
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "limiter", Namespace: "default"},
+				PluginName: "rate-limiting",
+			},
+		},
+	})
+	require.NoError(t, err)
+	build := func(streamingPlugins ...string) (map[string]kongstate.Route, []string) {
+		recorder := record.NewFakeRecorder(10)
+		p := NewParser(logrus.New(), fakeStore)
+		p.SetEventRecorder(recorder)
+		p.SetStreamingPlugins(streamingPlugins)
+		state, err := p.Build()
+		require.NoError(t, err)
+
+		routes := map[string]kongstate.Route{}
+		for _, service := range state.Services {
+			for _, route := range service.Routes {
+				routes[route.Ingress.Name] = route
+			}
+		}
+		close(recorder.Events)
+		var events []string
+		for event := range recorder.Events {
+			events = append(events, event)
+		}
+		return routes, events
+	}
+
+	t.Log("verifying that buffering is left enabled without streaming plugins")
+	routes, events := build()
+	require.Len(t, routes, 3)
+	for name, route := range routes {
+		assert.Equal(t, kong.Bool(true), route.RequestBuffering, name)
+		assert.Equal(t, kong.Bool(true), route.ResponseBuffering, name)
+	}
+	assert.Empty(t, events)
+
+	t.Log("verifying that buffering is disabled for routes which a streaming plugin is attached to")
+	routes, events = build("body-streamer")
+	for _, name := range []string{"streamed", "service-streamed"} {
+		require.NotNil(t, routes[name].RequestBuffering, name)
+		assert.False(t, *routes[name].RequestBuffering, name)
+		require.NotNil(t, routes[name].ResponseBuffering, name)
+		assert.False(t, *routes[name].ResponseBuffering, name)
+	}
+	assert.Equal(t, kong.Bool(true), routes["buffered"].RequestBuffering)
+	assert.Equal(t, kong.Bool(true), routes["buffered"].ResponseBuffering)
+	require.Len(t, events, 2)
+	for _, event := range events {
+		assert.Contains(t, event, BufferingDisabledReason)
+		assert.Contains(t, event, "body-streamer")
+	}
+}
+
+func TestSkipInvalidRoutePaths(t *testing.T) {
+	implementationSpecific := networkingv1.PathTypeImplementationSpecific
+	ingress := func(name string, paths ...string) *networkingv1.Ingress {
+		var ingressPaths []networkingv1.HTTPIngressPath
+		for _, path := range paths {
+			ingressPaths = append(ingressPaths, networkingv1.HTTPIngressPath{
+				Path:     path,
+				PathType: &implementationSpecific,
+				Backend: networkingv1.IngressBackend{
+					Service: &networkingv1.IngressServiceBackend{
+						Name: "foo-svc",
+						Port: networkingv1.ServiceBackendPort{Number: 80},
+					},
+				},
+			})
+		}
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Annotations: map[string]string{annotations.IngressClassKey: annotations.DefaultIngressClass},
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					Host: name + ".example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{Paths: ingressPaths},
+					},
+				}},
+			},
+		}
+	}
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		IngressesV1: []*networkingv1.Ingress{
+			ingress("mixed", "/valid", "/foo%zz"),
+			ingress("invalid", "/foo bar"),
+			ingress("valid", "/bar", `/baz/\d+$`),
+		},
+		Services: []*corev1.Service{
+			{ObjectMeta: metav1.ObjectMeta{Name: "foo-svc", Namespace: "default"}},
+		},
+	})
+	require.NoError(t, err)
+	recorder := record.NewFakeRecorder(10)
+	p := NewParser(logrus.New(), fakeStore)
+	p.SetEventRecorder(recorder)
+	state, err := p.Build()
+	require.NoError(t, err)
+
+	t.Log("verifying that only the invalid paths are skipped while the others are synced")
+	paths := map[string][]string{}
+	for _, service := range state.Services {
+		for _, route := range service.Routes {
+			for _, path := range route.Paths {
+				paths[route.Ingress.Name] = append(paths[route.Ingress.Name], *path)
+			}
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"mixed": {"/valid"},
+		"valid": {"/bar", `/baz/\d+$`},
+	}, paths)
+
+	t.Log("verifying that an event is recorded for each skipped path")
+	close(recorder.Events)
+	var events []string
+	for event := range recorder.Events {
+		events = append(events, event)
+	}
+	require.Len(t, events, 2)
+	for _, event := range events {
+		assert.Contains(t, event, InvalidRoutePathReason)
+	}
+	assert.Contains(t, strings.Join(events, "\n"), `"/foo%zz"`)
+	assert.Contains(t, strings.Join(events, "\n"), `"/foo bar"`)
+}
+
+func TestValidateRoutePath(t *testing.T) {
+	for _, path := range []string{"/", "/foo/bar", "/foo%2Fbar", "/foo$", "/~user@host:8000", `/foo/(\d+)$`} {
+		assert.NoError(t, validateRoutePath(path), path)
+	}
+	for _, path := range []string{"", "foo", "/foo//bar", "/foo bar", "/foo\tbar", "/foo%zz", "/foo%2", "/100%"} {
+		assert.Error(t, validateRoutePath(path), path)
+	}
+}
+
+func TestHealthcheckActiveConcurrencyAnnotation(t *testing.T) {
+	ingress := func(name, backend, concurrency string) *networkingv1beta1.Ingress {
+		return &networkingv1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Annotations: map[string]string{
+					annotations.IngressClassKey:                 annotations.DefaultIngressClass,
+					"konghq.com/healthcheck-active-concurrency": concurrency,
+				},
+			},
+			Spec: networkingv1beta1.IngressSpec{
+				Rules: []networkingv1beta1.IngressRule{{
+					Host: name + ".example.com",
+					IngressRuleValue: networkingv1beta1.IngressRuleValue{
+						HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+							Paths: []networkingv1beta1.HTTPIngressPath{{
+								Path: "/",
+								Backend: networkingv1beta1.IngressBackend{
+									ServiceName: backend,
+									ServicePort: intstr.FromInt(80),
+								},
+							}},
+						},
+					},
+				}},
+			},
+		}
+	}
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		IngressesV1beta1: []*networkingv1beta1.Ingress{
+			ingress("tuned", "foo-svc", "32"),
+			ingress("malformed", "bar-svc", "0"),
+		},
+		Services: []*corev1.Service{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo-svc", Namespace: "default"},
+				Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "bar-svc", Namespace: "default"},
+				Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	recorder := record.NewFakeRecorder(10)
+	p := NewParser(logrus.New(), fakeStore)
+	p.SetEventRecorder(recorder)
+	state, err := p.Build()
+	require.NoError(t, err)
+	upstreams := map[string]kong.Upstream{}
+	for _, upstream := range state.Upstreams {
+		upstreams[*upstream.Name] = upstream.Upstream
+	}
+	require.Len(t, upstreams, 2)
+
+	t.Log("verifying that the annotation sets the active health check concurrency of the upstream")
+	tuned := upstreams["foo-svc.default.80.svc"]
+	require.NotNil(t, tuned.Healthchecks)
+	require.NotNil(t, tuned.Healthchecks.Active)
+	assert.Equal(t, kong.Int(32), tuned.Healthchecks.Active.Concurrency)
+
+	t.Log("verifying that a malformed annotation is ignored and reported on the Ingress")
+	assert.Nil(t, upstreams["bar-svc.default.80.svc"].Healthchecks)
+	require.Len(t, recorder.Events, 1)
+	event := <-recorder.Events
+	assert.Contains(t, event, InvalidAnnotationReason)
+	assert.Contains(t, event, "konghq.com/healthcheck-active-concurrency")
+	assert.Contains(t, event, "default/malformed")
+}
+
+// objectRecorder is an EventRecorder which records the objects events are recorded on.
+type objectRecorder struct {
+	objects []runtime.Object
+}
+
+func (r *objectRecorder) Event(object runtime.Object, _, _, _ string) {
+	r.objects = append(r.objects, object)
+}
+
+func (r *objectRecorder) Eventf(object runtime.Object, _, _, _ string, _ ...interface{}) {
+	r.objects = append(r.objects, object)
+}
+
+func (r *objectRecorder) AnnotatedEventf(object runtime.Object, _ map[string]string, _, _, _ string, _ ...interface{}) {
+	r.objects = append(r.objects, object)
+}
+
+func TestMalformedAnnotationEventObject(t *testing.T) {
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		IngressesV1beta1: []*networkingv1beta1.Ingress{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+				Annotations: map[string]string{
+					annotations.IngressClassKey: annotations.DefaultIngressClass,
+				},
+			},
+			Spec: networkingv1beta1.IngressSpec{
+				Backend: &networkingv1beta1.IngressBackend{
+					ServiceName: "foo-svc",
+					ServicePort: intstr.FromInt(80),
+				},
+			},
+		}},
+		KnativeIngresses: []*knative.Ingress{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+				Annotations: map[string]string{
+					"networking.knative.dev/ingress.class": annotations.DefaultIngressClass,
+					"konghq.com/connect-timeout":           "fast",
+				},
+			},
+			Spec: knative.IngressSpec{
+				Rules: []knative.IngressRule{{
+					Hosts: []string{"my-func.example.com"},
+					HTTP: &knative.HTTPIngressRuleValue{
+						Paths: []knative.HTTPIngressPath{{
+							Path: "/",
+							Splits: []knative.IngressBackendSplit{{
+								IngressBackend: knative.IngressBackend{
+									ServiceNamespace: "default",
+									ServiceName:      "bar-svc",
+									ServicePort:      intstr.FromInt(80),
+								},
+								Percent: 100,
+							}},
+						}},
+					},
+				}},
+			},
+		}},
+		Services: []*corev1.Service{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo-svc", Namespace: "default"},
+				Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "bar-svc", Namespace: "default"},
+				Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	recorder := &objectRecorder{}
+	p := NewParser(logrus.New(), fakeStore)
+	p.SetEventRecorder(recorder)
+	_, err = p.Build()
+	require.NoError(t, err)
+
+	t.Log("verifying that the event is recorded on the Knative Ingress rather than the Ingress of the same name")
+	require.Len(t, recorder.objects, 1)
+	assert.IsType(t, &knative.Ingress{}, recorder.objects[0])
 }
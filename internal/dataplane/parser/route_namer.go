@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// -----------------------------------------------------------------------------
+// Route Namer - Public Types
+// -----------------------------------------------------------------------------
+
+// RouteNameParams are the properties of the rule path of an Ingress which a
+// route is generated for, from which the name of the route is derived.
+type RouteNameParams struct {
+	// Namespace and Name are the namespace and name of the Ingress.
+	Namespace string
+	Name      string
+	// Host is the host of the rule, or its first host for Knative Ingresses.
+	// It's empty for rules without hosts.
+	Host string
+	// Path is the path of the rule as configured in the Ingress.
+	Path string
+	// RuleIndex and PathIndex are the indexes of the rule in the Ingress and
+	// of the path in the rule.
+	RuleIndex int
+	PathIndex int
+}
+
+// RouteNamer names the Kong routes generated for the rule paths of Ingresses.
+// Names must be unique, the routes of rule paths with the same name overwrite
+// each other.
+type RouteNamer interface {
+	RouteName(params RouteNameParams) string
+}
+
+// DefaultRouteNamer names routes "<namespace>.<name>.<rule index><path index>".
+type DefaultRouteNamer struct{}
+
+// RouteName implements RouteNamer.
+func (DefaultRouteNamer) RouteName(params RouteNameParams) string {
+	return fmt.Sprintf("%s.%s.%d%d", params.Namespace, params.Name, params.RuleIndex, params.PathIndex)
+}
+
+// TemplateRouteNamer names routes by executing a text/template with the
+// RouteNameParams of the routes, e.g. "{{.Namespace}}-{{.Name}}-{{.Host}}".
+// Routes whose name can't be generated by the template are named by the
+// DefaultRouteNamer.
+type TemplateRouteNamer struct {
+	template *template.Template
+}
+
+// -----------------------------------------------------------------------------
+// Route Namer - Public Functions
+// -----------------------------------------------------------------------------
+
+// NewTemplateRouteNamer provides a TemplateRouteNamer for the provided
+// template. An error is returned if the template can't be parsed, refers to
+// unknown parameters, or generates an empty name.
+func NewTemplateRouteNamer(text string) (*TemplateRouteNamer, error) {
+	tmpl, err := template.New("route-name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid route name template %q: %w", text, err)
+	}
+	namer := &TemplateRouteNamer{template: tmpl}
+
+	// execute the template once so that references to unknown parameters are
+	// rejected before any route is named
+	name, err := namer.execute(RouteNameParams{Namespace: "default", Name: "ingress", Host: "example.com", Path: "/"})
+	if err != nil {
+		return nil, fmt.Errorf("invalid route name template %q: %w", text, err)
+	}
+	if name == "" {
+		return nil, fmt.Errorf("invalid route name template %q: generates empty route names", text)
+	}
+	return namer, nil
+}
+
+// RouteName implements RouteNamer.
+func (n *TemplateRouteNamer) RouteName(params RouteNameParams) string {
+	name, err := n.execute(params)
+	if err != nil || name == "" {
+		return DefaultRouteNamer{}.RouteName(params)
+	}
+	return name
+}
+
+// -----------------------------------------------------------------------------
+// Route Namer - Private Methods
+// -----------------------------------------------------------------------------
+
+func (n *TemplateRouteNamer) execute(params RouteNameParams) (string, error) {
+	var name bytes.Buffer
+	if err := n.template.Execute(&name, params); err != nil {
+		return "", err
+	}
+	return name.String(), nil
+}
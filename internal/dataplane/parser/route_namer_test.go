@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/store"
+)
+
+func TestDefaultRouteNamer(t *testing.T) {
+	assert.Equal(t, "default.foo.12", DefaultRouteNamer{}.RouteName(RouteNameParams{
+		Namespace: "default",
+		Name:      "foo",
+		Host:      "example.com",
+		Path:      "/bar",
+		RuleIndex: 1,
+		PathIndex: 2,
+	}))
+}
+
+func TestTemplateRouteNamer(t *testing.T) {
+	params := RouteNameParams{Namespace: "default", Name: "foo", Host: "example.com", Path: "/bar", RuleIndex: 1, PathIndex: 2}
+
+	for _, tt := range []struct {
+		template string
+		expected string
+	}{
+		{template: "{{.Namespace}}.{{.Name}}.{{.Host}}{{.Path}}", expected: "default.foo.example.com/bar"},
+		{template: "{{.Name}}-{{.RuleIndex}}-{{.PathIndex}}", expected: "foo-1-2"},
+		{template: "static", expected: "static"},
+	} {
+		namer, err := NewTemplateRouteNamer(tt.template)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, namer.RouteName(params))
+	}
+
+	t.Log("verifying that invalid templates are rejected")
+	for _, template := range []string{
+		"{{.Namespace",
+		"{{.Unknown}}",
+		"",
+		"{{if false}}name{{end}}",
+	} {
+		_, err := NewTemplateRouteNamer(template)
+		assert.Error(t, err, template)
+	}
+
+	t.Log("verifying that routes which the template generates no name for are named by default")
+	namer, err := NewTemplateRouteNamer("{{.Host}}")
+	require.NoError(t, err)
+	assert.Equal(t, "default.foo.12", namer.RouteName(RouteNameParams{Namespace: "default", Name: "foo", RuleIndex: 1, PathIndex: 2}))
+}
+
+func TestParserRouteNamer(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		IngressesV1: []*networkingv1.Ingress{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "foo",
+				Namespace:   "default",
+				Annotations: map[string]string{annotations.IngressClassKey: annotations.DefaultIngressClass},
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					Host: "example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{{
+								Path:     "/bar",
+								PathType: &prefix,
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{
+										Name: "foo-svc",
+										Port: networkingv1.ServiceBackendPort{Number: 80},
+									},
+								},
+							}},
+						},
+					},
+				}},
+			},
+		}},
+	})
+	require.NoError(t, err)
+	routeName := func(p *Parser) string {
+		state, err := p.Build()
+		require.NoError(t, err)
+		require.Len(t, state.Services, 1)
+		require.Len(t, state.Services[0].Routes, 1)
+		return *state.Services[0].Routes[0].Name
+	}
+
+	t.Log("verifying that routes are named by default as before")
+	assert.Equal(t, "default.foo.00", routeName(NewParser(logrus.New(), fakeStore)))
+
+	t.Log("verifying that routes are named by the configured route namer")
+	namer, err := NewTemplateRouteNamer("{{.Namespace}}.{{.Name}}.{{.Host}}{{.Path}}")
+	require.NoError(t, err)
+	p := NewParser(logrus.New(), fakeStore)
+	p.SetRouteNamer(namer)
+	assert.Equal(t, "default.foo.example.com/bar", routeName(p))
+}
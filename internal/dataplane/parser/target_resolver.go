@@ -0,0 +1,247 @@
+package parser
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/util"
+)
+
+// -----------------------------------------------------------------------------
+// Target Resolvers - Public Types
+// -----------------------------------------------------------------------------
+
+// TargetResolver resolves the addresses which the upstream of a Service port
+// targets for the provided protocol.
+type TargetResolver interface {
+	ResolveTargets(svc *corev1.Service, port *corev1.ServicePort, proto corev1.Protocol) ([]util.Endpoint, error)
+}
+
+// ClusterIPResolver targets the cluster IP of a Service, leaving the load
+// balancing between its endpoints to kube-proxy.
+type ClusterIPResolver struct{}
+
+// EndpointsResolver targets the endpoints of a Service (e.g. pod IPs), so that
+// Kong load balances between them directly.
+type EndpointsResolver struct {
+	// GetEndpoints provides the Endpoints of the Service with the provided
+	// namespace and name.
+	GetEndpoints func(namespace, name string) (*corev1.Endpoints, error)
+}
+
+// EndpointSliceResolver targets the ready endpoints of the EndpointSlices of
+// a Service, so that Kong load balances between them directly and stops
+// targeting Pods as soon as they stop being ready (e.g. because of readiness
+// gates). Services without EndpointSlices are resolved by their Endpoints.
+type EndpointSliceResolver struct {
+	// ListEndpointSlices provides the EndpointSlices of the Service with the
+	// provided namespace and name.
+	ListEndpointSlices func(namespace, name string) ([]*discoveryv1.EndpointSlice, error)
+	// GetEndpoints provides the Endpoints of the Service with the provided
+	// namespace and name.
+	GetEndpoints func(namespace, name string) (*corev1.Endpoints, error)
+}
+
+// ServiceDNSResolver targets the cluster DNS name of a Service.
+type ServiceDNSResolver struct{}
+
+// -----------------------------------------------------------------------------
+// Target Resolvers - Public Functions
+// -----------------------------------------------------------------------------
+
+// TargetResolverFor provides the TargetResolver of the provided Service,
+// which is selected by its konghq.com/upstream-target annotation. Services
+// with the ingress.kubernetes.io/service-upstream annotation are targeted by
+// their DNS name, and other Services by their EndpointSlices if
+// listEndpointSlices is provided and by their Endpoints otherwise.
+func TargetResolverFor(
+	svc *corev1.Service,
+	getEndpoints func(namespace, name string) (*corev1.Endpoints, error),
+	listEndpointSlices func(namespace, name string) ([]*discoveryv1.EndpointSlice, error),
+) TargetResolver {
+	target, ok := annotations.ExtractUpstreamTarget(svc.Annotations)
+	if !ok && annotations.HasServiceUpstreamAnnotation(svc.Annotations) {
+		target = annotations.UpstreamTargetServiceDNS
+	}
+	switch target {
+	case annotations.UpstreamTargetClusterIP:
+		return ClusterIPResolver{}
+	case annotations.UpstreamTargetServiceDNS:
+		return ServiceDNSResolver{}
+	default:
+		if listEndpointSlices != nil {
+			return EndpointSliceResolver{ListEndpointSlices: listEndpointSlices, GetEndpoints: getEndpoints}
+		}
+		return EndpointsResolver{GetEndpoints: getEndpoints}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Target Resolvers - Public Methods
+// -----------------------------------------------------------------------------
+
+// ResolveTargets provides the cluster IP of the Service and the port of the
+// Service port. Headless Services have no cluster IP and can't be resolved.
+func (ClusterIPResolver) ResolveTargets(svc *corev1.Service, port *corev1.ServicePort, proto corev1.Protocol) ([]util.Endpoint, error) {
+	if !servicePortHasProtocol(port, proto) {
+		return nil, nil
+	}
+	if svc.Spec.ClusterIP == "" || svc.Spec.ClusterIP == corev1.ClusterIPNone {
+		return nil, fmt.Errorf("service %s/%s has no cluster IP", svc.Namespace, svc.Name)
+	}
+	return []util.Endpoint{{
+		Address: svc.Spec.ClusterIP,
+		Port:    fmt.Sprintf("%v", port.Port),
+	}}, nil
+}
+
+// ResolveTargets provides the addresses of the endpoints of the Service which
+// serve the Service port with the provided protocol. The not ready addresses
+// are included if the Service publishes them.
+func (r EndpointsResolver) ResolveTargets(svc *corev1.Service, port *corev1.ServicePort, proto corev1.Protocol) ([]util.Endpoint, error) {
+	ep, err := r.GetEndpoints(svc.Namespace, svc.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch endpoints: %w", err)
+	}
+
+	// avoid duplicated upstream servers when the service
+	// contains multiple port definitions sharing the same
+	// targetport.
+	adus := make(map[string]bool)
+	upsServers := []util.Endpoint{}
+	for _, ss := range ep.Subsets {
+		for _, epPort := range ss.Ports {
+			if epPort.Protocol != proto {
+				continue
+			}
+
+			var targetPort int32
+
+			if port.Name == "" {
+				// port.Name is optional if there is only one port
+				targetPort = epPort.Port
+			} else if port.Name == epPort.Name {
+				targetPort = epPort.Port
+			}
+
+			// check for invalid port value
+			if targetPort <= 0 {
+				continue
+			}
+
+			addresses := ss.Addresses
+			if svc.Spec.PublishNotReadyAddresses {
+				// the Service author wants not-ready endpoints to be reachable (e.g. for StatefulSet peer discovery)
+				addresses = append(append([]corev1.EndpointAddress{}, ss.Addresses...), ss.NotReadyAddresses...)
+			}
+			for _, epAddress := range addresses {
+				ep := fmt.Sprintf("%v:%v", epAddress.IP, targetPort)
+				if _, exists := adus[ep]; exists {
+					continue
+				}
+				upsServers = append(upsServers, util.Endpoint{
+					Address: epAddress.IP,
+					Port:    fmt.Sprintf("%v", targetPort),
+				})
+				adus[ep] = true
+			}
+		}
+	}
+	return upsServers, nil
+}
+
+// ResolveTargets provides the addresses of the ready endpoints of the
+// EndpointSlices of the Service which serve the Service port with the provided
+// protocol. Endpoints with an unknown readiness are considered ready, and the
+// not ready endpoints are included if the Service publishes them.
+func (r EndpointSliceResolver) ResolveTargets(svc *corev1.Service, port *corev1.ServicePort, proto corev1.Protocol) ([]util.Endpoint, error) {
+	slices, err := r.ListEndpointSlices(svc.Namespace, svc.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch endpoint slices: %w", err)
+	}
+	if len(slices) == 0 {
+		return EndpointsResolver{GetEndpoints: r.GetEndpoints}.ResolveTargets(svc, port, proto)
+	}
+
+	// avoid duplicated upstream servers when the same endpoint
+	// is listed by multiple slices or ports.
+	adus := make(map[string]bool)
+	upsServers := []util.Endpoint{}
+	for _, slice := range slices {
+		for _, slicePort := range slice.Ports {
+			targetPort := endpointSlicePortFor(slicePort, port, proto)
+			if targetPort <= 0 {
+				continue
+			}
+
+			for _, endpoint := range slice.Endpoints {
+				ready := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+				if !ready && !svc.Spec.PublishNotReadyAddresses {
+					continue
+				}
+				for _, address := range endpoint.Addresses {
+					ep := fmt.Sprintf("%v:%v", address, targetPort)
+					if _, exists := adus[ep]; exists {
+						continue
+					}
+					upsServers = append(upsServers, util.Endpoint{
+						Address: address,
+						Port:    fmt.Sprintf("%v", targetPort),
+					})
+					adus[ep] = true
+				}
+			}
+		}
+	}
+	return upsServers, nil
+}
+
+// ResolveTargets provides the cluster DNS name of the Service and the port of
+// the Service port.
+func (ServiceDNSResolver) ResolveTargets(svc *corev1.Service, port *corev1.ServicePort, proto corev1.Protocol) ([]util.Endpoint, error) {
+	if !servicePortHasProtocol(port, proto) {
+		return nil, nil
+	}
+	return []util.Endpoint{{
+		Address: svc.Name + "." + svc.Namespace + ".svc",
+		Port:    fmt.Sprintf("%v", port.Port),
+	}}, nil
+}
+
+// -----------------------------------------------------------------------------
+// Target Resolvers - Private Functions
+// -----------------------------------------------------------------------------
+
+// servicePortHasProtocol indicates whether the provided Service port serves
+// the provided protocol. Ports without a protocol serve TCP.
+func servicePortHasProtocol(port *corev1.ServicePort, proto corev1.Protocol) bool {
+	portProto := port.Protocol
+	if portProto == "" {
+		portProto = corev1.ProtocolTCP
+	}
+	return portProto == proto
+}
+
+// endpointSlicePortFor returns the port number of the provided EndpointSlice
+// port if it serves the provided Service port and protocol, and 0 otherwise.
+// Ports without a protocol serve TCP.
+func endpointSlicePortFor(slicePort discoveryv1.EndpointPort, port *corev1.ServicePort, proto corev1.Protocol) int32 {
+	if slicePort.Port == nil {
+		return 0
+	}
+	slicePortProto := corev1.ProtocolTCP
+	if slicePort.Protocol != nil {
+		slicePortProto = *slicePort.Protocol
+	}
+	if slicePortProto != proto {
+		return 0
+	}
+	// port.Name is optional if there is only one port
+	if port.Name != "" && (slicePort.Name == nil || *slicePort.Name != port.Name) {
+		return 0
+	}
+	return *slicePort.Port
+}
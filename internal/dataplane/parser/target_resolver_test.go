@@ -0,0 +1,303 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/store"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/util"
+)
+
+func TestTargetResolvers(t *testing.T) {
+	service := func(name, clusterIP string, anns map[string]string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: anns},
+			Spec: corev1.ServiceSpec{
+				ClusterIP: clusterIP,
+				Ports:     []corev1.ServicePort{{Name: "http", Port: 80}},
+			},
+		}
+	}
+	endpoints := func(name string, ips ...string) *corev1.Endpoints {
+		subset := corev1.EndpointSubset{Ports: []corev1.EndpointPort{{Name: "http", Port: 8080, Protocol: corev1.ProtocolTCP}}}
+		for _, ip := range ips {
+			subset.Addresses = append(subset.Addresses, corev1.EndpointAddress{IP: ip})
+		}
+		return &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Subsets:    []corev1.EndpointSubset{subset},
+		}
+	}
+	upstreamTarget := func(target annotations.UpstreamTarget) map[string]string {
+		return map[string]string{annotations.AnnotationPrefix + annotations.UpstreamTargetKey: string(target)}
+	}
+
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		Services: []*corev1.Service{
+			service("default-target", "10.96.0.1", nil),
+			service("by-endpoints", "10.96.0.2", upstreamTarget(annotations.UpstreamTargetEndpoints)),
+			service("by-cluster-ip", "10.96.0.3", upstreamTarget(annotations.UpstreamTargetClusterIP)),
+			service("by-dns", "10.96.0.4", upstreamTarget(annotations.UpstreamTargetServiceDNS)),
+			service("legacy-service-upstream", "10.96.0.5", map[string]string{"ingress.kubernetes.io/service-upstream": "true"}),
+			service("headless", corev1.ClusterIPNone, upstreamTarget(annotations.UpstreamTargetClusterIP)),
+		},
+		Endpoints: []*corev1.Endpoints{
+			endpoints("default-target", "10.244.0.1", "10.244.0.2"),
+			endpoints("by-endpoints", "10.244.0.3"),
+			endpoints("by-cluster-ip", "10.244.0.4"),
+			endpoints("by-dns", "10.244.0.5"),
+			endpoints("legacy-service-upstream", "10.244.0.6"),
+		},
+	})
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		service          string
+		expectedResolver TargetResolver
+		expected         []util.Endpoint
+		expectedErr      bool
+	}{
+		{
+			service:          "default-target",
+			expectedResolver: EndpointsResolver{},
+			expected:         []util.Endpoint{{Address: "10.244.0.1", Port: "8080"}, {Address: "10.244.0.2", Port: "8080"}},
+		},
+		{
+			service:          "by-endpoints",
+			expectedResolver: EndpointsResolver{},
+			expected:         []util.Endpoint{{Address: "10.244.0.3", Port: "8080"}},
+		},
+		{
+			service:          "by-cluster-ip",
+			expectedResolver: ClusterIPResolver{},
+			expected:         []util.Endpoint{{Address: "10.96.0.3", Port: "80"}},
+		},
+		{
+			service:          "by-dns",
+			expectedResolver: ServiceDNSResolver{},
+			expected:         []util.Endpoint{{Address: "by-dns.default.svc", Port: "80"}},
+		},
+		{
+			service:          "legacy-service-upstream",
+			expectedResolver: ServiceDNSResolver{},
+			expected:         []util.Endpoint{{Address: "legacy-service-upstream.default.svc", Port: "80"}},
+		},
+		{
+			service:          "headless",
+			expectedResolver: ClusterIPResolver{},
+			expectedErr:      true,
+		},
+	} {
+		t.Run(tt.service, func(t *testing.T) {
+			svc, err := fakeStore.GetService("default", tt.service)
+			require.NoError(t, err)
+			resolver := TargetResolverFor(svc, fakeStore.GetEndpointsForService, nil)
+			assert.IsType(t, tt.expectedResolver, resolver)
+
+			targets, err := resolver.ResolveTargets(svc, &svc.Spec.Ports[0], corev1.ProtocolTCP)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, targets)
+
+			t.Log("verifying that no targets are resolved for another protocol")
+			targets, err = resolver.ResolveTargets(svc, &svc.Spec.Ports[0], corev1.ProtocolUDP)
+			require.NoError(t, err)
+			assert.Empty(t, targets)
+		})
+	}
+
+	t.Run("endpoints of a missing Service", func(t *testing.T) {
+		svc := service("missing", "10.96.0.9", nil)
+		_, err := EndpointsResolver{GetEndpoints: fakeStore.GetEndpointsForService}.ResolveTargets(svc, &svc.Spec.Ports[0], corev1.ProtocolTCP)
+		assert.Error(t, err)
+	})
+}
+
+func TestEndpointSliceResolverReadiness(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}},
+	}
+	ready, notReady := true, false
+	portName, portNumber := "http", int32(8080)
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app-abcde",
+			Namespace: "default",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "app"},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Ports:       []discoveryv1.EndpointPort{{Name: &portName, Port: &portNumber}},
+		Endpoints: []discoveryv1.Endpoint{
+			{Addresses: []string{"10.244.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: &ready}},
+			{Addresses: []string{"10.244.0.2"}, Conditions: discoveryv1.EndpointConditions{Ready: &notReady}},
+		},
+	}
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		Services:       []*corev1.Service{svc},
+		EndpointSlices: []*discoveryv1.EndpointSlice{slice},
+		Endpoints: []*corev1.Endpoints{{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: "10.244.0.9"}},
+				Ports:     []corev1.EndpointPort{{Name: "http", Port: 8080, Protocol: corev1.ProtocolTCP}},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+
+	resolver := TargetResolverFor(svc, fakeStore.GetEndpointsForService, fakeStore.ListEndpointSlicesForService)
+	require.IsType(t, EndpointSliceResolver{}, resolver)
+	resolve := func() []util.Endpoint {
+		targets, err := resolver.ResolveTargets(svc, &svc.Spec.Ports[0], corev1.ProtocolTCP)
+		require.NoError(t, err)
+		return targets
+	}
+
+	t.Log("verifying that only the ready endpoints are targeted")
+	assert.Equal(t, []util.Endpoint{{Address: "10.244.0.1", Port: "8080"}}, resolve())
+
+	t.Log("verifying that an endpoint becoming ready is targeted")
+	slice.Endpoints[1].Conditions.Ready = &ready
+	assert.Equal(t, []util.Endpoint{{Address: "10.244.0.1", Port: "8080"}, {Address: "10.244.0.2", Port: "8080"}}, resolve())
+
+	t.Log("verifying that an endpoint becoming not ready is no longer targeted")
+	slice.Endpoints[0].Conditions.Ready = &notReady
+	assert.Equal(t, []util.Endpoint{{Address: "10.244.0.2", Port: "8080"}}, resolve())
+
+	t.Log("verifying that endpoints with an unknown readiness are targeted")
+	slice.Endpoints[0].Conditions.Ready = nil
+	assert.Equal(t, []util.Endpoint{{Address: "10.244.0.1", Port: "8080"}, {Address: "10.244.0.2", Port: "8080"}}, resolve())
+
+	t.Log("verifying that not ready endpoints are targeted if the Service publishes them")
+	slice.Endpoints[0].Conditions.Ready = &notReady
+	svc.Spec.PublishNotReadyAddresses = true
+	assert.Len(t, resolve(), 2)
+	svc.Spec.PublishNotReadyAddresses = false
+
+	t.Log("verifying that Services without EndpointSlices are resolved by their Endpoints")
+	other := svc.DeepCopy()
+	other.Name = "other"
+	targets, err := EndpointSliceResolver{
+		ListEndpointSlices: fakeStore.ListEndpointSlicesForService,
+		GetEndpoints: func(namespace, _ string) (*corev1.Endpoints, error) {
+			return fakeStore.GetEndpointsForService(namespace, "app")
+		},
+	}.ResolveTargets(other, &other.Spec.Ports[0], corev1.ProtocolTCP)
+	require.NoError(t, err)
+	assert.Equal(t, []util.Endpoint{{Address: "10.244.0.9", Port: "8080"}}, targets)
+
+	t.Log("verifying that readiness transitions update the upstream targets built by the parser")
+	fakeStore, err = store.NewFakeStore(store.FakeObjects{
+		IngressesV1beta1: []*networkingv1beta1.Ingress{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "foo",
+				Namespace:   "default",
+				Annotations: map[string]string{annotations.IngressClassKey: annotations.DefaultIngressClass},
+			},
+			Spec: networkingv1beta1.IngressSpec{
+				Backend: &networkingv1beta1.IngressBackend{ServiceName: "app", ServicePort: intstr.FromInt(80)},
+			},
+		}},
+		Services:       []*corev1.Service{svc},
+		EndpointSlices: []*discoveryv1.EndpointSlice{slice},
+	})
+	require.NoError(t, err)
+	buildTargets := func() []string {
+		state, err := NewParser(logrus.New(), fakeStore).Build()
+		require.NoError(t, err)
+		require.Len(t, state.Upstreams, 1)
+		var targets []string
+		for _, target := range state.Upstreams[0].Targets {
+			targets = append(targets, *target.Target.Target)
+		}
+		return targets
+	}
+	assert.Equal(t, []string{"10.244.0.2:8080"}, buildTargets())
+	slice.Endpoints[0].Conditions.Ready = &ready
+	slice.Endpoints[1].Conditions.Ready = &notReady
+	assert.Equal(t, []string{"10.244.0.1:8080"}, buildTargets())
+}
+
+func TestEndpointSliceResolverAggregation(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}, {Name: "admin", Port: 81}}},
+	}
+	httpName, httpPort := "http", int32(8080)
+	adminName, adminPort := "admin", int32(8081)
+	endpointSlice := func(name string, addresses ...string) *discoveryv1.EndpointSlice {
+		slice := &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "app"},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Ports: []discoveryv1.EndpointPort{
+				{Name: &httpName, Port: &httpPort},
+				{Name: &adminName, Port: &adminPort},
+			},
+		}
+		for _, address := range addresses {
+			slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{Addresses: []string{address}})
+		}
+		return slice
+	}
+	// a Service's endpoints can be listed by more than one slice while the
+	// EndpointSlice controller moves them between slices
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		Services: []*corev1.Service{svc},
+		EndpointSlices: []*discoveryv1.EndpointSlice{
+			endpointSlice("app-bbbbb", "10.244.0.3", "10.244.0.2"),
+			endpointSlice("app-aaaaa", "10.244.0.1", "10.244.0.2"),
+			endpointSlice("app-ccccc", "10.244.0.4"),
+		},
+		Endpoints: []*corev1.Endpoints{{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: "10.244.0.1"}, {IP: "10.244.0.2"}, {IP: "10.244.0.3"}, {IP: "10.244.0.4"}},
+				Ports: []corev1.EndpointPort{
+					{Name: "http", Port: 8080, Protocol: corev1.ProtocolTCP},
+					{Name: "admin", Port: 8081, Protocol: corev1.ProtocolTCP},
+				},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+
+	slices := EndpointSliceResolver{ListEndpointSlices: fakeStore.ListEndpointSlicesForService, GetEndpoints: fakeStore.GetEndpointsForService}
+	endpoints := EndpointsResolver{GetEndpoints: fakeStore.GetEndpointsForService}
+	for i := range svc.Spec.Ports {
+		port := &svc.Spec.Ports[i]
+
+		t.Logf("verifying that the slices of port %s are aggregated without duplicate addresses", port.Name)
+		targets, err := slices.ResolveTargets(svc, port, corev1.ProtocolTCP)
+		require.NoError(t, err)
+		targetPort := fmt.Sprint(port.Port + 8000)
+		assert.Equal(t, []util.Endpoint{
+			{Address: "10.244.0.1", Port: targetPort},
+			{Address: "10.244.0.2", Port: targetPort},
+			{Address: "10.244.0.3", Port: targetPort},
+			{Address: "10.244.0.4", Port: targetPort},
+		}, targets)
+
+		t.Logf("verifying that the slices of port %s produce the same targets as the Endpoints", port.Name)
+		fromEndpoints, err := endpoints.ResolveTargets(svc, port, corev1.ProtocolTCP)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, fromEndpoints, targets)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/kongstate"
 )
 
@@ -30,6 +31,13 @@ func pathsFromK8s(path string, pathType networkingv1.PathType) ([]*string, error
 			"/"+base+"/",
 		), nil
 	case networkingv1.PathTypeExact:
+		// Kong matches routes against the request path without its query
+		// string, so an exact path matches regardless of the query, which is
+		// forwarded to the upstream unchanged. A query string in the path
+		// itself would never match and is stripped.
+		if i := strings.IndexByte(path, '?'); i >= 0 {
+			path = path[:i]
+		}
 		relative := strings.TrimLeft(path, "/")
 		return kong.StringSlice("/" + relative + "$"), nil
 	case networkingv1.PathTypeImplementationSpecific:
@@ -42,6 +50,15 @@ func pathsFromK8s(path string, pathType networkingv1.PathType) ([]*string, error
 	return nil, fmt.Errorf("unknown pathType %v", pathType)
 }
 
+// pathTypeForPathHandling maps the path handling configured with the
+// konghq.com/path-handling annotation to the path type whose translation
+// implements it.
+var pathTypeForPathHandling = map[annotations.PathHandling]networkingv1.PathType{
+	annotations.PathHandlingExact:  networkingv1.PathTypeExact,
+	annotations.PathHandlingPrefix: networkingv1.PathTypePrefix,
+	annotations.PathHandlingRegex:  networkingv1.PathTypeImplementationSpecific,
+}
+
 var priorityForPath = map[networkingv1.PathType]int{
 	networkingv1.PathTypeExact:                  300,
 	networkingv1.PathTypePrefix:                 200,
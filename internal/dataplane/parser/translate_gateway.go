@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// -----------------------------------------------------------------------------
+// Translate Gateway - Vars & Consts
+// -----------------------------------------------------------------------------
+
+// CertificateRefNotPermittedReason is the reason of the events recorded on
+// Gateways whose listeners reference a Secret in another namespace without a
+// ReferencePolicy permitting it.
+const CertificateRefNotPermittedReason = "KongCertificateRefNotPermitted"
+
+// -----------------------------------------------------------------------------
+// Translate Gateway - IngressRules Translation
+// -----------------------------------------------------------------------------
+
+// ingressRulesFromGatewayCertificates collects the Secrets referenced by the
+// TLS configuration of Gateway listeners, so that they are configured as
+// certificates for the hostnames of the listeners. A Secret in another
+// namespace than its Gateway is only used if a ReferencePolicy in the
+// Secret's namespace permits Gateways of the Gateway's namespace to reference
+// Secrets, references which aren't permitted are skipped and a warning event
+// is recorded on the Gateway.
+func (p *Parser) ingressRulesFromGatewayCertificates() ingressRules {
+	result := newIngressRules()
+
+	gateways, err := p.storer.ListGateways()
+	if err != nil {
+		p.logger.Errorf("failed to list Gateways: %v", err)
+		return result
+	}
+	if len(gateways) == 0 {
+		return result
+	}
+	policies, err := p.storer.ListReferencePolicies()
+	if err != nil {
+		p.logger.Errorf("failed to list ReferencePolicies: %v", err)
+		return result
+	}
+
+	for _, gateway := range gateways {
+		for _, listener := range gateway.Spec.Listeners {
+			if listener.TLS == nil {
+				continue
+			}
+			for _, ref := range listener.TLS.CertificateRefs {
+				if ref == nil || !isSecretReference(*ref) {
+					continue
+				}
+				namespace := gateway.Namespace
+				if ref.Namespace != nil && *ref.Namespace != "" {
+					namespace = string(*ref.Namespace)
+				}
+				if !secretReferencePermitted(gateway.Namespace, namespace, policies) {
+					p.logger.WithField("gateway", gateway.Namespace+"/"+gateway.Name).
+						Errorf("listener %s: reference to Secret %s/%s is not permitted by any ReferencePolicy, skipping it",
+							listener.Name, namespace, ref.Name)
+					if p.eventRecorder != nil {
+						p.eventRecorder.Eventf(gateway, corev1.EventTypeWarning, CertificateRefNotPermittedReason,
+							"listener %s references Secret %s/%s, which no ReferencePolicy in namespace %s permits",
+							listener.Name, namespace, ref.Name, namespace)
+					}
+					continue
+				}
+
+				secretKey := fmt.Sprintf("%s/%s", namespace, ref.Name)
+				if _, ok := result.SecretNameToSNIs[secretKey]; !ok {
+					result.SecretNameToSNIs[secretKey] = []string{}
+				}
+				if listener.Hostname != nil && *listener.Hostname != "" {
+					result.SecretNameToSNIs[secretKey] = append(result.SecretNameToSNIs[secretKey], string(*listener.Hostname))
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// -----------------------------------------------------------------------------
+// Translate Gateway - Private Functions
+// -----------------------------------------------------------------------------
+
+// isSecretReference indicates whether the provided reference refers to a core
+// Secret, which is the default when its group and kind are unset.
+func isSecretReference(ref gatewayv1alpha2.SecretObjectReference) bool {
+	if ref.Group != nil && *ref.Group != "" && *ref.Group != "core" {
+		return false
+	}
+	return ref.Kind == nil || *ref.Kind == "Secret"
+}
+
+// secretReferencePermitted indicates whether Gateways in the gateway namespace
+// may reference Secrets in the secret namespace. References within a namespace
+// are always permitted, references across namespaces need a ReferencePolicy in
+// the secret namespace from Gateways in the gateway namespace to Secrets.
+func secretReferencePermitted(gatewayNamespace, secretNamespace string, policies []*gatewayv1alpha2.ReferencePolicy) bool {
+	if gatewayNamespace == secretNamespace {
+		return true
+	}
+	for _, policy := range policies {
+		if policy.Namespace != secretNamespace {
+			continue
+		}
+		fromGateways := false
+		for _, from := range policy.Spec.From {
+			if string(from.Group) == gatewayv1alpha2.SchemeGroupVersion.Group &&
+				from.Kind == "Gateway" &&
+				string(from.Namespace) == gatewayNamespace {
+				fromGateways = true
+				break
+			}
+		}
+		if !fromGateways {
+			continue
+		}
+		for _, to := range policy.Spec.To {
+			if (to.Group == "" || to.Group == "core") && to.Kind == "Secret" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/manager/featuregates"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/store"
+)
+
+func TestGatewayCertificateRefs(t *testing.T) {
+	certsNamespace := gatewayv1alpha2.Namespace("certs")
+	hostname := gatewayv1alpha2.Hostname("example.com")
+	gateway := &gatewayv1alpha2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gateway", Namespace: "gateways"},
+		Spec: gatewayv1alpha2.GatewaySpec{
+			GatewayClassName: "kong",
+			Listeners: []gatewayv1alpha2.Listener{{
+				Name:     "https",
+				Hostname: &hostname,
+				Port:     443,
+				Protocol: gatewayv1alpha2.HTTPSProtocolType,
+				TLS: &gatewayv1alpha2.GatewayTLSConfig{
+					CertificateRefs: []*gatewayv1alpha2.SecretObjectReference{{
+						Name:      "example-com",
+						Namespace: &certsNamespace,
+					}},
+				},
+			}},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-com", Namespace: "certs", UID: "7428fb98-180b-4702-a91f-61351a33c6e4"},
+		Data: map[string][]byte{
+			"tls.crt": []byte(tlsPairs[0].Cert),
+			"tls.key": []byte(tlsPairs[0].Key),
+		},
+	}
+	policy := func(namespace, fromNamespace string) *gatewayv1alpha2.ReferencePolicy {
+		return &gatewayv1alpha2.ReferencePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "gateway-certs", Namespace: namespace},
+			Spec: gatewayv1alpha2.ReferencePolicySpec{
+				From: []gatewayv1alpha2.ReferencePolicyFrom{{
+					Group:     gatewayv1alpha2.Group(gatewayv1alpha2.SchemeGroupVersion.Group),
+					Kind:      "Gateway",
+					Namespace: gatewayv1alpha2.Namespace(fromNamespace),
+				}},
+				To: []gatewayv1alpha2.ReferencePolicyTo{{Group: "", Kind: "Secret"}},
+			},
+		}
+	}
+	featureGates := featuregates.FeatureGates{featuregates.GatewayFeature: true}
+	build := func(policies ...*gatewayv1alpha2.ReferencePolicy) ([]string, []string) {
+		fakeStore, err := store.NewFakeStore(store.FakeObjects{
+			Gateways:          []*gatewayv1alpha2.Gateway{gateway},
+			ReferencePolicies: policies,
+			Secrets:           []*corev1.Secret{secret},
+		})
+		require.NoError(t, err)
+		recorder := record.NewFakeRecorder(10)
+		p := NewParser(logrus.New(), fakeStore)
+		p.SetEventRecorder(recorder)
+		p.SetFeatureGates(featureGates)
+		state, err := p.Build()
+		require.NoError(t, err)
+
+		var snis []string
+		for _, cert := range state.Certificates {
+			for _, sni := range cert.SNIs {
+				snis = append(snis, *sni)
+			}
+		}
+		close(recorder.Events)
+		var events []string
+		for event := range recorder.Events {
+			events = append(events, event)
+		}
+		return snis, events
+	}
+
+	t.Log("verifying that a cross-namespace certificate reference without a ReferencePolicy is denied")
+	snis, events := build()
+	assert.Empty(t, snis)
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0], CertificateRefNotPermittedReason)
+	assert.Contains(t, events[0], "certs/example-com")
+
+	t.Log("verifying that a ReferencePolicy for another namespace doesn't permit the reference")
+	snis, events = build(policy("certs", "other"), policy("gateways", "gateways"))
+	assert.Empty(t, snis)
+	assert.Len(t, events, 1)
+
+	t.Log("verifying that a cross-namespace certificate reference with a ReferencePolicy is permitted")
+	snis, events = build(policy("certs", "gateways"))
+	assert.Equal(t, []string{"example.com"}, snis)
+	assert.Empty(t, events)
+
+	t.Log("verifying that a certificate reference within the Gateway's namespace needs no ReferencePolicy")
+	gateway = gateway.DeepCopy()
+	gateway.Namespace = "certs"
+	snis, events = build()
+	assert.Equal(t, []string{"example.com"}, snis)
+	assert.Empty(t, events)
+
+	t.Log("verifying that Gateways aren't translated unless the Gateway feature gate is enabled")
+	featureGates = nil
+	snis, events = build()
+	assert.Empty(t, snis)
+	assert.Empty(t, events)
+}
@@ -114,7 +114,7 @@ func getHTTPRouteHostnamesAsSliceOfStringPointers(httproute *gatewayv1alpha2.HTT
 // configurations, this will produce an error and the route is considered invalid.
 func generateKongRoutesFromHTTPRouteRule(httproute *gatewayv1alpha2.HTTPRoute, rule gatewayv1alpha2.HTTPRouteRule) ([]kongstate.Route, error) {
 	// gather the k8s object information and hostnames from the httproute
-	objectInfo := util.FromK8sObject(httproute)
+	objectInfo := util.FromK8sObject(httproute, httpRouteGVK)
 	hostnames := getHTTPRouteHostnamesAsSliceOfStringPointers(httproute)
 
 	// the HTTPRoute specification upstream specifically defines matches as
@@ -100,9 +100,10 @@ func Test_ingressRulesFromHTTPRoutes(t *testing.T) {
 								},
 							},
 							Ingress: util.K8sObjectInfo{
-								Name:        "basic-httproute",
-								Namespace:   corev1.NamespaceDefault,
-								Annotations: make(map[string]string),
+								Name:             "basic-httproute",
+								Namespace:        corev1.NamespaceDefault,
+								Annotations:      make(map[string]string),
+								GroupVersionKind: httpRouteGVK,
 							},
 						}},
 						K8sService: corev1.Service{},
@@ -212,9 +213,10 @@ func Test_ingressRulesFromHTTPRoutes(t *testing.T) {
 								},
 							},
 							Ingress: util.K8sObjectInfo{
-								Name:        "basic-httproute",
-								Namespace:   corev1.NamespaceDefault,
-								Annotations: make(map[string]string),
+								Name:             "basic-httproute",
+								Namespace:        corev1.NamespaceDefault,
+								Annotations:      make(map[string]string),
+								GroupVersionKind: httpRouteGVK,
 							},
 						}},
 						K8sService: corev1.Service{},
@@ -352,9 +354,10 @@ func Test_ingressRulesFromHTTPRoutes(t *testing.T) {
 								},
 							},
 							Ingress: util.K8sObjectInfo{
-								Name:        "basic-httproute",
-								Namespace:   corev1.NamespaceDefault,
-								Annotations: make(map[string]string),
+								Name:             "basic-httproute",
+								Namespace:        corev1.NamespaceDefault,
+								Annotations:      make(map[string]string),
+								GroupVersionKind: httpRouteGVK,
 							},
 						}},
 						K8sService: corev1.Service{},
@@ -429,9 +432,10 @@ func Test_ingressRulesFromHTTPRoutes(t *testing.T) {
 								},
 							},
 							Ingress: util.K8sObjectInfo{
-								Name:        "basic-httproute",
-								Namespace:   corev1.NamespaceDefault,
-								Annotations: make(map[string]string),
+								Name:             "basic-httproute",
+								Namespace:        corev1.NamespaceDefault,
+								Annotations:      make(map[string]string),
+								GroupVersionKind: httpRouteGVK,
 							},
 						}},
 						K8sService: corev1.Service{},
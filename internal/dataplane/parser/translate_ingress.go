@@ -7,9 +7,12 @@ import (
 
 	"github.com/kong/go-kong/kong"
 	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/kongstate"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/util"
 )
@@ -27,10 +30,11 @@ func (p *Parser) ingressRulesFromIngressV1beta1() ingressRules {
 
 	for _, ingress := range ingressList {
 		ingressSpec := ingress.Spec
-		log := p.logger.WithFields(logrus.Fields{
+		log := util.WithObjectDebugLevel(p.logger, ingress.Annotations).WithFields(logrus.Fields{
 			"ingress_namespace": ingress.Namespace,
 			"ingress_name":      ingress.Name,
 		})
+		log.Debugf("translating ingress")
 
 		if ingressSpec.Backend != nil {
 			allDefaultBackends = append(allDefaultBackends, *ingress)
@@ -38,12 +42,14 @@ func (p *Parser) ingressRulesFromIngressV1beta1() ingressRules {
 
 		result.SecretNameToSNIs.addFromIngressV1beta1TLS(ingressSpec.TLS, ingress.Namespace)
 
+		routes := routeLimiter{max: p.maxRoutesPerIngress}
 		var objectSuccessfullyParsed bool
 		for i, rule := range ingressSpec.Rules {
 			host := rule.Host
 			if rule.HTTP == nil {
 				continue
 			}
+			dedupedPaths := pathDeduplicator{}
 			for j, rule := range rule.HTTP.Paths {
 				path := rule.Path
 
@@ -54,10 +60,23 @@ func (p *Parser) ingressRulesFromIngressV1beta1() ingressRules {
 				if path == "" {
 					path = "/"
 				}
+				if !dedupedPaths.add(kong.StringSlice(path)) {
+					continue
+				}
+				if !routes.allow() {
+					continue
+				}
 				r := kongstate.Route{
-					Ingress: util.FromK8sObject(ingress),
+					Ingress: util.FromK8sObject(ingress, ingressV1beta1GVK),
 					Route: kong.Route{
-						Name:              kong.String(fmt.Sprintf("%s.%s.%d%d", ingress.Namespace, ingress.Name, i, j)),
+						Name: kong.String(p.routeNamer.RouteName(RouteNameParams{
+							Namespace: ingress.Namespace,
+							Name:      ingress.Name,
+							Host:      host,
+							Path:      path,
+							RuleIndex: i,
+							PathIndex: j,
+						})),
 						Paths:             kong.StringSlice(path),
 						StripPath:         kong.Bool(false),
 						PreserveHost:      kong.Bool(true),
@@ -101,9 +120,12 @@ func (p *Parser) ingressRulesFromIngressV1beta1() ingressRules {
 				service.Routes = append(service.Routes, r)
 				result.ServiceNameToServices[serviceName] = service
 				objectSuccessfullyParsed = true
+				log.Debugf("translated rule into route %s of service %s", *r.Name, serviceName)
 			}
+			p.reportDuplicatePaths(log, ingress, i, dedupedPaths)
 		}
 
+		p.reportRouteLimitExceeded(log, ingress, routes)
 		if objectSuccessfullyParsed {
 			p.ReportKubernetesObjectUpdate(ingress)
 		}
@@ -143,7 +165,7 @@ func (p *Parser) ingressRulesFromIngressV1beta1() ingressRules {
 			}
 		}
 		r := kongstate.Route{
-			Ingress: util.FromK8sObject(&ingress),
+			Ingress: util.FromK8sObject(&ingress, ingressV1beta1GVK),
 			Route: kong.Route{
 				Name:              kong.String(ingress.Namespace + "." + ingress.Name),
 				Paths:             kong.StringSlice("/"),
@@ -175,10 +197,11 @@ func (p *Parser) ingressRulesFromIngressV1() ingressRules {
 
 	for _, ingress := range ingressList {
 		ingressSpec := ingress.Spec
-		log := p.logger.WithFields(logrus.Fields{
+		log := util.WithObjectDebugLevel(p.logger, ingress.Annotations).WithFields(logrus.Fields{
 			"ingress_namespace": ingress.Namespace,
 			"ingress_name":      ingress.Name,
 		})
+		log.Debugf("translating ingress")
 
 		if ingressSpec.DefaultBackend != nil {
 			allDefaultBackends = append(allDefaultBackends, *ingress)
@@ -186,11 +209,13 @@ func (p *Parser) ingressRulesFromIngressV1() ingressRules {
 
 		result.SecretNameToSNIs.addFromIngressV1TLS(ingressSpec.TLS, ingress.Namespace)
 
+		routes := routeLimiter{max: p.maxRoutesPerIngress}
 		var objectSuccessfullyParsed bool
 		for i, rule := range ingressSpec.Rules {
 			if rule.HTTP == nil {
 				continue
 			}
+			dedupedPaths := pathDeduplicator{}
 			for j, rulePath := range rule.HTTP.Paths {
 				if strings.Contains(rulePath.Path, "//") {
 					log.Errorf("rule skipped: invalid path: '%v'", rulePath.Path)
@@ -201,17 +226,33 @@ func (p *Parser) ingressRulesFromIngressV1() ingressRules {
 				if rulePath.PathType != nil {
 					pathType = *rulePath.PathType
 				}
+				if pathHandling, ok := annotations.ExtractPathHandling(ingress.Annotations); ok {
+					pathType = pathTypeForPathHandling[pathHandling]
+				}
 
 				paths, err := pathsFromK8s(rulePath.Path, pathType)
 				if err != nil {
 					log.Errorf("rule skipped: pathsFromK8s: %v", err)
 					continue
 				}
+				if !dedupedPaths.add(paths) {
+					continue
+				}
+				if !routes.allow() {
+					continue
+				}
 
 				r := kongstate.Route{
-					Ingress: util.FromK8sObject(ingress),
+					Ingress: util.FromK8sObject(ingress, ingressV1GVK),
 					Route: kong.Route{
-						Name:              kong.String(fmt.Sprintf("%s.%s.%d%d", ingress.Namespace, ingress.Name, i, j)),
+						Name: kong.String(p.routeNamer.RouteName(RouteNameParams{
+							Namespace: ingress.Namespace,
+							Name:      ingress.Name,
+							Host:      rule.Host,
+							Path:      rulePath.Path,
+							RuleIndex: i,
+							PathIndex: j,
+						})),
 						Paths:             paths,
 						StripPath:         kong.Bool(false),
 						PreserveHost:      kong.Bool(true),
@@ -253,9 +294,12 @@ func (p *Parser) ingressRulesFromIngressV1() ingressRules {
 				service.Routes = append(service.Routes, r)
 				result.ServiceNameToServices[serviceName] = service
 				objectSuccessfullyParsed = true
+				log.Debugf("translated rule into route %s of service %s", *r.Name, serviceName)
 			}
+			p.reportDuplicatePaths(log, ingress, i, dedupedPaths)
 		}
 
+		p.reportRouteLimitExceeded(log, ingress, routes)
 		if objectSuccessfullyParsed {
 			p.ReportKubernetesObjectUpdate(ingress)
 		}
@@ -294,7 +338,7 @@ func (p *Parser) ingressRulesFromIngressV1() ingressRules {
 			}
 		}
 		r := kongstate.Route{
-			Ingress: util.FromK8sObject(&ingress),
+			Ingress: util.FromK8sObject(&ingress, ingressV1GVK),
 			Route: kong.Route{
 				Name:              kong.String(ingress.Namespace + "." + ingress.Name),
 				Paths:             kong.StringSlice("/"),
@@ -312,3 +356,78 @@ func (p *Parser) ingressRulesFromIngressV1() ingressRules {
 
 	return result
 }
+
+// routeLimiter caps the number of routes generated for a single Ingress.
+type routeLimiter struct {
+	max     int
+	allowed int
+	skipped int
+}
+
+// allow reports whether another route can be generated, counting the routes
+// which are allowed and the routes which are skipped. A max of 0 or less
+// allows any number of routes.
+func (l *routeLimiter) allow() bool {
+	if l.max > 0 && l.allowed >= l.max {
+		l.skipped++
+		return false
+	}
+	l.allowed++
+	return true
+}
+
+// pathDeduplicator tracks the Kong paths generated for the paths of a single
+// Ingress rule, so that a path listed more than once in the rule only
+// generates a single route, as Kong rejects the duplicates.
+type pathDeduplicator struct {
+	seen       map[string]struct{}
+	duplicates []string
+}
+
+// add reports whether the provided Kong paths weren't generated for the rule
+// yet, and records them as duplicates otherwise.
+func (d *pathDeduplicator) add(paths []*string) bool {
+	values := make([]string, 0, len(paths))
+	for _, path := range paths {
+		values = append(values, *path)
+	}
+	key := strings.Join(values, ",")
+	if _, ok := d.seen[key]; ok {
+		d.duplicates = append(d.duplicates, key)
+		return false
+	}
+	if d.seen == nil {
+		d.seen = map[string]struct{}{}
+	}
+	d.seen[key] = struct{}{}
+	return true
+}
+
+// reportDuplicatePaths logs and records an event on the provided Ingress if
+// some of the paths of its rule with the provided index were skipped by the
+// path deduplicator.
+func (p *Parser) reportDuplicatePaths(log logrus.FieldLogger, ingress client.Object, ruleIndex int, paths pathDeduplicator) {
+	if len(paths.duplicates) == 0 {
+		return
+	}
+	msg := fmt.Sprintf("duplicate paths of rule %d of the Ingress were skipped: %s",
+		ruleIndex, strings.Join(paths.duplicates, ", "))
+	log.Warn(msg)
+	if p.eventRecorder != nil {
+		p.eventRecorder.Event(ingress, corev1.EventTypeWarning, DuplicateRoutePathReason, msg)
+	}
+}
+
+// reportRouteLimitExceeded logs and records an event on the provided Ingress
+// if some of its routes were skipped by the route limiter.
+func (p *Parser) reportRouteLimitExceeded(log logrus.FieldLogger, ingress client.Object, routes routeLimiter) {
+	if routes.skipped == 0 {
+		return
+	}
+	msg := fmt.Sprintf("%d of the %d routes of the Ingress were skipped: the maximum number of routes of an Ingress is %d",
+		routes.skipped, routes.allowed+routes.skipped, routes.max)
+	log.Error(msg)
+	if p.eventRecorder != nil {
+		p.eventRecorder.Event(ingress, corev1.EventTypeWarning, RouteLimitExceededReason, msg)
+	}
+}
@@ -1,14 +1,23 @@
 package parser
 
 import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/kong/go-kong/kong"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/kongstate"
@@ -861,3 +870,362 @@ func TestFromIngressV1(t *testing.T) {
 		assert.True(ok)
 	})
 }
+
+func TestFromIngressV1DebugAnnotation(t *testing.T) {
+	ingress := func(name string, anns map[string]string) *networkingv1.Ingress {
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: anns},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					Host: name + ".example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{{
+								Path: "/",
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{
+										Name: "foo-svc",
+										Port: networkingv1.ServiceBackendPort{Number: 80},
+									},
+								},
+							}},
+						},
+					},
+				}},
+			},
+		}
+	}
+
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		IngressesV1: []*networkingv1.Ingress{
+			ingress("debugged", map[string]string{
+				annotations.IngressClassKey:                         annotations.DefaultIngressClass,
+				annotations.AnnotationPrefix + annotations.DebugKey: "true",
+			}),
+			ingress("other", map[string]string{
+				annotations.IngressClassKey: annotations.DefaultIngressClass,
+			}),
+		},
+	})
+	assert.NoError(t, err)
+
+	t.Log("translating ingresses with a logger at info level")
+	out := &bytes.Buffer{}
+	log := logrus.New()
+	log.Out = out
+	log.Level = logrus.InfoLevel
+	p := NewParser(log, fakeStore)
+	p.ingressRulesFromIngressV1()
+
+	t.Log("verifying that debug logs were only emitted for the annotated ingress")
+	assert.Contains(t, out.String(), "level=debug msg=\"translating ingress\" ingress_name=debugged")
+	assert.Contains(t, out.String(), "translated rule into route default.debugged.00")
+	assert.NotContains(t, out.String(), "ingress_name=other")
+	assert.Equal(t, logrus.InfoLevel, log.Level)
+}
+
+func TestFromIngressV1PathHandlingAnnotation(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	ingress := func(name, pathHandling string) *networkingv1.Ingress {
+		anns := map[string]string{annotations.IngressClassKey: annotations.DefaultIngressClass}
+		if pathHandling != "" {
+			anns[annotations.AnnotationPrefix+annotations.PathHandlingKey] = pathHandling
+		}
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: anns},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{{
+								Path:     "/foo",
+								PathType: &prefix,
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{
+										Name: name + "-svc",
+										Port: networkingv1.ServiceBackendPort{Number: 80},
+									},
+								},
+							}},
+						},
+					},
+				}},
+			},
+		}
+	}
+
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		IngressesV1: []*networkingv1.Ingress{
+			ingress("none", ""),
+			ingress("exact", "exact"),
+			ingress("prefix", "prefix"),
+			ingress("regex", "regex"),
+			ingress("invalid", "v1"),
+		},
+	})
+	assert.NoError(t, err)
+	parsedInfo := NewParser(logrus.New(), fakeStore).ingressRulesFromIngressV1()
+
+	for _, tt := range []struct {
+		name         string
+		wantPaths    []string
+		wantPriority int
+	}{
+		{name: "none", wantPaths: []string{"/foo$", "/foo/"}, wantPriority: 200},
+		{name: "exact", wantPaths: []string{"/foo$"}, wantPriority: 300},
+		{name: "prefix", wantPaths: []string{"/foo$", "/foo/"}, wantPriority: 200},
+		{name: "regex", wantPaths: []string{"/foo"}, wantPriority: 100},
+		{name: "invalid", wantPaths: []string{"/foo$", "/foo/"}, wantPriority: 200},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			service, ok := parsedInfo.ServiceNameToServices["default."+tt.name+"-svc.pnum-80"]
+			if !assert.True(t, ok) || !assert.Len(t, service.Routes, 1) {
+				return
+			}
+			route := service.Routes[0]
+			var paths []string
+			for _, path := range route.Paths {
+				paths = append(paths, *path)
+			}
+			assert.Equal(t, tt.wantPaths, paths)
+			assert.Equal(t, tt.wantPriority, *route.RegexPriority)
+		})
+	}
+}
+
+func TestFromIngressV1ExactPathIgnoresQuery(t *testing.T) {
+	exact := networkingv1.PathTypeExact
+	path := func(path string) networkingv1.HTTPIngressPath {
+		return networkingv1.HTTPIngressPath{
+			Path:     path,
+			PathType: &exact,
+			Backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{
+					Name: "foo-svc",
+					Port: networkingv1.ServiceBackendPort{Number: 80},
+				},
+			},
+		}
+	}
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		IngressesV1: []*networkingv1.Ingress{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "foo",
+				Namespace:   "default",
+				Annotations: map[string]string{annotations.IngressClassKey: annotations.DefaultIngressClass},
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{path("/foo"), path("/bar?debug=true")},
+						},
+					},
+				}},
+			},
+		}},
+	})
+	require.NoError(t, err)
+	parsedInfo := NewParser(logrus.New(), fakeStore).ingressRulesFromIngressV1()
+
+	service, ok := parsedInfo.ServiceNameToServices["default.foo-svc.pnum-80"]
+	require.True(t, ok)
+	require.Len(t, service.Routes, 2)
+
+	t.Log("verifying that a query string in an exact path is stripped")
+	foo, bar := service.Routes[0], service.Routes[1]
+	require.Equal(t, []*string{kong.String("/foo$")}, foo.Paths)
+	require.Equal(t, []*string{kong.String("/bar$")}, bar.Paths)
+
+	t.Log("verifying that exact paths don't strip the path, so path and query are forwarded unchanged")
+	assert.False(t, *foo.StripPath)
+	assert.False(t, *bar.StripPath)
+
+	// Kong matches the regex paths of routes from the start of the request
+	// path, which doesn't include the query string.
+	matches := func(route kongstate.Route, request string) bool {
+		u, err := url.Parse(request)
+		require.NoError(t, err)
+		for _, p := range route.Paths {
+			if regexp.MustCompile("^" + *p).MatchString(u.Path) {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Log("verifying that exact paths match regardless of the query string")
+	assert.True(t, matches(foo, "/foo"))
+	assert.True(t, matches(foo, "/foo?a=1&b=2"))
+	assert.True(t, matches(bar, "/bar"))
+	assert.True(t, matches(bar, "/bar?debug=false"))
+
+	t.Log("verifying that exact paths don't match other paths")
+	assert.False(t, matches(foo, "/foo/"))
+	assert.False(t, matches(foo, "/foo/baz?a=1"))
+	assert.False(t, matches(foo, "/foobar"))
+}
+
+func TestFromIngressV1MaxRoutesPerIngress(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	ingress := func(name string, hosts, paths int) *networkingv1.Ingress {
+		ingress := &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Annotations: map[string]string{annotations.IngressClassKey: annotations.DefaultIngressClass},
+			},
+		}
+		for h := 0; h < hosts; h++ {
+			rule := networkingv1.IngressRule{
+				Host: fmt.Sprintf("%d.example.com", h),
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{},
+				},
+			}
+			for p := 0; p < paths; p++ {
+				rule.HTTP.Paths = append(rule.HTTP.Paths, networkingv1.HTTPIngressPath{
+					Path:     fmt.Sprintf("/%d", p),
+					PathType: &prefix,
+					Backend: networkingv1.IngressBackend{
+						Service: &networkingv1.IngressServiceBackend{
+							Name: name + "-svc",
+							Port: networkingv1.ServiceBackendPort{Number: 80},
+						},
+					},
+				})
+			}
+			ingress.Spec.Rules = append(ingress.Spec.Rules, rule)
+		}
+		return ingress
+	}
+
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		IngressesV1: []*networkingv1.Ingress{
+			ingress("large", 3, 4),
+			ingress("small", 2, 2),
+		},
+	})
+	require.NoError(t, err)
+	recorder := record.NewFakeRecorder(10)
+	p := NewParser(logrus.New(), fakeStore)
+	p.SetMaxRoutesPerIngress(5)
+	p.SetEventRecorder(recorder)
+	parsedInfo := p.ingressRulesFromIngressV1()
+
+	t.Log("verifying that the routes of an Ingress beyond the limit are skipped")
+	assert.Len(t, parsedInfo.ServiceNameToServices["default.large-svc.pnum-80"].Routes, 5)
+	assert.Len(t, parsedInfo.ServiceNameToServices["default.small-svc.pnum-80"].Routes, 4)
+
+	t.Log("verifying that a single warning event is recorded on the Ingress exceeding the limit")
+	require.Len(t, recorder.Events, 1)
+	event := <-recorder.Events
+	assert.Contains(t, event, corev1.EventTypeWarning)
+	assert.Contains(t, event, RouteLimitExceededReason)
+	assert.Contains(t, event, "7 of the 12 routes")
+
+	t.Log("verifying that the number of routes is not limited by default")
+	parsedInfo = NewParser(logrus.New(), fakeStore).ingressRulesFromIngressV1()
+	assert.Len(t, parsedInfo.ServiceNameToServices["default.large-svc.pnum-80"].Routes, 12)
+}
+
+func TestFromIngressDuplicatePaths(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	v1Path := func(path string) networkingv1.HTTPIngressPath {
+		return networkingv1.HTTPIngressPath{
+			Path:     path,
+			PathType: &prefix,
+			Backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{
+					Name: "foo-svc",
+					Port: networkingv1.ServiceBackendPort{Number: 80},
+				},
+			},
+		}
+	}
+	v1beta1Path := func(path string) networkingv1beta1.HTTPIngressPath {
+		return networkingv1beta1.HTTPIngressPath{
+			Path: path,
+			Backend: networkingv1beta1.IngressBackend{
+				ServiceName: "bar-svc",
+				ServicePort: intstr.FromInt(80),
+			},
+		}
+	}
+	meta := metav1.ObjectMeta{
+		Name:        "duplicates",
+		Namespace:   "default",
+		Annotations: map[string]string{annotations.IngressClassKey: annotations.DefaultIngressClass},
+	}
+	fakeStore, err := store.NewFakeStore(store.FakeObjects{
+		IngressesV1: []*networkingv1.Ingress{{
+			ObjectMeta: meta,
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{
+					{
+						Host: "foo.example.com",
+						IngressRuleValue: networkingv1.IngressRuleValue{
+							HTTP: &networkingv1.HTTPIngressRuleValue{
+								Paths: []networkingv1.HTTPIngressPath{v1Path("/foo"), v1Path("/bar"), v1Path("/foo"), v1Path("/foo/")},
+							},
+						},
+					},
+					{
+						Host: "other.example.com",
+						IngressRuleValue: networkingv1.IngressRuleValue{
+							HTTP: &networkingv1.HTTPIngressRuleValue{
+								Paths: []networkingv1.HTTPIngressPath{v1Path("/foo")},
+							},
+						},
+					},
+				},
+			},
+		}},
+		IngressesV1beta1: []*networkingv1beta1.Ingress{{
+			ObjectMeta: meta,
+			Spec: networkingv1beta1.IngressSpec{
+				Rules: []networkingv1beta1.IngressRule{{
+					Host: "bar.example.com",
+					IngressRuleValue: networkingv1beta1.IngressRuleValue{
+						HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+							Paths: []networkingv1beta1.HTTPIngressPath{v1beta1Path("/baz"), v1beta1Path("/baz")},
+						},
+					},
+				}},
+			},
+		}},
+	})
+	require.NoError(t, err)
+
+	routePaths := func(service kongstate.Service) []string {
+		var paths []string
+		for _, route := range service.Routes {
+			var values []string
+			for _, path := range route.Paths {
+				values = append(values, *path)
+			}
+			paths = append(paths, strings.Join(values, ","))
+		}
+		return paths
+	}
+
+	t.Log("verifying that repeated paths of a networking/v1 rule only generate a single route")
+	recorder := record.NewFakeRecorder(10)
+	p := NewParser(logrus.New(), fakeStore)
+	p.SetEventRecorder(recorder)
+	parsedInfo := p.ingressRulesFromIngressV1()
+	assert.Equal(t, []string{"/foo$,/foo/", "/bar$,/bar/", "/foo$,/foo/"},
+		routePaths(parsedInfo.ServiceNameToServices["default.foo-svc.pnum-80"]),
+		"the same path of another rule must be kept")
+	require.Len(t, recorder.Events, 1)
+	event := <-recorder.Events
+	assert.Contains(t, event, corev1.EventTypeWarning)
+	assert.Contains(t, event, DuplicateRoutePathReason)
+	assert.Contains(t, event, "rule 0")
+
+	t.Log("verifying that repeated paths of a networking/v1beta1 rule only generate a single route")
+	parsedInfo = p.ingressRulesFromIngressV1beta1()
+	assert.Equal(t, []string{"/baz"}, routePaths(parsedInfo.ServiceNameToServices["default.bar-svc.80"]))
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, DuplicateRoutePathReason)
+}
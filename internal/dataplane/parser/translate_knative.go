@@ -45,10 +45,21 @@ func (p *Parser) ingressRulesFromKnativeIngress() ingressRules {
 				if path == "" {
 					path = "/"
 				}
+				var host string
+				if len(hosts) > 0 {
+					host = hosts[0]
+				}
 				r := kongstate.Route{
-					Ingress: util.FromK8sObject(ingress),
+					Ingress: util.FromK8sObject(ingress, knativeIngressGVK),
 					Route: kong.Route{
-						Name:              kong.String(fmt.Sprintf("%s.%s.%d%d", ingress.Namespace, ingress.Name, i, j)),
+						Name: kong.String(p.routeNamer.RouteName(RouteNameParams{
+							Namespace: ingress.Namespace,
+							Name:      ingress.Name,
+							Host:      host,
+							Path:      path,
+							RuleIndex: i,
+							PathIndex: j,
+						})),
 						Paths:             kong.StringSlice(path),
 						StripPath:         kong.Bool(false),
 						PreserveHost:      kong.Bool(true),
@@ -8,6 +8,7 @@ import (
 	"github.com/kong/go-kong/kong"
 	"github.com/sirupsen/logrus"
 
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/kongstate"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/util"
 )
@@ -26,8 +27,13 @@ func (p *Parser) ingressRulesFromTCPIngressV1beta1() ingressRules {
 			&ingressList[j].CreationTimestamp)
 	})
 
+	// ports are claimed by the rules of the oldest TCPIngresses, see
+	// tcpIngressPorts for details.
+	ports := tcpIngressPorts{}
+
 	for _, ingress := range ingressList {
 		ingressSpec := ingress.Spec
+		passthrough := annotations.ExtractTLSPassthrough(ingress.Annotations)
 
 		log := p.logger.WithFields(logrus.Fields{
 			"tcpingress_namespace": ingress.Namespace,
@@ -43,7 +49,7 @@ func (p *Parser) ingressRulesFromTCPIngressV1beta1() ingressRules {
 				continue
 			}
 			r := kongstate.Route{
-				Ingress: util.FromK8sObject(ingress),
+				Ingress: util.FromK8sObject(ingress, tcpIngressGVK),
 				Route: kong.Route{
 					Name:      kong.String(ingress.Namespace + "." + ingress.Name + "." + strconv.Itoa(i)),
 					Protocols: kong.StringSlice("tcp", "tls"),
@@ -66,6 +72,17 @@ func (p *Parser) ingressRulesFromTCPIngressV1beta1() ingressRules {
 				log.Errorf("invalid TCPIngress: invalid servicePort: %v", rule.Backend.ServicePort)
 				continue
 			}
+			if passthrough {
+				if host == "" {
+					log.Errorf("invalid TCPIngress: TLS passthrough rule for port %d has no host", rule.Port)
+					continue
+				}
+				r.Protocols = kong.StringSlice("tls_passthrough")
+			}
+			if err := ports.claim(rule.Port, host, passthrough); err != nil {
+				log.Errorf("invalid TCPIngress: %v", err)
+				continue
+			}
 
 			serviceName := fmt.Sprintf("%s.%s.%d", ingress.Namespace, rule.Backend.ServiceName, rule.Backend.ServicePort)
 			service, ok := result.ServiceNameToServices[serviceName]
@@ -102,6 +119,37 @@ func (p *Parser) ingressRulesFromTCPIngressV1beta1() ingressRules {
 	return result
 }
 
+// tcpIngressPorts tracks the ports used by the rules of TCPIngresses. A port
+// either routes TLS passthrough connections by their SNI or routes plain TCP
+// (and terminated TLS) connections, as Kong can't tell the two apart on the
+// same listener. TLS passthrough rules of a port must have distinct SNIs.
+type tcpIngressPorts map[int]*tcpIngressPort
+
+type tcpIngressPort struct {
+	passthrough bool
+	snis        map[string]struct{}
+}
+
+// claim records a rule for the provided port, or returns an error if the rule
+// conflicts with the rules which already use the port.
+func (ports tcpIngressPorts) claim(port int, sni string, passthrough bool) error {
+	used, ok := ports[port]
+	if !ok {
+		ports[port] = &tcpIngressPort{passthrough: passthrough, snis: map[string]struct{}{sni: {}}}
+		return nil
+	}
+	if used.passthrough != passthrough {
+		return fmt.Errorf("port %d is used by both TLS passthrough and plain TCP rules", port)
+	}
+	if passthrough {
+		if _, ok := used.snis[sni]; ok {
+			return fmt.Errorf("port %d has several TLS passthrough rules for SNI %s", port, sni)
+		}
+		used.snis[sni] = struct{}{}
+	}
+	return nil
+}
+
 func (p *Parser) ingressRulesFromUDPIngressV1beta1() ingressRules {
 	result := newIngressRules()
 
@@ -141,7 +189,7 @@ func (p *Parser) ingressRulesFromUDPIngressV1beta1() ingressRules {
 
 			// generate the kong Route based on the listen port
 			route := kongstate.Route{
-				Ingress: util.FromK8sObject(ingress),
+				Ingress: util.FromK8sObject(ingress, udpIngressGVK),
 				Route: kong.Route{
 					Name:         kong.String(ingress.Namespace + "." + ingress.Name + "." + strconv.Itoa(i) + ".udp"),
 					Protocols:    kong.StringSlice("udp"),
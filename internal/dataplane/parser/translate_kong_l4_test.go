@@ -2,10 +2,12 @@ package parser
 
 import (
 	"testing"
+	"time"
 
 	"github.com/kong/go-kong/kong"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
@@ -307,3 +309,81 @@ func TestFromTCPIngressV1beta1(t *testing.T) {
 		}, parsedInfo)
 	})
 }
+
+func TestFromTCPIngressV1beta1TLSPassthrough(t *testing.T) {
+	now := metav1.Now()
+	tcpIngress := func(name string, created metav1.Time, passthrough bool, rules ...configurationv1beta1.IngressRule) *configurationv1beta1.TCPIngress {
+		anns := map[string]string{annotations.IngressClassKey: annotations.DefaultIngressClass}
+		if passthrough {
+			anns[annotations.AnnotationPrefix+annotations.TLSPassthroughKey] = "true"
+		}
+		return &configurationv1beta1.TCPIngress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				Annotations:       anns,
+				CreationTimestamp: created,
+			},
+			Spec: configurationv1beta1.TCPIngressSpec{Rules: rules},
+		}
+	}
+	rule := func(host string, port int, service string) configurationv1beta1.IngressRule {
+		return configurationv1beta1.IngressRule{
+			Host:    host,
+			Port:    port,
+			Backend: configurationv1beta1.IngressBackend{ServiceName: service, ServicePort: 443},
+		}
+	}
+	parse := func(t *testing.T, ingresses ...*configurationv1beta1.TCPIngress) ingressRules {
+		fakeStore, err := store.NewFakeStore(store.FakeObjects{TCPIngresses: ingresses})
+		require.NoError(t, err)
+		return NewParser(logrus.New(), fakeStore).ingressRulesFromTCPIngressV1beta1()
+	}
+
+	t.Run("SNI rule produces a TLS passthrough route", func(t *testing.T) {
+		parsedInfo := parse(t, tcpIngress("foo", now, true, rule("example.com", 9443, "foo-svc")))
+		require.Len(t, parsedInfo.ServiceNameToServices, 1)
+		svc := parsedInfo.ServiceNameToServices["default.foo-svc.443"]
+		require.Len(t, svc.Routes, 1)
+		assert.Equal(t, kong.Route{
+			Name:         kong.String("default.foo.0"),
+			Protocols:    kong.StringSlice("tls_passthrough"),
+			SNIs:         kong.StringSlice("example.com"),
+			Destinations: []*kong.CIDRPort{{Port: kong.Int(9443)}},
+		}, svc.Routes[0].Route)
+	})
+
+	t.Run("TLS passthrough rule without host is rejected", func(t *testing.T) {
+		parsedInfo := parse(t, tcpIngress("foo", now, true, rule("", 9443, "foo-svc")))
+		assert.Empty(t, parsedInfo.ServiceNameToServices)
+	})
+
+	t.Run("plain TCP rule on a TLS passthrough port is rejected", func(t *testing.T) {
+		parsedInfo := parse(t,
+			tcpIngress("passthrough", now, true, rule("example.com", 9443, "foo-svc")),
+			tcpIngress("plain", metav1.NewTime(now.Add(time.Minute)), false, rule("", 9443, "bar-svc")),
+		)
+		require.Len(t, parsedInfo.ServiceNameToServices, 1)
+		assert.Contains(t, parsedInfo.ServiceNameToServices, "default.foo-svc.443")
+	})
+
+	t.Run("TLS passthrough rule on a plain TCP port is rejected", func(t *testing.T) {
+		parsedInfo := parse(t,
+			tcpIngress("plain", now, false, rule("", 9443, "bar-svc")),
+			tcpIngress("passthrough", metav1.NewTime(now.Add(time.Minute)), true, rule("example.com", 9443, "foo-svc")),
+		)
+		require.Len(t, parsedInfo.ServiceNameToServices, 1)
+		assert.Contains(t, parsedInfo.ServiceNameToServices, "default.bar-svc.443")
+	})
+
+	t.Run("TLS passthrough rules with the same SNI on a port are rejected", func(t *testing.T) {
+		parsedInfo := parse(t, tcpIngress("foo", now, true,
+			rule("example.com", 9443, "foo-svc"),
+			rule("example.com", 9443, "bar-svc"),
+			rule("other.example.com", 9443, "baz-svc"),
+		))
+		require.Len(t, parsedInfo.ServiceNameToServices, 2)
+		assert.Contains(t, parsedInfo.ServiceNameToServices, "default.foo-svc.443")
+		assert.Contains(t, parsedInfo.ServiceNameToServices, "default.baz-svc.443")
+	})
+}
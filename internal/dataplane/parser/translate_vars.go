@@ -1,5 +1,14 @@
 package parser
 
+import (
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	knative "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1beta1"
+)
+
 // -----------------------------------------------------------------------------
 // Translation - Vars & Constants
 // -----------------------------------------------------------------------------
@@ -17,4 +26,20 @@ const (
 	// DefaultHTTPPort is the network port that should be assumed by default
 	// for HTTP traffic to services.
 	DefaultHTTPPort = 80
+
+	// FallbackTargetWeight is the weight given to the targets of an upstream's
+	// fallback Service. Kong's default target weight is 100, so while primary
+	// targets are healthy fallback targets only receive a marginal share of traffic.
+	FallbackTargetWeight = 1
+)
+
+// The kinds of the objects which routes are generated for. Extensions v1beta1
+// Ingresses are converted to networking v1beta1 Ingresses by the store.
+var (
+	ingressV1GVK      = networkingv1.SchemeGroupVersion.WithKind("Ingress")
+	ingressV1beta1GVK = networkingv1beta1.SchemeGroupVersion.WithKind("Ingress")
+	tcpIngressGVK     = configurationv1beta1.SchemeGroupVersion.WithKind("TCPIngress")
+	udpIngressGVK     = configurationv1beta1.SchemeGroupVersion.WithKind("UDPIngress")
+	knativeIngressGVK = knative.SchemeGroupVersion.WithKind("Ingress")
+	httpRouteGVK      = gatewayv1alpha2.SchemeGroupVersion.WithKind("HTTPRoute")
 )
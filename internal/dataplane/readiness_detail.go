@@ -0,0 +1,82 @@
+package dataplane
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// -----------------------------------------------------------------------------
+// Readiness Detail - Public Types
+// -----------------------------------------------------------------------------
+
+// ReadinessGate is a named condition which must be met for the controller to
+// be ready, e.g. caches being synced or the Kong Admin API being reachable.
+// Check returns nil when the gate is met and the reason why it isn't otherwise.
+type ReadinessGate struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// ReadinessDetailHandler is an HTTP handler which reports which readiness
+// gates are unmet. It responds with 200 OK when all gates are met and with 503
+// Service Unavailable otherwise. The JSON body lists the unmet gates and their
+// reasons, and also lists the met gates when the "verbose" query parameter is
+// set, as /readyz?verbose does.
+type ReadinessDetailHandler struct {
+	gates []ReadinessGate
+}
+
+// ReadinessDetail is the JSON body served by the ReadinessDetailHandler.
+type ReadinessDetail struct {
+	Ready bool                `json:"ready"`
+	Gates []ReadinessGateInfo `json:"gates"`
+}
+
+// ReadinessGateInfo is the state of a single readiness gate.
+type ReadinessGateInfo struct {
+	Name   string `json:"name"`
+	Met    bool   `json:"met"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// NewReadinessDetailHandler provides a new ReadinessDetailHandler checking the
+// provided gates, in order.
+func NewReadinessDetailHandler(gates ...ReadinessGate) *ReadinessDetailHandler {
+	return &ReadinessDetailHandler{gates: gates}
+}
+
+// -----------------------------------------------------------------------------
+// Readiness Detail - Public Methods
+// -----------------------------------------------------------------------------
+
+// Detail checks all the readiness gates. When verbose is false only the unmet
+// gates are listed.
+func (h *ReadinessDetailHandler) Detail(ctx context.Context, verbose bool) ReadinessDetail {
+	detail := ReadinessDetail{Ready: true, Gates: []ReadinessGateInfo{}}
+	for _, gate := range h.gates {
+		info := ReadinessGateInfo{Name: gate.Name, Met: true}
+		if err := gate.Check(ctx); err != nil {
+			info.Met = false
+			info.Reason = err.Error()
+			detail.Ready = false
+		}
+		if verbose || !info.Met {
+			detail.Gates = append(detail.Gates, info)
+		}
+	}
+	return detail
+}
+
+// ServeHTTP satisfies the http.Handler interface.
+func (h *ReadinessDetailHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	_, verbose := req.URL.Query()["verbose"]
+	detail := h.Detail(req.Context(), verbose)
+	rw.Header().Set("Content-Type", "application/json")
+	if detail.Ready {
+		rw.WriteHeader(http.StatusOK)
+	} else {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(rw).Encode(detail)
+}
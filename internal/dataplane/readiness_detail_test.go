@@ -0,0 +1,68 @@
+package dataplane
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadinessDetailHandler(t *testing.T) {
+	var cachesSynced, configApplied bool
+	var adminAPIErr error
+	gate := func(name string, met *bool, reason string) ReadinessGate {
+		return ReadinessGate{Name: name, Check: func(context.Context) error {
+			if !*met {
+				return errors.New(reason)
+			}
+			return nil
+		}}
+	}
+	h := NewReadinessDetailHandler(
+		gate("caches-synced", &cachesSynced, "caches not synced"),
+		gate("first-sync", &configApplied, "first sync pending"),
+		ReadinessGate{Name: "admin-api", Check: func(context.Context) error { return adminAPIErr }},
+	)
+	serve := func(target string) (int, ReadinessDetail) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		var detail ReadinessDetail
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&detail))
+		return rec.Code, detail
+	}
+
+	t.Log("verifying that every unmet gate is reported with its reason")
+	adminAPIErr = errors.New("dial tcp 127.0.0.1:8001: connect: connection refused")
+	code, detail := serve("/readiness")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, ReadinessDetail{Ready: false, Gates: []ReadinessGateInfo{
+		{Name: "caches-synced", Reason: "caches not synced"},
+		{Name: "first-sync", Reason: "first sync pending"},
+		{Name: "admin-api", Reason: "dial tcp 127.0.0.1:8001: connect: connection refused"},
+	}}, detail)
+
+	t.Log("verifying that met gates are only listed in verbose mode")
+	cachesSynced = true
+	adminAPIErr = nil
+	code, detail = serve("/readiness")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, []ReadinessGateInfo{{Name: "first-sync", Reason: "first sync pending"}}, detail.Gates)
+	code, detail = serve("/readiness?verbose")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, []ReadinessGateInfo{
+		{Name: "caches-synced", Met: true},
+		{Name: "first-sync", Reason: "first sync pending"},
+		{Name: "admin-api", Met: true},
+	}, detail.Gates)
+
+	t.Log("verifying that the handler is ready once all gates are met")
+	configApplied = true
+	code, detail = serve("/readiness")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, ReadinessDetail{Ready: true, Gates: []ReadinessGateInfo{}}, detail)
+}
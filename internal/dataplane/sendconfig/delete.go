@@ -0,0 +1,286 @@
+package sendconfig
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kong/deck/state"
+	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
+)
+
+// -----------------------------------------------------------------------------
+// Sendconfig - Batched Deletes - Public Types
+// -----------------------------------------------------------------------------
+
+// EntityRef identifies an entity of the Kong Admin API by the collection it
+// belongs to (e.g. "routes") and its ID.
+type EntityRef struct {
+	Collection string
+	ID         string
+}
+
+// String provides the Admin API path of the entity.
+func (e EntityRef) String() string {
+	return fmt.Sprintf("/%s/%s", e.Collection, e.ID)
+}
+
+// DeleteReport is the outcome of DeleteInBatches. As the Admin API doesn't
+// support transactions, deletes which were applied before a failure can't be
+// rolled back: the report describes the partial state left in Kong instead.
+type DeleteReport struct {
+	// Deleted are the entities which were deleted or which were already gone.
+	Deleted []EntityRef
+
+	// Failed are the entities which could not be deleted.
+	Failed []EntityRef
+
+	// Skipped are the entities which were not attempted because an earlier
+	// batch failed.
+	Skipped []EntityRef
+}
+
+// Partial indicates whether only some of the entities were deleted.
+func (r DeleteReport) Partial() bool {
+	return len(r.Deleted) > 0 && (len(r.Failed) > 0 || len(r.Skipped) > 0)
+}
+
+// -----------------------------------------------------------------------------
+// Sendconfig - Batched Deletes - Public Functions
+// -----------------------------------------------------------------------------
+
+// DeleteInBatches deletes the provided entities from the Kong Admin API level
+// by level, in batches of batchSize concurrent deletes. Levels must be ordered
+// so that dependent entities (e.g. plugins, routes) come before the entities
+// they depend on (e.g. services). A batch is only started once the previous
+// batch fully succeeded, so that after a failure the cleanup stops at a batch
+// boundary: the remaining entities are reported as skipped and can be retried
+// on the next sync. A batchSize of 0 or less deletes the entities one at a
+// time.
+func DeleteInBatches(
+	ctx context.Context,
+	log logrus.FieldLogger,
+	client *kong.Client,
+	levels [][]EntityRef,
+	batchSize int,
+) (DeleteReport, error) {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	var report DeleteReport
+	for level, entities := range levels {
+		for start := 0; start < len(entities); start += batchSize {
+			end := start + batchSize
+			if end > len(entities) {
+				end = len(entities)
+			}
+			batch := entities[start:end]
+
+			errs := make([]error, len(batch))
+			var wg sync.WaitGroup
+			for i, entity := range batch {
+				wg.Add(1)
+				go func(i int, entity EntityRef) {
+					defer wg.Done()
+					errs[i] = deleteEntity(ctx, client, entity)
+				}(i, entity)
+			}
+			wg.Wait()
+
+			var batchErrs []error
+			for i, err := range errs {
+				if err != nil {
+					report.Failed = append(report.Failed, batch[i])
+					batchErrs = append(batchErrs, fmt.Errorf("deleting %s: %w", batch[i], err))
+					continue
+				}
+				report.Deleted = append(report.Deleted, batch[i])
+			}
+
+			if len(batchErrs) > 0 {
+				report.Skipped = append(report.Skipped, entities[end:]...)
+				for _, remaining := range levels[level+1:] {
+					report.Skipped = append(report.Skipped, remaining...)
+				}
+				log.WithFields(logrus.Fields{
+					"deleted": len(report.Deleted),
+					"failed":  len(report.Failed),
+					"skipped": len(report.Skipped),
+				}).Errorf("deletes partially failed, remaining entities will be retried on the next sync: failed entities: %v", report.Failed)
+				total := len(report.Deleted) + len(report.Failed) + len(report.Skipped)
+				return report, fmt.Errorf("%d of %d deletes failed and %d were skipped: %v",
+					len(report.Failed), total, len(report.Skipped), batchErrs)
+			}
+		}
+	}
+	return report, nil
+}
+
+// -----------------------------------------------------------------------------
+// Sendconfig - Batched Deletes - Private Functions
+// -----------------------------------------------------------------------------
+
+// detachOrphans removes the entities of the current state which are absent
+// from the target state, so that the deck syncer only creates and updates
+// entities, and provides them grouped in the order they must be deleted in:
+// plugins, routes and SNIs, then services, then upstreams, then certificates.
+// Targets of orphaned upstreams are removed along with them, as Kong deletes
+// them with their upstream. Other entities (e.g. consumers) are left to the
+// syncer.
+func detachOrphans(current, target *state.KongState) ([][]EntityRef, error) {
+	var routesAndPlugins, services, upstreams, certificates []EntityRef
+
+	plugins, err := current.Plugins.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range plugins {
+		serviceID, routeID, consumerID := pluginForeignIDs(p)
+		if _, err := target.Plugins.GetByProp(*p.Name, serviceID, routeID, consumerID); err == nil {
+			continue
+		} else if err != state.ErrNotFound {
+			return nil, err
+		}
+		if err := current.Plugins.Delete(*p.ID); err != nil {
+			return nil, err
+		}
+		routesAndPlugins = append(routesAndPlugins, EntityRef{Collection: "plugins", ID: *p.ID})
+	}
+
+	routes, err := current.Routes.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range routes {
+		if _, err := target.Routes.Get(*r.ID); err == nil {
+			continue
+		} else if err != state.ErrNotFound {
+			return nil, err
+		}
+		if err := current.Routes.Delete(*r.ID); err != nil {
+			return nil, err
+		}
+		routesAndPlugins = append(routesAndPlugins, EntityRef{Collection: "routes", ID: *r.ID})
+	}
+
+	snis, err := current.SNIs.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range snis {
+		if _, err := target.SNIs.Get(*s.ID); err == nil {
+			continue
+		} else if err != state.ErrNotFound {
+			return nil, err
+		}
+		if err := current.SNIs.Delete(*s.ID); err != nil {
+			return nil, err
+		}
+		routesAndPlugins = append(routesAndPlugins, EntityRef{Collection: "snis", ID: *s.ID})
+	}
+
+	currentServices, err := current.Services.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range currentServices {
+		if _, err := target.Services.Get(*s.ID); err == nil {
+			continue
+		} else if err != state.ErrNotFound {
+			return nil, err
+		}
+		if err := current.Services.Delete(*s.ID); err != nil {
+			return nil, err
+		}
+		services = append(services, EntityRef{Collection: "services", ID: *s.ID})
+	}
+
+	currentUpstreams, err := current.Upstreams.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range currentUpstreams {
+		if _, err := target.Upstreams.Get(*u.ID); err == nil {
+			continue
+		} else if err != state.ErrNotFound {
+			return nil, err
+		}
+		targets, err := current.Targets.GetAllByUpstreamID(*u.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range targets {
+			if err := current.Targets.Delete(*u.ID, *t.ID); err != nil {
+				return nil, err
+			}
+		}
+		if err := current.Upstreams.Delete(*u.ID); err != nil {
+			return nil, err
+		}
+		upstreams = append(upstreams, EntityRef{Collection: "upstreams", ID: *u.ID})
+	}
+
+	currentCertificates, err := current.Certificates.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range currentCertificates {
+		if _, err := target.Certificates.Get(*c.ID); err == nil {
+			continue
+		} else if err != state.ErrNotFound {
+			return nil, err
+		}
+		if err := current.Certificates.Delete(*c.ID); err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, EntityRef{Collection: "certificates", ID: *c.ID})
+	}
+
+	caCertificates, err := current.CACertificates.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range caCertificates {
+		if _, err := target.CACertificates.Get(*c.ID); err == nil {
+			continue
+		} else if err != state.ErrNotFound {
+			return nil, err
+		}
+		if err := current.CACertificates.Delete(*c.ID); err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, EntityRef{Collection: "ca_certificates", ID: *c.ID})
+	}
+
+	return [][]EntityRef{routesAndPlugins, services, upstreams, certificates}, nil
+}
+
+// pluginForeignIDs provides the IDs of the service, route and consumer the
+// plugin is attached to, which identify the plugin across states.
+func pluginForeignIDs(p *state.Plugin) (serviceID, routeID, consumerID string) {
+	if p.Service != nil && p.Service.ID != nil {
+		serviceID = *p.Service.ID
+	}
+	if p.Route != nil && p.Route.ID != nil {
+		routeID = *p.Route.ID
+	}
+	if p.Consumer != nil && p.Consumer.ID != nil {
+		consumerID = *p.Consumer.ID
+	}
+	return
+}
+
+// deleteEntity deletes a single entity, entities which are already gone are
+// considered deleted.
+func deleteEntity(ctx context.Context, client *kong.Client, entity EntityRef) error {
+	req, err := client.NewRequest("DELETE", entity.String(), nil, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := client.Do(ctx, req, nil); err != nil && !kong.IsNotFoundErr(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package sendconfig
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/kong/deck/state"
+	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAdminAPI serves DELETE requests, failing for the configured paths and
+// responding with 404 for the paths of entities which are already gone.
+type fakeAdminAPI struct {
+	lock    sync.Mutex
+	deleted []string
+	failing map[string]bool
+	gone    map[string]bool
+}
+
+func (f *fakeAdminAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	switch {
+	case r.Method != http.MethodDelete:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	case f.failing[r.URL.Path]:
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"message":"an unexpected error occurred"}`))
+	case f.gone[r.URL.Path]:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Not found"}`))
+	default:
+		f.deleted = append(f.deleted, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestDeleteInBatches(t *testing.T) {
+	levels := [][]EntityRef{
+		{
+			{Collection: "plugins", ID: "p1"},
+			{Collection: "routes", ID: "r1"},
+			{Collection: "routes", ID: "r2"},
+		},
+		{
+			{Collection: "services", ID: "s1"},
+			{Collection: "services", ID: "s2"},
+		},
+	}
+
+	t.Run("all deletes succeed", func(t *testing.T) {
+		adminAPI := &fakeAdminAPI{gone: map[string]bool{"/routes/r2": true}}
+		report, err := DeleteInBatches(context.Background(), logrus.New(), newFakeAdminAPIClient(t, adminAPI), levels, 2)
+		require.NoError(t, err)
+		assert.Equal(t, append(levels[0], levels[1]...), report.Deleted)
+		assert.Empty(t, report.Failed)
+		assert.Empty(t, report.Skipped)
+		assert.False(t, report.Partial())
+		assert.Len(t, adminAPI.deleted, 4)
+	})
+
+	t.Run("a delete fails mid-way", func(t *testing.T) {
+		adminAPI := &fakeAdminAPI{failing: map[string]bool{"/routes/r1": true}}
+		report, err := DeleteInBatches(context.Background(), logrus.New(), newFakeAdminAPIClient(t, adminAPI), levels, 2)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "1 of 5 deletes failed and 3 were skipped")
+		assert.Contains(t, err.Error(), "deleting /routes/r1")
+
+		t.Log("verifying that the batch of the failing delete completed and later batches and levels were not started")
+		assert.Equal(t, []EntityRef{levels[0][0]}, report.Deleted)
+		assert.Equal(t, []EntityRef{levels[0][1]}, report.Failed)
+		assert.Equal(t, []EntityRef{levels[0][2], levels[1][0], levels[1][1]}, report.Skipped)
+		assert.True(t, report.Partial())
+		assert.Equal(t, []string{"/plugins/p1"}, adminAPI.deleted)
+	})
+
+	t.Run("deletes are performed one at a time by default", func(t *testing.T) {
+		adminAPI := &fakeAdminAPI{failing: map[string]bool{"/plugins/p1": true}}
+		report, err := DeleteInBatches(context.Background(), logrus.New(), newFakeAdminAPIClient(t, adminAPI), levels, 0)
+		require.Error(t, err)
+		assert.Empty(t, report.Deleted)
+		assert.Equal(t, []EntityRef{levels[0][0]}, report.Failed)
+		assert.Equal(t, []EntityRef{levels[0][1], levels[0][2], levels[1][0], levels[1][1]}, report.Skipped)
+		assert.False(t, report.Partial())
+		assert.Empty(t, adminAPI.deleted)
+	})
+}
+
+func TestSyncDBModeDeletesInBatches(t *testing.T) {
+	newStates := func(t *testing.T) (current, target *state.KongState) {
+		current, err := state.NewKongState()
+		require.NoError(t, err)
+		target, err = state.NewKongState()
+		require.NoError(t, err)
+
+		for _, s := range []*state.KongState{current, target} {
+			require.NoError(t, s.Services.Add(state.Service{Service: kong.Service{ID: kong.String("s-kept"), Name: kong.String("kept")}}))
+		}
+		require.NoError(t, current.Services.Add(state.Service{Service: kong.Service{ID: kong.String("s1"), Name: kong.String("s1")}}))
+		require.NoError(t, current.Routes.Add(state.Route{Route: kong.Route{
+			ID:      kong.String("r1"),
+			Name:    kong.String("r1"),
+			Service: &kong.Service{ID: kong.String("s1")},
+		}}))
+		require.NoError(t, current.Plugins.Add(state.Plugin{Plugin: kong.Plugin{
+			ID:    kong.String("p1"),
+			Name:  kong.String("cors"),
+			Route: &kong.Route{ID: kong.String("r1")},
+		}}))
+		require.NoError(t, current.Upstreams.Add(state.Upstream{Upstream: kong.Upstream{ID: kong.String("u1"), Name: kong.String("u1")}}))
+		require.NoError(t, current.Targets.Add(state.Target{Target: kong.Target{
+			ID:       kong.String("t1"),
+			Target:   kong.String("10.0.0.1:80"),
+			Upstream: &kong.Upstream{ID: kong.String("u1")},
+		}}))
+		require.NoError(t, current.Certificates.Add(state.Certificate{Certificate: kong.Certificate{
+			ID:   kong.String("c1"),
+			Cert: kong.String("cert"),
+			Key:  kong.String("key"),
+		}}))
+		return current, target
+	}
+
+	t.Run("entities are deleted by dependency level", func(t *testing.T) {
+		adminAPI := &fakeAdminAPI{}
+		kongConfig := &Kong{Client: newFakeAdminAPIClient(t, adminAPI), Concurrency: 10, DeleteBatchSize: 1}
+		current, target := newStates(t)
+		require.NoError(t, syncDBMode(context.Background(), logrus.New(), kongConfig, current, target))
+		assert.Equal(t, []string{"/plugins/p1", "/routes/r1", "/services/s1", "/upstreams/u1", "/certificates/c1"}, adminAPI.deleted)
+	})
+
+	t.Run("a failing delete is reported and stops the deletes of the entities it depends on", func(t *testing.T) {
+		adminAPI := &fakeAdminAPI{failing: map[string]bool{"/routes/r1": true}}
+		kongConfig := &Kong{Client: newFakeAdminAPIClient(t, adminAPI), Concurrency: 10}
+		current, target := newStates(t)
+		log, hook := test.NewNullLogger()
+		err := syncDBMode(context.Background(), log, kongConfig, current, target)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "1 of 5 deletes failed and 3 were skipped")
+		assert.Contains(t, err.Error(), "deleting /routes/r1")
+
+		t.Log("verifying that the plugin batch completed and that services, upstreams and certificates were not deleted")
+		assert.Equal(t, []string{"/plugins/p1"}, adminAPI.deleted)
+
+		t.Log("verifying that the partial state is logged")
+		entry := hook.LastEntry()
+		require.NotNil(t, entry)
+		assert.Equal(t, logrus.ErrorLevel, entry.Level)
+		assert.Equal(t, logrus.Fields{"deleted": 1, "failed": 1, "skipped": 3}, entry.Data)
+		assert.Contains(t, entry.Message, "/routes/r1")
+	})
+}
+
+func newFakeAdminAPIClient(t *testing.T, adminAPI *fakeAdminAPI) *kong.Client {
+	server := httptest.NewServer(adminAPI)
+	t.Cleanup(server.Close)
+	client, err := kong.NewClient(kong.String(server.URL), server.Client())
+	require.NoError(t, err)
+	return client
+}
@@ -29,4 +29,7 @@ type Kong struct {
 	Version semver.Version
 
 	Concurrency int
+	// DeleteBatchSize is the number of concurrent deletes of entities removed
+	// from the configuration. 0 uses Concurrency.
+	DeleteBatchSize int
 }
@@ -61,7 +61,7 @@ func PerformUpdate(ctx context.Context,
 		err = onUpdateInMemoryMode(ctx, log, targetContent, customEntities, kongConfig)
 	} else {
 		metricsProtocol = metrics.ProtocolDeck
-		err = onUpdateDBMode(ctx, targetContent, kongConfig, selectorTags)
+		err = onUpdateDBMode(ctx, log, targetContent, kongConfig, selectorTags)
 	}
 	timeEnd := time.Now()
 
@@ -180,6 +180,7 @@ func onUpdateInMemoryMode(ctx context.Context,
 }
 
 func onUpdateDBMode(ctx context.Context,
+	log logrus.FieldLogger,
 	targetContent *file.Content,
 	kongConfig *Kong,
 	selectorTags []string,
@@ -208,6 +209,23 @@ func onUpdateDBMode(ctx context.Context,
 		return err
 	}
 
+	return syncDBMode(ctx, log, kongConfig, currentState, targetState)
+}
+
+// syncDBMode applies the target state to Kong. The entities to delete are
+// detached from the deck sync and deleted afterwards in dependency-ordered
+// batches, so that a failing delete stops the cleanup before the entities
+// which the remaining ones depend on.
+func syncDBMode(ctx context.Context,
+	log logrus.FieldLogger,
+	kongConfig *Kong,
+	currentState, targetState *state.KongState,
+) error {
+	orphans, err := detachOrphans(currentState, targetState)
+	if err != nil {
+		return fmt.Errorf("computing the entities to delete: %w", err)
+	}
+
 	syncer, err := diff.NewSyncer(diff.SyncerOpts{
 		CurrentState:    currentState,
 		TargetState:     targetState,
@@ -221,7 +239,13 @@ func onUpdateDBMode(ctx context.Context,
 	if errs != nil {
 		return deckutils.ErrArray{Errors: errs}
 	}
-	return nil
+
+	batchSize := kongConfig.DeleteBatchSize
+	if batchSize <= 0 {
+		batchSize = kongConfig.Concurrency
+	}
+	_, err = DeleteInBatches(ctx, log, kongConfig.Client, orphans, batchSize)
+	return err
 }
 
 func equalSHA(a, b []byte) bool {
@@ -0,0 +1,110 @@
+package dataplane
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Sync Readiness - Public Types
+// -----------------------------------------------------------------------------
+
+// SyncReadinessHandler is an HTTP handler which reports whether configuration
+// was successfully synced to the Kong Admin API recently. It responds with
+// 200 OK when the last successful sync happened within the staleness window
+// and with 503 Service Unavailable otherwise, with a JSON body describing the
+// last successful sync and the last sync error. It's safe for concurrent use.
+type SyncReadinessHandler struct {
+	staleness time.Duration
+	now       func() time.Time
+
+	// lastSync is the time of the last successful sync in Unix nanoseconds,
+	// 0 if no sync succeeded yet.
+	lastSync int64
+	// lastError is the error of the last failed sync since the last
+	// successful one, "" if there is none.
+	lastError atomic.Value
+}
+
+// SyncReadiness is the JSON body served by the SyncReadinessHandler.
+type SyncReadiness struct {
+	Ready              bool       `json:"ready"`
+	LastSuccessfulSync *time.Time `json:"lastSuccessfulSync,omitempty"`
+	LastError          string     `json:"lastError,omitempty"`
+}
+
+// NewSyncReadinessHandler provides a new SyncReadinessHandler which reports
+// syncs older than the provided staleness window as not ready.
+func NewSyncReadinessHandler(staleness time.Duration) *SyncReadinessHandler {
+	h := &SyncReadinessHandler{
+		staleness: staleness,
+		now:       time.Now,
+	}
+	h.lastError.Store("")
+	return h
+}
+
+// -----------------------------------------------------------------------------
+// Sync Readiness - Public Methods
+// -----------------------------------------------------------------------------
+
+// RecordSuccess records a successful sync which happened at the provided time
+// and clears the last sync error. Syncs older than the last recorded one don't
+// move the last successful sync backwards.
+func (h *SyncReadinessHandler) RecordSuccess(at time.Time) {
+	for {
+		last := atomic.LoadInt64(&h.lastSync)
+		if at.UnixNano() <= last || atomic.CompareAndSwapInt64(&h.lastSync, last, at.UnixNano()) {
+			break
+		}
+	}
+	h.lastError.Store("")
+}
+
+// RecordFailure records a failed sync. The last successful sync is kept.
+func (h *SyncReadinessHandler) RecordFailure(err error) {
+	if err == nil {
+		return
+	}
+	h.lastError.Store(err.Error())
+}
+
+// Readiness reports the current sync readiness.
+func (h *SyncReadinessHandler) Readiness() SyncReadiness {
+	readiness := SyncReadiness{LastError: h.lastError.Load().(string)}
+	if last := atomic.LoadInt64(&h.lastSync); last != 0 {
+		lastSync := time.Unix(0, last).UTC()
+		readiness.LastSuccessfulSync = &lastSync
+		readiness.Ready = h.now().Sub(lastSync) <= h.staleness
+	}
+	return readiness
+}
+
+// Check is a controller-runtime healthz.Checker which fails when the last
+// successful sync is older than the staleness window.
+func (h *SyncReadinessHandler) Check(_ *http.Request) error {
+	readiness := h.Readiness()
+	if readiness.Ready {
+		return nil
+	}
+	if readiness.LastSuccessfulSync == nil {
+		return fmt.Errorf("no successful sync to the Kong Admin API yet")
+	}
+	return fmt.Errorf("last successful sync to the Kong Admin API at %s is older than %s",
+		readiness.LastSuccessfulSync.Format(time.RFC3339), h.staleness)
+}
+
+// ServeHTTP satisfies the http.Handler interface.
+func (h *SyncReadinessHandler) ServeHTTP(rw http.ResponseWriter, _ *http.Request) {
+	readiness := h.Readiness()
+	rw.Header().Set("Content-Type", "application/json")
+	if readiness.Ready {
+		rw.WriteHeader(http.StatusOK)
+	} else {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(rw).Encode(readiness)
+}
@@ -0,0 +1,70 @@
+package dataplane
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncReadinessHandler(t *testing.T) {
+	now := time.Date(2022, time.March, 1, 12, 0, 0, 0, time.UTC)
+	h := NewSyncReadinessHandler(time.Minute)
+	h.now = func() time.Time { return now }
+
+	serve := func() (int, SyncReadiness) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sync-readiness", nil))
+		var readiness SyncReadiness
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&readiness))
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		return rec.Code, readiness
+	}
+
+	t.Log("verifying that the handler is not ready before any successful sync")
+	code, readiness := serve()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Nil(t, readiness.LastSuccessfulSync)
+	assert.Error(t, h.Check(nil))
+
+	t.Log("verifying that a recent successful sync makes the handler ready")
+	h.RecordSuccess(now.Add(-30 * time.Second))
+	code, readiness = serve()
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, readiness.Ready)
+	require.NotNil(t, readiness.LastSuccessfulSync)
+	assert.True(t, now.Add(-30*time.Second).Equal(*readiness.LastSuccessfulSync))
+	assert.NoError(t, h.Check(nil))
+
+	t.Log("verifying that the handler is not ready once the last successful sync is older than the staleness window")
+	now = now.Add(time.Minute)
+	h.RecordFailure(errors.New("connection refused"))
+	code, readiness = serve()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, readiness.Ready)
+	assert.Equal(t, "connection refused", readiness.LastError)
+	assert.Error(t, h.Check(nil))
+
+	t.Log("verifying that recording an older sync does not move the last successful sync backwards")
+	h.RecordSuccess(now.Add(-time.Hour))
+	code, readiness = serve()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.True(t, now.Add(-90*time.Second).Equal(*readiness.LastSuccessfulSync))
+	assert.Empty(t, readiness.LastError, "a successful sync clears the last error")
+
+	t.Log("verifying that a new successful sync makes the handler ready again")
+	h.RecordSuccess(now)
+	code, readiness = serve()
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, now.Equal(*readiness.LastSuccessfulSync))
+
+	t.Log("verifying that moving the clock backwards keeps the handler ready")
+	now = now.Add(-time.Hour)
+	code, _ = serve()
+	assert.Equal(t, http.StatusOK, code)
+}
@@ -26,6 +26,10 @@ const (
 	//
 	// See Also: https://github.com/Kong/kubernetes-ingress-controller/issues/1398
 	DefaultSyncSeconds float32 = 3.0
+
+	// MaxInitialSyncDelay is the longest delay which the first update to the
+	// DataplaneClient can be configured to wait for.
+	MaxInitialSyncDelay = 10 * time.Minute
 )
 
 // -----------------------------------------------------------------------------
@@ -33,18 +37,25 @@ const (
 // -----------------------------------------------------------------------------
 
 // Synchronizer is a threadsafe object which starts a goroutine to updates
-// the data-plane at regular intervals.
+// the data-plane at regular intervals and, when a sync debounce is configured,
+// shortly after changes are notified.
 type Synchronizer struct {
 	logger logr.Logger
 
 	// dataplane client to send updates to the Kong Admin API
 	dataplaneClient Client
 
+	// syncReadiness, if set, records the outcome of every update
+	syncReadiness *SyncReadinessHandler
+
 	// server configuration, flow control, channels and utility attributes
-	stagger         time.Duration
-	syncTicker      *time.Ticker
-	configApplied   bool
-	isServerRunning bool
+	stagger          time.Duration
+	initialSyncDelay time.Duration
+	syncDebounce     time.Duration
+	syncTicker       *time.Ticker
+	changes          chan struct{}
+	configApplied    bool
+	isServerRunning  bool
 
 	lock sync.RWMutex
 }
@@ -69,6 +80,7 @@ func NewSynchronizerWithStagger(logger logrus.FieldLogger, dataplaneClient Clien
 		logger:          logrusr.New(logger),
 		dataplaneClient: dataplaneClient,
 		stagger:         stagger,
+		changes:         make(chan struct{}, 1),
 		configApplied:   false,
 	}
 
@@ -97,12 +109,57 @@ func (p *Synchronizer) Start(ctx context.Context) error {
 	}
 
 	p.syncTicker = time.NewTicker(p.stagger)
-	go p.startUpdateServer(ctx)
+	go p.startUpdateServer(ctx, p.initialSyncDelay, p.syncDebounce)
 	p.isServerRunning = true
 
 	return nil
 }
 
+// SetSyncReadinessHandler configures the synchronizer to record the outcome of
+// every update to the data-plane in the provided SyncReadinessHandler.
+func (p *Synchronizer) SetSyncReadinessHandler(h *SyncReadinessHandler) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.syncReadiness = h
+}
+
+// SetInitialSyncDelay configures the synchronizer to wait for the provided
+// delay after it's started before the first update to the data-plane, e.g. to
+// let dependent systems stabilize. The delay is interrupted when the context
+// of the synchronizer is Done(). Delays longer than MaxInitialSyncDelay are
+// shortened to MaxInitialSyncDelay.
+func (p *Synchronizer) SetInitialSyncDelay(delay time.Duration) {
+	if delay > MaxInitialSyncDelay {
+		delay = MaxInitialSyncDelay
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.initialSyncDelay = delay
+}
+
+// SetSyncDebounce configures the synchronizer to update the data-plane once
+// the provided debounce window has elapsed after a change is notified with
+// NotifyChange, rather than waiting for the next regular update. Changes
+// notified within the window are coalesced into that single update, and the
+// stagger between regular updates is then restarted, so that it remains the
+// maximum interval between updates. A debounce of 0 disables updates on
+// change.
+func (p *Synchronizer) SetSyncDebounce(debounce time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.syncDebounce = debounce
+}
+
+// NotifyChange notifies the synchronizer that the configuration of the
+// data-plane changed. It never blocks, and has no effect unless a sync
+// debounce is configured.
+func (p *Synchronizer) NotifyChange() {
+	select {
+	case p.changes <- struct{}{}:
+	default:
+	}
+}
+
 // IsRunning informs the caller whether the synchronization server is running.
 func (p *Synchronizer) IsRunning() bool {
 	p.lock.RLock()
@@ -140,38 +197,87 @@ func (p *Synchronizer) NeedLeaderElection() bool {
 // -----------------------------------------------------------------------------
 
 // startUpdateServer runs a server in a background goroutine that is responsible for
-// updating the kong proxy backend at regular intervals.
-func (p *Synchronizer) startUpdateServer(ctx context.Context) {
-	var initialConfig sync.Once
-	for {
+// updating the kong proxy backend at regular intervals, after the provided initial delay,
+// and once the provided debounce window has elapsed after a change, if it's not 0.
+func (p *Synchronizer) startUpdateServer(ctx context.Context, initialDelay, debounce time.Duration) {
+	if initialDelay > 0 {
+		p.logger.Info("delaying the first update of the kong proxy", "delay", initialDelay.String())
+		delay := time.NewTimer(initialDelay)
 		select {
 		case <-ctx.Done():
-			p.logger.Info("context done: shutting down the proxy update server")
-			if err := ctx.Err(); err != nil {
-				p.logger.Error(err, "context completed with error")
-			}
-			p.syncTicker.Stop()
+			delay.Stop()
+			p.stopUpdateServer(ctx)
+			return
+		case <-delay.C:
+		}
+	}
 
-			p.lock.Lock()
-			defer p.lock.Unlock()
-			p.isServerRunning = false
-			p.configApplied = false
+	var initialConfig sync.Once
+	update := func() {
+		err := p.dataplaneClient.Update(ctx)
+		p.recordSyncReadiness(err)
+		if err != nil {
+			p.logger.Error(err, "could not update kong admin")
+			return
+		}
+		initialConfig.Do(p.markConfigApplied)
+	}
 
+	// debounced is only set while an update on change is pending
+	var debounced <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			p.stopUpdateServer(ctx)
 			return
 		case <-p.syncTicker.C:
-			if err := p.dataplaneClient.Update(ctx); err != nil {
-				p.logger.Error(err, "could not update kong admin")
-				break
+			update()
+		case <-p.changes:
+			if debounce > 0 && debounced == nil {
+				debounced = time.After(debounce)
 			}
-			initialConfig.Do(p.markConfigApplied)
+		case <-debounced:
+			debounced = nil
+			update()
+			p.syncTicker.Reset(p.stagger)
 		}
 	}
 }
 
+// stopUpdateServer stops the server once the provided context is Done().
+func (p *Synchronizer) stopUpdateServer(ctx context.Context) {
+	p.logger.Info("context done: shutting down the proxy update server")
+	if err := ctx.Err(); err != nil {
+		p.logger.Error(err, "context completed with error")
+	}
+	p.syncTicker.Stop()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.isServerRunning = false
+	p.configApplied = false
+}
+
 // -----------------------------------------------------------------------------
 // Synchronizer - Private Methods - Helper
 // -----------------------------------------------------------------------------
 
+// recordSyncReadiness records the outcome of an update in the configured
+// SyncReadinessHandler, if any.
+func (p *Synchronizer) recordSyncReadiness(err error) {
+	p.lock.RLock()
+	h := p.syncReadiness
+	p.lock.RUnlock()
+	if h == nil {
+		return
+	}
+	if err != nil {
+		h.RecordFailure(err)
+		return
+	}
+	h.RecordSuccess(time.Now())
+}
+
 // markConfigApplied marks that config has been applied
 func (p *Synchronizer) markConfigApplied() {
 	p.lock.Lock()
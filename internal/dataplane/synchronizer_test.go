@@ -7,6 +7,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
 )
 
@@ -75,11 +76,98 @@ func TestSynchronizer(t *testing.T) {
 	assert.Eventually(t, func() bool { return !sync.IsReady() }, time.Second, time.Millisecond*200)
 }
 
+func TestSynchronizerInitialSyncDelay(t *testing.T) {
+	stagger := time.Millisecond * 50
+	delay := time.Millisecond * 500
+
+	t.Log("verifying that the first update is delayed by the initial sync delay")
+	c := &fakeDataplaneClient{dbmode: "off"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sync, err := NewSynchronizerWithStagger(logrus.New(), c, stagger)
+	require.NoError(t, err)
+	sync.SetInitialSyncDelay(delay)
+	require.NoError(t, sync.Start(ctx))
+	started := time.Now()
+	assert.Eventually(t, func() bool { return c.totalUpdates() > 0 }, delay*4, stagger)
+	c.lock.RLock()
+	assert.GreaterOrEqual(t, c.firstUpdate.Sub(started), delay)
+	c.lock.RUnlock()
+	assert.True(t, sync.IsReady())
+
+	t.Log("verifying that the synchronizer shuts down during the initial sync delay without updating")
+	c = &fakeDataplaneClient{dbmode: "off"}
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	sync, err = NewSynchronizerWithStagger(logrus.New(), c, stagger)
+	require.NoError(t, err)
+	sync.SetInitialSyncDelay(time.Hour)
+	require.NoError(t, sync.Start(ctx))
+	assert.True(t, sync.IsRunning())
+	cancel()
+	assert.Eventually(t, func() bool { return !sync.IsRunning() }, time.Second, stagger)
+	assert.Equal(t, 0, c.totalUpdates())
+	assert.False(t, sync.IsReady())
+
+	t.Log("verifying that the initial sync delay is bounded")
+	sync.SetInitialSyncDelay(MaxInitialSyncDelay + time.Hour)
+	assert.Equal(t, MaxInitialSyncDelay, sync.initialSyncDelay)
+}
+
+func TestSynchronizerSyncDebounce(t *testing.T) {
+	stagger := time.Second * 2
+	debounce := time.Millisecond * 100
+
+	t.Log("verifying that a burst of changes results in a single coalesced update")
+	c := &fakeDataplaneClient{dbmode: "off"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sync, err := NewSynchronizerWithStagger(logrus.New(), c, stagger)
+	require.NoError(t, err)
+	sync.SetSyncDebounce(debounce)
+	require.NoError(t, sync.Start(ctx))
+	notified := time.Now()
+	for i := 0; i < 10; i++ {
+		sync.NotifyChange()
+	}
+	assert.Eventually(t, func() bool { return c.totalUpdates() > 0 }, stagger/2, debounce/10)
+	c.lock.RLock()
+	assert.GreaterOrEqual(t, c.firstUpdate.Sub(notified), debounce)
+	c.lock.RUnlock()
+	time.Sleep(debounce * 3)
+	assert.Equal(t, 1, c.totalUpdates())
+	assert.True(t, sync.IsReady())
+	cancel()
+	assert.Eventually(t, func() bool { return !sync.IsRunning() }, time.Second, debounce)
+
+	t.Log("verifying that the periodic update still happens when no changes are notified")
+	c = &fakeDataplaneClient{dbmode: "off"}
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	sync, err = NewSynchronizerWithStagger(logrus.New(), c, debounce)
+	require.NoError(t, err)
+	sync.SetSyncDebounce(debounce / 2)
+	require.NoError(t, sync.Start(ctx))
+	assert.Eventually(t, func() bool { return c.totalUpdates() >= 3 }, debounce*10, debounce/10)
+
+	t.Log("verifying that changes don't trigger updates without a sync debounce")
+	c = &fakeDataplaneClient{dbmode: "off"}
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	sync, err = NewSynchronizerWithStagger(logrus.New(), c, stagger)
+	require.NoError(t, err)
+	require.NoError(t, sync.Start(ctx))
+	sync.NotifyChange()
+	time.Sleep(debounce * 3)
+	assert.Equal(t, 0, c.totalUpdates())
+}
+
 // fakeDataplaneClient fakes the dataplane.Client interface so that we can
 // unit test the dataplane.Synchronizer.
 type fakeDataplaneClient struct {
 	dbmode      string
 	updateCount int
+	firstUpdate time.Time
 	lock        sync.RWMutex
 }
 
@@ -92,6 +180,9 @@ func (c *fakeDataplaneClient) DBMode() string {
 func (c *fakeDataplaneClient) Update(ctx context.Context) error {
 	c.lock.Lock()
 	defer c.lock.Unlock()
+	if c.updateCount == 0 {
+		c.firstUpdate = time.Now()
+	}
 	c.updateCount++
 	return nil
 }
@@ -15,7 +15,10 @@ import (
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/adminapi"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/admission"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
+	ctrlutils "github.com/kong/kubernetes-ingress-controller/v2/internal/controllers/utils"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/parser"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/manager/featuregates"
 )
 
 // -----------------------------------------------------------------------------
@@ -39,6 +42,58 @@ type Config struct {
 	EnableReverseSync  bool
 	SyncPeriod         time.Duration
 
+	// WorkspaceNamespaceAnnotation and WorkspaceNamespaceMap configure the
+	// Kong Enterprise workspaces which the objects of namespaces are synced to.
+	WorkspaceNamespaceAnnotation string
+	WorkspaceNamespaceMap        map[string]string
+
+	// DropRoutesWithoutTargets indicates that the routes of Services without
+	// any targets are dropped instead of routing to an empty upstream.
+	DropRoutesWithoutTargets bool
+
+	// MaxPathsPerRoute is the maximum number of paths of a single route,
+	// routes with more paths are split. 0 means that it's not limited.
+	MaxPathsPerRoute int
+
+	// MaxRoutesPerIngress is the maximum number of routes generated for a
+	// single Ingress, routes beyond it are skipped. 0 means that it's not limited.
+	MaxRoutesPerIngress int
+
+	// SNIStrictness is the way in which HTTPS routes whose hosts aren't
+	// covered by any certificate are handled: "strict" or "lenient".
+	SNIStrictness string
+
+	// TranslationFailurePolicy is the way in which objects referencing broken
+	// plugins are handled: "skip-broken" or "fail-all".
+	TranslationFailurePolicy string
+
+	// RouteNameTemplate is the text/template which the names of the routes
+	// generated for the rule paths of Ingresses are generated with. Routes
+	// are named "<namespace>.<name>.<rule index><path index>" when empty.
+	RouteNameTemplate string
+
+	// StreamingPlugins are the names of the Kong plugins which stream request
+	// or response bodies. The buffering of the routes they're attached to is
+	// disabled.
+	StreamingPlugins []string
+
+	// IncrementalTargetUpdates indicates that changes of only Endpoints and
+	// EndpointSlices are applied by updating the targets of upstreams instead
+	// of syncing the whole configuration.
+	IncrementalTargetUpdates bool
+
+	// ConfigPublishURL is the URL which the generated configuration, with
+	// sensitive values redacted, is PUT to whenever it changes.
+	ConfigPublishURL string
+
+	// StableCertificateIDs indicates that the IDs of certificates are derived
+	// from the namespace, name and content of their Secrets.
+	StableCertificateIDs bool
+
+	// IngressClassTags indicates that the generated Kong entities are tagged
+	// with the ingress class managed by the controller.
+	IngressClassTags bool
+
 	// Kong Proxy configurations
 	APIServerHost            string
 	APIServerQPS             int
@@ -47,7 +102,12 @@ type Config struct {
 	ProbeAddr                string
 	KongAdminURL             string
 	ProxySyncSeconds         float32
+	ProxySyncMaxInterval     time.Duration
 	ProxyTimeoutSeconds      float32
+	SyncStalenessWindow      time.Duration
+	InitialSyncDelay         time.Duration
+	SyncDebounce             time.Duration
+	ClassDefaultTimeouts     map[string]string
 	KongCustomEntitiesSecret string
 
 	// Kubernetes configurations
@@ -57,6 +117,7 @@ type Config struct {
 	LeaderElectionNamespace string
 	LeaderElectionID        string
 	Concurrency             int
+	DeleteBatchSize         int
 	FilterTags              []string
 	WatchNamespaces         []string
 
@@ -64,6 +125,7 @@ type Config struct {
 	PublishService       string
 	PublishStatusAddress []string
 	UpdateStatus         bool
+	StatusUpdateRetries  int
 
 	// Kubernetes API toggling
 	IngressExtV1beta1Enabled bool
@@ -78,6 +140,7 @@ type Config struct {
 	KongPluginEnabled        bool
 	KongConsumerEnabled      bool
 	ServiceEnabled           bool
+	EndpointSliceEnabled     bool
 
 	// Admission Webhook server config
 	AdmissionServer admission.ServerConfig
@@ -113,10 +176,32 @@ func (c *Config) FlagSet() *pflag.FlagSet {
 	flagSet.StringVar(&c.KongAdminAPIConfig.CACert, "kong-admin-ca-cert", "", `PEM-encoded CA certificate to verify Kong's Admin SSL certificate.`)
 
 	flagSet.StringSliceVar(&c.KongAdminAPIConfig.Headers, "kong-admin-header", nil, `add a header (key:value) to every Admin API call, this flag can be used multiple times to specify multiple headers`)
+	flagSet.IntVar(&c.KongAdminAPIConfig.RetryPolicy.MaxRetries, "kong-admin-retries", 0, `Number of times an Admin API call is retried while Kong is unavailable, with exponential backoff and jitter between attempts. 0 disables retries.`)
+	flagSet.DurationVar(&c.KongAdminAPIConfig.RetryPolicy.BaseDelay, "kong-admin-retry-base-delay", adminapi.DefaultRetryPolicy().BaseDelay, `Delay before the first retry of an Admin API call.`)
+	flagSet.DurationVar(&c.KongAdminAPIConfig.RetryPolicy.MaxDelay, "kong-admin-retry-max-delay", adminapi.DefaultRetryPolicy().MaxDelay, `Maximum delay between two retries of an Admin API call.`)
+	flagSet.Float64Var(&c.KongAdminAPIConfig.RetryPolicy.Multiplier, "kong-admin-retry-multiplier", adminapi.DefaultRetryPolicy().Multiplier, `Factor by which the delay between retries of an Admin API call grows.`)
+	flagSet.Float64Var(&c.KongAdminAPIConfig.RetryPolicy.Jitter, "kong-admin-retry-jitter", adminapi.DefaultRetryPolicy().Jitter, `Fraction (between 0 and 1) of the delay between retries of an Admin API call which is randomized.`)
 	flagSet.StringVar(&c.KongAdminToken, "kong-admin-token", "", `The Kong Enterprise RBAC token used by the controller.`)
 	flagSet.StringVar(&c.KongWorkspace, "kong-workspace", "", "Kong Enterprise workspace to configure. Leave this empty if not using Kong workspaces.")
+	flagSet.StringVar(&c.WorkspaceNamespaceAnnotation, "kong-workspace-namespace-annotation", "",
+		`Annotation of namespaces holding the Kong Enterprise workspace which the configuration generated from their objects is synced to. Namespaces without it use --kong-workspace.`,
+	)
+	flagSet.StringToStringVar(&c.WorkspaceNamespaceMap, "kong-workspace-namespace-map", nil,
+		`Kong Enterprise workspaces which the configuration generated from the objects of namespaces is synced to, in "namespace=workspace" format. Takes precedence over --kong-workspace-namespace-annotation.`,
+	)
 	flagSet.BoolVar(&c.AnonymousReports, "anonymous-reports", true, `Send anonymized usage data to help improve Kong`)
 	flagSet.BoolVar(&c.EnableReverseSync, "enable-reverse-sync", false, `Send configuration to Kong even if the configuration checksum has not changed since previous update.`)
+	flagSet.IntVar(&c.MaxPathsPerRoute, "max-paths-per-route", 0, `Maximum number of paths of a single route, routes with more paths are split into multiple routes. 0 means no limit.`)
+	flagSet.StringVar(&c.SNIStrictness, "sni-strictness", string(parser.SNIStrictnessLenient), `Handling of HTTPS routes whose hosts aren't covered by any certificate: "strict" rejects them and records a warning event naming the uncovered host, "lenient" keeps them and Kong serves its default certificate.`)
+	flagSet.StringVar(&c.TranslationFailurePolicy, "translation-failure-policy", string(parser.FailurePolicySkipBroken), `Handling of objects referencing KongPlugins which don't exist or can't be translated: "skip-broken" skips the plugin, records a warning event on the object and pushes the rest of the configuration, "fail-all" pushes no configuration until the reference is fixed.`)
+	flagSet.StringVar(&c.RouteNameTemplate, "route-name-template", "", `Go text/template which the names of the Kong routes generated for the rule paths of Ingresses are generated with, e.g. "{{.Namespace}}.{{.Name}}.{{.Host}}{{.Path}}". The template has access to .Namespace, .Name, .Host, .Path, .RuleIndex and .PathIndex and must generate unique names. Routes are named "<namespace>.<name>.<rule index><path index>" when empty.`)
+	flagSet.StringSliceVar(&c.StreamingPlugins, "streaming-plugins", nil, `Names of the Kong plugins which stream request or response bodies, e.g. custom plugins. The request and response buffering of the routes which such a plugin is attached to, directly or through their service, is disabled and an event is recorded on their Ingress.`)
+	flagSet.BoolVar(&c.IncrementalTargetUpdates, "incremental-target-updates", false, `Apply changes of only Endpoints and EndpointSlices by updating the targets of the affected upstreams with targeted Admin API calls instead of syncing the whole configuration. Only supported with a database-backed Kong.`)
+	flagSet.StringVar(&c.ConfigPublishURL, "config-publish-url", "", `URL which the generated configuration, with sensitive values redacted, is PUT to as JSON whenever it changes (e.g. for GitOps diffing). Publishing is disabled when empty.`)
+	flagSet.IntVar(&c.MaxRoutesPerIngress, "max-routes-per-ingress", 0, `Maximum number of routes (host and path combinations) generated for a single Ingress, the routes beyond it are skipped and a warning event is recorded on the Ingress. 0 means no limit.`)
+	flagSet.BoolVar(&c.DropRoutesWithoutTargets, "drop-routes-without-targets", false, `Drop the routes of Services without any targets (e.g. Deployments scaled to zero) instead of responding with 503 errors, so that other routing can take over.`)
+	flagSet.BoolVar(&c.StableCertificateIDs, "stable-certificate-ids", false, `Derive the IDs of certificates from the namespace, name and content of their Secrets instead of the Secret UIDs, so that unchanged certificates keep their IDs when their Secrets are re-created.`)
+	flagSet.BoolVar(&c.IngressClassTags, "kong-admin-ingress-class-tag", false, `Tag the Kong entities generated by the controller with "ingress-class:<name>", where <name> is the ingress class managed by the controller.`)
 	flagSet.DurationVar(&c.SyncPeriod, "sync-period", time.Hour*48, `Relist and confirm cloud resources this often`) // 48 hours derived from controller-runtime defaults
 
 	flagSet.StringVar(&c.KongAdminAPIConfig.TLSClientCertPath, "kong-admin-tls-client-cert-file", "", "mTLS client certificate file for authentication.")
@@ -131,12 +216,26 @@ func (c *Config) FlagSet() *pflag.FlagSet {
 	flagSet.StringVar(&c.MetricsAddr, "metrics-bind-address", fmt.Sprintf(":%v", MetricsPort), "The address the metric endpoint binds to.")
 	flagSet.StringVar(&c.ProbeAddr, "health-probe-bind-address", fmt.Sprintf(":%v", HealthzPort), "The address the probe endpoint binds to.")
 	flagSet.StringVar(&c.KongAdminURL, "kong-admin-url", "http://localhost:8001", `The Kong Admin URL to connect to in the format "protocol://address:port".`)
-	flagSet.Float32Var(&c.ProxySyncSeconds, "proxy-sync-seconds", dataplane.DefaultSyncSeconds,
-		"Define the rate (in seconds) in which configuration updates will be applied to the Kong Admin API.",
+	flagSet.DurationVar(&c.ProxySyncMaxInterval, "proxy-sync-max-interval", 0,
+		`Maximum interval between configuration updates applied to the Kong Admin API, i.e. the period of the updates which happen even when no configuration change was notified. `+
+			`Supersedes --proxy-sync-seconds, which is used when this is 0.`,
 	)
 	flagSet.Float32Var(&c.ProxyTimeoutSeconds, "proxy-timeout-seconds", dataplane.DefaultTimeoutSeconds,
 		"Define the rate (in seconds) in which the timeout configuration will be applied to the Kong client.",
 	)
+	flagSet.DurationVar(&c.InitialSyncDelay, "initial-sync-delay", 0,
+		fmt.Sprintf(`Delay between the start of the configuration updates to the Kong Admin API and the first update, e.g. to let dependent systems stabilize. At most %s.`, dataplane.MaxInitialSyncDelay),
+	)
+	flagSet.DurationVar(&c.SyncDebounce, "sync-debounce", 0,
+		`Delay after a configuration change before the configuration is applied to the Kong Admin API, coalescing the changes made within it, `+
+			`rather than waiting for the next periodic update, which still happens every --proxy-sync-max-interval. 0 disables updates on change.`,
+	)
+	flagSet.DurationVar(&c.SyncStalenessWindow, "sync-staleness-window", 0,
+		`If set, the controller is only reported as ready while its last successful sync to the Kong Admin API happened within this window. 0 disables the check.`,
+	)
+	flagSet.StringToStringVar(&c.ClassDefaultTimeouts, "class-default-timeouts", nil,
+		`Default connect, read and write timeouts (in milliseconds) of the Kong services generated for Ingresses of an ingress class, in "class=connect,read,write" format. Only the timeouts of the class managed by the controller are used, and empty values keep the default of 60000.`,
+	)
 	flagSet.StringVar(&c.KongCustomEntitiesSecret, "kong-custom-entities-secret", "", `A Secret containing custom entities for DB-less mode, in "namespace/name" format`)
 
 	// Kubernetes configurations
@@ -147,6 +246,8 @@ func (c *Config) FlagSet() *pflag.FlagSet {
 	flagSet.StringVar(&c.LeaderElectionNamespace, "election-namespace", "", `Leader election namespace to use when running outside a cluster`)
 	flagSet.StringSliceVar(&c.FilterTags, "kong-admin-filter-tag", []string{"managed-by-ingress-controller"}, "The tag used to manage and filter entities in Kong. This flag can be specified multiple times to specify multiple tags. This setting will be silently ignored if the Kong instance has no tags support.")
 	flagSet.IntVar(&c.Concurrency, "kong-admin-concurrency", 10, "Max number of concurrent requests sent to Kong's Admin API.")
+	flagSet.IntVar(&c.DeleteBatchSize, "kong-admin-delete-batch-size", 0,
+		`Number of concurrent deletes sent to Kong's Admin API when entities are removed from the configuration. Deletes stop at the first failing batch and are retried on the next sync. 0 uses --kong-admin-concurrency.`)
 	flagSet.StringSliceVar(&c.WatchNamespaces, "watch-namespace", nil,
 		`Namespace(s) to watch for Kubernetes resources. Defaults to all namespaces. To watch multiple namespaces, use
 		a comma-separated list of namespaces.`)
@@ -159,6 +260,8 @@ func (c *Config) FlagSet() *pflag.FlagSet {
 			information (for example, in bare-metal environments).`)
 	flagSet.BoolVar(&c.UpdateStatus, "update-status", true,
 		`Indicates if the ingress controller should update the status of resources (e.g. IP/Hostname for v1.Ingress, e.t.c.)`)
+	flagSet.IntVar(&c.StatusUpdateRetries, "status-update-retries", ctrlutils.DefaultStatusUpdateRetries,
+		`Number of times a status update which conflicts with a concurrent change of the resource is retried.`)
 
 	// Kubernetes API toggling
 	flagSet.BoolVar(&c.IngressNetV1Enabled, "enable-controller-ingress-networkingv1", true, "Enable the networking.k8s.io/v1 Ingress controller.")
@@ -173,6 +276,7 @@ func (c *Config) FlagSet() *pflag.FlagSet {
 	flagSet.BoolVar(&c.KongPluginEnabled, "enable-controller-kongplugin", true, "Enable the KongPlugin controller.")
 	flagSet.BoolVar(&c.KongConsumerEnabled, "enable-controller-kongconsumer", true, "Enable the KongConsumer controller. ")
 	flagSet.BoolVar(&c.ServiceEnabled, "enable-controller-service", true, "Enable the Service controller.")
+	flagSet.BoolVar(&c.EndpointSliceEnabled, "enable-controller-endpointslice", true, "Enable the discovery.k8s.io/v1 EndpointSlice controller, so that upstream targets are aggregated from the EndpointSlices of Services, which aren't truncated at 1000 addresses, and follow the ready condition of their endpoints (e.g. for Pods with readiness gates). The controller is skipped if the apiserver doesn't serve discovery.k8s.io/v1 (Kubernetes older than v1.21), in which case targets are resolved from Endpoints.")
 
 	// Admission Webhook server config
 	flagSet.StringVar(&c.AdmissionServer.ListenAddr, "admission-webhook-listen", "off",
@@ -195,11 +299,14 @@ func (c *Config) FlagSet() *pflag.FlagSet {
 
 	// Feature Gates (see FEATURE_GATES.md)
 	flagSet.Var(cliflag.NewMapStringBool(&c.FeatureGates), "feature-gates", "A set of key=value pairs that describe feature gates for alpha/beta/experimental features. "+
-		fmt.Sprintf("See the Feature Gates documentation for information and available options: %s", featureGatesDocsURL))
+		fmt.Sprintf("See the Feature Gates documentation for information and available options: %s", featuregates.DocsURL))
 
 	// Deprecated (to be removed in future releases)
+	flagSet.Float32Var(&c.ProxySyncSeconds, "proxy-sync-seconds", dataplane.DefaultSyncSeconds,
+		"Define the rate (in seconds) in which configuration updates will be applied to the Kong Admin API (DEPRECATED, use --proxy-sync-max-interval instead)",
+	)
 	flagSet.Float32Var(&c.ProxySyncSeconds, "sync-rate-limit", dataplane.DefaultSyncSeconds,
-		"Define the rate (in seconds) in which configuration updates will be applied to the Kong Admin API (DEPRECATED, use --proxy-sync-seconds instead)",
+		"Define the rate (in seconds) in which configuration updates will be applied to the Kong Admin API (DEPRECATED, use --proxy-sync-max-interval instead)",
 	)
 	flagSet.Int("stderrthreshold", 0, "DEPRECATED: has no effect and will be removed in future releases (see github issue #1297)")
 	flagSet.Bool("update-status-on-shutdown", false, `DEPRECATED: no longer has any effect and will be removed in a later release (see github issue #1304)`)
@@ -208,15 +315,22 @@ func (c *Config) FlagSet() *pflag.FlagSet {
 }
 
 func (c *Config) GetKongClient(ctx context.Context) (*kong.Client, error) {
+	return c.GetKongClientForWorkspace(ctx, c.KongWorkspace)
+}
+
+// GetKongClientForWorkspace provides a Kong Admin API client for the provided
+// workspace, creating the workspace if it doesn't exist.
+func (c *Config) GetKongClientForWorkspace(ctx context.Context, workspace string) (*kong.Client, error) {
+	opts := c.KongAdminAPIConfig
 	if c.KongAdminToken != "" {
-		c.KongAdminAPIConfig.Headers = append(c.KongAdminAPIConfig.Headers, "kong-admin-token:"+c.KongAdminToken)
+		opts.Headers = append(append([]string{}, opts.Headers...), "kong-admin-token:"+c.KongAdminToken)
 	}
-	httpclient, err := adminapi.MakeHTTPClient(&c.KongAdminAPIConfig)
+	httpclient, err := adminapi.MakeHTTPClient(&opts)
 	if err != nil {
 		return nil, err
 	}
 
-	return adminapi.GetKongClientForWorkspace(ctx, c.KongAdminURL, c.KongWorkspace, httpclient)
+	return adminapi.GetKongClientForWorkspace(ctx, c.KongAdminURL, workspace, httpclient)
 }
 
 func (c *Config) GetKubeconfig() (*rest.Config, error) {
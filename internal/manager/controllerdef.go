@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	knativev1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -16,6 +17,7 @@ import (
 	ctrlutils "github.com/kong/kubernetes-ingress-controller/v2/internal/controllers/utils"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/kubernetes/object/status"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/manager/featuregates"
 	konghqcomv1 "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1"
 )
 
@@ -68,7 +70,7 @@ func setupControllers(
 	dataplaneAddressFinder *dataplane.AddressFinder,
 	kubernetesStatusQueue *status.Queue,
 	c *Config,
-	featureGates map[string]bool,
+	featureGates featuregates.FeatureGates,
 ) ([]ControllerDef, error) {
 	// Choose the best API version of Ingress to inform which ingress controller to enable.
 	var ingressPicker ingressControllerStrategy
@@ -100,6 +102,7 @@ func setupControllers(
 				DataplaneClient:        dataplaneClient,
 				IngressClassName:       c.IngressClassName,
 				StatusQueue:            kubernetesStatusQueue,
+				StatusUpdateRetries:    c.StatusUpdateRetries,
 				DataplaneAddressFinder: dataplaneAddressFinder,
 			},
 		},
@@ -113,6 +116,7 @@ func setupControllers(
 				DataplaneClient:        dataplaneClient,
 				IngressClassName:       c.IngressClassName,
 				StatusQueue:            kubernetesStatusQueue,
+				StatusUpdateRetries:    c.StatusUpdateRetries,
 				DataplaneAddressFinder: dataplaneAddressFinder,
 			},
 		},
@@ -126,6 +130,7 @@ func setupControllers(
 				DataplaneClient:        dataplaneClient,
 				IngressClassName:       c.IngressClassName,
 				StatusQueue:            kubernetesStatusQueue,
+				StatusUpdateRetries:    c.StatusUpdateRetries,
 				DataplaneAddressFinder: dataplaneAddressFinder,
 			},
 		},
@@ -147,6 +152,22 @@ func setupControllers(
 				DataplaneClient: dataplaneClient,
 			},
 		},
+		{
+			// EndpointSlices are only watched if the apiserver serves them, on older clusters the upstream
+			// targets are resolved from Endpoints alone.
+			Enabled: c.ServiceEnabled && c.EndpointSliceEnabled,
+			AutoHandler: crdExistsChecker{GVR: schema.GroupVersionResource{
+				Group:    discoveryv1.SchemeGroupVersion.Group,
+				Version:  discoveryv1.SchemeGroupVersion.Version,
+				Resource: "endpointslices",
+			}}.CRDExists,
+			Controller: &configuration.DiscoveryV1EndpointSliceReconciler{
+				Client:          mgr.GetClient(),
+				Log:             ctrl.Log.WithName("controllers").WithName("EndpointSlice"),
+				Scheme:          mgr.GetScheme(),
+				DataplaneClient: dataplaneClient,
+			},
+		},
 		{
 			Enabled: true,
 			Controller: &configuration.CoreV1SecretReconciler{
@@ -168,6 +189,7 @@ func setupControllers(
 				DataplaneClient:        dataplaneClient,
 				IngressClassName:       c.IngressClassName,
 				StatusQueue:            kubernetesStatusQueue,
+				StatusUpdateRetries:    c.StatusUpdateRetries,
 				DataplaneAddressFinder: dataplaneAddressFinder,
 			},
 		},
@@ -180,6 +202,7 @@ func setupControllers(
 				DataplaneClient:        dataplaneClient,
 				IngressClassName:       c.IngressClassName,
 				StatusQueue:            kubernetesStatusQueue,
+				StatusUpdateRetries:    c.StatusUpdateRetries,
 				DataplaneAddressFinder: dataplaneAddressFinder,
 			},
 		},
@@ -233,7 +256,7 @@ func setupControllers(
 			// knative is a special case because it existed before we added feature gates functionality
 			// for this controller (only) the existing --enable-controller-knativeingress flag overrides
 			// any feature gate configuration. See FEATURE_GATES.md for more information.
-			Enabled: featureGates[gatewayFeature] || c.KnativeIngressEnabled,
+			Enabled: featureGates.Enabled(featuregates.GatewayFeature) || c.KnativeIngressEnabled,
 			AutoHandler: crdExistsChecker{GVR: schema.GroupVersionResource{
 				Group:    knativev1alpha1.SchemeGroupVersion.Group,
 				Version:  knativev1alpha1.SchemeGroupVersion.Version,
@@ -246,6 +269,7 @@ func setupControllers(
 				DataplaneClient:        dataplaneClient,
 				IngressClassName:       c.IngressClassName,
 				StatusQueue:            kubernetesStatusQueue,
+				StatusUpdateRetries:    c.StatusUpdateRetries,
 				DataplaneAddressFinder: dataplaneAddressFinder,
 			},
 		},
@@ -253,7 +277,7 @@ func setupControllers(
 		// GatewayAPI Controllers
 		// ---------------------------------------------------------------------------
 		{
-			Enabled: featureGates[gatewayFeature],
+			Enabled: featureGates.Enabled(featuregates.GatewayFeature),
 			AutoHandler: crdExistsChecker{
 				GVR: schema.GroupVersionResource{
 					Group:    gatewayv1alpha2.SchemeGroupVersion.Group,
@@ -262,7 +286,7 @@ func setupControllers(
 				}}.CRDExists,
 			Controller: &gateway.GatewayReconciler{
 				Client:          mgr.GetClient(),
-				Log:             ctrl.Log.WithName("controllers").WithName(gatewayFeature),
+				Log:             ctrl.Log.WithName("controllers").WithName(featuregates.GatewayFeature),
 				Scheme:          mgr.GetScheme(),
 				DataplaneClient: dataplaneClient,
 				PublishService:  c.PublishService,
@@ -270,7 +294,7 @@ func setupControllers(
 			},
 		},
 		{
-			Enabled: featureGates[gatewayFeature],
+			Enabled: featureGates.Enabled(featuregates.GatewayFeature),
 			AutoHandler: crdExistsChecker{
 				GVR: schema.GroupVersionResource{
 					Group:    gatewayv1alpha2.SchemeGroupVersion.Group,
@@ -284,6 +308,21 @@ func setupControllers(
 				DataplaneClient: dataplaneClient,
 			},
 		},
+		{
+			Enabled: featureGates.Enabled(featuregates.GatewayFeature),
+			AutoHandler: crdExistsChecker{
+				GVR: schema.GroupVersionResource{
+					Group:    gatewayv1alpha2.SchemeGroupVersion.Group,
+					Version:  gatewayv1alpha2.SchemeGroupVersion.Version,
+					Resource: "referencepolicies",
+				}}.CRDExists,
+			Controller: &configuration.GatewayV1Alpha2ReferencePolicyReconciler{
+				Client:          mgr.GetClient(),
+				Log:             ctrl.Log.WithName("controllers").WithName("ReferencePolicy"),
+				Scheme:          mgr.GetScheme(),
+				DataplaneClient: dataplaneClient,
+			},
+		},
 	}
 
 	return controllers, nil
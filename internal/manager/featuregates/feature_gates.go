@@ -0,0 +1,89 @@
+package featuregates
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+)
+
+// -----------------------------------------------------------------------------
+// Feature Gates - Vars & Consts
+// -----------------------------------------------------------------------------
+
+const (
+	// KnativeFeature is the name of the feature-gate for enabling/disabling Knative
+	KnativeFeature = "Knative"
+
+	// GatewayFeature is the name of the feature-gate for enabling/disabling Gateway APIs
+	GatewayFeature = "Gateway"
+
+	// DocsURL provides a link to the documentation for feature gates in the KIC repository
+	DocsURL = "https://github.com/Kong/kubernetes-ingress-controller/blob/main/FEATURE_GATES.md"
+)
+
+// -----------------------------------------------------------------------------
+// Feature Gates - Public Types
+// -----------------------------------------------------------------------------
+
+// FeatureGates is the enablement of gated features by their names, as
+// configured by the --feature-gates flag.
+type FeatureGates map[string]bool
+
+// -----------------------------------------------------------------------------
+// Feature Gates - Public Functions
+// -----------------------------------------------------------------------------
+
+// New provides the FeatureGates configured by the provided feature gates
+// settings, which override the defaults of the known feature gates. An error
+// is returned if any of the settings is for an unknown feature gate.
+func New(setupLog logr.Logger, featureGates map[string]bool) (FeatureGates, error) {
+	// generate a map of feature gates by string names to their controller enablement
+	ctrlMap := GetFeatureGatesDefaults()
+
+	// reject unknown feature gates in a stable order
+	features := make([]string, 0, len(featureGates))
+	for feature := range featureGates {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+
+	// override the default settings
+	for _, feature := range features {
+		enabled := featureGates[feature]
+		setupLog.Info("found configuration option for gated feature", "feature", feature, "enabled", enabled)
+		_, ok := ctrlMap[feature]
+		if !ok {
+			return ctrlMap, fmt.Errorf("%s is not a valid feature, please see the documentation: %s", feature, DocsURL)
+		}
+		ctrlMap[feature] = enabled
+	}
+
+	return ctrlMap, nil
+}
+
+// GetFeatureGatesDefaults initializes a feature gate map given the currently
+// supported feature gates options and derives defaults for them based on
+// manager configuration options if present.
+//
+// NOTE: if you're adding a new feature gate, it needs to be added here.
+func GetFeatureGatesDefaults() FeatureGates {
+	return FeatureGates{
+		KnativeFeature: false,
+		GatewayFeature: false,
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Feature Gates - Public Methods
+// -----------------------------------------------------------------------------
+
+// Enabled indicates whether the feature with the provided name is enabled.
+// Features which aren't configured are enabled according to their defaults,
+// unknown features are disabled.
+func (fg FeatureGates) Enabled(name string) bool {
+	if enabled, ok := fg[name]; ok {
+		return enabled
+	}
+	return GetFeatureGatesDefaults()[name]
+}
@@ -0,0 +1,78 @@
+package featuregates
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bombsimon/logrusr/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cliflag "k8s.io/component-base/cli/flag"
+)
+
+func TestFeatureGates(t *testing.T) {
+	t.Log("setting up configurations and logging for feature gates testing")
+	out := new(bytes.Buffer)
+	baseLogger := logrus.New()
+	baseLogger.SetOutput(out)
+	baseLogger.SetLevel(logrus.DebugLevel)
+	setupLog := logrusr.New(baseLogger)
+
+	t.Log("verifying feature gates setup defaults when no feature gates are configured")
+	fgs, err := New(setupLog, nil)
+	assert.NoError(t, err)
+	assert.Len(t, fgs, len(GetFeatureGatesDefaults()))
+	for feature, enabled := range GetFeatureGatesDefaults() {
+		assert.Equal(t, enabled, fgs.Enabled(feature), feature)
+	}
+
+	t.Log("verifying feature gates setup results when valid feature gates options are present")
+	fgs, err = New(setupLog, map[string]bool{KnativeFeature: true})
+	assert.NoError(t, err)
+	assert.True(t, fgs.Enabled(KnativeFeature))
+	assert.False(t, fgs.Enabled(GatewayFeature))
+
+	t.Log("configuring several invalid feature gates options")
+	featureGates := map[string]bool{"invalidGateway": true, GatewayFeature: true}
+
+	t.Log("verifying feature gates setup results when invalid feature gates options are present")
+	_, err = New(setupLog, featureGates)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalidGateway is not a valid feature")
+}
+
+func TestFeatureGatesFlag(t *testing.T) {
+	setupLog := logrusr.New(logrus.New())
+
+	t.Log("verifying that feature gates are parsed from key=bool pairs")
+	var featureGates map[string]bool
+	require.NoError(t, cliflag.NewMapStringBool(&featureGates).Set("Gateway=true, Knative=false"))
+	fgs, err := New(setupLog, featureGates)
+	require.NoError(t, err)
+	assert.True(t, fgs.Enabled(GatewayFeature))
+	assert.False(t, fgs.Enabled(KnativeFeature))
+
+	t.Log("verifying that malformed feature gates are rejected")
+	for _, val := range []string{"Gateway", "Gateway=yes"} {
+		featureGates = nil
+		assert.Error(t, cliflag.NewMapStringBool(&featureGates).Set(val), val)
+	}
+
+	t.Log("verifying that unknown feature gates are rejected")
+	featureGates = nil
+	require.NoError(t, cliflag.NewMapStringBool(&featureGates).Set("Gateway=true,RegexPaths=true"))
+	_, err = New(setupLog, featureGates)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "RegexPaths is not a valid feature")
+}
+
+func TestFeatureGatesEnabled(t *testing.T) {
+	t.Log("verifying that unconfigured feature gates fall back to their defaults")
+	var fgs FeatureGates
+	assert.False(t, fgs.Enabled(GatewayFeature))
+	assert.False(t, fgs.Enabled(KnativeFeature))
+
+	t.Log("verifying that unknown feature gates are disabled")
+	assert.False(t, FeatureGates{GatewayFeature: true}.Enabled("unknown"))
+}
@@ -15,12 +15,17 @@ import (
 	knativev1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/adminapi"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/dataplane/parser"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/kubernetes/object/status"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/manager/featuregates"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/manager/metadata"
 	mgrutils "github.com/kong/kubernetes-ingress-controller/v2/internal/manager/utils"
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/metrics"
 	"github.com/kong/kubernetes-ingress-controller/v2/internal/util"
 	konghqcomv1 "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1"
 	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/v2/pkg/apis/configuration/v1beta1"
@@ -52,7 +57,7 @@ func Run(ctx context.Context, c *Config, diagnostic util.ConfigDumpDiagnostic) e
 	}
 
 	setupLog.Info("getting enabled options and features")
-	featureGates, err := setupFeatureGates(setupLog, c)
+	featureGates, err := featuregates.New(setupLog, c.FeatureGates)
 	if err != nil {
 		return fmt.Errorf("failed to configure feature gates: %w", err)
 	}
@@ -98,6 +103,7 @@ func Run(ctx context.Context, c *Config, diagnostic util.ConfigDumpDiagnostic) e
 	if err != nil {
 		return fmt.Errorf("unable to start controller manager: %w", err)
 	}
+	metrics.RegisterClassMetrics(ctrlmetrics.Registry)
 
 	setupLog.Info("Starting Admission Server")
 	if err := setupAdmissionServer(ctx, c, mgr.GetClient()); err != nil {
@@ -113,12 +119,74 @@ func Run(ctx context.Context, c *Config, diagnostic util.ConfigDumpDiagnostic) e
 	if err != nil {
 		return fmt.Errorf("failed to initialize kong data-plane client: %w", err)
 	}
+	if c.DropRoutesWithoutTargets {
+		dataplaneClient.EnableDropRoutesWithoutTargets()
+	}
+	dataplaneClient.SetMaxPathsPerRoute(c.MaxPathsPerRoute)
+	dataplaneClient.SetMaxRoutesPerIngress(c.MaxRoutesPerIngress)
+	dataplaneClient.SetEventRecorder(mgr.GetEventRecorderFor("kong-client"))
+	sniStrictness, err := parser.ParseSNIStrictness(c.SNIStrictness)
+	if err != nil {
+		return err
+	}
+	dataplaneClient.SetSNIStrictness(sniStrictness)
+	failurePolicy, err := parser.ParseFailurePolicy(c.TranslationFailurePolicy)
+	if err != nil {
+		return err
+	}
+	dataplaneClient.SetFailurePolicy(failurePolicy)
+	dataplaneClient.SetFeatureGates(featureGates)
+	dataplaneClient.SetStreamingPlugins(c.StreamingPlugins)
+	if c.RouteNameTemplate != "" {
+		routeNamer, err := parser.NewTemplateRouteNamer(c.RouteNameTemplate)
+		if err != nil {
+			return err
+		}
+		dataplaneClient.SetRouteNamer(routeNamer)
+	}
+	if c.IncrementalTargetUpdates {
+		dataplaneClient.EnableIncrementalTargetUpdates()
+	}
+	if c.ConfigPublishURL != "" {
+		dataplaneClient.SetConfigPublisher(dataplane.NewConfigPublisher(c.ConfigPublishURL, nil))
+	}
+	if c.StableCertificateIDs {
+		dataplaneClient.EnableStableCertificateIDs()
+	}
+	if c.IngressClassTags {
+		dataplaneClient.EnableIngressClassTags()
+	}
+	if len(c.ClassDefaultTimeouts) > 0 {
+		classDefaultTimeouts := make(map[string]parser.ServiceTimeouts, len(c.ClassDefaultTimeouts))
+		for class, val := range c.ClassDefaultTimeouts {
+			timeouts, err := parser.ParseServiceTimeouts(val)
+			if err != nil {
+				return fmt.Errorf("invalid default timeouts for ingress class %q: %w", class, err)
+			}
+			classDefaultTimeouts[class] = timeouts
+		}
+		dataplaneClient.SetClassDefaultTimeouts(classDefaultTimeouts)
+	}
+
+	if c.WorkspaceNamespaceAnnotation != "" || len(c.WorkspaceNamespaceMap) > 0 {
+		if dataplaneClient.DBMode() == "off" {
+			return fmt.Errorf("per-namespace Kong workspaces are not supported in DB-less mode")
+		}
+		dataplaneClient.SetWorkspaceResolver(&adminapi.WorkspaceResolver{
+			Reader:     mgr.GetClient(),
+			Annotation: c.WorkspaceNamespaceAnnotation,
+			Static:     c.WorkspaceNamespaceMap,
+		}, c.GetKongClientForWorkspace)
+	}
 
 	setupLog.Info("Initializing Dataplane Synchronizer")
 	synchronizer, err := setupDataplaneSynchronizer(setupLog, deprecatedLogger, mgr, dataplaneClient, c)
 	if err != nil {
 		return fmt.Errorf("unable to initialize dataplane synchronizer: %w", err)
 	}
+	if c.SyncDebounce > 0 {
+		dataplaneClient.SetChangeNotifier(synchronizer.NotifyChange)
+	}
 
 	var kubernetesStatusQueue *status.Queue
 	if c.UpdateStatus {
@@ -162,6 +230,44 @@ func Run(ctx context.Context, c *Config, diagnostic util.ConfigDumpDiagnostic) e
 	}); err != nil {
 		return fmt.Errorf("unable to setup readyz: %w", err)
 	}
+	readinessDetail := dataplane.NewReadinessDetailHandler(
+		dataplane.ReadinessGate{Name: "caches-synced", Check: func(ctx context.Context) error {
+			// a done context makes WaitForCacheSync report the current state instead of blocking
+			doneCtx, cancel := context.WithCancel(ctx)
+			cancel()
+			if !mgr.GetCache().WaitForCacheSync(doneCtx) {
+				return errors.New("caches not synced")
+			}
+			return nil
+		}},
+		dataplane.ReadinessGate{Name: "first-sync", Check: func(context.Context) error {
+			if !synchronizer.IsReady() {
+				return errors.New("first sync to the Kong Admin API pending")
+			}
+			return nil
+		}},
+		dataplane.ReadinessGate{Name: "admin-api", Check: func(context.Context) error {
+			if _, err := dataplaneClient.RootWithTimeout(); err != nil {
+				return fmt.Errorf("kong admin api unreachable: %w", err)
+			}
+			return nil
+		}},
+	)
+	// the health probe server only serves checks, so the readiness details are served by the metrics server
+	if err := mgr.AddMetricsExtraHandler("/readiness", readinessDetail); err != nil {
+		return fmt.Errorf("unable to setup readiness detail handler: %w", err)
+	}
+	if c.SyncStalenessWindow > 0 {
+		// the health probe server only serves checks, so the detailed sync readiness is served by the metrics server
+		syncReadiness := dataplane.NewSyncReadinessHandler(c.SyncStalenessWindow)
+		synchronizer.SetSyncReadinessHandler(syncReadiness)
+		if err := mgr.AddReadyzCheck("sync", syncReadiness.Check); err != nil {
+			return fmt.Errorf("unable to setup sync readyz: %w", err)
+		}
+		if err := mgr.AddMetricsExtraHandler("/sync-readiness", syncReadiness); err != nil {
+			return fmt.Errorf("unable to setup sync readiness handler: %w", err)
+		}
+	}
 
 	if c.AnonymousReports {
 		setupLog.Info("Starting anonymous reports")
@@ -123,6 +123,7 @@ func setupKongConfig(ctx context.Context, logger logr.Logger, c *Config) (sendco
 		URL:               c.KongAdminURL,
 		FilterTags:        filterTags,
 		Concurrency:       c.Concurrency,
+		DeleteBatchSize:   c.DeleteBatchSize,
 		Client:            kongClient,
 		PluginSchemaStore: util.NewPluginSchemaStore(kongClient),
 	}
@@ -137,17 +138,32 @@ func setupDataplaneSynchronizer(
 	dataplaneClient dataplane.Client,
 	c *Config,
 ) (*dataplane.Synchronizer, error) {
-	if c.ProxySyncSeconds < dataplane.DefaultSyncSeconds {
-		logger.Info(fmt.Sprintf("WARNING: --proxy-sync-seconds is configured for %fs, in DBLESS mode this may result in"+
-			" problems of inconsistency in the proxy state. For DBLESS mode %fs+ is recommended (3s is the default).",
-			c.ProxySyncSeconds, dataplane.DefaultSyncSeconds,
+	syncTickDuration := c.ProxySyncMaxInterval
+	if syncTickDuration == 0 {
+		var err error
+		syncTickDuration, err = time.ParseDuration(fmt.Sprintf("%gs", c.ProxySyncSeconds))
+		if err != nil {
+			logger.Error(err, "%s is not a valid number of seconds to stagger the proxy server synchronization")
+			return nil, err
+		}
+	}
+	if syncTickDuration < 0 {
+		return nil, fmt.Errorf("--proxy-sync-max-interval must not be negative, got %s", syncTickDuration)
+	}
+
+	defaultSyncTickDuration := time.Duration(dataplane.DefaultSyncSeconds * float32(time.Second))
+	if syncTickDuration < defaultSyncTickDuration {
+		logger.Info(fmt.Sprintf("WARNING: --proxy-sync-max-interval is configured for %s, in DBLESS mode this may result in"+
+			" problems of inconsistency in the proxy state. For DBLESS mode %s+ is recommended (3s is the default).",
+			syncTickDuration, defaultSyncTickDuration,
 		))
 	}
 
-	syncTickDuration, err := time.ParseDuration(fmt.Sprintf("%gs", c.ProxySyncSeconds))
-	if err != nil {
-		logger.Error(err, "%s is not a valid number of seconds to stagger the proxy server synchronization")
-		return nil, err
+	if c.InitialSyncDelay < 0 || c.InitialSyncDelay > dataplane.MaxInitialSyncDelay {
+		return nil, fmt.Errorf("--initial-sync-delay must be between 0s and %s, got %s", dataplane.MaxInitialSyncDelay, c.InitialSyncDelay)
+	}
+	if c.SyncDebounce < 0 || c.SyncDebounce > syncTickDuration {
+		return nil, fmt.Errorf("--sync-debounce must be between 0s and --proxy-sync-max-interval (%s), got %s", syncTickDuration, c.SyncDebounce)
 	}
 
 	dataplaneSynchronizer, err := dataplane.NewSynchronizerWithStagger(
@@ -158,6 +174,8 @@ func setupDataplaneSynchronizer(
 	if err != nil {
 		return nil, err
 	}
+	dataplaneSynchronizer.SetInitialSyncDelay(c.InitialSyncDelay)
+	dataplaneSynchronizer.SetSyncDebounce(c.SyncDebounce)
 
 	err = mgr.Add(dataplaneSynchronizer)
 	if err != nil {
@@ -224,21 +242,7 @@ func setupDataplaneAddressFinder(ctx context.Context, mgrc client.Client, c *Con
 					return nil, err
 				}
 
-				var addrs []string
-				switch svc.Spec.Type { //nolint:exhaustive
-				case corev1.ServiceTypeLoadBalancer:
-					for _, lbaddr := range svc.Status.LoadBalancer.Ingress {
-						if lbaddr.IP != "" {
-							addrs = append(addrs, lbaddr.IP)
-						}
-						if lbaddr.Hostname != "" {
-							addrs = append(addrs, lbaddr.Hostname)
-						}
-					}
-				default:
-					addrs = append(addrs, svc.Spec.ClusterIPs...)
-				}
-
+				addrs := dataplane.PublishServiceAddresses(svc)
 				if len(addrs) == 0 {
 					return nil, fmt.Errorf("waiting for addresses to be provisioned for publish service %s/%s", nsn.Namespace, nsn.Name)
 				}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// ClassOutcomeMatched indicates that an object was accepted because its ingress class matched.
+	ClassOutcomeMatched string = "matched"
+	// ClassOutcomeMatchedDefault indicates that a classless object was accepted because the controller's class is the default.
+	ClassOutcomeMatchedDefault string = "matched-default"
+	// ClassOutcomeDroppedMismatch indicates that an object was dropped because its ingress class did not match.
+	ClassOutcomeDroppedMismatch string = "dropped-mismatch"
+	// ClassOutcomeDroppedEmpty indicates that a classless object was dropped because the controller's class is not the default.
+	ClassOutcomeDroppedEmpty string = "dropped-empty"
+	// ClassOutcomeDroppedIgnored indicates that an Ingress was dropped because of its konghq.com/ignore annotation.
+	ClassOutcomeDroppedIgnored string = "dropped-ignored"
+
+	// KindKey defines the key of the metric label indicating the kind of the filtered object.
+	KindKey string = "kind"
+	// OutcomeKey defines the key of the metric label indicating the outcome of the class filtering.
+	OutcomeKey string = "outcome"
+)
+
+const (
+	MetricNameClassFilterCount = "ingress_controller_class_filter_count"
+)
+
+// ClassFilterCount is a Prometheus metric counting the outcomes of ingress class filtering.
+var ClassFilterCount = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: MetricNameClassFilterCount,
+		Help: "Count of objects accepted or dropped by ingress class filtering. `" +
+			KindKey + "` describes the kind of the object. `" +
+			OutcomeKey + "` describes whether the object was accepted (`" +
+			ClassOutcomeMatched + "` or `" + ClassOutcomeMatchedDefault + "`) or dropped (`" +
+			ClassOutcomeDroppedMismatch + "`, `" + ClassOutcomeDroppedEmpty + "` or `" + ClassOutcomeDroppedIgnored + "`).",
+	},
+	[]string{KindKey, OutcomeKey},
+)
+
+// RegisterClassMetrics registers the ingress class filtering metrics with the provided registry.
+func RegisterClassMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(ClassFilterCount)
+}
+
+// ClassFilterRecorder records ingress class filtering outcomes in ClassFilterCount.
+type ClassFilterRecorder struct{}
+
+// RecordClassMatch increments the counter for the provided object kind and outcome.
+func (ClassFilterRecorder) RecordClassMatch(kind, outcome string) {
+	ClassFilterCount.With(prometheus.Labels{KindKey: kind, OutcomeKey: outcome}).Inc()
+}
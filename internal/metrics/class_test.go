@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassFilterRecorder(t *testing.T) {
+	t.Log("registering the class metrics with a fresh registry")
+	reg := prometheus.NewRegistry()
+	RegisterClassMetrics(reg)
+
+	t.Log("verifying that every outcome increments its own counter")
+	recorder := ClassFilterRecorder{}
+	for _, outcome := range []string{
+		ClassOutcomeMatched,
+		ClassOutcomeMatchedDefault,
+		ClassOutcomeDroppedMismatch,
+		ClassOutcomeDroppedEmpty,
+		ClassOutcomeDroppedIgnored,
+	} {
+		counter := ClassFilterCount.With(prometheus.Labels{KindKey: "Ingress", OutcomeKey: outcome})
+		before := testutil.ToFloat64(counter)
+		recorder.RecordClassMatch("Ingress", outcome)
+		assert.Equal(t, before+1, testutil.ToFloat64(counter))
+	}
+
+	count, err := testutil.GatherAndCount(reg, MetricNameClassFilterCount)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, count)
+}
@@ -4,6 +4,7 @@ import (
 	"reflect"
 
 	apiv1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	"k8s.io/client-go/tools/cache"
@@ -33,10 +34,13 @@ type FakeObjects struct {
 	IngressesV1        []*networkingv1.Ingress
 	IngressClassesV1   []*networkingv1.IngressClass
 	HTTPRoute          []*gatewayv1alpha2.HTTPRoute
+	Gateways           []*gatewayv1alpha2.Gateway
+	ReferencePolicies  []*gatewayv1alpha2.ReferencePolicy
 	TCPIngresses       []*configurationv1beta1.TCPIngress
 	UDPIngresses       []*configurationv1beta1.UDPIngress
 	Services           []*apiv1.Service
 	Endpoints          []*apiv1.Endpoints
+	EndpointSlices     []*discoveryv1.EndpointSlice
 	Secrets            []*apiv1.Secret
 	KongPlugins        []*configurationv1.KongPlugin
 	KongClusterPlugins []*configurationv1.KongClusterPlugin
@@ -78,6 +82,18 @@ func NewFakeStore(
 			return nil, err
 		}
 	}
+	gatewayStore := cache.NewStore(keyFunc)
+	for _, gateway := range objects.Gateways {
+		if err := gatewayStore.Add(gateway); err != nil {
+			return nil, err
+		}
+	}
+	referencePolicyStore := cache.NewStore(keyFunc)
+	for _, policy := range objects.ReferencePolicies {
+		if err := referencePolicyStore.Add(policy); err != nil {
+			return nil, err
+		}
+	}
 	tcpIngressStore := cache.NewStore(keyFunc)
 	for _, ingress := range objects.TCPIngresses {
 		err := tcpIngressStore.Add(ingress)
@@ -112,6 +128,13 @@ func NewFakeStore(
 			return nil, err
 		}
 	}
+	endpointSliceStore := newEndpointSliceStore()
+	for _, e := range objects.EndpointSlices {
+		err := endpointSliceStore.Add(e)
+		if err != nil {
+			return nil, err
+		}
+	}
 	kongIngressStore := cache.NewStore(keyFunc)
 	for _, k := range objects.KongIngresses {
 		err := kongIngressStore.Add(k)
@@ -150,15 +173,18 @@ func NewFakeStore(
 	}
 	s = Store{
 		stores: CacheStores{
-			IngressV1beta1: ingressV1beta1Store,
-			IngressV1:      ingressV1Store,
-			IngressClassV1: ingressClassV1Store,
-			HTTPRoute:      httprouteStore,
-			TCPIngress:     tcpIngressStore,
-			UDPIngress:     udpIngressStore,
-			Service:        serviceStore,
-			Endpoint:       endpointStore,
-			Secret:         secretsStore,
+			IngressV1beta1:  ingressV1beta1Store,
+			IngressV1:       ingressV1Store,
+			IngressClassV1:  ingressClassV1Store,
+			HTTPRoute:       httprouteStore,
+			Gateway:         gatewayStore,
+			ReferencePolicy: referencePolicyStore,
+			TCPIngress:      tcpIngressStore,
+			UDPIngress:      udpIngressStore,
+			Service:         serviceStore,
+			Endpoint:        endpointStore,
+			EndpointSlice:   endpointSliceStore,
+			Secret:          secretsStore,
 
 			Plugin:        kongPluginsStore,
 			ClusterPlugin: kongClusterPluginsStore,
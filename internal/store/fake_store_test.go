@@ -5,7 +5,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	apiv1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -263,6 +265,39 @@ func TestFakeStoreIngressV1(t *testing.T) {
 	assert.Len(store.ListIngressesV1beta1(), 0)
 }
 
+func TestFakeStoreIgnoredIngress(t *testing.T) {
+	ingress := func(name string, anns map[string]string) metav1.ObjectMeta {
+		meta := metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Annotations: map[string]string{annotations.IngressClassKey: annotations.DefaultIngressClass},
+		}
+		for k, v := range anns {
+			meta.Annotations[k] = v
+		}
+		return meta
+	}
+	ignore := map[string]string{annotations.AnnotationPrefix + annotations.IgnoreKey: "true"}
+	fakeStore, err := NewFakeStore(FakeObjects{
+		IngressesV1: []*networkingv1.Ingress{
+			{ObjectMeta: ingress("kept", nil)},
+			{ObjectMeta: ingress("ignored", ignore)},
+		},
+		IngressesV1beta1: []*networkingv1beta1.Ingress{
+			{ObjectMeta: ingress("kept", nil)},
+			{ObjectMeta: ingress("ignored", ignore)},
+		},
+	})
+	require.NoError(t, err)
+
+	ingressesV1 := fakeStore.ListIngressesV1()
+	require.Len(t, ingressesV1, 1)
+	assert.Equal(t, "kept", ingressesV1[0].Name)
+	ingressesV1beta1 := fakeStore.ListIngressesV1beta1()
+	require.Len(t, ingressesV1beta1, 1)
+	assert.Equal(t, "kept", ingressesV1beta1[0].Name)
+}
+
 func TestFakeStoreIngressClassV1(t *testing.T) {
 	assert := assert.New(t)
 
@@ -489,6 +524,34 @@ func TestFakeStoreEndpiont(t *testing.T) {
 	assert.Nil(c)
 }
 
+func TestFakeStoreEndpointSlices(t *testing.T) {
+	slice := func(namespace, name, service string) *discoveryv1.EndpointSlice {
+		s := &discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		if service != "" {
+			s.Labels = map[string]string{discoveryv1.LabelServiceName: service}
+		}
+		return s
+	}
+	store, err := NewFakeStore(FakeObjects{EndpointSlices: []*discoveryv1.EndpointSlice{
+		slice("default", "foo-2", "foo"),
+		slice("default", "foo-1", "foo"),
+		slice("default", "bar-1", "bar"),
+		slice("other", "foo-1", "foo"),
+		slice("default", "unlabeled", ""),
+	}})
+	require.NoError(t, err)
+
+	slices, err := store.ListEndpointSlicesForService("default", "foo")
+	require.NoError(t, err)
+	require.Len(t, slices, 2)
+	assert.Equal(t, "foo-1", slices[0].Name)
+	assert.Equal(t, "foo-2", slices[1].Name)
+
+	slices, err = store.ListEndpointSlicesForService("default", "does-not-exist")
+	require.NoError(t, err)
+	assert.Empty(t, slices)
+}
+
 func TestFakeStoreConsumer(t *testing.T) {
 	assert := assert.New(t)
 
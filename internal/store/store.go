@@ -26,6 +26,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	extensions "k8s.io/api/extensions/v1beta1"
 	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
@@ -72,6 +73,7 @@ type Storer interface {
 	GetSecret(namespace, name string) (*corev1.Secret, error)
 	GetService(namespace, name string) (*corev1.Service, error)
 	GetEndpointsForService(namespace, name string) (*corev1.Endpoints, error)
+	ListEndpointSlicesForService(namespace, name string) ([]*discoveryv1.EndpointSlice, error)
 	GetKongIngress(namespace, name string) (*kongv1.KongIngress, error)
 	GetKongPlugin(namespace, name string) (*kongv1.KongPlugin, error)
 	GetKongClusterPlugin(name string) (*kongv1.KongClusterPlugin, error)
@@ -81,6 +83,8 @@ type Storer interface {
 	ListIngressesV1() []*networkingv1.Ingress
 	ListIngressClassesV1() []*networkingv1.IngressClass
 	ListHTTPRoutes() ([]*gatewayv1alpha2.HTTPRoute, error)
+	ListGateways() ([]*gatewayv1alpha2.Gateway, error)
+	ListReferencePolicies() ([]*gatewayv1alpha2.ReferencePolicy, error)
 	ListTCPIngresses() ([]*kongv1beta1.TCPIngress, error)
 	ListUDPIngresses() ([]*kongv1beta1.UDPIngress, error)
 	ListKnativeIngresses() ([]*knative.Ingress, error)
@@ -119,9 +123,12 @@ type CacheStores struct {
 	Service        cache.Store
 	Secret         cache.Store
 	Endpoint       cache.Store
+	EndpointSlice  cache.Indexer
 
 	// Gateway API Stores
-	HTTPRoute cache.Store
+	HTTPRoute       cache.Store
+	Gateway         cache.Store
+	ReferencePolicy cache.Store
 
 	// Kong Stores
 	Plugin        cache.Store
@@ -137,15 +144,41 @@ type CacheStores struct {
 	l *sync.RWMutex
 }
 
+// endpointSliceServiceIndex is the name of the index of EndpointSlices by the
+// 'namespace/name' key of the service they are labeled with.
+const endpointSliceServiceIndex = "service"
+
+// endpointSliceServiceIndexFunc indexes EndpointSlices by the 'namespace/name'
+// key of the service which their kubernetes.io/service-name label refers to.
+func endpointSliceServiceIndexFunc(obj interface{}) ([]string, error) {
+	slice, ok := obj.(*discoveryv1.EndpointSlice)
+	if !ok {
+		return nil, nil
+	}
+	name, ok := slice.Labels[discoveryv1.LabelServiceName]
+	if !ok {
+		return nil, nil
+	}
+	return []string{slice.Namespace + "/" + name}, nil
+}
+
+// newEndpointSliceStore returns a store of EndpointSlices indexed by service.
+func newEndpointSliceStore() cache.Indexer {
+	return cache.NewIndexer(keyFunc, cache.Indexers{endpointSliceServiceIndex: endpointSliceServiceIndexFunc})
+}
+
 // NewCacheStores is a convenience function for CacheStores to initialize all attributes with new cache stores
 func NewCacheStores() (c CacheStores) {
 	c.ClusterPlugin = cache.NewStore(clusterResourceKeyFunc)
 	c.Consumer = cache.NewStore(keyFunc)
 	c.Endpoint = cache.NewStore(keyFunc)
+	c.EndpointSlice = newEndpointSliceStore()
 	c.IngressV1 = cache.NewStore(keyFunc)
 	c.IngressClassV1 = cache.NewStore(keyFunc)
 	c.IngressV1beta1 = cache.NewStore(keyFunc)
 	c.HTTPRoute = cache.NewStore(keyFunc)
+	c.Gateway = cache.NewStore(keyFunc)
+	c.ReferencePolicy = cache.NewStore(keyFunc)
 	c.KnativeIngress = cache.NewStore(keyFunc)
 	c.Plugin = cache.NewStore(keyFunc)
 	c.Secret = cache.NewStore(keyFunc)
@@ -222,11 +255,17 @@ func (c CacheStores) Get(obj runtime.Object) (item interface{}, exists bool, err
 		return c.Secret.Get(obj)
 	case *corev1.Endpoints:
 		return c.Endpoint.Get(obj)
+	case *discoveryv1.EndpointSlice:
+		return c.EndpointSlice.Get(obj)
 	// ----------------------------------------------------------------------------
 	// Kubernetes Gateway API Support
 	// ----------------------------------------------------------------------------
 	case *gatewayv1alpha2.HTTPRoute:
 		return c.HTTPRoute.Get(obj)
+	case *gatewayv1alpha2.Gateway:
+		return c.Gateway.Get(obj)
+	case *gatewayv1alpha2.ReferencePolicy:
+		return c.ReferencePolicy.Get(obj)
 	// ----------------------------------------------------------------------------
 	// Kong API Support
 	// ----------------------------------------------------------------------------
@@ -275,11 +314,17 @@ func (c CacheStores) Add(obj runtime.Object) error {
 		return c.Secret.Add(obj)
 	case *corev1.Endpoints:
 		return c.Endpoint.Add(obj)
+	case *discoveryv1.EndpointSlice:
+		return c.EndpointSlice.Add(obj)
 	// ----------------------------------------------------------------------------
 	// Kubernetes Gateway API Support
 	// ----------------------------------------------------------------------------
 	case *gatewayv1alpha2.HTTPRoute:
 		return c.HTTPRoute.Add(obj)
+	case *gatewayv1alpha2.Gateway:
+		return c.Gateway.Add(obj)
+	case *gatewayv1alpha2.ReferencePolicy:
+		return c.ReferencePolicy.Add(obj)
 	// ----------------------------------------------------------------------------
 	// Kong API Support
 	// ----------------------------------------------------------------------------
@@ -329,11 +374,17 @@ func (c CacheStores) Delete(obj runtime.Object) error {
 		return c.Secret.Delete(obj)
 	case *corev1.Endpoints:
 		return c.Endpoint.Delete(obj)
+	case *discoveryv1.EndpointSlice:
+		return c.EndpointSlice.Delete(obj)
 	// ----------------------------------------------------------------------------
 	// Kubernetes Gateway API Support
 	// ----------------------------------------------------------------------------
 	case *gatewayv1alpha2.HTTPRoute:
 		return c.HTTPRoute.Delete(obj)
+	case *gatewayv1alpha2.Gateway:
+		return c.Gateway.Delete(obj)
+	case *gatewayv1alpha2.ReferencePolicy:
+		return c.ReferencePolicy.Delete(obj)
 	// ----------------------------------------------------------------------------
 	// Kong API Support
 	// ----------------------------------------------------------------------------
@@ -428,6 +479,9 @@ func (s Store) ListIngressesV1() []*networkingv1.Ingress {
 			s.logger.Warnf("listIngressesV1: dropping object of unexpected type: %#v", item)
 			continue
 		}
+		if annotations.IsIgnored(ing.Annotations) {
+			continue
+		}
 		if ing.ObjectMeta.GetAnnotations()[annotations.IngressClassKey] != "" {
 			if !s.isValidIngressClass(&ing.ObjectMeta, s.ingressV1ClassMatching) {
 				continue
@@ -477,7 +531,7 @@ func (s Store) ListIngressesV1beta1() []*networkingv1beta1.Ingress {
 	var ingresses []*networkingv1beta1.Ingress
 	for _, item := range s.stores.IngressV1beta1.List() {
 		ing := s.networkingIngressV1Beta1(item)
-		if !s.isValidIngressClass(&ing.ObjectMeta, s.ingressV1Beta1ClassMatching) {
+		if annotations.IsIgnored(ing.Annotations) || !s.isValidIngressClass(&ing.ObjectMeta, s.ingressV1Beta1ClassMatching) {
 			continue
 		}
 		ingresses = append(ingresses, ing)
@@ -505,6 +559,43 @@ func (s Store) ListHTTPRoutes() ([]*gatewayv1alpha2.HTTPRoute, error) {
 	return httproutes, nil
 }
 
+// ListGateways returns the list of Gateways in the Gateway cache store, sorted
+// by namespace and name.
+func (s Store) ListGateways() ([]*gatewayv1alpha2.Gateway, error) {
+	var gateways []*gatewayv1alpha2.Gateway
+	if err := cache.ListAll(s.stores.Gateway, labels.NewSelector(),
+		func(ob interface{}) {
+			gateway, ok := ob.(*gatewayv1alpha2.Gateway)
+			if ok {
+				gateways = append(gateways, gateway)
+			}
+		},
+	); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(gateways, func(i, j int) bool {
+		return gateways[i].Namespace+"/"+gateways[i].Name < gateways[j].Namespace+"/"+gateways[j].Name
+	})
+	return gateways, nil
+}
+
+// ListReferencePolicies returns the list of ReferencePolicies in the
+// ReferencePolicy cache store.
+func (s Store) ListReferencePolicies() ([]*gatewayv1alpha2.ReferencePolicy, error) {
+	var policies []*gatewayv1alpha2.ReferencePolicy
+	if err := cache.ListAll(s.stores.ReferencePolicy, labels.NewSelector(),
+		func(ob interface{}) {
+			policy, ok := ob.(*gatewayv1alpha2.ReferencePolicy)
+			if ok {
+				policies = append(policies, policy)
+			}
+		},
+	); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
 // ListTCPIngresses returns the list of TCP Ingresses from
 // configuration.konghq.com group.
 func (s Store) ListTCPIngresses() ([]*kongv1beta1.TCPIngress, error) {
@@ -569,7 +660,7 @@ func (s Store) ListKnativeIngresses() ([]*knative.Ingress, error) {
 			// this is implemented directly in store as s.isValidIngressClass only checks the value of the
 			// kubernetes.io/ingress.class annotation (annotations.ingressClassKey), not
 			// networking.knative.dev/ingress.class (knativeIngressClassKey)
-			if ok && s.validKnativeIngressClass(&ing.ObjectMeta) {
+			if ok && !annotations.IsIgnored(ing.Annotations) && s.validKnativeIngressClass(&ing.ObjectMeta) {
 				ingresses = append(ingresses, ing)
 			}
 		})
@@ -598,6 +689,27 @@ func (s Store) GetEndpointsForService(namespace, name string) (*corev1.Endpoints
 	return eps.(*corev1.Endpoints), nil
 }
 
+// ListEndpointSlicesForService returns the EndpointSlices of the service
+// 'namespace/name' inside k8s, which are the ones labeled with its name.
+func (s Store) ListEndpointSlicesForService(namespace, name string) ([]*discoveryv1.EndpointSlice, error) {
+	items, err := s.stores.EndpointSlice.ByIndex(endpointSliceServiceIndex, fmt.Sprintf("%v/%v", namespace, name))
+	if err != nil {
+		return nil, err
+	}
+	slices := make([]*discoveryv1.EndpointSlice, 0, len(items))
+	for _, item := range items {
+		slice, ok := item.(*discoveryv1.EndpointSlice)
+		if !ok {
+			continue
+		}
+		slices = append(slices, slice)
+	}
+	sort.SliceStable(slices, func(i, j int) bool {
+		return slices[i].Name < slices[j].Name
+	})
+	return slices, nil
+}
+
 // GetKongPlugin returns the 'name' KongPlugin resource in namespace.
 func (s Store) GetKongPlugin(namespace, name string) (*kongv1.KongPlugin, error) {
 	key := fmt.Sprintf("%v/%v", namespace, name)
@@ -806,11 +918,17 @@ func mkObjFromGVK(gvk schema.GroupVersionKind) (runtime.Object, error) {
 		return &corev1.Secret{}, nil
 	case corev1.SchemeGroupVersion.WithKind("Endpoints"):
 		return &corev1.Endpoints{}, nil
+	case discoveryv1.SchemeGroupVersion.WithKind("EndpointSlice"):
+		return &discoveryv1.EndpointSlice{}, nil
 	// ----------------------------------------------------------------------------
 	// Kubernetes Gateway APIs
 	// ----------------------------------------------------------------------------
 	case gatewayv1alpha2.SchemeGroupVersion.WithKind("HTTPRoutes"):
 		return &gatewayv1alpha2.HTTPRoute{}, nil
+	case gatewayv1alpha2.SchemeGroupVersion.WithKind("Gateway"):
+		return &gatewayv1alpha2.Gateway{}, nil
+	case gatewayv1alpha2.SchemeGroupVersion.WithKind("ReferencePolicy"):
+		return &gatewayv1alpha2.ReferencePolicy{}, nil
 	// ----------------------------------------------------------------------------
 	// Kong APIs
 	// ----------------------------------------------------------------------------
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/kong/kubernetes-ingress-controller/v2/internal/annotations"
 )
 
 // we currently implement two different loggers and use a middleware called
@@ -59,6 +61,39 @@ func MakeLogger(level string, formatter string) (logrus.FieldLogger, error) {
 	return log, nil
 }
 
+// WithObjectDebugLevel returns a logger which logs at debug level regardless of the level of
+// the provided logger if the provided object annotations enable debug logging via the
+// konghq.com/debug annotation. Otherwise the provided logger is returned as is.
+func WithObjectDebugLevel(log logrus.FieldLogger, anns map[string]string) logrus.FieldLogger {
+	if !annotations.ExtractDebug(anns) {
+		return log
+	}
+
+	var base *logrus.Logger
+	fields := logrus.Fields{}
+	switch l := log.(type) {
+	case *logrus.Logger:
+		base = l
+	case *logrus.Entry:
+		base = l.Logger
+		fields = l.Data
+	default:
+		return log
+	}
+	if base.IsLevelEnabled(logrus.DebugLevel) {
+		return log
+	}
+
+	debugLog := logrus.New()
+	debugLog.Out = base.Out
+	debugLog.Formatter = base.Formatter
+	debugLog.Hooks = base.Hooks
+	debugLog.ReportCaller = base.ReportCaller
+	debugLog.ExitFunc = base.ExitFunc
+	debugLog.Level = logrus.DebugLevel
+	return debugLog.WithFields(fields)
+}
+
 func getLogrusLevel(level string) (logrus.Level, error) {
 	res, ok := logrusLevels[level]
 	if !ok {
@@ -2,6 +2,7 @@ package util
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // K8sObjectInfo describes a Kubernetes object.
@@ -9,6 +10,9 @@ type K8sObjectInfo struct {
 	Name        string
 	Namespace   string
 	Annotations map[string]string
+	// GroupVersionKind is the kind of the object, which tells apart objects
+	// of different kinds with the same namespace and name.
+	GroupVersionKind schema.GroupVersionKind
 }
 
 func deepCopy(m map[string]string) map[string]string {
@@ -19,10 +23,14 @@ func deepCopy(m map[string]string) map[string]string {
 	return result
 }
 
-func FromK8sObject(obj metav1.Object) K8sObjectInfo {
+// FromK8sObject describes the provided object of the provided kind. The kind
+// is provided separately as objects retrieved from caches usually have an
+// empty TypeMeta.
+func FromK8sObject(obj metav1.Object, gvk schema.GroupVersionKind) K8sObjectInfo {
 	return K8sObjectInfo{
-		Name:        obj.GetName(),
-		Namespace:   obj.GetNamespace(),
-		Annotations: deepCopy(obj.GetAnnotations()),
+		Name:             obj.GetName(),
+		Namespace:        obj.GetNamespace(),
+		Annotations:      deepCopy(obj.GetAnnotations()),
+		GroupVersionKind: gvk,
 	}
 }
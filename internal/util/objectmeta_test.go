@@ -23,9 +23,10 @@ func TestFromK8sObject(t *testing.T) {
 				},
 			},
 			want: K8sObjectInfo{
-				Name:        "name",
-				Namespace:   "namespace",
-				Annotations: map[string]string{},
+				Name:             "name",
+				Namespace:        "namespace",
+				Annotations:      map[string]string{},
+				GroupVersionKind: networkingv1beta1.SchemeGroupVersion.WithKind("Ingress"),
 			},
 		},
 		{
@@ -38,14 +39,15 @@ func TestFromK8sObject(t *testing.T) {
 				},
 			},
 			want: K8sObjectInfo{
-				Name:        "name",
-				Namespace:   "namespace",
-				Annotations: map[string]string{"a": "1", "b": "2"},
+				Name:             "name",
+				Namespace:        "namespace",
+				Annotations:      map[string]string{"a": "1", "b": "2"},
+				GroupVersionKind: networkingv1beta1.SchemeGroupVersion.WithKind("Ingress"),
 			},
 		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
-			got := FromK8sObject(tt.in)
+			got := FromK8sObject(tt.in, networkingv1beta1.SchemeGroupVersion.WithKind("Ingress"))
 			assert.Equal(t, tt.want, got)
 		})
 	}
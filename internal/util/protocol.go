@@ -8,4 +8,16 @@ func ValidateProtocol(protocol string) bool {
 	return match
 }
 
+// ValidateServiceProtocol returns a bool of whether string is a protocol which Kong services can use to connect to
+// their upstreams, which are the valid protocols besides tls_passthrough, and ws and wss.
+func ValidateServiceProtocol(protocol string) bool {
+	switch protocol {
+	case "ws", "wss":
+		return true
+	case "tls_passthrough":
+		return false
+	}
+	return ValidateProtocol(protocol)
+}
+
 var validProtocols = regexp.MustCompile(`\Ahttps$|\Ahttp$|\Agrpc$|\Agrpcs|\Atcp|\Atls|\Atls_passthrough$`)
@@ -26,3 +26,25 @@ func TestValidateProtocol(t *testing.T) {
 		assert.Equal(isMatch, testcase.result)
 	}
 }
+
+func TestValidateServiceProtocol(t *testing.T) {
+	assert := assert.New(t)
+	testTable := []struct {
+		input  string
+		result bool
+	}{
+		{"http", true},
+		{"https", true},
+		{"grpc", true},
+		{"grpcs", true},
+		{"tls", true},
+		{"tcp", true},
+		{"ws", true},
+		{"wss", true},
+		{"tls_passthrough", false},
+		{"udp", false},
+	}
+	for _, testcase := range testTable {
+		assert.Equal(testcase.result, ValidateServiceProtocol(testcase.input), testcase.input)
+	}
+}
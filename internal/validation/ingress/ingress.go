@@ -0,0 +1,146 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	netv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// -----------------------------------------------------------------------------
+// Validation - Ingress - Public Functions
+// -----------------------------------------------------------------------------
+
+// ValidateIngressHostUniqueness validates that no host and path pair is declared
+// more than once across the rules of an Ingress: the routes translated from such
+// rules would collide and only one of them would be effective. The returned error
+// lists all the conflicting host and path pairs.
+func ValidateIngressHostUniqueness(ing *netv1.Ingress) error {
+	seen := make(map[string]struct{})
+	reported := make(map[string]struct{})
+	var conflicts []string
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			pair := hostPathPair(rule.Host, path.Path)
+			if _, ok := seen[pair]; !ok {
+				seen[pair] = struct{}{}
+				continue
+			}
+			if _, ok := reported[pair]; !ok {
+				reported[pair] = struct{}{}
+				conflicts = append(conflicts, pair)
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("ingress %s/%s declares the following host and path pairs more than once: %s",
+			ing.Namespace, ing.Name, strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// ValidateIngressClassExists validates that the IngressClass with the provided
+// name exists, so that admission webhooks can warn about Ingresses referencing
+// an absent class in their .spec. An empty class name is valid, as classless
+// Ingresses don't reference any IngressClass. Errors other than the class not
+// being found are returned.
+func ValidateIngressClassExists(ctx context.Context, c client.Reader, className string) (bool, error) {
+	if className == "" {
+		return true, nil
+	}
+	if err := c.Get(ctx, client.ObjectKey{Name: className}, &netv1.IngressClass{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get IngressClass %s: %w", className, err)
+	}
+	return true, nil
+}
+
+// FindIngressHostPathConflicts lists the host and path pairs of an Ingress
+// which are already declared by other Ingresses: the routes translated from
+// them would collide in Kong. Only the Ingresses accepted by sameClass are
+// considered, and the Ingress itself is skipped so that updates don't conflict
+// with its current version. Conflicts are provided as "<host><path> (<namespace>/<name>)",
+// naming the Ingress which already declares the pair, in the order of the rules
+// of the Ingress.
+func FindIngressHostPathConflicts(
+	ctx context.Context,
+	c client.Reader,
+	ing *netv1.Ingress,
+	sameClass func(*netv1.Ingress) bool,
+) ([]string, error) {
+	ingresses := &netv1.IngressList{}
+	if err := c.List(ctx, ingresses); err != nil {
+		return nil, fmt.Errorf("failed to list Ingresses: %w", err)
+	}
+	sort.SliceStable(ingresses.Items, func(i, j int) bool {
+		a, b := ingresses.Items[i], ingresses.Items[j]
+		return a.Namespace < b.Namespace || (a.Namespace == b.Namespace && a.Name < b.Name)
+	})
+
+	owners := make(map[string]string)
+	for i := range ingresses.Items {
+		other := &ingresses.Items[i]
+		if (other.Namespace == ing.Namespace && other.Name == ing.Name) || !sameClass(other) {
+			continue
+		}
+		for _, pair := range hostPathPairs(other) {
+			if _, ok := owners[pair]; !ok {
+				owners[pair] = other.Namespace + "/" + other.Name
+			}
+		}
+	}
+
+	var conflicts []string
+	reported := make(map[string]struct{})
+	for _, pair := range hostPathPairs(ing) {
+		owner, ok := owners[pair]
+		if !ok {
+			continue
+		}
+		if _, ok := reported[pair]; !ok {
+			reported[pair] = struct{}{}
+			conflicts = append(conflicts, fmt.Sprintf("%s (%s)", pair, owner))
+		}
+	}
+	return conflicts, nil
+}
+
+// -----------------------------------------------------------------------------
+// Validation - Ingress - Private Functions
+// -----------------------------------------------------------------------------
+
+// hostPathPair provides a readable representation of a host and path pair, rules
+// without a host match any host.
+func hostPathPair(host, path string) string {
+	if host == "" {
+		host = "*"
+	}
+	if path == "" {
+		path = "/"
+	}
+	return host + path
+}
+
+// hostPathPairs provides the host and path pairs of the rules of an Ingress.
+func hostPathPairs(ing *netv1.Ingress) []string {
+	var pairs []string
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			pairs = append(pairs, hostPathPair(rule.Host, path.Path))
+		}
+	}
+	return pairs
+}
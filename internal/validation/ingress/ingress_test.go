@@ -0,0 +1,157 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateIngressHostUniqueness(t *testing.T) {
+	rule := func(host string, paths ...string) netv1.IngressRule {
+		httpPaths := make([]netv1.HTTPIngressPath, 0, len(paths))
+		for _, path := range paths {
+			httpPaths = append(httpPaths, netv1.HTTPIngressPath{Path: path})
+		}
+		return netv1.IngressRule{
+			Host: host,
+			IngressRuleValue: netv1.IngressRuleValue{
+				HTTP: &netv1.HTTPIngressRuleValue{Paths: httpPaths},
+			},
+		}
+	}
+
+	for _, tt := range []struct {
+		name    string
+		rules   []netv1.IngressRule
+		wantErr string
+	}{
+		{
+			name: "all unique rules",
+			rules: []netv1.IngressRule{
+				rule("foo.example.com", "/foo"),
+				rule("bar.example.com", "/foo"),
+				rule("", "/foo"),
+			},
+		},
+		{
+			name: "duplicate host with different paths",
+			rules: []netv1.IngressRule{
+				rule("foo.example.com", "/foo"),
+				rule("foo.example.com", "/bar"),
+			},
+		},
+		{
+			name: "duplicate host and path",
+			rules: []netv1.IngressRule{
+				rule("foo.example.com", "/foo", "/bar"),
+				rule("foo.example.com", "/foo"),
+				rule("foo.example.com", "/foo"),
+			},
+			wantErr: "ingress default/test declares the following host and path pairs more than once: foo.example.com/foo",
+		},
+		{
+			name: "duplicate paths within a rule and without a host",
+			rules: []netv1.IngressRule{
+				rule("", "/foo", "/foo"),
+				rule("bar.example.com", "", "/"),
+			},
+			wantErr: "ingress default/test declares the following host and path pairs more than once: */foo, bar.example.com/",
+		},
+		{
+			name:  "rule without paths",
+			rules: []netv1.IngressRule{{Host: "foo.example.com"}, {Host: "foo.example.com"}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ing := &netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "test"},
+				Spec:       netv1.IngressSpec{Rules: tt.rules},
+			}
+			err := ValidateIngressHostUniqueness(ing)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateIngressClassExists(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, netv1.AddToScheme(scheme))
+
+	t.Log("verifying that a class is reported as absent without an IngressClass")
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	exists, err := ValidateIngressClassExists(ctx, c, "kong")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	t.Log("verifying that an empty class is valid")
+	exists, err = ValidateIngressClassExists(ctx, c, "")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	t.Log("verifying that a class is reported as present with an IngressClass")
+	c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(&netv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "kong"},
+	}).Build()
+	exists, err = ValidateIngressClassExists(ctx, c, "kong")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = ValidateIngressClassExists(ctx, c, "nginx")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	t.Log("verifying that errors other than the class not being found are returned")
+	_, err = ValidateIngressClassExists(ctx, fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build(), "kong")
+	assert.Error(t, err)
+}
+
+func TestFindIngressHostPathConflicts(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, netv1.AddToScheme(scheme))
+	ingress := func(namespace, name string, paths ...string) *netv1.Ingress {
+		httpPaths := make([]netv1.HTTPIngressPath, 0, len(paths))
+		for _, path := range paths {
+			httpPaths = append(httpPaths, netv1.HTTPIngressPath{Path: path})
+		}
+		return &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: netv1.IngressSpec{Rules: []netv1.IngressRule{{
+				Host:             "example.com",
+				IngressRuleValue: netv1.IngressRuleValue{HTTP: &netv1.HTTPIngressRuleValue{Paths: httpPaths}},
+			}}},
+		}
+	}
+	all := func(*netv1.Ingress) bool { return true }
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		ingress("b", "second", "/foo", "/bar"),
+		ingress("a", "first", "/foo"),
+	).Build()
+
+	t.Log("verifying that conflicts name the first ingress declaring the pair")
+	conflicts, err := FindIngressHostPathConflicts(ctx, c, ingress("a", "new", "/bar", "/foo", "/baz"), all)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/bar (b/second)", "example.com/foo (a/first)"}, conflicts)
+
+	t.Log("verifying that the ingress itself and ingresses rejected by the filter are skipped")
+	conflicts, err = FindIngressHostPathConflicts(ctx, c, ingress("b", "second", "/foo", "/bar"), func(other *netv1.Ingress) bool {
+		return other.Name != "first"
+	})
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	t.Log("verifying that listing errors are returned")
+	_, err = FindIngressHostPathConflicts(ctx, fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build(), ingress("a", "new", "/foo"), all)
+	assert.Error(t, err)
+}